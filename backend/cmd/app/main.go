@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"math/big"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,9 +17,14 @@ import (
 	"github.com/Pyegorchik/bdd/backend/internal/repository/postgres"
 	"github.com/Pyegorchik/bdd/backend/internal/server"
 	"github.com/Pyegorchik/bdd/backend/internal/service"
+	"github.com/Pyegorchik/bdd/backend/pkg/blobstore"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/failover"
 	"github.com/Pyegorchik/bdd/backend/pkg/hash"
 	"github.com/Pyegorchik/bdd/backend/pkg/jwtoken"
 	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func main() {
@@ -27,6 +34,12 @@ func main() {
 	}
 	defer logging.Sync()
 
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		code := runReconcile(logging, os.Args[2:])
+		logging.Sync()
+		os.Exit(code)
+	}
+
 	var cfgPath string
 
 	flag.StringVar(&cfgPath, "cfg", "", "")
@@ -50,7 +63,35 @@ func main() {
 		logging.Panic(err)
 	}
 
-	bddService, err := service.NewService(bddRepos, jwtokenManager, hash.NewHashManager(), cfg.Service, logging)
+	blobStore, err := newBlobStore(cfg.Service.Attachments)
+	if err != nil {
+		logging.Panic(err)
+	}
+
+	chains, err := newChainRegistry(ctx, cfg.Service)
+	if err != nil {
+		logging.Panic(err)
+	}
+	chainBackend := primaryChainBackend(chains, cfg.Service.Chain)
+
+	chainParts, err := newChain(chainBackend, cfg.Service)
+	if err != nil {
+		logging.Panic(err)
+	}
+
+	// token balances are read through the same failover backend, cached so gate checks don't hit the node per message
+	var holdings chain.Holdings
+	if chainBackend != nil {
+		holdings = chain.NewCachedHoldings(chain.NewHoldings(chainBackend), cfg.Service.TokenGate.CacheTTL)
+	}
+
+	names, err := newNames(chains, cfg.Service)
+	if err != nil {
+		logging.Panic(err)
+	}
+
+	bddService, err := service.NewService(bddRepos, jwtokenManager, hash.NewHashManager(), blobStore, chainParts.client,
+		chainParts.events, chainParts.anchorer, holdings, chainParts.payments, names, cfg.Service, logging)
 	if err != nil {
 		logging.Panic(err)
 	}
@@ -76,4 +117,145 @@ func main() {
 	}
 
 	bddService.Shutdown()
-}
\ No newline at end of file
+	chains.Close()
+}
+
+func newBlobStore(cfg *config.AttachmentsConfig) (blobstore.BlobStore, error) {
+	switch cfg.Storage {
+	case "s3":
+		return blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}, nil)
+	case "local", "":
+		return blobstore.NewLocalStore(cfg.LocalPath)
+	default:
+		return nil, fmt.Errorf("newBlobStore: unknown storage %q", cfg.Storage)
+	}
+}
+
+// newChainRegistry dials every supported chain that has endpoints, they share the RPC settings of the chain config.
+func newChainRegistry(ctx context.Context, cfg *config.ServiceConfig) (*failover.Registry, error) {
+	endpoints := make(map[int64][]string, len(cfg.Chains))
+	for _, c := range cfg.Chains {
+		endpoints[c.ChainID] = c.RPCURLs
+	}
+	return failover.DialRegistry(ctx, endpoints, failover.Config{
+		HedgeDelay:     cfg.Chain.RPC.HedgeDelay,
+		MaxHeadLag:     cfg.Chain.RPC.MaxHeadLag,
+		HealthInterval: cfg.Chain.RPC.HealthInterval,
+	})
+}
+
+// primaryChainBackend returns nil when no node is configured for the contract's chain, on-chain sending
+// and indexing are disabled then.
+func primaryChainBackend(chains *failover.Registry, cfg *config.ChainConfig) *failover.Client {
+	client, err := chains.Client(cfg.ChainID)
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// newNames returns nil when no name registry is configured. The registry may be on another supported
+// chain than the contract, mainnet ENS usually is.
+func newNames(chains *failover.Registry, cfg *config.ServiceConfig) (chain.Names, error) {
+	if cfg.Names.RegistryAddress == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(cfg.Names.RegistryAddress) {
+		return nil, fmt.Errorf("newNames: invalid registry address %q", cfg.Names.RegistryAddress)
+	}
+	chainID := cfg.Names.ChainID
+	if chainID == 0 {
+		chainID = cfg.Chain.ChainID
+	}
+	backend, err := chains.Client(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("newNames/Client: chain %d: %w", chainID, err)
+	}
+	names, err := chain.NewNames(backend, common.HexToAddress(cfg.Names.RegistryAddress))
+	if err != nil {
+		return nil, fmt.Errorf("newNames/NewNames: %w", err)
+	}
+	return chain.NewCachedNames(names, cfg.Names.CacheTTL), nil
+}
+
+// chainParts are the chain integrations the service runs with, each is nil when it isn't configured.
+type chainParts struct {
+	client   chain.Client
+	events   chain.EventSource
+	anchorer chain.Anchorer
+	payments chain.Payments
+}
+
+// newChain runs only the indexer and payment checks without a relayer key. Anchoring also needs the anchor
+// contract address, the relayer is shared so the contracts' transactions don't race for nonces. Escrow
+// refunds need the relayer to be the escrow operator.
+func newChain(backend *failover.Client, cfg *config.ServiceConfig) (*chainParts, error) {
+	parts := &chainParts{}
+	if backend == nil {
+		return parts, nil
+	}
+	if !common.IsHexAddress(cfg.Chain.ContractAddress) {
+		return nil, fmt.Errorf("newChain: invalid contract address %q", cfg.Chain.ContractAddress)
+	}
+	contract := common.HexToAddress(cfg.Chain.ContractAddress)
+	var escrow common.Address
+	if cfg.Payments.EscrowAddress != "" {
+		if !common.IsHexAddress(cfg.Payments.EscrowAddress) {
+			return nil, fmt.Errorf("newChain: invalid escrow contract address %q", cfg.Payments.EscrowAddress)
+		}
+		escrow = common.HexToAddress(cfg.Payments.EscrowAddress)
+	}
+
+	var err error
+	parts.events, err = chain.NewEventSource(backend, contract)
+	if err != nil {
+		return nil, fmt.Errorf("newChain/NewEventSource: %w", err)
+	}
+	if cfg.Chain.RelayerKey == "" {
+		parts.payments, err = chain.NewPayments(backend, big.NewInt(cfg.Chain.ChainID), escrow, nil)
+		if err != nil {
+			return nil, fmt.Errorf("newChain/NewPayments: %w", err)
+		}
+		return parts, nil
+	}
+
+	relayerKey, err := chain.ParseRelayerKey(cfg.Chain.RelayerKey)
+	if err != nil {
+		return nil, fmt.Errorf("newChain/ParseRelayerKey: %w", err)
+	}
+	gas := chain.GasPolicy{BumpPercent: cfg.Chain.Gas.BumpPercent}
+	if cfg.Chain.Gas.MaxFeeGwei > 0 {
+		gas.MaxFeePerGas = new(big.Int).Mul(big.NewInt(cfg.Chain.Gas.MaxFeeGwei), big.NewInt(params.GWei))
+	}
+	relayer, err := chain.NewRelayer(backend, relayerKey, big.NewInt(cfg.Chain.ChainID), gas)
+	if err != nil {
+		return nil, fmt.Errorf("newChain/NewRelayer: %w", err)
+	}
+	parts.client, err = chain.NewClient(backend, contract, relayer)
+	if err != nil {
+		return nil, fmt.Errorf("newChain/NewClient: %w", err)
+	}
+	parts.payments, err = chain.NewPayments(backend, big.NewInt(cfg.Chain.ChainID), escrow, relayer)
+	if err != nil {
+		return nil, fmt.Errorf("newChain/NewPayments: %w", err)
+	}
+	if cfg.Anchor.ContractAddress == "" {
+		return parts, nil
+	}
+
+	if !common.IsHexAddress(cfg.Anchor.ContractAddress) {
+		return nil, fmt.Errorf("newChain: invalid anchor contract address %q", cfg.Anchor.ContractAddress)
+	}
+	parts.anchorer, err = chain.NewAnchorer(backend, common.HexToAddress(cfg.Anchor.ContractAddress), relayer)
+	if err != nil {
+		return nil, fmt.Errorf("newChain/NewAnchorer: %w", err)
+	}
+
+	return parts, nil
+}
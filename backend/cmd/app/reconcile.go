@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/internal/repository/postgres"
+	"github.com/Pyegorchik/bdd/backend/internal/service"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type reconcileSummary struct {
+	Pairs         int64            `json:"pairs"`
+	Messages      int64            `json:"messages"`
+	ChainMessages int64            `json:"chain_messages"`
+	Diffs         map[string]int64 `json:"diffs"`
+	Repaired      int64            `json:"repaired"`
+}
+
+type reconcileDiff struct {
+	Kind         string  `json:"kind"`
+	Sender       string  `json:"sender"`
+	Receiver     string  `json:"receiver"`
+	MessageID    *int64  `json:"message_id,omitempty"`
+	DialogID     *int64  `json:"dialog_id,omitempty"`
+	ChainIndex   *int64  `json:"chain_index,omitempty"`
+	DBContent    *string `json:"db_content,omitempty"`
+	ChainContent *string `json:"chain_content,omitempty"`
+	Repaired     bool    `json:"repaired"`
+	Note         string  `json:"note,omitempty"`
+}
+
+type reconcileOutput struct {
+	Summary reconcileSummary `json:"summary"`
+	Diffs   []reconcileDiff  `json:"diffs"`
+}
+
+// runReconcile compares the database with the Messaging contract and prints a JSON report.
+// It exits with 1 on errors and 2 when differences are left unrepaired.
+func runReconcile(logging logger.Logger, args []string) int {
+	var (
+		cfgPath string
+		outPath string
+		repair  bool
+	)
+
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	flags.StringVar(&cfgPath, "cfg", "", "")
+	flags.StringVar(&outPath, "out", "", "file the report is written to, stdout when empty")
+	flags.BoolVar(&repair, "repair", false, "fix the database side of the differences")
+	flags.Parse(args)
+
+	report, err := reconcile(cfgPath, repair, logging)
+	if err != nil {
+		logging.Errorf("reconcile: %v", err)
+		return 1
+	}
+
+	out := io.Writer(os.Stdout)
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			logging.Errorf("reconcile: %v", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	output := reconcileReportToOutput(report)
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(output); err != nil {
+		logging.Errorf("reconcile: %v", err)
+		return 1
+	}
+
+	if int64(len(output.Diffs)) > output.Summary.Repaired {
+		return 2
+	}
+	return 0
+}
+
+func reconcile(cfgPath string, repair bool, logging logger.Logger) (*domain.ReconcileReport, error) {
+	cfg, err := config.Init(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile/Init: %w", err)
+	}
+	if len(cfg.Service.Chain.RPCURLs) == 0 {
+		return nil, errors.New("reconcile: no rpcURLs are configured")
+	}
+	if !common.IsHexAddress(cfg.Service.Chain.ContractAddress) {
+		return nil, fmt.Errorf("reconcile: invalid contract address %q", cfg.Service.Chain.ContractAddress)
+	}
+	ctx := context.Background()
+
+	// the relayer's address is enough, messages it sent are stored under it
+	var relayer common.Address
+	if cfg.Service.Chain.RelayerKey != "" {
+		key, err := chain.ParseRelayerKey(cfg.Service.Chain.RelayerKey)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile/ParseRelayerKey: %w", err)
+		}
+		relayer = crypto.PubkeyToAddress(key.PublicKey)
+	}
+
+	pool, err := postgres.New(ctx, cfg.Postgres)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile/postgres.New: %w", err)
+	}
+	defer pool.Close()
+
+	repos, err := repository.NewRepository(cfg, pool)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile/NewRepository: %w", err)
+	}
+
+	chains, err := newChainRegistry(ctx, cfg.Service)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile/newChainRegistry: %w", err)
+	}
+	defer chains.Close()
+	backend := primaryChainBackend(chains, cfg.Service.Chain)
+
+	dialogues, err := chain.NewDialogueReader(backend, common.HexToAddress(cfg.Service.Chain.ContractAddress))
+	if err != nil {
+		return nil, fmt.Errorf("reconcile/NewDialogueReader: %w", err)
+	}
+
+	reconciler := service.NewReconciler(cfg.Service.Chain, dialogues, relayer, repos.Reconcile, repos.Dialogs,
+		repos.ChainIndexer, repos.Transactions, logging)
+
+	return reconciler.Reconcile(ctx, repair)
+}
+
+func reconcileReportToOutput(report *domain.ReconcileReport) *reconcileOutput {
+	output := &reconcileOutput{
+		Summary: reconcileSummary{
+			Pairs:         report.Pairs,
+			Messages:      report.Messages,
+			ChainMessages: report.ChainMessages,
+			Diffs:         make(map[string]int64),
+		},
+		Diffs: make([]reconcileDiff, 0, len(report.Diffs)),
+	}
+	for _, d := range report.Diffs {
+		diff := reconcileDiff{
+			Kind:         d.Kind.String(),
+			Sender:       d.Pair.Sender,
+			Receiver:     d.Pair.Receiver,
+			ChainContent: d.ChainContent,
+			Repaired:     d.Repaired,
+			Note:         d.Note,
+		}
+		if d.Message != nil {
+			diff.MessageID, diff.DialogID = &d.Message.MessageID, &d.Message.DialogID
+			if !d.Message.Deleted {
+				diff.DBContent = &d.Message.Content
+			}
+		}
+		if d.ChainIndex >= 0 {
+			diff.ChainIndex = &d.ChainIndex
+		}
+		output.Summary.Diffs[diff.Kind]++
+		if d.Repaired {
+			output.Summary.Repaired++
+		}
+		output.Diffs = append(output.Diffs, diff)
+	}
+	return output
+}
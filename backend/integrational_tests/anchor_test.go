@@ -0,0 +1,205 @@
+package integrationstests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/merkle"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func (s *TestSuiteUser) TestMessageProof() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	recepeintAddress := s.accounts[2].auth.From.String()
+
+	for _, content := range []string{"first", "second", "third"} {
+		content := content
+		err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &recepeintAddress,
+		}, nil)
+		s.Require().NoError(err)
+	}
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 3)
+	msg := (*resMessages)[1]
+
+	// Strangers to the dialog can't ask for the proof
+	strangerCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, strangerCookie, http.MethodGet,
+		fmt.Sprintf("/g1/messages/%d/proof", msg.MessageID), nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	var proof *models.MessageProof
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, cookie, http.MethodGet, fmt.Sprintf("/g1/messages/%d/proof", msg.MessageID), nil, &proof)
+		return err == nil && proof.Anchor.Status == models.ChainTxStatusConfirmed
+	}, 10*time.Second, 200*time.Millisecond)
+
+	// The proof checks out against the message as the API returned it and the root on-chain
+	hash, err := domain.AnchoredMessageHash(&domain.Message{
+		ID:            msg.MessageID,
+		DialogID:      1,
+		SenderAddress: msg.SenderAddress,
+		CreatedAt:     msg.CreatedAt,
+		Content:       msg.Content,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(hash.Hex(), proof.MessageHash)
+	s.Require().Equal(domain.AnchorLeafHash(hash).Hex(), proof.Leaf)
+
+	siblings := make([]common.Hash, 0, len(proof.Proof))
+	for _, p := range proof.Proof {
+		siblings = append(siblings, common.HexToHash(p))
+	}
+	s.Require().True(merkle.Verify(siblings, common.HexToHash(proof.Root), common.HexToHash(proof.Leaf)))
+
+	root, ok := s.chain.anchored(proof.BatchID)
+	s.Require().True(ok)
+	s.Require().Equal(root.Hex(), proof.Root)
+	s.Require().NotEmpty(proof.Anchor.TxHash)
+
+	// Deleting the message doesn't invalidate its proof
+	err = makeJsonRequest(s.handler, cookie, http.MethodDelete, fmt.Sprintf("/g1/dialogs/1/messages/%d", msg.MessageID), nil, nil)
+	s.Require().NoError(err)
+	var afterDelete *models.MessageProof
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, fmt.Sprintf("/g1/messages/%d/proof", msg.MessageID), nil, &afterDelete)
+	s.Require().NoError(err)
+	s.Require().Equal(proof, afterDelete)
+}
+
+func (s *TestSuiteUser) TestAnchorWaitsForUnsettledMessages() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	recepeintAddress := s.accounts[2].auth.From.String()
+
+	// Hold the worker off while the first message is made to look unsettled
+	ctx := context.Background()
+	lock, err := s.pgxpool.Begin(ctx)
+	s.Require().NoError(err)
+	_, err = lock.Exec(ctx, `LOCK TABLE anchor_batches IN SHARE ROW EXCLUSIVE MODE`)
+	s.Require().NoError(err)
+
+	var messageIDs []int64
+	for _, content := range []string{"late", "early"} {
+		content := content
+		var resSend *models.SendMessageResponse
+		err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &recepeintAddress,
+		}, &resSend)
+		s.Require().NoError(err)
+		messageIDs = append(messageIDs, resSend.MessageID)
+	}
+	var createdAt int64
+	err = lock.QueryRow(ctx, `UPDATE messages SET created_at = created_at + 3600000 WHERE id = $1 RETURNING created_at`,
+		messageIDs[0]).Scan(&createdAt)
+	s.Require().NoError(err)
+	s.Require().NoError(lock.Commit(ctx))
+
+	// The settled message waits for the one before it
+	time.Sleep(time.Second)
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodGet,
+		fmt.Sprintf("/g1/messages/%d/proof", messageIDs[1]), nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusNotFound), resErr.Code)
+
+	_, err = s.pgxpool.Exec(ctx, `UPDATE messages SET created_at = $2 WHERE id = $1`, messageIDs[0], createdAt-3600000)
+	s.Require().NoError(err)
+
+	for _, id := range messageIDs {
+		var proof *models.MessageProof
+		s.Require().Eventually(func() bool {
+			err := makeJsonRequest(s.handler, cookie, http.MethodGet, fmt.Sprintf("/g1/messages/%d/proof", id), nil, &proof)
+			return err == nil
+		}, 10*time.Second, 200*time.Millisecond)
+	}
+}
+
+func (s *TestSuiteUser) TestAnchorRevertedIsRequeued() {
+	cookie, err := makeAuthRequest(s.handler, s.accounts[1])
+	s.Require().NoError(err)
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	recepeintAddress := s.accounts[2].auth.From.String()
+
+	// Batch the message by hand while the worker is held off, as if its anchor was sent and is still pending
+	ctx := context.Background()
+	lock, err := s.pgxpool.Begin(ctx)
+	s.Require().NoError(err)
+	_, err = lock.Exec(ctx, `LOCK TABLE anchor_batches IN SHARE ROW EXCLUSIVE MODE`)
+	s.Require().NoError(err)
+
+	content := "reverted"
+	var resSend *models.SendMessageResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}, &resSend)
+	s.Require().NoError(err)
+
+	msg := &domain.Message{ID: resSend.MessageID}
+	err = lock.QueryRow(ctx, `
+		SELECT m.dialog_id, u_c.address, m.created_at, m.content
+		FROM messages AS m
+		JOIN users_chain AS u_c ON u_c.id = m.sender_id
+		WHERE m.id = $1`, msg.ID).Scan(&msg.DialogID, &msg.SenderAddress, &msg.CreatedAt, &msg.Content)
+	s.Require().NoError(err)
+	hash, err := domain.AnchoredMessageHash(msg)
+	s.Require().NoError(err)
+	root := merkle.New([]common.Hash{domain.AnchorLeafHash(hash)}).Root()
+
+	var batchID int64
+	err = lock.QueryRow(ctx, `
+		INSERT INTO anchor_batches (root, first_message_id, last_message_id, size, status, tx_hash, created_at, updated_at)
+		VALUES ($1, $2, $2, 1, $3, $4, 0, 0)
+		RETURNING id`, root.Hex(), msg.ID, domain.ChainTxSubmitted, common.Hash{}.Hex()).Scan(&batchID)
+	s.Require().NoError(err)
+	_, err = lock.Exec(ctx, `INSERT INTO anchor_leaves (message_id, batch_id, leaf_index, message_hash) VALUES ($1, $2, 0, $3)`,
+		msg.ID, batchID, hash.Hex())
+	s.Require().NoError(err)
+	s.Require().NoError(lock.Commit(ctx))
+
+	// The batch isn't on-chain yet, so there is no proof
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodGet,
+		fmt.Sprintf("/g1/messages/%d/proof", msg.ID), nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusNotFound), resErr.Code)
+
+	// The anchor reverts, the batch is queued again and anchored by the worker
+	reverted := s.chain.revertedAnchor(batchID, root)
+	_, err = s.pgxpool.Exec(ctx, `UPDATE anchor_batches SET tx_hash = $2 WHERE id = $1`, batchID, reverted.Hex())
+	s.Require().NoError(err)
+
+	var proof *models.MessageProof
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, cookie, http.MethodGet, fmt.Sprintf("/g1/messages/%d/proof", msg.ID), nil, &proof)
+		return err == nil
+	}, 10*time.Second, 200*time.Millisecond)
+	s.Require().Equal(models.ChainTxStatusConfirmed, proof.Anchor.Status)
+	s.Require().NotEqual(reverted.Hex(), proof.Anchor.TxHash)
+	anchored, ok := s.chain.anchored(batchID)
+	s.Require().True(ok)
+	s.Require().Equal(root, anchored)
+}
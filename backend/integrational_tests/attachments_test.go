@@ -0,0 +1,105 @@
+package integrationstests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestSendMessageWithAttachments() {
+	cookie, err := makeAuthRequest(s.handler, s.accounts[1])
+	s.Require().NoError(err)
+	recepeintCookie, err := makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	outsiderCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+
+	fields := map[string]string{
+		"recipient_id": s.accounts[2].auth.From.String(),
+		"content":      "see attached",
+	}
+	notes := []byte("meeting notes")
+
+	// MIME type is sniffed, not taken from the file name
+	err = makeMultipartRequest(s.handler, cookie, "/g1/dialogs/message", fields,
+		map[string][]byte{"script.txt": {0x7f, 'E', 'L', 'F', 0, 1, 2}}, nil)
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "400")
+
+	err = makeMultipartRequest(s.handler, cookie, "/g1/dialogs/message", fields,
+		map[string][]byte{"notes.txt": notes}, nil)
+	s.Require().NoError(err)
+
+	var resDialogMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resDialogMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogMessages, 1)
+	s.Require().Len((*resDialogMessages)[0].Attachments, 1)
+
+	attachment := (*resDialogMessages)[0].Attachments[0]
+	s.Require().Equal("notes.txt", attachment.FileName)
+	s.Require().Equal("text/plain", attachment.MimeType)
+	s.Require().Equal(int64(len(notes)), attachment.Size)
+
+	// Only participants get a download link
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, outsiderCookie, http.MethodGet, "/g1/dialogs/1/attachments/1/url", nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	var resURL *models.AttachmentURLResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/attachments/1/url", nil, &resURL)
+	s.Require().NoError(err)
+	s.Require().NotZero(resURL.ExpiresAt)
+
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, resURL.URL, nil))
+	resp := recorder.Result()
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	data, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(notes, data)
+
+	// Tampered links are rejected
+	recorder = httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, strings.Replace(resURL.URL, "/1?", "/2?", 1), nil))
+	s.Require().Equal(http.StatusForbidden, recorder.Result().StatusCode)
+}
+
+func (s *TestSuiteUser) TestExpiredMessageAttachments() {
+	cookie, err := makeAuthRequest(s.handler, s.accounts[1])
+	s.Require().NoError(err)
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+
+	err = makeMultipartRequest(s.handler, cookie, "/g1/dialogs/message", map[string]string{
+		"recipient_id": s.accounts[2].auth.From.String(),
+		"content":      "see attached",
+	}, map[string][]byte{"notes.txt": []byte("meeting notes")}, nil)
+	s.Require().NoError(err)
+
+	var resURL *models.AttachmentURLResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/attachments/1/url", nil, &resURL)
+	s.Require().NoError(err)
+
+	// The message expires, the purge worker hasn't removed it yet
+	_, err = s.pgxpool.Exec(context.Background(), `UPDATE messages SET expires_at = $1 WHERE dialog_id = 1`,
+		time.Now().Add(-time.Minute).UnixMilli())
+	s.Require().NoError(err)
+
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/attachments/1/url", nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusNotFound), resErr.Code)
+
+	// Links issued before it expired stop working too
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, resURL.URL, nil))
+	s.Require().Equal(http.StatusNotFound, recorder.Result().StatusCode)
+}
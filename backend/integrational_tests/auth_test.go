@@ -82,3 +82,119 @@ func (s *TestSuiteUser) TestAuthByMsg() {
 	diff := cmp.Diff(s.accounts[1].auth.From.String(), resAuth.User.Address)
 	s.Require().Empty(diff)
 }
+
+func (s *TestSuiteUser) TestQRLogin() {
+	// десктоп создаёт сессию и показывает QR-код
+	var session models.LoginSessionResponse
+	s.Require().NoError(makeJsonRequest(s.handler, "", http.MethodPost, "/g1/auth/qr/session", nil, &session))
+	s.Require().NotEmpty(session.SessionID)
+	s.Require().NotEmpty(session.PollToken)
+	s.Require().Contains(session.URI, session.SessionID)
+
+	// телефон получает сообщение и подписывает его
+	var challenge models.LoginSessionChallengeResponse
+	s.Require().NoError(makeJsonRequest(s.handler, "", http.MethodGet,
+		fmt.Sprintf("/g1/auth/qr/session/%s", session.SessionID), nil, &challenge))
+	s.Require().NotEmpty(challenge.Message)
+
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(challenge.Message), challenge.Message)))
+	sig, err := crypto.Sign(hash, s.accounts[2].pk)
+	s.Require().NoError(err)
+	signature := hexutil.Encode(sig)
+	addr := s.accounts[2].auth.From.String()
+
+	// чужая подпись не подходит
+	wrongAddr := s.accounts[1].auth.From.String()
+	s.Require().NoError(makeJsonRequestWithError(s.handler, "", http.MethodPost,
+		fmt.Sprintf("/g1/auth/qr/session/%s/signature", session.SessionID),
+		models.AuthBySignatureRequest{Address: &wrongAddr, Signature: &signature}, nil))
+
+	s.Require().NoError(makeJsonRequest(s.handler, "", http.MethodPost,
+		fmt.Sprintf("/g1/auth/qr/session/%s/signature", session.SessionID),
+		models.AuthBySignatureRequest{Address: &addr, Signature: &signature}, nil))
+
+	// без poll token сессию забрать нельзя
+	wrongToken := "00"
+	s.Require().NoError(makeJsonRequestWithError(s.handler, "", http.MethodPost,
+		fmt.Sprintf("/g1/auth/qr/session/%s/poll", session.SessionID),
+		models.LoginSessionPollRequest{PollToken: &wrongToken}, nil))
+
+	// десктоп получает cookie
+	dataReq, err := json.Marshal(models.LoginSessionPollRequest{PollToken: &session.PollToken})
+	s.Require().NoError(err)
+	httpReq := httptest.NewRequest(http.MethodPost,
+		fmt.Sprintf("/g1/auth/qr/session/%s/poll", session.SessionID), bytes.NewReader(dataReq))
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httpReq)
+	resp := recorder.Result()
+	defer resp.Body.Close()
+	s.Require().Equal(200, resp.StatusCode)
+
+	var accessToken string
+	for _, c := range resp.Cookies() {
+		if c.Name == h.NameCookie {
+			accessToken = c.Value
+		}
+	}
+	s.Require().NotEmpty(accessToken)
+
+	var poll models.LoginSessionPollResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&poll))
+	s.Require().Equal(models.LoginSessionPollResponseStatusRedeemed, poll.Status)
+	s.Require().Equal(addr, poll.Auth.User.Address)
+
+	var dialogs models.DialogsResponse
+	s.Require().NoError(makeJsonRequest(s.handler, accessToken, http.MethodGet, "/g1/dialogs", nil, &dialogs))
+
+	// повторно сессию использовать нельзя
+	var errResp models.ErrorResponse
+	s.Require().NoError(makeJsonRequestWithError(s.handler, "", http.MethodPost,
+		fmt.Sprintf("/g1/auth/qr/session/%s/poll", session.SessionID),
+		models.LoginSessionPollRequest{PollToken: &session.PollToken}, &errResp))
+	s.Require().Equal(int64(http.StatusBadRequest), errResp.Code)
+}
+
+func (s *TestSuiteUser) TestMultiChainSignIn() {
+	var chains models.SupportedChainsResponse
+	s.Require().NoError(makeJsonRequest(s.handler, "", http.MethodGet, "/g1/auth/chains", nil, &chains))
+	s.Require().Equal(models.SupportedChainsResponse{
+		{ChainID: 1337, Name: "Localhost", Primary: true},
+		{ChainID: 10, Name: "OP Mainnet"},
+	}, chains)
+
+	addr := s.accounts[3].auth.From.String()
+	var resErr *models.ErrorResponse
+	s.Require().NoError(makeJsonRequestWithError(s.handler, "", http.MethodPost, "/g1/auth/message",
+		models.AuthMessageRequest{Address: &addr, ChainID: 5}, &resErr))
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	signIn := func(chainID int64) *models.AuthResponse {
+		var msg models.AuthMessageResponse
+		s.Require().NoError(makeJsonRequest(s.handler, "", http.MethodPost, "/g1/auth/message",
+			models.AuthMessageRequest{Address: &addr, ChainID: chainID}, &msg))
+		// the signed message names the chain, it can't be replayed on another one
+		if chainID != 0 {
+			s.Require().Contains(*msg.Message, fmt.Sprintf("on chain %d", chainID))
+		}
+
+		hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(*msg.Message), *msg.Message)))
+		sig, err := crypto.Sign(hash, s.accounts[3].pk)
+		s.Require().NoError(err)
+		signature := hexutil.Encode(sig)
+
+		var res models.AuthResponse
+		s.Require().NoError(makeJsonRequest(s.handler, "", http.MethodPost, "/g1/auth/by_signature",
+			models.AuthBySignatureRequest{Address: &addr, Signature: &signature}, &res))
+		return &res
+	}
+
+	onOptimism := signIn(10)
+	s.Require().Equal(int64(10), onOptimism.ChainID)
+	s.Require().Equal([]int64{10}, onOptimism.User.ChainIDs)
+
+	// the same address is the same user on every chain
+	onPrimary := signIn(0)
+	s.Require().Equal(int64(1337), onPrimary.ChainID)
+	s.Require().Equal(onOptimism.User.Address, onPrimary.User.Address)
+	s.Require().ElementsMatch([]int64{10, 1337}, onPrimary.User.ChainIDs)
+}
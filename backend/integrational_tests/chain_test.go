@@ -0,0 +1,265 @@
+package integrationstests
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func (s *TestSuiteUser) TestOnChainMessages() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	recepeintAddress := s.accounts[2].auth.From.String()
+
+	// The contract only carries public text
+	var resErr *models.ErrorResponse
+	nonce, empty := "00", ""
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &empty,
+		RecipientID: &recepeintAddress,
+		Encrypted:   &models.EncryptedPayload{Nonce: &nonce, Ciphertexts: []*models.RecipientCiphertext{}},
+		OnChain:     true,
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	content := "hello on-chain"
+	var res *models.SendMessageResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+		OnChain:     true,
+	}, &res)
+	s.Require().NoError(err)
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().NotNil((*resMessages)[0].ChainTx)
+
+	// The worker submits the message and follows the receipt
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+		s.Require().NoError(err)
+		return (*resMessages)[0].ChainTx.Status == models.ChainTxStatusConfirmed
+	}, 10*time.Second, 200*time.Millisecond)
+
+	sent := s.chain.get((*resMessages)[0].ChainTx.TxHash)
+	s.Require().NotNil(sent)
+	s.Require().Equal(s.chain.Relayer(), sent.Sender)
+	s.Require().Equal(strings.ToLower(recepeintAddress), strings.ToLower(sent.Receiver.Hex()))
+	s.Require().Equal(content, sent.Content)
+
+	edited := "changed"
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPatch, "/g1/dialogs/1/messages/1",
+		&models.EditMessageRequest{Content: &edited}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+}
+
+func (s *TestSuiteUser) TestChainIndexer() {
+	recepeint := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From
+
+	// accounts[3] only ever talks to the contract from the wallet
+	sender := s.accounts[3]
+	first := s.chain.emit(sender, recepeintAddress, "first from the wallet")
+	s.chain.emit(sender, recepeintAddress, "second from the wallet")
+
+	var (
+		resDialogs  *models.DialogsResponse
+		resMessages *models.MessagesResponse
+	)
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+		s.Require().NoError(err)
+		if len(*resDialogs) != 1 {
+			return false
+		}
+		err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+		s.Require().NoError(err)
+		return len(*resMessages) == 2
+	}, 10*time.Second, 200*time.Millisecond)
+	s.Require().Equal(strings.ToLower(sender.auth.From.Hex()), (*resDialogs)[0].RecepeintAddress)
+	s.Require().Equal("first from the wallet", (*resMessages)[0].Content)
+	s.Require().Equal(strings.ToLower(sender.auth.From.Hex()), strings.ToLower((*resMessages)[0].SenderAddress))
+	s.Require().Equal(models.ChainTxStatusConfirmed, (*resMessages)[0].ChainTx.Status)
+	s.Require().Equal(first.Hex(), (*resMessages)[0].ChainTx.TxHash)
+
+	// Messages queued for an address the indexer registers are delivered along with the indexed ones
+	unregistered := s.accounts[2]
+	content := "hello before you joined"
+	unregisteredAddress := unregistered.auth.From.String()
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &unregisteredAddress,
+	}, nil)
+	s.Require().NoError(err)
+	s.chain.emit(recepeint, unregistered.auth.From, "and from the wallet")
+
+	unregisteredCookie, err := makeAuthRequest(s.handler, unregistered)
+	s.Require().NoError(err)
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, unregisteredCookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+		s.Require().NoError(err)
+		if len(*resDialogs) != 1 {
+			return false
+		}
+		err = makeJsonRequest(s.handler, unregisteredCookie, http.MethodGet,
+			fmt.Sprintf("/g1/dialogs/%d/messages", (*resDialogs)[0].DialogID), nil, &resMessages)
+		s.Require().NoError(err)
+		return len(*resMessages) == 2
+	}, 10*time.Second, 200*time.Millisecond)
+	s.Require().Equal(content, (*resMessages)[0].Content)
+	s.Require().Nil((*resMessages)[0].ChainTx)
+	s.Require().Equal("and from the wallet", (*resMessages)[1].Content)
+}
+
+func (s *TestSuiteUser) TestChainIndexerAppliesRecipientSettings() {
+	recepeint := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	sender := s.accounts[3]
+
+	// The contract takes the messages, the indexer drops the ones the API would have refused
+	contacts := models.PrivacySettingsWhoCanMessageContacts
+	err = makeJsonRequest(s.handler, cookie, http.MethodPut, "/g1/users/privacy",
+		&models.PrivacySettings{WhoCanMessage: &contacts}, nil)
+	s.Require().NoError(err)
+	s.chain.emit(sender, recepeint.auth.From, "refused by privacy")
+
+	everyone := models.PrivacySettingsWhoCanMessageEveryone
+	err = makeJsonRequest(s.handler, cookie, http.MethodPut, "/g1/users/privacy",
+		&models.PrivacySettings{WhoCanMessage: &everyone}, nil)
+	s.Require().NoError(err)
+	amount := "1000"
+	err = makeJsonRequest(s.handler, cookie, http.MethodPut, "/g1/users/price",
+		&models.MessagePriceRequest{Amount: &amount}, nil)
+	s.Require().NoError(err)
+	s.chain.emit(sender, recepeint.auth.From, "unpaid")
+
+	// Logs are indexed in order, once a later one shows up the earlier ones were dropped
+	other := s.accounts[2]
+	s.chain.emit(sender, other.auth.From, "delivered")
+	otherCookie, err := makeAuthRequest(s.handler, other)
+	s.Require().NoError(err)
+	var resDialogs *models.DialogsResponse
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, otherCookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+		s.Require().NoError(err)
+		return len(*resDialogs) == 1
+	}, 10*time.Second, 200*time.Millisecond)
+
+	for _, folder := range []string{"inbox", "requests"} {
+		err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs?folder="+folder, nil, &resDialogs)
+		s.Require().NoError(err)
+		s.Require().Empty(*resDialogs)
+	}
+}
+
+func (s *TestSuiteUser) TestGaslessMessages() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	recepeintAddress := s.accounts[2].auth.From.String()
+
+	quota := s.cfg.Service.Chain.Relay.Quota
+	s.cfg.Service.Chain.Relay.Quota = 2
+	defer func() { s.cfg.Service.Chain.Relay.Quota = quota }()
+
+	var info *models.RelayInfo
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/relay", nil, &info)
+	s.Require().NoError(err)
+	s.Require().Equal(s.chain.Domain().VerifyingContract.Hex(), info.Domain.VerifyingContract)
+	s.Require().Equal(int64(2), info.Remaining)
+
+	deadline := time.Now().Add(time.Hour).Unix()
+	relayRequest := func(content string, nonce int64, signedContent string) *models.SendMessageRequest {
+		digest := s.chain.Domain().Digest(&chain.SignedMessage{
+			Receiver: common.HexToAddress(recepeintAddress),
+			Content:  signedContent,
+			Nonce:    big.NewInt(nonce),
+			Deadline: big.NewInt(deadline),
+		})
+		sig, err := crypto.Sign(digest[:], sender.pk)
+		s.Require().NoError(err)
+		sig[64] += 27
+		nonceString, signature := strconv.FormatInt(nonce, 10), hexutil.Encode(sig)
+		return &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &recepeintAddress,
+			OnChain:     true,
+			Relay:       &models.RelaySignature{Nonce: &nonceString, Deadline: &deadline, Signature: &signature},
+		}
+	}
+
+	// A relay signature only makes sense for an on-chain message
+	var resErr *models.ErrorResponse
+	offChain := relayRequest("hello without gas", 7, "hello without gas")
+	offChain.OnChain = false
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", offChain, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.ErrorResponse{Code: http.StatusBadRequest, Message: "relay is only accepted for on-chain messages"}, resErr)
+
+	// The signature has to cover what is sent
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message",
+		relayRequest("hello without gas", 7, "something else"), &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message",
+		relayRequest("hello without gas", 7, "hello without gas"), nil)
+	s.Require().NoError(err)
+
+	var resMessages *models.MessagesResponse
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+		s.Require().NoError(err)
+		return (*resMessages)[0].ChainTx.Status == models.ChainTxStatusConfirmed
+	}, 10*time.Second, 200*time.Millisecond)
+
+	// The log names the sender, not the relayer who paid for it, and the indexer doesn't store it twice
+	sent := s.chain.get((*resMessages)[0].ChainTx.TxHash)
+	s.Require().NotNil(sent)
+	s.Require().Equal(sender.auth.From, sent.Sender)
+	s.Require().Never(func() bool {
+		err := makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+		s.Require().NoError(err)
+		return len(*resMessages) != 1
+	}, 2*time.Second, 200*time.Millisecond)
+
+	// A signature is relayed once
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message",
+		relayRequest("hello without gas", 7, "hello without gas"), &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusConflict), resErr.Code)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message",
+		relayRequest("again", 8, "again"), nil)
+	s.Require().NoError(err)
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message",
+		relayRequest("over the quota", 9, "over the quota"), &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusTooManyRequests), resErr.Code)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/relay", nil, &info)
+	s.Require().NoError(err)
+	s.Require().Zero(info.Remaining)
+}
@@ -1,6 +1,7 @@
 package integrationstests
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -22,15 +23,14 @@ func (s *TestSuiteUser) TestSendMessage() {
 		RecipientID: &recepeintAddress,
 	}
 
-	// Recepeint not registered
+	// Recepeint not registered, only text can wait for them
 	var resErr *models.ErrorResponse
 	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", emptyMsg, &resErr)
 	s.Require().NoError(err)
 
 	resTargetError := &models.ErrorResponse{
 		Code:    400,
-		Detail:  fmt.Sprintf("user with address %v is not registered", recepeintAddress),
-		Message: "Bad Request",
+		Message: "only non-empty text messages can be sent to unregistered addresses",
 	}
 	s.Require().Equal(resTargetError, resErr)
 
@@ -58,7 +58,18 @@ func (s *TestSuiteUser) TestSendMessage() {
 	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
 	s.Require().NoError(err)
 
-	targetDialogs := models.DialogsResponse([]*models.DialogsResponseItems0{{DialogID: 1, RecepeintAddress: strings.ToLower(recepeintAddress)}})
+	s.Require().Len(*resDialogs, 1)
+	s.Require().NotNil((*resDialogs)[0].LastMessage)
+
+	targetDialogs := models.DialogsResponse([]*models.DialogsResponseItems0{{DialogID: 1, RecepeintAddress: strings.ToLower(recepeintAddress),
+		LastMessage: &models.MessagePreview{
+			MessageID:     1,
+			SenderAddress: strings.ToLower(senderAddress),
+			Content:       content,
+			CreatedAt:     (*resDialogs)[0].LastMessage.CreatedAt,
+		},
+		RequestStatus: models.DialogsResponseItems0RequestStatusPending,
+	}})
 	s.Require().Equal(&targetDialogs, resDialogs)
 
 	dialogId := 1
@@ -68,7 +79,249 @@ func (s *TestSuiteUser) TestSendMessage() {
 	err = makeJsonRequest(s.handler, cookie, http.MethodGet, fmt.Sprintf("/g1/dialogs/%d/messages", dialogId), nil, &resDialogMessages)
 	s.Require().NoError(err)
 
+	s.Require().Len(*resDialogMessages, 1)
+	s.Require().NotZero((*resDialogMessages)[0].CreatedAt)
+
 	targetDialogMessages := models.MessagesResponse(
-		[]*models.MessagesResponseItems0{{MessageID: messageId, SenderAddress: strings.ToLower(senderAddress), Content: content}})
+		[]*models.MessagesResponseItems0{{MessageID: messageId, SenderAddress: strings.ToLower(senderAddress), Content: content,
+			CreatedAt: (*resDialogMessages)[0].CreatedAt, Kind: models.MessagesResponseItems0KindMessage}})
 	s.Require().Equal(&targetDialogMessages, resDialogMessages)
+	// Only participants can read the dialog
+	outsiderCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+	err = makeJsonRequestWithError(s.handler, outsiderCookie, http.MethodGet, fmt.Sprintf("/g1/dialogs/%d/messages", dialogId), nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.ErrorResponse{Code: http.StatusForbidden, Message: "not a dialog participant"}, resErr)
+}
+
+func (s *TestSuiteUser) TestEditAndDeleteMessage() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	content := "helo"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+
+	// Only the sender can edit
+	edited := "hello"
+	editReq := &models.EditMessageRequest{Content: &edited}
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, recepeintCookie, http.MethodPatch, "/g1/dialogs/1/messages/1", editReq, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	// Message must belong to the dialog
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPatch, "/g1/dialogs/2/messages/1", editReq, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusNotFound), resErr.Code)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPatch, "/g1/dialogs/1/messages/1", editReq, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodDelete, "/g1/dialogs/1/messages/2", nil, nil)
+	s.Require().NoError(err)
+
+	// Deleted messages can't be edited
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPatch, "/g1/dialogs/1/messages/2", editReq, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	var resDialogMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resDialogMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogMessages, 2)
+
+	s.Require().Equal(edited, (*resDialogMessages)[0].Content)
+	s.Require().NotZero((*resDialogMessages)[0].EditedAt)
+	s.Require().False((*resDialogMessages)[0].Deleted)
+
+	s.Require().Equal(int64(2), (*resDialogMessages)[1].MessageID)
+	s.Require().Empty((*resDialogMessages)[1].Content)
+	s.Require().True((*resDialogMessages)[1].Deleted)
+
+	var revisions int
+	err = s.pgxpool.QueryRow(context.Background(), `SELECT count(*) FROM message_revisions`).Scan(&revisions)
+	s.Require().NoError(err)
+	s.Require().Equal(2, revisions)
+}
+
+func (s *TestSuiteUser) TestReadReceipts() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	content := "ping"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	for i := 0; i < 3; i++ {
+		err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+		s.Require().NoError(err)
+	}
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/1/request/accept", nil, nil)
+	s.Require().NoError(err)
+
+	var resDialogs *models.DialogsResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogs, 1)
+	s.Require().Equal(int64(3), (*resDialogs)[0].UnreadCount)
+	s.Require().Zero((*resDialogs)[0].LastReadMessageID)
+
+	// Outsiders can't move the read position
+	outsiderCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+
+	messageID := int64(2)
+	readReq := &models.ReadDialogRequest{MessageID: &messageID}
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, outsiderCookie, http.MethodPost, "/g1/dialogs/1/read", readReq, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/1/read", readReq, nil)
+	s.Require().NoError(err)
+
+	// Read position never moves backwards
+	staleID := int64(1)
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/1/read", &models.ReadDialogRequest{MessageID: &staleID}, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), (*resDialogs)[0].UnreadCount)
+	s.Require().Equal(messageID, (*resDialogs)[0].LastReadMessageID)
+
+	// The sender sees how far the recepeint has read
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Zero((*resDialogs)[0].UnreadCount)
+	s.Require().Equal(messageID, (*resDialogs)[0].RecipientLastReadMessageID)
+}
+
+func (s *TestSuiteUser) TestDialogsOrderedByLastMessage() {
+	cookie, err := makeAuthRequest(s.handler, s.accounts[1])
+	s.Require().NoError(err)
+
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	_, err = makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+
+	send := func(recepeint *Signer, content string) {
+		address := recepeint.auth.From.String()
+		err := makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &address,
+		}, nil)
+		s.Require().NoError(err)
+	}
+
+	send(s.accounts[2], "first")
+	send(s.accounts[3], "second")
+	send(s.accounts[2], strings.Repeat("long", 100))
+
+	var resDialogs *models.DialogsResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogs, 2)
+
+	s.Require().Equal(strings.ToLower(s.accounts[2].auth.From.String()), (*resDialogs)[0].RecepeintAddress)
+	s.Require().Equal(int64(3), (*resDialogs)[0].LastMessage.MessageID)
+	s.Require().Len((*resDialogs)[0].LastMessage.Content, 100)
+
+	s.Require().Equal(strings.ToLower(s.accounts[3].auth.From.String()), (*resDialogs)[1].RecepeintAddress)
+	s.Require().Equal("second", (*resDialogs)[1].LastMessage.Content)
+}
+
+func (s *TestSuiteUser) TestRepliesAndThreads() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+	senderAddress := sender.auth.From.String()
+
+	send := func(cookie string, to string, content string, replyTo int64) error {
+		return makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+			Content:          &content,
+			RecipientID:      &to,
+			ReplyToMessageID: replyTo,
+		}, nil)
+	}
+
+	s.Require().NoError(send(cookie, recepeintAddress, "root", 0))         // 1
+	s.Require().NoError(send(recepeintCookie, senderAddress, "reply", 1))  // 2
+	s.Require().NoError(send(cookie, recepeintAddress, "nested", 2))       // 3
+	s.Require().NoError(send(recepeintCookie, senderAddress, "second", 1)) // 4
+	s.Require().NoError(send(cookie, recepeintAddress, "unrelated", 0))    // 5
+
+	// Replies must stay within the dialog
+	_, err = makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+	var resErr *models.ErrorResponse
+	content := "elsewhere"
+	other := s.accounts[3].auth.From.String()
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:          &content,
+		RecipientID:      &other,
+		ReplyToMessageID: 1,
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	// A deleted parent keeps a stable quote stub
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodDelete, "/g1/dialogs/1/messages/2", nil, nil)
+	s.Require().NoError(err)
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	nested := (*resMessages)[2]
+	s.Require().Equal(int64(2), nested.ReplyToMessageID)
+	s.Require().Equal(&models.MessagePreview{
+		MessageID:     2,
+		SenderAddress: strings.ToLower(recepeintAddress),
+		CreatedAt:     nested.ReplyTo.CreatedAt,
+		Deleted:       true,
+	}, nested.ReplyTo)
+	s.Require().Equal("root", (*resMessages)[1].ReplyTo.Content)
+
+	var thread *models.ThreadResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages/1/thread", nil, &thread)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), thread.Root.MessageID)
+	s.Require().Equal(int64(3), thread.Total)
+	s.Require().Len(thread.Replies, 3)
+	s.Require().Equal([]int64{2, 3, 4}, []int64{thread.Replies[0].MessageID, thread.Replies[1].MessageID, thread.Replies[2].MessageID})
+	s.Require().Equal([]int64{1, 2, 1}, []int64{thread.Replies[0].Depth, thread.Replies[1].Depth, thread.Replies[2].Depth})
+	s.Require().True(thread.Replies[0].Deleted)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages/1/thread?limit=1&offset=1", nil, &thread)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(3), thread.Total)
+	s.Require().Len(thread.Replies, 1)
+	s.Require().Equal(int64(3), thread.Replies[0].MessageID)
 }
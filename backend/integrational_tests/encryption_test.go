@@ -0,0 +1,157 @@
+package integrationstests
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/e2e"
+)
+
+func (s *TestSuiteUser) publishEncryptionKey(cookie string, account *Signer) (*ecdsa.PrivateKey, int64) {
+	prv, err := e2e.GenerateKey()
+	s.Require().NoError(err)
+
+	publicKey := e2e.PublicKeyHex(&prv.PublicKey)
+	signature, err := signMessage(account, e2e.SignedKeyMessage(publicKey))
+	s.Require().NoError(err)
+
+	var key *models.EncryptionKey
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/users/keys", &models.PublishEncryptionKeyRequest{
+		PublicKey: &publicKey,
+		Signature: &signature,
+	}, &key)
+	s.Require().NoError(err)
+	s.Require().Equal(publicKey, key.PublicKey)
+
+	return prv, key.KeyID
+}
+
+func (s *TestSuiteUser) TestEncryptedMessages() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+	senderAddress := sender.auth.From.String()
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	// A key signed by another wallet is rejected
+	prv, err := e2e.GenerateKey()
+	s.Require().NoError(err)
+	publicKey := e2e.PublicKeyHex(&prv.PublicKey)
+	signature, err := signMessage(recepeint, e2e.SignedKeyMessage(publicKey))
+	s.Require().NoError(err)
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/users/keys", &models.PublishEncryptionKeyRequest{
+		PublicKey: &publicKey,
+		Signature: &signature,
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusUnauthorized), resErr.Code)
+
+	senderKey, senderKeyID := s.publishEncryptionKey(cookie, sender)
+	oldKey, oldKeyID := s.publishEncryptionKey(recepeintCookie, recepeint)
+
+	encrypt := func(text string, recepeintKey *ecdsa.PrivateKey, recepeintKeyID int64) *models.EncryptedPayload {
+		nonce, err := e2e.NewNonce()
+		s.Require().NoError(err)
+		payload := &models.EncryptedPayload{Nonce: &nonce}
+		for _, r := range []struct {
+			address string
+			key     *ecdsa.PrivateKey
+			keyID   int64
+		}{{senderAddress, senderKey, senderKeyID}, {recepeintAddress, recepeintKey, recepeintKeyID}} {
+			r := r
+			ct, err := e2e.Encrypt(&r.key.PublicKey, []byte(text), nonce)
+			s.Require().NoError(err)
+			payload.Ciphertexts = append(payload.Ciphertexts, &models.RecipientCiphertext{
+				Address:    &r.address,
+				KeyID:      &r.keyID,
+				Ciphertext: &ct,
+			})
+		}
+		return payload
+	}
+
+	empty := ""
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &empty,
+		RecipientID: &recepeintAddress,
+		Encrypted:   encrypt("before rotation", oldKey, oldKeyID),
+	}, nil)
+	s.Require().NoError(err)
+
+	// The recepeint rotates the key, old messages still point at the old one
+	newKey, newKeyID := s.publishEncryptionKey(recepeintCookie, recepeint)
+
+	// Stale keys are rejected
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &empty,
+		RecipientID: &recepeintAddress,
+		Encrypted:   encrypt("stale", oldKey, oldKeyID),
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	// Plaintext can't ride along with an encrypted payload
+	leaked := "after rotation"
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &leaked,
+		RecipientID: &recepeintAddress,
+		Encrypted:   encrypt("after rotation", newKey, newKeyID),
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &empty,
+		RecipientID: &recepeintAddress,
+		Encrypted:   encrypt("after rotation", newKey, newKeyID),
+	}, nil)
+	s.Require().NoError(err)
+
+	var keys *models.EncryptionKeysResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, fmt.Sprintf("/g1/users/%s/keys", recepeintAddress), nil, &keys)
+	s.Require().NoError(err)
+	s.Require().Len(*keys, 2)
+	s.Require().Equal(newKeyID, (*keys)[0].KeyID)
+	s.Require().Zero((*keys)[0].RotatedAt)
+	s.Require().Equal(oldKeyID, (*keys)[1].KeyID)
+	s.Require().NotZero((*keys)[1].RotatedAt)
+	s.Require().Equal(strings.ToLower(e2e.PublicKeyHex(&oldKey.PublicKey)), (*keys)[1].PublicKey)
+
+	keyring := e2e.Keyring{oldKeyID: oldKey, newKeyID: newKey}
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 2)
+	for i, expected := range []string{"before rotation", "after rotation"} {
+		msg := (*resMessages)[i]
+		s.Require().Empty(msg.Content)
+		s.Require().NotNil(msg.Encrypted)
+		plaintext, err := keyring.Decrypt(msg.Encrypted.KeyID, msg.Encrypted.Ciphertext, msg.Encrypted.Nonce)
+		s.Require().NoError(err)
+		s.Require().Equal(expected, string(plaintext))
+	}
+
+	// The sender reads its own copy
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	msg := (*resMessages)[1]
+	s.Require().Equal(senderKeyID, msg.Encrypted.KeyID)
+	plaintext, err := e2e.Decrypt(senderKey, msg.Encrypted.Ciphertext, msg.Encrypted.Nonce)
+	s.Require().NoError(err)
+	s.Require().Equal("after rotation", string(plaintext))
+
+	// Encrypted messages can't be edited in place
+	edited := "edited"
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPatch, "/g1/dialogs/1/messages/2",
+		&models.EditMessageRequest{Content: &edited}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"time"
@@ -163,3 +164,65 @@ func makeJsonRequestWithError(handler http.Handler, cookie string, method string
 
 	return nil
 }
+
+func makeMultipartRequest(handler http.Handler, cookie string, url string, fields map[string]string, files map[string][]byte, res any) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := writer.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+	for name, content := range files {
+		part, err := writer.CreateFormFile("attachments", name)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	httpReq := httptest.NewRequest(http.MethodPost, url, &body)
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+	httpReq.AddCookie(&http.Cookie{
+		Name:     h.NameCookie,
+		Value:    cookie,
+		Expires:  time.Now().Add(time.Minute),
+		Secure:   true,
+		HttpOnly: true,
+	})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httpReq)
+	resp := recorder.Result()
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non 200 response: %d %s", resp.StatusCode, string(data))
+	}
+
+	if res != nil {
+		if err = json.Unmarshal(data, res); err != nil {
+			return fmt.Errorf("json unmarshal failed: %w %s", err, string(data))
+		}
+	}
+	return nil
+}
+
+func signMessage(account *Signer, message string) (string, error) {
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	sig, err := crypto.Sign(hash, account.pk)
+	if err != nil {
+		return "", err
+	}
+
+	return hexutil.Encode(sig), nil
+}
@@ -0,0 +1,75 @@
+package integrationstests
+
+import (
+	"net/http"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestIdempotentSendMessage() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	recepeintAddress := s.accounts[2].auth.From.String()
+
+	content := "sent once"
+	msg := &models.SendMessageRequest{
+		Content:         &content,
+		RecipientID:     &recepeintAddress,
+		ClientMessageID: "retry-1",
+	}
+
+	var first, retry *models.SendMessageResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, &first)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), first.MessageID)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, &retry)
+	s.Require().NoError(err)
+	s.Require().Equal(first, retry)
+
+	// Same key, different body
+	var resErr *models.ErrorResponse
+	changed := "sent twice"
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:         &changed,
+		RecipientID:     &recepeintAddress,
+		ClientMessageID: "retry-1",
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusConflict), resErr.Code)
+
+	// Keys are scoped to the sender
+	otherCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+	var other *models.SendMessageResponse
+	err = makeJsonRequest(s.handler, otherCookie, http.MethodPost, "/g1/dialogs/message", msg, &other)
+	s.Require().NoError(err)
+	s.Require().NotEqual(first.MessageID, other.MessageID)
+
+	// Multipart retries carry the key as a form field
+	fields := map[string]string{
+		"recipient_id":      recepeintAddress,
+		"content":           "with a file",
+		"client_message_id": "retry-2",
+	}
+	files := map[string][]byte{"notes.txt": []byte("some notes")}
+	err = makeMultipartRequest(s.handler, cookie, "/g1/dialogs/message", fields, files, &first)
+	s.Require().NoError(err)
+	err = makeMultipartRequest(s.handler, cookie, "/g1/dialogs/message", fields, files, &retry)
+	s.Require().NoError(err)
+	s.Require().Equal(first.MessageID, retry.MessageID)
+
+	err = makeMultipartRequest(s.handler, cookie, "/g1/dialogs/message", fields,
+		map[string][]byte{"notes.txt": []byte("other notes")}, nil)
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "409")
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 2)
+}
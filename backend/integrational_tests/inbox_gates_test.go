@@ -0,0 +1,100 @@
+package integrationstests
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func (s *TestSuiteUser) TestTokenGatedInbox() {
+	holder := s.accounts[1]
+	holderCookie, err := makeAuthRequest(s.handler, holder)
+	s.Require().NoError(err)
+
+	stranger := s.accounts[3]
+	strangerCookie, err := makeAuthRequest(s.handler, stranger)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	erc721, erc20 := models.InboxGateStandardErc721, models.InboxGateStandardErc20
+
+	// A token that doesn't answer balanceOf would lock the inbox for everyone
+	var resErr *models.ErrorResponse
+	undeployed := common.HexToAddress("0x00000000000000000000000000000000000000c0").Hex()
+	err = makeJsonRequestWithError(s.handler, recepeintCookie, http.MethodPut, "/g1/users/gates", &models.InboxGatesRequest{
+		Gates: []*models.InboxGate{{TokenAddress: &undeployed, Standard: &erc721}},
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	collection, nft := s.chain.newCollection()
+	coin, token := s.chain.newToken()
+	collectionAddress, coinAddress := collection.Hex(), coin.Hex()
+	gates := &models.InboxGatesRequest{Gates: []*models.InboxGate{
+		{TokenAddress: &collectionAddress, Standard: &erc721},
+		{TokenAddress: &coinAddress, Standard: &erc20, MinBalance: "100"},
+	}}
+
+	s.chain.mined(nft.Mint(s.chain.owner, holder.auth.From, big.NewInt(1)))
+	s.chain.mined(token.Mint(s.chain.owner, stranger.auth.From, big.NewInt(50)))
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPut, "/g1/users/gates", gates, nil)
+	s.Require().NoError(err)
+
+	var resGates *models.InboxGatesResponse
+	err = makeJsonRequest(s.handler, strangerCookie, http.MethodGet, "/g1/users/"+recepeintAddress+"/gates", nil, &resGates)
+	s.Require().NoError(err)
+	s.Require().Len(*resGates, 2)
+	s.Require().Equal("1", (*resGates)[0].MinBalance)
+	s.Require().Equal(coinAddress, *(*resGates)[1].TokenAddress)
+
+	content := "hello"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	err = makeJsonRequestWithError(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.ErrorResponse{
+		Code:    http.StatusForbidden,
+		Message: "this user only accepts new dialogs from holders of their tokens",
+	}, resErr)
+
+	err = makeJsonRequest(s.handler, holderCookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+
+	// The holder sold the token, the worker flags their dialog for the recepeint
+	s.chain.mined(nft.TransferFrom(holder.auth, holder.auth.From, s.chain.owner.From, big.NewInt(1)))
+	var resDialogs *models.DialogsResponse
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+		s.Require().NoError(err)
+		return len(*resDialogs) == 1 && (*resDialogs)[0].GateFailed
+	}, 5*time.Second, 200*time.Millisecond)
+
+	// The flag is only shown to the gate owner
+	err = makeJsonRequest(s.handler, holderCookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogs, 1)
+	s.Require().False((*resDialogs)[0].GateFailed)
+
+	s.chain.mined(token.Mint(s.chain.owner, holder.auth.From, big.NewInt(100)))
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+		s.Require().NoError(err)
+		return !(*resDialogs)[0].GateFailed
+	}, 5*time.Second, 200*time.Millisecond)
+
+	// Removing the gates opens the inbox again
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPut, "/g1/users/gates",
+		&models.InboxGatesRequest{Gates: []*models.InboxGate{}}, nil)
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+}
@@ -0,0 +1,124 @@
+package integrationstests
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestMessageRequests() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+	senderAddress := sender.auth.From.String()
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	content := "hi, we haven't met"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	for i := 0; i < 3; i++ {
+		err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+		s.Require().NoError(err)
+	}
+
+	// Until the request is answered the sender can't keep writing
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	// The sender keeps the dialog in the inbox, the recepeint sees it as a request
+	var resDialogs *models.DialogsResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogs, 1)
+	s.Require().Equal(models.DialogsResponseItems0RequestStatusPending, (*resDialogs)[0].RequestStatus)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Empty(*resDialogs)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogs, 1)
+	s.Require().Equal(strings.ToLower(senderAddress), (*resDialogs)[0].RecepeintAddress)
+
+	err = makeJsonRequestWithError(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs?folder=archive", nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	// Only the recepeint can answer the request
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/1/request/accept", nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/1/request/accept", nil, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogs, 1)
+	s.Require().Equal(models.DialogsResponseItems0RequestStatusAccepted, (*resDialogs)[0].RequestStatus)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+}
+
+func (s *TestSuiteUser) TestDeclineAndReportMessageRequests() {
+	recepeint := s.accounts[1]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	declinedCookie, err := makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	reportedCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+
+	content := "hello"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	err = makeJsonRequest(s.handler, declinedCookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, reportedCookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/1/request/decline", nil, nil)
+	s.Require().NoError(err)
+
+	// The declined sender gets the same answer as a blocked one
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, declinedCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	reason := "spam"
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/2/request/report",
+		&models.ReportMessageRequest{Reason: reason}, nil)
+	s.Require().NoError(err)
+
+	var blocked *models.BlockedUsersResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/users/blocks", nil, &blocked)
+	s.Require().NoError(err)
+	s.Require().Len(*blocked, 1)
+	s.Require().Equal(strings.ToLower(s.accounts[3].auth.From.String()), (*blocked)[0].Address)
+
+	var resDialogs *models.DialogsResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Empty(*resDialogs)
+
+	// A declined request can't be accepted or declined again
+	err = makeJsonRequestWithError(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/1/request/accept", nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+}
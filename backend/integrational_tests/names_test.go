@@ -0,0 +1,79 @@
+package integrationstests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestNames() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := strings.ToLower(recepeint.auth.From.String())
+
+	s.chain.setName("alice.eth", sender.auth.From, true)
+	s.chain.setName("bob.eth", recepeint.auth.From, true)
+
+	var resErr *models.ErrorResponse
+	unknown, content := "nobody.eth", "hello by name"
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &unknown,
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusNotFound), resErr.Code)
+
+	// Names are case-insensitive
+	name := "Bob.ETH"
+	var resSend *models.SendMessageResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &name,
+	}, &resSend)
+	s.Require().NoError(err)
+	s.Require().NotZero(resSend.MessageID)
+
+	var resDialogs *models.DialogsResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	var dialog *models.DialogsResponseItems0
+	for _, d := range *resDialogs {
+		if d.RecepeintAddress == recepeintAddress {
+			dialog = d
+		}
+	}
+	s.Require().NotNil(dialog)
+	s.Require().Equal("bob.eth", dialog.RecepeintName)
+	s.Require().Equal(content, dialog.LastMessage.Content)
+	s.Require().Equal("alice.eth", dialog.LastMessage.SenderName)
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet,
+		fmt.Sprintf("/g1/dialogs/%d/messages", dialog.DialogID), nil, &resMessages)
+	s.Require().NoError(err)
+	for _, m := range *resMessages {
+		switch strings.ToLower(m.SenderAddress) {
+		case strings.ToLower(sender.auth.From.String()):
+			s.Require().Equal("alice.eth", m.SenderName)
+		default:
+			s.Require().Equal("bob.eth", m.SenderName)
+		}
+	}
+
+	// bob.eth moved to another address, the reverse record left behind isn't shown anymore
+	s.chain.setName("bob.eth", s.accounts[3].auth.From, false)
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	for _, d := range *resDialogs {
+		if d.RecepeintAddress == recepeintAddress {
+			s.Require().Empty(d.RecepeintName)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package integrationstests
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestPaidFirstContact() {
+	stranger := s.accounts[1]
+	strangerCookie, err := makeAuthRequest(s.handler, stranger)
+	s.Require().NoError(err)
+
+	other := s.accounts[3]
+	otherCookie, err := makeAuthRequest(s.handler, other)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	amount := "1000"
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPut, "/g1/users/price",
+		&models.MessagePriceRequest{Amount: &amount}, nil)
+	s.Require().NoError(err)
+
+	var resPrice *models.MessagePrice
+	err = makeJsonRequest(s.handler, strangerCookie, http.MethodGet, "/g1/users/"+recepeintAddress+"/price", nil, &resPrice)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.MessagePrice{
+		Amount:        "1000",
+		EscrowAddress: s.chain.Escrow().Hex(),
+		Confirmations: s.cfg.Service.Payments.Confirmations,
+	}, resPrice)
+
+	content := "hello"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.ErrorResponse{
+		Code:    http.StatusPaymentRequired,
+		Message: "this user only accepts new dialogs with a payment",
+	}, resErr)
+
+	// Too little, and to the wrong address
+	msg.PaymentTxHash = s.chain.transfer(stranger, recepeint.auth.From, 999).Hex()
+	err = makeJsonRequestWithError(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusPaymentRequired), resErr.Code)
+
+	msg.PaymentTxHash = s.chain.transfer(stranger, other.auth.From, 1000).Hex()
+	err = makeJsonRequestWithError(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusPaymentRequired), resErr.Code)
+
+	msg.PaymentTxHash = s.chain.deposit(stranger, recepeint.auth.From, 1000, false).Hex()
+	err = makeJsonRequestWithError(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusConflict), resErr.Code)
+	s.Require().Equal("payment transaction is not confirmed yet", resErr.Message)
+
+	// An escrow deposit opens the dialog, the pending one above is mined along with it as deposit 0
+	deposit := s.chain.deposit(stranger, recepeint.auth.From, 1500, true)
+	msg.PaymentTxHash = deposit.Hex()
+	err = makeJsonRequest(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+
+	// Each transaction pays for one message only
+	err = makeJsonRequestWithError(s.handler, otherCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.ErrorResponse{
+		Code:    http.StatusConflict,
+		Message: "payment transaction was already used",
+	}, resErr)
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 1)
+	s.Require().Equal(&models.MessagePayment{
+		TxHash: deposit.Hex(),
+		Amount: "1500",
+		Escrow: true,
+		Status: models.MessagePaymentStatusHeld,
+	}, (*resMessages)[0].Payment)
+
+	// The reply refunds the deposit
+	strangerAddress := stranger.auth.From.String()
+	reply := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &strangerAddress,
+	}
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/message", reply, nil)
+	s.Require().NoError(err)
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, strangerCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+		s.Require().NoError(err)
+		return (*resMessages)[0].Payment.Status == models.MessagePaymentStatusRefunded
+	}, 5*time.Second, 200*time.Millisecond)
+	s.Require().True(s.chain.refunded(1))
+	s.Require().False(s.chain.refunded(0))
+	s.Require().NotEmpty((*resMessages)[0].Payment.RefundTxHash)
+
+	// A direct transfer in the price token can't be refunded
+	coin, token := s.chain.newToken()
+	coinAddress := coin.Hex()
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPut, "/g1/users/price",
+		&models.MessagePriceRequest{Amount: &amount, TokenAddress: coinAddress}, nil)
+	s.Require().NoError(err)
+
+	s.chain.mined(token.Mint(s.chain.owner, other.auth.From, big.NewInt(1000)))
+	msg.PaymentTxHash = s.chain.mined(token.Transfer(other.auth, recepeint.auth.From, big.NewInt(1000))).Hex()
+	err = makeJsonRequest(s.handler, otherCookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, otherCookie, http.MethodGet, "/g1/dialogs/2/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.MessagePayment{
+		TxHash:       msg.PaymentTxHash,
+		TokenAddress: coinAddress,
+		Amount:       "1000",
+		Status:       models.MessagePaymentStatusPaid,
+	}, (*resMessages)[0].Payment)
+}
@@ -0,0 +1,99 @@
+package integrationstests
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestPendingMessages() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	// accounts[2] hasn't signed in yet
+	recepeint := s.accounts[2]
+	recepeintAddress := recepeint.auth.From.String()
+
+	var resErr *models.ErrorResponse
+	garbage, content := "0x1234", "hello before you joined"
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &garbage,
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:          &content,
+		RecipientID:      &recepeintAddress,
+		ReplyToMessageID: 1,
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}, nil)
+	s.Require().NoError(err)
+
+	// Pending messages start a dialog, so they are signed for dialog 0
+	signedContent := "signed before you joined"
+	signedAt := time.Now().UnixMilli()
+	signature, err := signMessage(sender, domain.SignedMessageText(0, recepeintAddress, signedAt, signedContent))
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &signedContent,
+		RecipientID: &recepeintAddress,
+		Signature:   signature,
+		SignedAt:    signedAt,
+	}, nil)
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}, nil)
+	s.Require().NoError(err)
+
+	// The per-recipient quota matches the message request limit
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusTooManyRequests), resErr.Code)
+
+	var resPending *models.PendingMessagesResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/pending", nil, &resPending)
+	s.Require().NoError(err)
+	s.Require().Len(*resPending, 3)
+	s.Require().Equal(strings.ToLower(recepeintAddress), (*resPending)[0].RecipientAddress)
+	s.Require().Equal(signedContent, (*resPending)[1].Content)
+
+	// Signing in delivers them as a message request
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/pending", nil, &resPending)
+	s.Require().NoError(err)
+	s.Require().Empty(*resPending)
+
+	var resDialogs *models.DialogsResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs?folder=requests", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Len(*resDialogs, 1)
+	s.Require().Equal(strings.ToLower(sender.auth.From.String()), (*resDialogs)[0].RecepeintAddress)
+	s.Require().Equal(content, (*resDialogs)[0].LastMessage.Content)
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 3)
+	s.Require().Nil((*resMessages)[0].Signature)
+	s.Require().NotNil((*resMessages)[1].Signature)
+	s.Require().Equal(signature, (*resMessages)[1].Signature.Signature)
+}
@@ -0,0 +1,112 @@
+package integrationstests
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestBlockList() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+	senderAddress := sender.auth.From.String()
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	content := "hello"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/users/blocks",
+		&models.BlockUserRequest{Address: &senderAddress}, nil)
+	s.Require().NoError(err)
+
+	var blocked *models.BlockedUsersResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/users/blocks", nil, &blocked)
+	s.Require().NoError(err)
+	s.Require().Len(*blocked, 1)
+	s.Require().Equal(strings.ToLower(senderAddress), (*blocked)[0].Address)
+
+	// The blocked sender gets the same error as for a privacy rejection
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(&models.ErrorResponse{
+		Code:    http.StatusForbidden,
+		Message: "message can't be delivered to this user",
+	}, resErr)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodDelete, "/g1/users/blocks/"+senderAddress, nil, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+}
+
+func (s *TestSuiteUser) TestPrivacySettings() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	stranger := s.accounts[3]
+	strangerCookie, err := makeAuthRequest(s.handler, stranger)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	var settings *models.PrivacySettings
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/users/privacy", nil, &settings)
+	s.Require().NoError(err)
+	s.Require().Equal(models.PrivacySettingsWhoCanMessageEveryone, *settings.WhoCanMessage)
+
+	content := "hello"
+	msg := &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+
+	contacts := models.PrivacySettingsWhoCanMessageContacts
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPut, "/g1/users/privacy",
+		&models.PrivacySettings{WhoCanMessage: &contacts}, nil)
+	s.Require().NoError(err)
+
+	// Existing dialogs keep working, new ones are rejected
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, strangerCookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	// With nobody, only dialogs the recepeint has replied in stay open
+	nobody := models.PrivacySettingsWhoCanMessageNobody
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPut, "/g1/users/privacy",
+		&models.PrivacySettings{WhoCanMessage: &nobody}, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	senderAddress := sender.auth.From.String()
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &senderAddress,
+	}, nil)
+	s.Require().NoError(err)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", msg, nil)
+	s.Require().NoError(err)
+}
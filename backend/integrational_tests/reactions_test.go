@@ -0,0 +1,90 @@
+package integrationstests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestReactions() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	content := "hello"
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}, nil)
+	s.Require().NoError(err)
+
+	like := "👍"
+	var change *models.ReactionChange
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPost, "/g1/dialogs/1/messages/1/reactions",
+		&models.ReactionRequest{Emoji: &like}, &change)
+	s.Require().NoError(err)
+	s.Require().True(change.Added)
+	s.Require().Equal(strings.ToLower(recepeintAddress), change.UserAddress)
+	firstChangeID := change.ChangeID
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/1/messages/1/reactions",
+		&models.ReactionRequest{Emoji: &like}, nil)
+	s.Require().NoError(err)
+
+	// Only emoji from the allowed set are accepted
+	unknown := "🦄"
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/1/messages/1/reactions",
+		&models.ReactionRequest{Emoji: &unknown}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	// Outsiders can't react
+	outsiderCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+	err = makeJsonRequestWithError(s.handler, outsiderCookie, http.MethodPost, "/g1/dialogs/1/messages/1/reactions",
+		&models.ReactionRequest{Emoji: &like}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len((*resMessages)[0].Reactions, 1)
+	s.Require().Equal(like, (*resMessages)[0].Reactions[0].Emoji)
+	s.Require().Equal(int64(2), (*resMessages)[0].Reactions[0].Count)
+	s.Require().True((*resMessages)[0].Reactions[0].ReactedByMe)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodDelete,
+		"/g1/dialogs/1/messages/1/reactions?emoji="+url.QueryEscape(like), nil, &change)
+	s.Require().NoError(err)
+	s.Require().False(change.Added)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), (*resMessages)[0].Reactions[0].Count)
+	s.Require().False((*resMessages)[0].Reactions[0].ReactedByMe)
+
+	// Every change is kept so clients can sync incrementally
+	var changes *models.ReactionChangesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/reactions", nil, &changes)
+	s.Require().NoError(err)
+	s.Require().Len(*changes, 3)
+	s.Require().False((*changes)[2].Added)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet,
+		fmt.Sprintf("/g1/dialogs/1/reactions?after=%d", firstChangeID), nil, &changes)
+	s.Require().NoError(err)
+	s.Require().Len(*changes, 2)
+	for _, c := range *changes {
+		s.Require().Greater(c.ChangeID, firstChangeID)
+	}
+}
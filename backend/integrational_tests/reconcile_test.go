@@ -0,0 +1,80 @@
+package integrationstests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/service"
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestReconcile() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	_, err = makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	recepeintAddress := s.accounts[2].auth.From.String()
+
+	for _, content := range []string{"first", "second"} {
+		content := content
+		err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &recepeintAddress,
+			OnChain:     true,
+		}, nil)
+		s.Require().NoError(err)
+	}
+
+	var resMessages *models.MessagesResponse
+	s.Require().Eventually(func() bool {
+		err := makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+		s.Require().NoError(err)
+		for _, m := range *resMessages {
+			if m.ChainTx == nil || m.ChainTx.Status != models.ChainTxStatusConfirmed {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 200*time.Millisecond)
+
+	reconciler := service.NewReconciler(s.cfg.Service.Chain, s.chain.dialogues, s.chain.Relayer(), s.repo.Reconcile,
+		s.repo.Dialogs, s.repo.ChainIndexer, s.repo.Transactions, s.logging)
+	ctx := context.Background()
+
+	report, err := reconciler.Reconcile(ctx, false)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(2), report.Messages)
+	s.Require().Equal(int64(2), report.ChainMessages)
+	s.Require().Empty(report.Diffs)
+
+	// The database has something else than the contract stores
+	tampered := (*resMessages)[1]
+	_, err = s.pgxpool.Exec(ctx, `UPDATE messages SET content='forged' WHERE id=$1`, tampered.MessageID)
+	s.Require().NoError(err)
+
+	report, err = reconciler.Reconcile(ctx, false)
+	s.Require().NoError(err)
+	s.Require().Len(report.Diffs, 1)
+	diff := report.Diffs[0]
+	s.Require().Equal(domain.ReconcileContentMismatch, diff.Kind)
+	s.Require().Equal(tampered.MessageID, diff.Message.MessageID)
+	s.Require().Equal("second", *diff.ChainContent)
+	s.Require().False(diff.Repaired)
+
+	report, err = reconciler.Reconcile(ctx, true)
+	s.Require().NoError(err)
+	s.Require().Len(report.Diffs, 1)
+	s.Require().True(report.Diffs[0].Repaired)
+
+	report, err = reconciler.Reconcile(ctx, false)
+	s.Require().NoError(err)
+	s.Require().Empty(report.Diffs)
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Equal("second", (*resMessages)[1].Content)
+}
@@ -0,0 +1,92 @@
+package integrationstests
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+func (s *TestSuiteUser) TestDisappearingMessages() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	send := func(content string) {
+		err := makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &recepeintAddress,
+		}, nil)
+		s.Require().NoError(err)
+	}
+	send("kept")
+
+	var settings *models.RetentionSettings
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/retention", nil, &settings)
+	s.Require().NoError(err)
+	s.Require().Equal(models.RetentionSettingsModeOff, *settings.Mode)
+
+	// Custom timers are bounded
+	var resErr *models.ErrorResponse
+	custom := models.RetentionSettingsModeCustom
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodPut, "/g1/dialogs/1/retention",
+		&models.RetentionSettings{Mode: &custom, TTL: 1}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	outsiderCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+	hour := models.RetentionSettingsModeNr1h
+	err = makeJsonRequestWithError(s.handler, outsiderCookie, http.MethodPut, "/g1/dialogs/1/retention",
+		&models.RetentionSettings{Mode: &hour}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusForbidden), resErr.Code)
+
+	// Either participant can set it
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodPut, "/g1/dialogs/1/retention",
+		&models.RetentionSettings{Mode: &hour}, nil)
+	s.Require().NoError(err)
+	send("disappearing")
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/retention", nil, &settings)
+	s.Require().NoError(err)
+	s.Require().Equal(models.RetentionSettingsModeNr1h, *settings.Mode)
+	s.Require().Equal(int64(3600), settings.TTL)
+
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 3)
+	s.Require().Equal(models.MessagesResponseItems0KindRetentionChanged, (*resMessages)[1].Kind)
+	s.Require().Equal(models.RetentionSettingsModeNr1h, *(*resMessages)[1].Retention.Mode)
+	s.Require().Zero((*resMessages)[1].ExpiresAt)
+	s.Require().Equal(models.MessagesResponseItems0KindMessage, (*resMessages)[2].Kind)
+	s.Require().NotZero((*resMessages)[2].ExpiresAt)
+
+	// System messages can't be edited
+	edited := "edited"
+	err = makeJsonRequestWithError(s.handler, recepeintCookie, http.MethodPatch, "/g1/dialogs/1/messages/2",
+		&models.EditMessageRequest{Content: &edited}, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+
+	// Expired messages are hidden before the worker gets to them
+	now.SetNow(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	defer now.SetNow(time.Now)
+
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 2)
+	s.Require().Equal("kept", (*resMessages)[0].Content)
+
+	var resDialogs *models.DialogsResponse
+	err = makeJsonRequest(s.handler, cookie, http.MethodGet, "/g1/dialogs", nil, &resDialogs)
+	s.Require().NoError(err)
+	s.Require().Nil((*resDialogs)[0].LastMessage)
+}
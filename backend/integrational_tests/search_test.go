@@ -0,0 +1,71 @@
+package integrationstests
+
+import (
+	"net/http"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+)
+
+func (s *TestSuiteUser) TestSearchMessages() {
+	cookie, err := makeAuthRequest(s.handler, s.accounts[1])
+	s.Require().NoError(err)
+	recepeintCookie, err := makeAuthRequest(s.handler, s.accounts[2])
+	s.Require().NoError(err)
+	outsiderCookie, err := makeAuthRequest(s.handler, s.accounts[3])
+	s.Require().NoError(err)
+
+	recepeintAddress := s.accounts[2].auth.From.String()
+	for _, content := range []string{"let's meet at the gallery", "the gallery is closed today", "ok"} {
+		content := content
+		err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &recepeintAddress,
+		}, nil)
+		s.Require().NoError(err)
+	}
+
+	var res *models.SearchMessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/search/messages?q=gallery", nil, &res)
+	s.Require().NoError(err)
+	s.Require().Len(*res, 2)
+	for _, item := range *res {
+		s.Require().Equal(int64(1), item.DialogID)
+		s.Require().Contains(item.Snippet, "<mark>gallery</mark>")
+	}
+
+	// Pagination
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/search/messages?q=gallery&limit=1&offset=1", nil, &res)
+	s.Require().NoError(err)
+	s.Require().Len(*res, 1)
+
+	// Deleted messages are not searchable
+	err = makeJsonRequest(s.handler, cookie, http.MethodDelete, "/g1/dialogs/1/messages/2", nil, nil)
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/search/messages?q=closed", nil, &res)
+	s.Require().NoError(err)
+	s.Require().Empty(*res)
+
+	// Only dialogs of the caller are searched
+	err = makeJsonRequest(s.handler, outsiderCookie, http.MethodGet, "/g1/search/messages?q=gallery", nil, &res)
+	s.Require().NoError(err)
+	s.Require().Empty(*res)
+
+	// Snippets are HTML, the message text in them is escaped
+	content := `<img src=x onerror="alert(1)"> museum`
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &content,
+		RecipientID: &recepeintAddress,
+	}, nil)
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/search/messages?q=museum", nil, &res)
+	s.Require().NoError(err)
+	s.Require().Len(*res, 1)
+	s.Require().NotContains((*res)[0].Snippet, "<img")
+	s.Require().Contains((*res)[0].Snippet, "&lt;img")
+	s.Require().Contains((*res)[0].Snippet, "<mark>museum</mark>")
+
+	var resErr *models.ErrorResponse
+	err = makeJsonRequestWithError(s.handler, cookie, http.MethodGet, "/g1/search/messages?q=", nil, &resErr)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(http.StatusBadRequest), resErr.Code)
+}
@@ -0,0 +1,86 @@
+package integrationstests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func (s *TestSuiteUser) TestSignedMessages() {
+	sender := s.accounts[1]
+	cookie, err := makeAuthRequest(s.handler, sender)
+	s.Require().NoError(err)
+
+	recepeint := s.accounts[2]
+	recepeintCookie, err := makeAuthRequest(s.handler, recepeint)
+	s.Require().NoError(err)
+	recepeintAddress := recepeint.auth.From.String()
+
+	signed := func(signer *Signer, dialogID int64, content string, signedAt int64) *models.SendMessageRequest {
+		signature, err := signMessage(signer, domain.SignedMessageText(dialogID, recepeintAddress, signedAt, content))
+		s.Require().NoError(err)
+		return &models.SendMessageRequest{
+			Content:     &content,
+			RecipientID: &recepeintAddress,
+			Signature:   signature,
+			SignedAt:    signedAt,
+		}
+	}
+
+	// The first message is signed for dialog 0, it doesn't exist yet
+	signedAt := time.Now().UnixMilli()
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", signed(sender, 0, "hello\nthere", signedAt), nil)
+	s.Require().NoError(err)
+
+	var resErr *models.ErrorResponse
+	for _, req := range []*models.SendMessageRequest{
+		signed(s.accounts[3], 1, "forged", signedAt),
+		signed(sender, 0, "wrong dialog", signedAt),
+		signed(sender, 1, "too old", time.Now().Add(-time.Hour).UnixMilli()),
+	} {
+		err = makeJsonRequestWithError(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", req, &resErr)
+		s.Require().NoError(err)
+		s.Require().Equal(int64(http.StatusBadRequest), resErr.Code, *req.Content)
+	}
+
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", signed(sender, 1, "second", signedAt), nil)
+	s.Require().NoError(err)
+	unsigned := "unsigned"
+	err = makeJsonRequest(s.handler, cookie, http.MethodPost, "/g1/dialogs/message", &models.SendMessageRequest{
+		Content:     &unsigned,
+		RecipientID: &recepeintAddress,
+	}, nil)
+	s.Require().NoError(err)
+
+	// Anyone holding the message can check who wrote it
+	var resMessages *models.MessagesResponse
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Len(*resMessages, 3)
+	for _, msg := range (*resMessages)[:2] {
+		s.Require().NotNil(msg.Signature)
+		text := domain.SignedMessageText(msg.Signature.DialogID, msg.Signature.RecipientAddress, msg.Signature.SignedAt, msg.Content)
+		hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text)))
+		sig := hexutil.MustDecode(msg.Signature.Signature)
+		pub, err := crypto.SigToPub(hash, sig)
+		s.Require().NoError(err)
+		s.Require().True(strings.EqualFold(msg.SenderAddress, crypto.PubkeyToAddress(*pub).Hex()))
+	}
+	s.Require().Equal(int64(0), (*resMessages)[0].Signature.DialogID)
+	s.Require().Equal(int64(1), (*resMessages)[1].Signature.DialogID)
+	s.Require().Nil((*resMessages)[2].Signature)
+
+	// Editing drops the signature, it no longer matches the content
+	edited := "second, edited"
+	err = makeJsonRequest(s.handler, cookie, http.MethodPatch, "/g1/dialogs/1/messages/2", &models.EditMessageRequest{Content: &edited}, nil)
+	s.Require().NoError(err)
+	err = makeJsonRequest(s.handler, recepeintCookie, http.MethodGet, "/g1/dialogs/1/messages", nil, &resMessages)
+	s.Require().NoError(err)
+	s.Require().Nil((*resMessages)[1].Signature)
+}
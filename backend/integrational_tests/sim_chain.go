@@ -0,0 +1,261 @@
+package integrationstests
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/anchor"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/ens"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/escrow"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/relayed"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/tokentest"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/require"
+)
+
+// minedBackend mines every transaction it sends right away, in a block of its own.
+type minedBackend struct {
+	simulated.Client
+	sim *simulated.Backend
+	mu  sync.Mutex
+}
+
+func (b *minedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.sim.Commit()
+	return nil
+}
+
+// simChain is a simulated node with the contracts deployed, the service talks to it through the
+// real chain clients. The relayer deploys the contracts it operates, owner the rest.
+type simChain struct {
+	require *require.Assertions
+	sim     *simulated.Backend
+	backend *minedBackend
+	// owner owns every name in the registry and mints the test tokens, it isn't the relayer
+	// so the relayer's nonce stays its own
+	owner *bind.TransactOpts
+
+	messaging *relayed.RelayedMessaging
+	anchor    *anchor.MessageAnchor
+	escrow    *escrow.MessageEscrow
+	registry  *ens.Registry
+	resolver  *ens.Resolver
+	escrowAt  common.Address
+	resolveAt common.Address
+
+	client    chain.Client
+	events    chain.EventSource
+	anchorer  chain.Anchorer
+	holdings  chain.Holdings
+	payments  chain.Payments
+	names     chain.Names
+	dialogues chain.DialogueReader
+}
+
+// newSimChain funds the accounts and points cfg at the deployed contracts.
+func newSimChain(r *require.Assertions, accounts map[int64]*Signer, cfg *config.ServiceConfig) *simChain {
+	relayerKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	ownerKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	alloc := types.GenesisAlloc{
+		crypto.PubkeyToAddress(relayerKey.PublicKey): {Balance: ether(1000)},
+		crypto.PubkeyToAddress(ownerKey.PublicKey):   {Balance: ether(1000)},
+	}
+	for _, a := range accounts {
+		alloc[a.auth.From] = types.Account{Balance: ether(1000)}
+	}
+	sim := simulated.NewBackend(alloc)
+	// the block pending on top of the genesis doesn't run the Shanghai rules the contracts need
+	sim.Commit()
+
+	chainID := big.NewInt(cfg.Chain.ChainID)
+	c := &simChain{require: r, sim: sim, backend: &minedBackend{Client: sim.Client(), sim: sim}}
+	operator, err := bind.NewKeyedTransactorWithChainID(relayerKey, chainID)
+	r.NoError(err)
+	c.owner, err = bind.NewKeyedTransactorWithChainID(ownerKey, chainID)
+	r.NoError(err)
+
+	var contract, anchorAt, registryAt common.Address
+	contract, _, c.messaging, err = relayed.DeployRelayedMessaging(operator, c.backend)
+	r.NoError(err)
+	anchorAt, _, c.anchor, err = anchor.DeployMessageAnchor(operator, c.backend)
+	r.NoError(err)
+	c.escrowAt, _, c.escrow, err = escrow.DeployMessageEscrow(operator, c.backend)
+	r.NoError(err)
+	registryAt, _, c.registry, err = ens.DeployRegistry(c.owner, c.backend)
+	r.NoError(err)
+	c.resolveAt, _, c.resolver, err = ens.DeployResolver(c.owner, c.backend, registryAt)
+	r.NoError(err)
+	cfg.Chain.ContractAddress = contract.Hex()
+	cfg.Anchor.ContractAddress = anchorAt.Hex()
+	cfg.Payments.EscrowAddress = c.escrowAt.Hex()
+	cfg.Names.RegistryAddress = registryAt.Hex()
+
+	relayer, err := chain.NewRelayer(c.backend, relayerKey, chainID, chain.GasPolicy{BumpPercent: cfg.Chain.Gas.BumpPercent})
+	r.NoError(err)
+	c.client, err = chain.NewClient(c.backend, contract, relayer)
+	r.NoError(err)
+	c.events, err = chain.NewEventSource(c.backend, contract)
+	r.NoError(err)
+	c.anchorer, err = chain.NewAnchorer(c.backend, anchorAt, relayer)
+	r.NoError(err)
+	c.holdings = chain.NewHoldings(c.backend)
+	c.payments, err = chain.NewPayments(c.backend, chainID, c.escrowAt, relayer)
+	r.NoError(err)
+	c.names, err = chain.NewNames(c.backend, registryAt)
+	r.NoError(err)
+	c.dialogues, err = chain.NewDialogueReader(c.backend, contract)
+	r.NoError(err)
+	return c
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func (c *simChain) close() {
+	c.require.NoError(c.sim.Close())
+}
+
+// mined requires the transaction to have succeeded and returns its hash.
+func (c *simChain) mined(tx *types.Transaction, err error) common.Hash {
+	c.require.NoError(err)
+	receipt, err := c.backend.TransactionReceipt(context.Background(), tx.Hash())
+	c.require.NoError(err)
+	c.require.Equal(types.ReceiptStatusSuccessful, receipt.Status)
+	return tx.Hash()
+}
+
+func (c *simChain) Relayer() common.Address {
+	return c.client.Relayer()
+}
+
+func (c *simChain) Domain() *chain.Domain {
+	return c.client.Domain()
+}
+
+func (c *simChain) Escrow() common.Address {
+	return c.escrowAt
+}
+
+// emit sends a message to the contract from the sender's wallet.
+func (c *simChain) emit(sender *Signer, receiver common.Address, content string) common.Hash {
+	return c.mined(c.messaging.SendMessage(sender.auth, receiver, content))
+}
+
+// get returns the MessageSent log of the transaction, nil if it didn't send a message.
+func (c *simChain) get(hash string) *chain.MessageSent {
+	ctx := context.Background()
+	receipt, err := c.backend.TransactionReceipt(ctx, common.HexToHash(hash))
+	c.require.NoError(err)
+	block := receipt.BlockNumber.Uint64()
+	logs, err := c.events.MessageSent(ctx, block, block)
+	c.require.NoError(err)
+	for _, l := range logs {
+		if l.TxHash == receipt.TxHash {
+			return l
+		}
+	}
+	return nil
+}
+
+// anchored returns the root committed for batchID.
+func (c *simChain) anchored(batchID int64) (common.Hash, bool) {
+	root, err := c.anchor.Roots(&bind.CallOpts{}, big.NewInt(batchID))
+	c.require.NoError(err)
+	return root, root != [32]byte{}
+}
+
+// revertedAnchor sends an anchor for batchID from an account the contract takes no roots from. The gas
+// limit skips the estimate, so the transaction is mined and reverts.
+func (c *simChain) revertedAnchor(batchID int64, root common.Hash) common.Hash {
+	opts := *c.owner
+	opts.GasLimit = 100000
+	tx, err := c.anchor.Anchor(&opts, big.NewInt(batchID), root)
+	c.require.NoError(err)
+	return tx.Hash()
+}
+
+func (c *simChain) newToken() (common.Address, *tokentest.TestToken) {
+	address, tx, token, err := tokentest.DeployTestToken(c.owner, c.backend)
+	c.mined(tx, err)
+	return address, token
+}
+
+func (c *simChain) newCollection() (common.Address, *tokentest.TestCollection) {
+	address, tx, collection, err := tokentest.DeployTestCollection(c.owner, c.backend)
+	c.mined(tx, err)
+	return address, collection
+}
+
+// transfer sends amount wei from the account.
+func (c *simChain) transfer(from *Signer, to common.Address, amount int64) common.Hash {
+	ctx := context.Background()
+	nonce, err := c.backend.PendingNonceAt(ctx, from.auth.From)
+	c.require.NoError(err)
+	gasPrice, err := c.backend.SuggestGasPrice(ctx)
+	c.require.NoError(err)
+	tx, err := from.auth.Signer(from.auth.From, types.NewTx(&types.LegacyTx{
+		Nonce: nonce, To: &to, Value: big.NewInt(amount), Gas: 21000, GasPrice: gasPrice,
+	}))
+	c.require.NoError(err)
+	return c.mined(tx, c.backend.SendTransaction(ctx, tx))
+}
+
+// deposit pays amount wei into the escrow for the recipient. Unless mined, the transaction stays
+// pending until the next one is sent.
+func (c *simChain) deposit(from *Signer, recipient common.Address, amount int64, mined bool) common.Hash {
+	opts := *from.auth
+	opts.Value = big.NewInt(amount)
+	if mined {
+		return c.mined(c.escrow.Deposit(&opts, recipient))
+	}
+	pending, err := escrow.NewMessageEscrowTransactor(c.escrowAt, c.sim.Client())
+	c.require.NoError(err)
+	tx, err := pending.Deposit(&opts, recipient)
+	c.require.NoError(err)
+	return tx.Hash()
+}
+
+// refunded reports whether the escrow deposit was refunded.
+func (c *simChain) refunded(depositID int64) bool {
+	deposit, err := c.escrow.Deposits(&bind.CallOpts{}, big.NewInt(depositID))
+	c.require.NoError(err)
+	return deposit.Settled
+}
+
+// setName sets the forward record of name and, with primary, the address's reverse record.
+func (c *simChain) setName(name string, address common.Address, primary bool) {
+	c.mined(c.resolver.SetAddr(c.owner, c.claim(name), address))
+	if primary {
+		reverse := c.claim(strings.ToLower(strings.TrimPrefix(address.Hex(), "0x")) + ".addr.reverse")
+		c.mined(c.resolver.SetName(c.owner, reverse, name))
+	}
+}
+
+// claim makes the owner own name and points it to the resolver.
+func (c *simChain) claim(name string) common.Hash {
+	labels := strings.Split(name, ".")
+	node := common.Hash{}
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256Hash([]byte(labels[i]))
+		c.mined(c.registry.SetSubnodeOwner(c.owner, node, label, c.owner.From))
+		node = crypto.Keccak256Hash(node.Bytes(), label.Bytes())
+	}
+	c.mined(c.registry.SetResolver(c.owner, node, c.resolveAt))
+	return node
+}
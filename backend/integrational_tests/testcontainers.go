@@ -17,6 +17,7 @@ import (
 	"github.com/Pyegorchik/bdd/backend/internal/repository/postgres"
 	"github.com/Pyegorchik/bdd/backend/internal/service"
 	"github.com/Pyegorchik/bdd/backend/migrations"
+	"github.com/Pyegorchik/bdd/backend/pkg/blobstore"
 	"github.com/Pyegorchik/bdd/backend/pkg/hash"
 	"github.com/Pyegorchik/bdd/backend/pkg/jwtoken"
 	"github.com/Pyegorchik/bdd/backend/pkg/logger"
@@ -39,6 +40,7 @@ type TestSuite struct {
 	repo       repository.Repository
 	pgxpool    *pgxpool.Pool
 	accounts   map[int64]*Signer
+	chain      *simChain
 	server     *httptest.Server
 	handler    http.Handler
 
@@ -51,6 +53,14 @@ func (s *TestSuite) SetupSuite() {
 	s.cfg, err = config.Init("../configs/local")
 	s.Require().NoError(err)
 	s.cfg.Service.Mode = ModeIntTest
+	// anchor right away so the proofs can be checked without waiting out the edit window
+	s.cfg.Service.Anchor.WorkerInterval = 200 * time.Millisecond
+	s.cfg.Service.Anchor.SettleDelay = 0
+	s.cfg.Service.Chain.Relay.Enabled = true
+	s.cfg.Service.TokenGate.WorkerInterval = 200 * time.Millisecond
+	s.cfg.Service.TokenGate.RecheckInterval = 0
+	s.cfg.Service.Payments.WorkerInterval = 200 * time.Millisecond
+	s.cfg.Service.Chains = append(s.cfg.Service.Chains, &config.SupportedChainConfig{ChainID: 10, Name: "OP Mainnet"})
 }
 
 func (s *TestSuite) SetupTest() {
@@ -95,7 +105,13 @@ func (s *TestSuite) SetupTest() {
 
 	s.Require().NoError(s.setupBlockChain(ctx))
 
-	s.service, err = service.NewService(repo, jwtokenManager, hash.NewHashManager(), s.cfg.Service, logging)
+	blobStore, err := blobstore.NewLocalStore(s.T().TempDir())
+	s.Require().NoError(err)
+	s.cfg.Service.Attachments.SigningKey = "integrationtest"
+
+	s.chain = newSimChain(s.Require(), s.accounts, s.cfg.Service)
+	s.service, err = service.NewService(repo, jwtokenManager, hash.NewHashManager(), blobStore, s.chain.client,
+		s.chain.events, s.chain.anchorer, s.chain.holdings, s.chain.payments, s.chain.names, s.cfg.Service, logging)
 	s.Require().NoError(err)
 
 	h := handler.NewHandler(s.cfg.Handler, s.service, logging)
@@ -245,6 +261,7 @@ func (p *PostgreSQLContainer) GetDSN() string {
 func (s *TestSuite) TearDownTest() {
 	ctx := context.Background()
 	s.service.Shutdown()
+	s.chain.close()
 	s.Require().NoError(s.postgreSQL.Terminate(ctx))
 }
 
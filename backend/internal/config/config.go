@@ -1,6 +1,10 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -35,6 +39,7 @@ type (
 	HandlerConfig struct {
 		RequestTimeout time.Duration
 		SwaggerHost    string
+		MaxUploadSize  int64
 	}
 
 	ServiceConfig struct {
@@ -42,6 +47,171 @@ type (
 		RefreshTokenTTL time.Duration
 		StaticPath      string
 		Mode            string
+
+		MessageEditWindow time.Duration
+		// Postgres text search configuration used to index and query messages
+		SearchLanguage string
+
+		Attachments *AttachmentsConfig
+		QRLogin     *QRLoginConfig
+		Retention   *RetentionConfig
+		// PendingInbox limits messages sent to addresses that haven't signed in yet
+		PendingInbox *PendingInboxConfig
+		Chain        *ChainConfig
+		Anchor       *AnchorConfig
+		TokenGate    *TokenGateConfig
+		Payments     *PaymentsConfig
+		// MaxPendingMessages is how many messages a sender can send until the recipient accepts the message request
+		MaxPendingMessages int64
+		// SignedMessageMaxSkew bounds how far the client timestamp of a signed message may be from the server time
+		SignedMessageMaxSkew time.Duration
+		// IdempotencyKeyTTL is how long a client message id is remembered for retries
+		IdempotencyKeyTTL time.Duration
+		// Chains are the chains users can sign in from, the chain of Chain is always one of them
+		Chains []*SupportedChainConfig
+		Names  *NamesConfig
+	}
+
+	QRLoginConfig struct {
+		SessionTTL  time.Duration
+		PollTimeout time.Duration
+		// URIBase is the deep link the QR code points to, the session id is appended as a query parameter
+		URIBase string
+	}
+
+	RetentionConfig struct {
+		// WorkerInterval is how often expired messages are purged
+		WorkerInterval time.Duration
+		// BatchSize is how many messages are deleted per transaction
+		BatchSize int64
+		// MaxTTL bounds the custom retention a dialog can be set to
+		MaxTTL time.Duration
+	}
+
+	PendingInboxConfig struct {
+		// MaxRecipients is how many unregistered addresses a sender can start a conversation with per Window,
+		// each of them is also capped at MaxPendingMessages messages
+		MaxRecipients int64
+		Window        time.Duration
+	}
+
+	ChainConfig struct {
+		// RPCURLs are endpoints of the same chain, on-chain sending and indexing are disabled when there are none
+		RPCURLs         []string
+		RPC             *RPCConfig
+		ChainID         int64
+		ContractAddress string
+		// RelayerKey signs the Messaging.sendMessage transactions, only the indexer runs without it
+		RelayerKey string
+		// WorkerInterval is how often queued messages are submitted and receipts are checked
+		WorkerInterval time.Duration
+		BatchSize      int64
+		// IndexerStartBlock is where the MessageSent indexer starts on its first run
+		IndexerStartBlock int64
+		// Confirmations is how deep a block has to be before its logs are indexed
+		Confirmations int64
+		// IndexerBatchBlocks bounds the block range of a single logs query
+		IndexerBatchBlocks int64
+		Gas                *GasConfig
+		Relay              *RelayConfig
+	}
+
+	SupportedChainConfig struct {
+		ChainID int64
+		Name    string
+		// RPCURLs are the chain's endpoints, the chain of Chain always uses Chain.RPCURLs
+		RPCURLs []string
+	}
+
+	GasConfig struct {
+		// MaxFeeGwei caps the fee per gas the relayer pays, 0 for no cap
+		MaxFeeGwei int64
+		// BumpPercent is how much more a replacement pays than the transaction it replaces, at least 10
+		BumpPercent int64
+		// ReplaceAfter is how long a transaction may stay unmined before it's replaced with higher fees
+		ReplaceAfter time.Duration
+		// MaxSendAttempts is how many times a transaction the node rejects is retried before it's marked failed
+		MaxSendAttempts int64
+	}
+
+	RelayConfig struct {
+		// Enabled turns on gasless messages, the contract has to be RelayedMessaging
+		Enabled bool
+		// Quota is how many gasless messages a user can send per Window
+		Quota  int64
+		Window time.Duration
+	}
+
+	RPCConfig struct {
+		// HedgeDelay is how long a read waits for the healthiest endpoint before it's also sent to the next one
+		HedgeDelay time.Duration
+		// MaxHeadLag is how many blocks an endpoint may trail the others and still take transactions
+		MaxHeadLag uint64
+		// HealthInterval is how often the endpoints' heads are polled
+		HealthInterval time.Duration
+	}
+
+	AnchorConfig struct {
+		// ContractAddress is the MessageAnchor contract, anchoring is disabled when it's empty
+		ContractAddress string
+		// WorkerInterval is how often batches are built, submitted and their receipts are checked
+		WorkerInterval time.Duration
+		// BatchSize is the most messages a single root commits to
+		BatchSize int64
+		// SettleDelay is how old a message has to be before it's anchored, it should cover the edit window
+		SettleDelay time.Duration
+	}
+
+	TokenGateConfig struct {
+		// MaxGates is how many tokens a user can gate the inbox with
+		MaxGates int64
+		// CacheTTL is how long a token balance read from the chain is reused
+		CacheTTL time.Duration
+		// WorkerInterval is how often dialogs are picked up for a recheck
+		WorkerInterval time.Duration
+		// RecheckInterval is how often the other participant of a gated dialog is checked again
+		RecheckInterval time.Duration
+		BatchSize       int64
+	}
+
+	PaymentsConfig struct {
+		// EscrowAddress is the MessageEscrow contract, payments are only made directly to recipients when it's empty
+		EscrowAddress string
+		// Confirmations is how deep a payment has to be mined before a message is delivered
+		Confirmations int64
+		// WorkerInterval is how often escrow refunds are submitted and their receipts are checked
+		WorkerInterval time.Duration
+		BatchSize      int64
+		// MaxRefundAttempts is how many times a refund the node rejects is retried
+		MaxRefundAttempts int64
+	}
+
+	NamesConfig struct {
+		// RegistryAddress is the ENS-compatible registry, names are disabled when it's empty
+		RegistryAddress string
+		// ChainID is the chain of the registry, 0 means the chain of Chain
+		ChainID int64
+		// CacheTTL is how long a resolved or reverse-resolved name is reused
+		CacheTTL time.Duration
+		// LookupTimeout bounds the name lookups of a dialog or message listing, names still missing then are left out
+		LookupTimeout time.Duration
+	}
+
+	AttachmentsConfig struct {
+		// Storage is either "local" or "s3"
+		Storage          string
+		LocalPath        string
+		MaxSize          int64
+		MaxCount         int
+		AllowedMimeTypes []string
+		URLTTL           time.Duration
+		SigningKey       string
+
+		S3Endpoint  string
+		S3Region    string
+		S3Bucket    string
+		S3AccessKey string
+		S3SecretKey string
 	}
 
 	TokenManagerConfig struct {
@@ -65,7 +235,13 @@ func Init(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("config/Init/envCfg.ReadInConfig: %w", err)
 	}
 
-	return &Config{
+	attachmentsSigningKey, err := deriveAttachmentsSigningKey(envCfg.GetString("ATTACHMENTS_SIGNING_KEY"),
+		envCfg.GetString("JWT_SIGNING_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("config/Init/deriveAttachmentsSigningKey: %w", err)
+	}
+
+	cfg := &Config{
 		Postgres: &PostgresConfig{
 			Host:     envCfg.GetString("POSTGRES_HOST"),
 			User:     envCfg.GetString("POSTGRES_USER"),
@@ -82,17 +258,167 @@ func Init(configPath string) (*Config, error) {
 		Handler: &HandlerConfig{
 			RequestTimeout: jsonCfg.GetDuration("handler.requestTimeout"),
 			SwaggerHost:    jsonCfg.GetString("handler.swaggerHost"),
+			MaxUploadSize:  jsonCfg.GetInt64("handler.maxUploadSize"),
 		},
 		Service: &ServiceConfig{
 			AccessTokenTTL:  jsonCfg.GetDuration("service.accessTTL"),
 			RefreshTokenTTL: jsonCfg.GetDuration("service.refreshTTL"),
 			StaticPath:      jsonCfg.GetString("service.staticPath"),
 			Mode:            jsonCfg.GetString("mode"),
+
+			MessageEditWindow: jsonCfg.GetDuration("service.messageEditWindow"),
+			SearchLanguage:    jsonCfg.GetString("service.searchLanguage"),
+
+			MaxPendingMessages:   jsonCfg.GetInt64("service.maxPendingMessages"),
+			SignedMessageMaxSkew: jsonCfg.GetDuration("service.signedMessageMaxSkew"),
+			IdempotencyKeyTTL:    jsonCfg.GetDuration("service.idempotencyKeyTTL"),
+
+			Attachments: &AttachmentsConfig{
+				Storage:          jsonCfg.GetString("service.attachments.storage"),
+				LocalPath:        jsonCfg.GetString("service.attachments.localPath"),
+				MaxSize:          jsonCfg.GetInt64("service.attachments.maxSize"),
+				MaxCount:         jsonCfg.GetInt("service.attachments.maxCount"),
+				AllowedMimeTypes: jsonCfg.GetStringSlice("service.attachments.allowedMimeTypes"),
+				URLTTL:           jsonCfg.GetDuration("service.attachments.urlTTL"),
+				SigningKey:       attachmentsSigningKey,
+
+				S3Endpoint:  jsonCfg.GetString("service.attachments.s3.endpoint"),
+				S3Region:    jsonCfg.GetString("service.attachments.s3.region"),
+				S3Bucket:    jsonCfg.GetString("service.attachments.s3.bucket"),
+				S3AccessKey: envCfg.GetString("S3_ACCESS_KEY"),
+				S3SecretKey: envCfg.GetString("S3_SECRET_KEY"),
+			},
+			QRLogin: &QRLoginConfig{
+				SessionTTL:  jsonCfg.GetDuration("service.qrLogin.sessionTTL"),
+				PollTimeout: jsonCfg.GetDuration("service.qrLogin.pollTimeout"),
+				URIBase:     jsonCfg.GetString("service.qrLogin.uriBase"),
+			},
+			Retention: &RetentionConfig{
+				WorkerInterval: jsonCfg.GetDuration("service.retention.workerInterval"),
+				BatchSize:      jsonCfg.GetInt64("service.retention.batchSize"),
+				MaxTTL:         jsonCfg.GetDuration("service.retention.maxTTL"),
+			},
+			PendingInbox: &PendingInboxConfig{
+				MaxRecipients: jsonCfg.GetInt64("service.pendingInbox.maxRecipients"),
+				Window:        jsonCfg.GetDuration("service.pendingInbox.window"),
+			},
+			Chain: &ChainConfig{
+				RPCURLs: jsonCfg.GetStringSlice("service.chain.rpcURLs"),
+				RPC: &RPCConfig{
+					HedgeDelay:     jsonCfg.GetDuration("service.chain.rpc.hedgeDelay"),
+					MaxHeadLag:     jsonCfg.GetUint64("service.chain.rpc.maxHeadLag"),
+					HealthInterval: jsonCfg.GetDuration("service.chain.rpc.healthInterval"),
+				},
+				ChainID:            jsonCfg.GetInt64("service.chain.chainID"),
+				ContractAddress:    jsonCfg.GetString("service.chain.contractAddress"),
+				RelayerKey:         envCfg.GetString("RELAYER_PRIVATE_KEY"),
+				WorkerInterval:     jsonCfg.GetDuration("service.chain.workerInterval"),
+				BatchSize:          jsonCfg.GetInt64("service.chain.batchSize"),
+				IndexerStartBlock:  jsonCfg.GetInt64("service.chain.indexerStartBlock"),
+				Confirmations:      jsonCfg.GetInt64("service.chain.confirmations"),
+				IndexerBatchBlocks: jsonCfg.GetInt64("service.chain.indexerBatchBlocks"),
+				Gas: &GasConfig{
+					MaxFeeGwei:      jsonCfg.GetInt64("service.chain.gas.maxFeeGwei"),
+					BumpPercent:     jsonCfg.GetInt64("service.chain.gas.bumpPercent"),
+					ReplaceAfter:    jsonCfg.GetDuration("service.chain.gas.replaceAfter"),
+					MaxSendAttempts: jsonCfg.GetInt64("service.chain.gas.maxSendAttempts"),
+				},
+				Relay: &RelayConfig{
+					Enabled: jsonCfg.GetBool("service.chain.relay.enabled"),
+					Quota:   jsonCfg.GetInt64("service.chain.relay.quota"),
+					Window:  jsonCfg.GetDuration("service.chain.relay.window"),
+				},
+			},
+			Anchor: &AnchorConfig{
+				ContractAddress: jsonCfg.GetString("service.anchor.contractAddress"),
+				WorkerInterval:  jsonCfg.GetDuration("service.anchor.workerInterval"),
+				BatchSize:       jsonCfg.GetInt64("service.anchor.batchSize"),
+				SettleDelay:     jsonCfg.GetDuration("service.anchor.settleDelay"),
+			},
+			TokenGate: &TokenGateConfig{
+				MaxGates:        jsonCfg.GetInt64("service.tokenGate.maxGates"),
+				CacheTTL:        jsonCfg.GetDuration("service.tokenGate.cacheTTL"),
+				WorkerInterval:  jsonCfg.GetDuration("service.tokenGate.workerInterval"),
+				RecheckInterval: jsonCfg.GetDuration("service.tokenGate.recheckInterval"),
+				BatchSize:       jsonCfg.GetInt64("service.tokenGate.batchSize"),
+			},
+			Payments: &PaymentsConfig{
+				EscrowAddress:     jsonCfg.GetString("service.payments.escrowAddress"),
+				Confirmations:     jsonCfg.GetInt64("service.payments.confirmations"),
+				WorkerInterval:    jsonCfg.GetDuration("service.payments.workerInterval"),
+				BatchSize:         jsonCfg.GetInt64("service.payments.batchSize"),
+				MaxRefundAttempts: jsonCfg.GetInt64("service.payments.maxRefundAttempts"),
+			},
+			Names: &NamesConfig{
+				RegistryAddress: jsonCfg.GetString("service.names.registryAddress"),
+				ChainID:         jsonCfg.GetInt64("service.names.chainID"),
+				CacheTTL:        jsonCfg.GetDuration("service.names.cacheTTL"),
+				LookupTimeout:   jsonCfg.GetDuration("service.names.lookupTimeout"),
+			},
 		},
 		TokenManager: &TokenManagerConfig{
 			SigningKey: envCfg.GetString("JWT_SIGNING_KEY"),
 		},
-	}, nil
+	}
+
+	chains, err := supportedChains(jsonCfg, cfg.Service.Chain)
+	if err != nil {
+		return nil, fmt.Errorf("config/Init/supportedChains: %w", err)
+	}
+	cfg.Service.Chains = chains
+
+	return cfg, nil
+}
+
+// deriveAttachmentsSigningKey returns the key of attachment download links. Without a dedicated key it's
+// derived from the JWT key under a label of its own, so a link signature never passes for a token's.
+func deriveAttachmentsSigningKey(key, jwtKey string) (string, error) {
+	if key != "" {
+		return key, nil
+	}
+	if jwtKey == "" {
+		return "", errors.New("neither ATTACHMENTS_SIGNING_KEY nor JWT_SIGNING_KEY is set")
+	}
+	mac := hmac.New(sha256.New, []byte(jwtKey))
+	mac.Write([]byte("bdd attachment links"))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// supportedChains reads service.chains and adds the chain of primary when it isn't listed.
+func supportedChains(jsonCfg *viper.Viper, primary *ChainConfig) ([]*SupportedChainConfig, error) {
+	var chains []*SupportedChainConfig
+	if err := jsonCfg.UnmarshalKey("service.chains", &chains); err != nil {
+		return nil, fmt.Errorf("supportedChains/UnmarshalKey: %w", err)
+	}
+
+	seen := make(map[int64]bool, len(chains))
+	for _, c := range chains {
+		if c.ChainID <= 0 {
+			return nil, fmt.Errorf("supportedChains: invalid chain id %d", c.ChainID)
+		}
+		if seen[c.ChainID] {
+			return nil, fmt.Errorf("supportedChains: chain %d is listed twice", c.ChainID)
+		}
+		seen[c.ChainID] = true
+		if c.ChainID == primary.ChainID {
+			c.RPCURLs = primary.RPCURLs
+		}
+	}
+	if !seen[primary.ChainID] {
+		chains = append([]*SupportedChainConfig{{ChainID: primary.ChainID, RPCURLs: primary.RPCURLs}}, chains...)
+	}
+
+	return chains, nil
+}
+
+// SupportedChain returns nil when users can't sign in from chainID.
+func (s *ServiceConfig) SupportedChain(chainID int64) *SupportedChainConfig {
+	for _, c := range s.Chains {
+		if c.ChainID == chainID {
+			return c
+		}
+	}
+	return nil
 }
 
 func (p *PostgresConfig) PgSource() string {
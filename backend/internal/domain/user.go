@@ -1,14 +1,24 @@
 package domain
 
 import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
 	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type (
 	Role int
 )
 
+// MessagePreviewLength is the number of characters of the last message shown in the dialog list.
+const MessagePreviewLength = 100
+
 type UserWithTokenNumber struct {
 	ID         int64
 	Role       Role
@@ -20,6 +30,192 @@ type AuthMessage struct {
 	Address   string
 	Message   string
 	CreatedAt int64
+	// ChainID is the chain the user signs in from
+	ChainID int64
+}
+
+type LoginSessionStatus int
+
+const (
+	LoginSessionPending = LoginSessionStatus(iota)
+	LoginSessionVerified
+	LoginSessionRedeemed
+)
+
+func (s LoginSessionStatus) String() string {
+	switch s {
+	case LoginSessionPending:
+		return "pending"
+	case LoginSessionVerified:
+		return "verified"
+	case LoginSessionRedeemed:
+		return "redeemed"
+	default:
+		return "unknown"
+	}
+}
+
+// LoginSession is a cross-device login started on one device and signed on another.
+type LoginSession struct {
+	ID            string
+	PollTokenHash string
+	Message       string
+	Status        LoginSessionStatus
+	UserID        *int64
+	CreatedAt     int64
+	ExpiresAt     int64
+	ChainID       int64
+}
+
+// MessagePrivacy controls who can message a user.
+type MessagePrivacy int
+
+const (
+	// MessagePrivacyEveryone lets any registered user message the user.
+	MessagePrivacyEveryone = MessagePrivacy(iota)
+	// MessagePrivacyContacts rejects new dialogs, existing dialogs keep working.
+	MessagePrivacyContacts
+	// MessagePrivacyNobody rejects new dialogs and existing dialogs the user has never replied in.
+	MessagePrivacyNobody
+)
+
+var messagePrivacyNames = map[MessagePrivacy]string{
+	MessagePrivacyEveryone: "everyone",
+	MessagePrivacyContacts: "contacts",
+	MessagePrivacyNobody:   "nobody",
+}
+
+func (p MessagePrivacy) String() string {
+	if name, ok := messagePrivacyNames[p]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func ParseMessagePrivacy(s string) (MessagePrivacy, bool) {
+	for p, name := range messagePrivacyNames {
+		if name == s {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+type BlockedUser struct {
+	UserID    int64
+	Address   string
+	CreatedAt int64
+}
+
+// TokenStandard is the kind of token an inbox gate asks senders to hold.
+type TokenStandard int
+
+const (
+	TokenERC20 = TokenStandard(iota)
+	TokenERC721
+)
+
+var tokenStandardNames = map[TokenStandard]string{
+	TokenERC20:  "erc20",
+	TokenERC721: "erc721",
+}
+
+func (t TokenStandard) String() string {
+	if name, ok := tokenStandardNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func ParseTokenStandard(s string) (TokenStandard, bool) {
+	for t, name := range tokenStandardNames {
+		if name == s {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// InboxGate lets only holders of at least MinBalance of a token open a dialog with the user.
+// A sender who meets any one of the user's gates qualifies.
+type InboxGate struct {
+	ID           int64
+	UserID       int64
+	TokenAddress string
+	Standard     TokenStandard
+	// MinBalance is a decimal amount in the token's base units, the number of tokens for ERC-721
+	MinBalance string
+	CreatedAt  int64
+}
+
+// GatedDialog is a dialog of a user with inbox gates, the other participant is rechecked against them.
+type GatedDialog struct {
+	DialogID           int64
+	OwnerID            int64
+	CounterpartAddress string
+	GateFailedAt       *int64
+}
+
+// MessagePrice is what a stranger pays to open a dialog with the user.
+type MessagePrice struct {
+	// TokenAddress is the ERC-20 token the price is in, nil for ETH
+	TokenAddress *string
+	// Amount is a decimal amount in wei or the token's base units
+	Amount string
+}
+
+type PaymentStatus int
+
+const (
+	// PaymentPaid is a transfer made straight to the recipient, it can't be refunded
+	PaymentPaid PaymentStatus = iota
+	// PaymentHeld is a MessageEscrow deposit, refunded once the recipient replies
+	PaymentHeld
+	PaymentRefundQueued
+	PaymentRefundSubmitted
+	PaymentRefunded
+	PaymentRefundFailed
+)
+
+func (s PaymentStatus) String() string {
+	switch s {
+	case PaymentHeld:
+		return "held"
+	case PaymentRefundQueued, PaymentRefundSubmitted:
+		return "refunding"
+	case PaymentRefunded:
+		return "refunded"
+	case PaymentRefundFailed:
+		return "refund_failed"
+	default:
+		return "paid"
+	}
+}
+
+// MessagePayment is the transaction a sender paid a user's MessagePrice with to open a dialog.
+type MessagePayment struct {
+	ID     int64
+	TxHash string
+	// MessageID is nil once the message is gone, the row stays so the transaction can't pay twice
+	MessageID    *int64
+	DialogID     int64
+	PayerID      int64
+	RecipientID  int64
+	TokenAddress *string
+	Amount       string
+	// DepositID is the MessageEscrow deposit, nil for direct transfers
+	DepositID    *string
+	Status       PaymentStatus
+	RefundTxHash *string
+	// RefundSent describes the refund RefundTxHash points to, it's nil until the first one is broadcast
+	RefundSent *SentChainTx
+	// ReplacedRefundTxHashes are the refunds the current one replaced, any of them may still get mined
+	ReplacedRefundTxHashes []string
+	Error                  *string
+	// Attempts counts the failed refund broadcasts
+	Attempts  int64
+	CreatedAt int64
+	UpdatedAt int64
 }
 
 type UserChain struct {
@@ -32,9 +228,51 @@ type Dialog struct {
 	ID int64
 }
 
+// DialogStatus tracks first contact: a dialog opened by a stranger stays a message request until the recipient accepts it.
+type DialogStatus int
+
+const (
+	DialogAccepted = DialogStatus(iota)
+	DialogPending
+	DialogDeclined
+)
+
+// DialogFolder splits a user's dialogs into the main inbox and incoming message requests.
+type DialogFolder int
+
+const (
+	DialogFolderInbox = DialogFolder(iota)
+	DialogFolderRequests
+)
+
+type DialogRequest struct {
+	DialogID    int64
+	Status      DialogStatus
+	RequestedBy *int64
+}
+
+type DialogReport struct {
+	DialogID       int64
+	ReporterID     int64
+	ReportedUserID int64
+	Reason         string
+	CreatedAt      int64
+}
+
 type DialogParticipant struct {
 	DialogID   int64
 	UserAdress string
+
+	LastReadMessageID          int64
+	RecipientLastReadMessageID int64
+	UnreadCount                int64
+
+	LastMessage *Message
+
+	Status      DialogStatus
+	RequestedBy *int64
+	// GateFailedAt is set when the other participant stopped meeting the user's inbox gates
+	GateFailedAt *int64
 }
 
 type Message struct {
@@ -43,6 +281,428 @@ type Message struct {
 	SenderAddress string
 	SenderID      int64
 	Content       string
+	CreatedAt     int64
+	EditedAt      *int64
+	DeletedAt     *int64
+	Attachments   []*Attachment
+	Reactions     []*ReactionSummary
+
+	ReplyToMessageID *int64
+	// ReplyTo is a preview of the quoted message, it is nil if the quoted message is gone
+	ReplyTo *Message
+	// Depth is the level of the message within a thread, direct replies to the root are at 1
+	Depth int64
+
+	// Nonce is set for end-to-end encrypted messages, their Content is empty
+	Nonce *string
+	// Ciphertexts of an encrypted message, when read only the reader's own ciphertext is loaded
+	Ciphertexts []*MessageCiphertext
+	// Signature is the sender's wallet signature over SignedMessageText, if the sender attached one
+	Signature *MessageSignature
+
+	Kind MessageKind
+	// RetentionTTL is the new dialog retention in milliseconds, set for MessageRetentionChanged
+	RetentionTTL *int64
+	// ExpiresAt is when the message disappears, nil if the dialog had no retention when it was sent
+	ExpiresAt *int64
+	// ChainTx tracks the copy of the message sent to the Messaging contract, nil for off-chain messages
+	ChainTx *MessageChainTx
+	// ChainLog is set for messages the indexer ingested from a MessageSent log
+	ChainLog *ChainLog
+	// Payment is the first-contact payment the message was delivered with
+	Payment *MessagePayment
+}
+
+// Expired reports whether the message's retention timer ran out by at.
+func (m *Message) Expired(at int64) bool {
+	return m.ExpiresAt != nil && *m.ExpiresAt <= at
+}
+
+// MessageKind tells user messages from the ones the server adds to a dialog.
+type MessageKind int
+
+const (
+	MessageRegular = MessageKind(iota)
+	// MessageRetentionChanged records a change of the dialog's retention timer.
+	MessageRetentionChanged
+)
+
+var messageKindNames = map[MessageKind]string{
+	MessageRegular:          models.MessagesResponseItems0KindMessage,
+	MessageRetentionChanged: models.MessagesResponseItems0KindRetentionChanged,
+}
+
+func (k MessageKind) String() string {
+	if name, ok := messageKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// retentionPresets are the named retention timers, anything else is a custom one.
+var retentionPresets = map[string]time.Duration{
+	models.RetentionSettingsModeNr1h: time.Hour,
+	models.RetentionSettingsModeNr1d: 24 * time.Hour,
+	models.RetentionSettingsModeNr7d: 7 * 24 * time.Hour,
+}
+
+// RetentionFromModel returns the timer the settings describe, 0 turns retention off.
+func RetentionFromModel(settings *models.RetentionSettings) time.Duration {
+	switch *settings.Mode {
+	case models.RetentionSettingsModeOff:
+		return 0
+	case models.RetentionSettingsModeCustom:
+		return time.Duration(settings.TTL) * time.Second
+	}
+	return retentionPresets[*settings.Mode]
+}
+
+func RetentionToModel(ttl time.Duration) *models.RetentionSettings {
+	mode := models.RetentionSettingsModeOff
+	if ttl > 0 {
+		mode = models.RetentionSettingsModeCustom
+		for name, preset := range retentionPresets {
+			if preset == ttl {
+				mode = name
+			}
+		}
+	}
+
+	return &models.RetentionSettings{
+		Mode: &mode,
+		TTL:  int64(ttl / time.Second),
+	}
+}
+
+// signedMessageFormat is the canonical encoding of a message signed by the sender's wallet. Content
+// goes last so it can contain line breaks without making the encoding ambiguous.
+const signedMessageFormat = "bdd signed message\ndialog: %d\nrecipient: %s\ntimestamp: %d\ncontent: %s"
+
+type MessageSignature struct {
+	// DialogID is the dialog the message was signed for, 0 if the message started the dialog
+	DialogID         int64
+	RecipientAddress string
+	// SignedAt is the client timestamp in milliseconds
+	SignedAt  int64
+	Signature string
+}
+
+// SignedMessageText returns the text signed with EIP-191 personal_sign.
+func SignedMessageText(dialogID int64, recipientAddress string, signedAt int64, content string) string {
+	return fmt.Sprintf(signedMessageFormat, dialogID, strings.ToLower(recipientAddress), signedAt, content)
+}
+
+// PendingMessage is a message sent to an address that hasn't signed in yet.
+// It is moved into a dialog when the address first authenticates.
+type PendingMessage struct {
+	ID               int64
+	SenderID         int64
+	RecipientAddress string
+	Content          string
+	CreatedAt        int64
+	Signature        *MessageSignature
+}
+
+// IdempotencyKey remembers the outcome of a send so a retry with the same key doesn't create a duplicate.
+type IdempotencyKey struct {
+	SenderID int64
+	Key      string
+	// RequestHash fingerprints the request body, a retry has to match it
+	RequestHash      string
+	MessageID        *int64
+	PendingMessageID *int64
+	CreatedAt        int64
+}
+
+type ChainTxStatus int
+
+const (
+	ChainTxQueued ChainTxStatus = iota
+	ChainTxSubmitted
+	ChainTxConfirmed
+	ChainTxFailed
+)
+
+func (s ChainTxStatus) String() string {
+	switch s {
+	case ChainTxSubmitted:
+		return "submitted"
+	case ChainTxConfirmed:
+		return "confirmed"
+	case ChainTxFailed:
+		return "failed"
+	default:
+		return "queued"
+	}
+}
+
+// ChainLog identifies the MessageSent log a message was indexed from.
+type ChainLog struct {
+	BlockNumber int64
+	TxHash      string
+	LogIndex    int64
+}
+
+// MessageChainTx is the Messaging.sendMessage transaction the relayer submits for an on-chain message.
+type MessageChainTx struct {
+	MessageID        int64
+	RecipientAddress string
+	// Content is loaded by the chain worker only
+	Content string
+	// Relay is the sender's signature of a gasless message, nil when the relayer sends the message itself
+	Relay  *RelaySignature
+	Status ChainTxStatus
+	TxHash *string
+	Error  *string
+	// Sent describes the transaction TxHash points to, it's nil until the first one is broadcast
+	Sent *SentChainTx
+	// ReplacedTxHashes are the transactions the current one replaced, any of them may still get mined
+	ReplacedTxHashes []string
+	// Attempts counts the failed broadcasts
+	Attempts  int64
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// RelaySignature is the EIP-712 SendMessage signature a sender hands the relayer.
+type RelaySignature struct {
+	SenderAddress string
+	// Nonce is a decimal uint256
+	Nonce string
+	// Deadline is in unix seconds, as the contract compares it with the block timestamp
+	Deadline  int64
+	Signature string
+}
+
+type SentChainTx struct {
+	AccountNonce int64
+	// GasTipCap and GasFeeCap are decimal wei, a legacy transaction's gas price is in both
+	GasTipCap   string
+	GasFeeCap   string
+	SubmittedAt int64
+}
+
+// AnchorBatch is a batch of messages whose Merkle root the relayer commits to the MessageAnchor contract.
+type AnchorBatch struct {
+	ID             int64
+	Root           string
+	FirstMessageID int64
+	LastMessageID  int64
+	Size           int64
+	Status         ChainTxStatus
+	TxHash         *string
+	BlockNumber    *int64
+	Error          *string
+	// Sent describes the anchor transaction TxHash points to, it's nil until the first one is broadcast
+	Sent *SentChainTx
+	// ReplacedTxHashes are the anchor transactions the current one replaced, any of them may still get mined
+	ReplacedTxHashes []string
+	CreatedAt        int64
+	UpdatedAt        int64
+}
+
+// ChainAddressPair is a direction of the Messaging contract's dialogues mapping, addresses are lowercase.
+type ChainAddressPair struct {
+	Sender   string
+	Receiver string
+}
+
+// OnChainMessage is a message the database says the Messaging contract stores.
+type OnChainMessage struct {
+	MessageID int64
+	DialogID  int64
+	Content   string
+	Deleted   bool
+	// Indexed is set for messages ingested from a MessageSent log, the others were sent by the chain worker
+	Indexed bool
+}
+
+type ReconcileDiffKind int
+
+const (
+	// ReconcileMissingOnChain is a message the database has as on-chain that the contract doesn't store
+	ReconcileMissingOnChain ReconcileDiffKind = iota
+	// ReconcileMissingInDB is a message the contract stores that the database doesn't have
+	ReconcileMissingInDB
+	// ReconcileContentMismatch is a message stored in both with different content
+	ReconcileContentMismatch
+)
+
+func (k ReconcileDiffKind) String() string {
+	switch k {
+	case ReconcileMissingInDB:
+		return "missing_in_db"
+	case ReconcileContentMismatch:
+		return "content_mismatch"
+	default:
+		return "missing_on_chain"
+	}
+}
+
+// ReconcileDiff is a difference between the database and the contract's dialogue of Pair.
+type ReconcileDiff struct {
+	Kind ReconcileDiffKind
+	Pair ChainAddressPair
+	// Message is nil for messages missing in the database
+	Message *OnChainMessage
+	// ChainIndex is the position in the contract's dialogue, -1 for messages missing on-chain
+	ChainIndex   int64
+	ChainContent *string
+	Repaired     bool
+	// Note says how a difference was repaired or why it wasn't
+	Note string
+}
+
+// ReconcileReport is the outcome of comparing the database with the contract.
+type ReconcileReport struct {
+	Pairs         int64
+	Messages      int64
+	ChainMessages int64
+	Diffs         []*ReconcileDiff
+}
+
+// AnchorLeaf is a message's place in its batch, MessageHash is the message's hash when it was anchored.
+type AnchorLeaf struct {
+	MessageID   int64
+	BatchID     int64
+	LeafIndex   int64
+	MessageHash string
+}
+
+var anchoredMessageArgs = abi.Arguments{
+	{Type: mustABIType("uint64")},
+	{Type: mustABIType("uint64")},
+	{Type: mustABIType("address")},
+	{Type: mustABIType("uint64")},
+	{Type: mustABIType("string")},
+}
+
+func mustABIType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// AnchoredMessageHash is the canonical hash of a message,
+// keccak256(abi.encode(uint64 id, uint64 dialogId, address sender, uint64 createdAt, string content)).
+// Content is empty for encrypted and deleted messages.
+func AnchoredMessageHash(m *Message) (common.Hash, error) {
+	encoded, err := anchoredMessageArgs.Pack(uint64(m.ID), uint64(m.DialogID), common.HexToAddress(m.SenderAddress),
+		uint64(m.CreatedAt), m.Content)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("AnchoredMessageHash/Pack: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// AnchorLeafHash hashes the message hash once more, so a leaf can't pass for an inner node of the tree.
+func AnchorLeafHash(messageHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(messageHash[:])
+}
+
+func MessageProofToResponse(leaf *AnchorLeaf, batch *AnchorBatch, proof []common.Hash, contract string, chainID int64) *models.MessageProof {
+	res := &models.MessageProof{
+		MessageID:   leaf.MessageID,
+		MessageHash: leaf.MessageHash,
+		Leaf:        AnchorLeafHash(common.HexToHash(leaf.MessageHash)).Hex(),
+		Proof:       make([]string, 0, len(proof)),
+		Root:        batch.Root,
+		BatchID:     batch.ID,
+		Anchor: &models.Anchor{
+			Status:          batch.Status.String(),
+			ContractAddress: contract,
+			ChainID:         chainID,
+		},
+	}
+	for _, p := range proof {
+		res.Proof = append(res.Proof, p.Hex())
+	}
+	if batch.TxHash != nil {
+		res.Anchor.TxHash = *batch.TxHash
+	}
+	if batch.BlockNumber != nil {
+		res.Anchor.BlockNumber = *batch.BlockNumber
+	}
+
+	return res
+}
+
+type EncryptionKey struct {
+	ID        int64
+	UserID    int64
+	PublicKey string
+	Signature string
+	CreatedAt int64
+	RotatedAt *int64
+}
+
+type MessageCiphertext struct {
+	MessageID   int64
+	RecipientID int64
+	KeyID       int64
+	Ciphertext  string
+}
+
+// AllowedReactions is the set of emoji a message can be reacted with.
+var AllowedReactions = map[string]struct{}{
+	"👍": {}, "👎": {}, "❤️": {}, "😂": {}, "😮": {},
+	"😢": {}, "🙏": {}, "🔥": {}, "🎉": {}, "👀": {},
+}
+
+type ReactionSummary struct {
+	MessageID   int64
+	Emoji       string
+	Count       int64
+	ReactedByMe bool
+}
+
+// ReactionChange is a single add or remove of a reaction, clients replay them to sync.
+type ReactionChange struct {
+	ID          int64
+	DialogID    int64
+	MessageID   int64
+	UserID      int64
+	UserAddress string
+	Emoji       string
+	Added       bool
+	CreatedAt   int64
+}
+
+type Attachment struct {
+	ID         int64
+	MessageID  int64
+	DialogID   int64
+	StorageKey string
+	FileName   string
+	MimeType   string
+	Size       int64
+	CreatedAt  int64
+}
+
+// AttachmentUpload is a file received with a message before it is stored.
+type AttachmentUpload struct {
+	FileName string
+	Size     int64
+	Content  io.ReadSeeker
+}
+
+type MessageSearchResult struct {
+	MessageID     int64
+	DialogID      int64
+	SenderAddress string
+	CreatedAt     int64
+	Snippet       string
+	Rank          float32
+}
+
+type MessageRevision struct {
+	ID        int64
+	MessageID int64
+	EditorID  int64
+	Content   string
+	CreatedAt int64
 }
 
 type User struct {
@@ -63,8 +723,69 @@ func RecepientsToRecepinetsResponce(rc []*DialogParticipant) []*models.DialogsRe
 		res = append(res, &models.DialogsResponseItems0{
 			RecepeintAddress: v.UserAdress,
 			DialogID:         v.DialogID,
+
+			LastReadMessageID:          v.LastReadMessageID,
+			RecipientLastReadMessageID: v.RecipientLastReadMessageID,
+			UnreadCount:                v.UnreadCount,
+
+			LastMessage: messageToPreview(v.LastMessage),
+
+			RequestStatus: dialogRequestStatus(v),
+			GateFailed:    v.GateFailedAt != nil,
+		})
+	}
+
+	return res
+}
+
+func InboxGatesToResponse(gates []*InboxGate) []*models.InboxGate {
+	res := make([]*models.InboxGate, 0, len(gates))
+	for _, g := range gates {
+		tokenAddress, standard := g.TokenAddress, g.Standard.String()
+		res = append(res, &models.InboxGate{
+			TokenAddress: &tokenAddress,
+			Standard:     &standard,
+			MinBalance:   g.MinBalance,
 		})
 	}
+	return res
+}
+
+// MessagePriceToResponse describes price for senders, a user without a price reads as "0".
+func MessagePriceToResponse(price *MessagePrice, escrow string, confirmations int64) *models.MessagePrice {
+	res := &models.MessagePrice{Amount: "0", EscrowAddress: escrow, Confirmations: confirmations}
+	if price != nil {
+		res.Amount = price.Amount
+		if price.TokenAddress != nil {
+			res.TokenAddress = *price.TokenAddress
+		}
+	}
+	return res
+}
+
+// dialogRequestStatus hides a decline from the requester, who keeps seeing the request as pending.
+func dialogRequestStatus(p *DialogParticipant) string {
+	if p.Status == DialogAccepted {
+		return models.DialogsResponseItems0RequestStatusAccepted
+	}
+	return models.DialogsResponseItems0RequestStatusPending
+}
+
+func messageToPreview(msg *Message) *models.MessagePreview {
+	if msg == nil {
+		return nil
+	}
+
+	res := &models.MessagePreview{
+		MessageID:     msg.ID,
+		SenderAddress: msg.SenderAddress,
+		Content:       msg.Content,
+		CreatedAt:     msg.CreatedAt,
+	}
+	if msg.DeletedAt != nil {
+		res.Content = ""
+		res.Deleted = true
+	}
 
 	return res
 }
@@ -72,10 +793,197 @@ func RecepientsToRecepinetsResponce(rc []*DialogParticipant) []*models.DialogsRe
 func MessageToMessageResponse(msgs []*Message) []*models.MessagesResponseItems0 {
 	var res []*models.MessagesResponseItems0
 	for _, v := range msgs {
-		res = append(res, &models.MessagesResponseItems0{
+		item := &models.MessagesResponseItems0{
 			SenderAddress: v.SenderAddress,
 			Content:       v.Content,
 			MessageID:     v.ID,
+			CreatedAt:     v.CreatedAt,
+			Kind:          v.Kind.String(),
+		}
+		if v.RetentionTTL != nil {
+			item.Retention = RetentionToModel(time.Duration(*v.RetentionTTL) * time.Millisecond)
+		}
+		if v.ExpiresAt != nil {
+			item.ExpiresAt = *v.ExpiresAt
+		}
+		if v.EditedAt != nil {
+			item.EditedAt = *v.EditedAt
+		}
+		if v.ReplyToMessageID != nil {
+			item.ReplyToMessageID = *v.ReplyToMessageID
+			item.ReplyTo = messageToPreview(v.ReplyTo)
+		}
+		if v.ChainTx != nil {
+			// the on-chain copy outlives a deletion, so it is reported either way
+			item.ChainTx = &models.ChainTx{Status: v.ChainTx.Status.String()}
+			if v.ChainTx.TxHash != nil {
+				item.ChainTx.TxHash = *v.ChainTx.TxHash
+			}
+		}
+		if v.Payment != nil {
+			item.Payment = &models.MessagePayment{
+				TxHash: v.Payment.TxHash,
+				Amount: v.Payment.Amount,
+				Status: v.Payment.Status.String(),
+				Escrow: v.Payment.DepositID != nil,
+			}
+			if v.Payment.TokenAddress != nil {
+				item.Payment.TokenAddress = *v.Payment.TokenAddress
+			}
+			if v.Payment.RefundTxHash != nil {
+				item.Payment.RefundTxHash = *v.Payment.RefundTxHash
+			}
+		}
+		if v.DeletedAt != nil {
+			item.Content = ""
+			item.Deleted = true
+		} else {
+			if v.Signature != nil {
+				item.Signature = &models.MessageSignature{
+					Signature:        v.Signature.Signature,
+					DialogID:         v.Signature.DialogID,
+					RecipientAddress: v.Signature.RecipientAddress,
+					SignedAt:         v.Signature.SignedAt,
+				}
+			}
+			if v.Nonce != nil && len(v.Ciphertexts) > 0 {
+				item.Encrypted = &models.EncryptedContent{
+					Nonce:      *v.Nonce,
+					KeyID:      v.Ciphertexts[0].KeyID,
+					Ciphertext: v.Ciphertexts[0].Ciphertext,
+				}
+			}
+			for _, a := range v.Attachments {
+				item.Attachments = append(item.Attachments, &models.Attachment{
+					AttachmentID: a.ID,
+					FileName:     a.FileName,
+					MimeType:     a.MimeType,
+					Size:         a.Size,
+				})
+			}
+			for _, r := range v.Reactions {
+				item.Reactions = append(item.Reactions, &models.Reaction{
+					Emoji:       r.Emoji,
+					Count:       r.Count,
+					ReactedByMe: r.ReactedByMe,
+				})
+			}
+		}
+		res = append(res, item)
+	}
+
+	return res
+}
+
+func ThreadToResponse(root *Message, replies []*Message, total int64) *models.ThreadResponse {
+	res := &models.ThreadResponse{
+		Root:    messageToThreadMessage(root),
+		Replies: make([]*models.ThreadMessage, 0, len(replies)),
+		Total:   total,
+	}
+	for _, v := range replies {
+		res.Replies = append(res.Replies, messageToThreadMessage(v))
+	}
+
+	return res
+}
+
+func messageToThreadMessage(msg *Message) *models.ThreadMessage {
+	res := &models.ThreadMessage{
+		MessageID:     msg.ID,
+		SenderAddress: msg.SenderAddress,
+		Content:       msg.Content,
+		CreatedAt:     msg.CreatedAt,
+		Depth:         msg.Depth,
+	}
+	if msg.ReplyToMessageID != nil {
+		res.ReplyToMessageID = *msg.ReplyToMessageID
+	}
+	if msg.EditedAt != nil {
+		res.EditedAt = *msg.EditedAt
+	}
+	if msg.DeletedAt != nil {
+		res.Content = ""
+		res.Deleted = true
+	}
+
+	return res
+}
+
+func BlockedUsersToResponse(blocked []*BlockedUser) []*models.BlockedUser {
+	res := make([]*models.BlockedUser, 0, len(blocked))
+	for _, b := range blocked {
+		res = append(res, &models.BlockedUser{
+			Address:   b.Address,
+			BlockedAt: b.CreatedAt,
+		})
+	}
+
+	return res
+}
+
+func PendingMessagesToResponse(pending []*PendingMessage) []*models.PendingMessage {
+	res := make([]*models.PendingMessage, 0, len(pending))
+	for _, p := range pending {
+		res = append(res, &models.PendingMessage{
+			ID:               p.ID,
+			RecipientAddress: p.RecipientAddress,
+			Content:          p.Content,
+			CreatedAt:        p.CreatedAt,
+		})
+	}
+
+	return res
+}
+
+func EncryptionKeysToResponse(keys []*EncryptionKey) []*models.EncryptionKey {
+	res := make([]*models.EncryptionKey, 0, len(keys))
+	for _, k := range keys {
+		item := &models.EncryptionKey{
+			KeyID:     k.ID,
+			PublicKey: k.PublicKey,
+			Signature: k.Signature,
+			CreatedAt: k.CreatedAt,
+		}
+		if k.RotatedAt != nil {
+			item.RotatedAt = *k.RotatedAt
+		}
+		res = append(res, item)
+	}
+
+	return res
+}
+
+func ReactionChangeToModel(c *ReactionChange) *models.ReactionChange {
+	return &models.ReactionChange{
+		ChangeID:    c.ID,
+		MessageID:   c.MessageID,
+		UserAddress: c.UserAddress,
+		Emoji:       c.Emoji,
+		Added:       c.Added,
+		ChangedAt:   c.CreatedAt,
+	}
+}
+
+func ReactionChangesToResponse(changes []*ReactionChange) []*models.ReactionChange {
+	res := make([]*models.ReactionChange, 0, len(changes))
+	for _, c := range changes {
+		res = append(res, ReactionChangeToModel(c))
+	}
+
+	return res
+}
+
+func SearchResultsToResponse(results []*MessageSearchResult) []*models.SearchMessagesResponseItems0 {
+	res := make([]*models.SearchMessagesResponseItems0, 0, len(results))
+	for _, v := range results {
+		res = append(res, &models.SearchMessagesResponseItems0{
+			MessageID:     v.MessageID,
+			DialogID:      v.DialogID,
+			SenderAddress: v.SenderAddress,
+			CreatedAt:     v.CreatedAt,
+			Snippet:       v.Snippet,
+			Rank:          v.Rank,
 		})
 	}
 
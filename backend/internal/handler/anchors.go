@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/gorilla/mux"
+)
+
+func (h *handler) GetMessageProof(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	messageID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.GetMessageProof(ctx, int64(messageID), user.ID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/gorilla/mux"
+)
+
+// multipartMemory is how much of a multipart body is kept in memory, the rest goes to temp files.
+const multipartMemory = 10 << 20
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+func formValue(form *multipart.Form, key string) *string {
+	values, ok := form.Value[key]
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	return &values[0]
+}
+
+func (h *handler) GetAttachmentURL(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachmentId"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.GetAttachmentURL(ctx, int64(dialogID), int64(attachmentID), user.ID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachmentId"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	attachment, content, err := h.service.OpenAttachment(ctx, int64(attachmentID), expires, r.URL.Query().Get("signature"))
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": strings.ToValidUTF8(attachment.FileName, "_"),
+	}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		h.logging.Errorf("Method: %s, URL: %v, error: %v", r.Method, r.URL, err)
+	}
+}
@@ -3,11 +3,15 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Pyegorchik/bdd/backend/internal/domain"
 	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/jwtoken"
+	"github.com/gorilla/mux"
 )
 
 func (h *handler) Logout(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
@@ -126,26 +130,7 @@ func (h *handler) AuthByMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cookie := &http.Cookie{
-		Name:     NameCookie,
-		Value:    accessToken.Token,
-		Secure:   true,
-		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
-		Path:     "/api",
-		MaxAge:   -int(time.Since(accessToken.ExpiresAt).Seconds()),
-	}
-	http.SetCookie(w, cookie)
-	refreshCookie := &http.Cookie{
-		Name:     NameRefreshCookie,
-		Value:    refreshToken.Token,
-		Secure:   true,
-		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
-		Path:     "/api/auth/refresh",
-		MaxAge:   -int(time.Since(refreshToken.ExpiresAt).Seconds()),
-	}
-	http.SetCookie(w, refreshCookie)
+	setAuthCookies(w, accessToken, refreshToken)
 	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
 		h.makeErrorResponse(w, r, err, code500)
 		return
@@ -162,6 +147,125 @@ func (h *handler) RefreshAuth(w http.ResponseWriter, user *domain.UserWithTokenN
 		h.makeErrorResponse(w, r, err, code500)
 		return
 	}
+	setAuthCookies(w, accessToken, refreshToken)
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) SupportedChains(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := writeResponse(w, r, http.StatusOK, h.service.GetSupportedChains(ctx)); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) CreateLoginSession(w http.ResponseWriter, r *http.Request) {
+	var chainID int64
+	if v := r.URL.Query().Get("chain_id"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 {
+			h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+			return
+		}
+		chainID = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.CreateLoginSession(ctx, chainID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) GetLoginSessionChallenge(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["sessionId"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.GetLoginSessionChallenge(ctx, sessionID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) SignLoginSession(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	sessionID := mux.Vars(r)["sessionId"]
+
+	var req models.AuthBySignatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := req.Validate(h.validationFormats); err != nil {
+		h.makeErrorResponse(w, r, makeValidationError("handleSignLoginSession", err), code400)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := h.service.SignLoginSession(ctx, sessionID, &req); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	result := true
+	if err := writeResponse(w, r, http.StatusOK, models.SuccessResponse{Success: &result}); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) PollLoginSession(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	sessionID := mux.Vars(r)["sessionId"]
+
+	var req models.LoginSessionPollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := req.Validate(h.validationFormats); err != nil {
+		h.makeErrorResponse(w, r, makeValidationError("handlePollLoginSession", err), code400)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, accessToken, refreshToken, err := h.service.PollLoginSession(ctx, sessionID, &req)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if accessToken != nil && refreshToken != nil {
+		setAuthCookies(w, accessToken, refreshToken)
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func setAuthCookies(w http.ResponseWriter, accessToken, refreshToken *jwtoken.JWTokenData) {
 	cookie := &http.Cookie{
 		Name:     NameCookie,
 		Value:    accessToken.Token,
@@ -182,8 +286,4 @@ func (h *handler) RefreshAuth(w http.ResponseWriter, user *domain.UserWithTokenN
 		MaxAge:   -int(time.Since(refreshToken.ExpiresAt).Seconds()),
 	}
 	http.SetCookie(w, refreshCookie)
-	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
-		h.makeErrorResponse(w, r, err, code500)
-		return
-	}
 }
@@ -12,13 +12,87 @@ import (
 	"github.com/gorilla/mux"
 )
 
+const (
+	defaultThreadLimit = 50
+	maxThreadLimit     = 100
+
+	// idempotencyKeyHeader is an alternative to the client_message_id field of a sent message
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
 func (h *handler) SendMessage(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
 	defer r.Body.Close()
-	var req models.SendMessageRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	var (
+		req     models.SendMessageRequest
+		uploads []*domain.AttachmentUpload
+	)
+	if isMultipart(r) {
+		r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadSize)
+		if err := r.ParseMultipartForm(multipartMemory); err != nil {
+			h.makeErrorResponse(w, r, makeValidationError("handleSendMessage", err), code400)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		req.RecipientID = formValue(r.MultipartForm, "recipient_id")
+		req.Content = formValue(r.MultipartForm, "content")
+		if v := formValue(r.MultipartForm, "reply_to_message_id"); v != nil {
+			replyTo, err := strconv.ParseInt(*v, 10, 64)
+			if err != nil {
+				h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+				return
+			}
+			req.ReplyToMessageID = replyTo
+		}
+		if v := formValue(r.MultipartForm, "signature"); v != nil {
+			req.Signature = *v
+		}
+		if v := formValue(r.MultipartForm, "signed_at"); v != nil {
+			signedAt, err := strconv.ParseInt(*v, 10, 64)
+			if err != nil {
+				h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+				return
+			}
+			req.SignedAt = signedAt
+		}
+		if v := formValue(r.MultipartForm, "client_message_id"); v != nil {
+			req.ClientMessageID = *v
+		}
+		if v := formValue(r.MultipartForm, "on_chain"); v != nil {
+			onChain, err := strconv.ParseBool(*v)
+			if err != nil {
+				h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+				return
+			}
+			req.OnChain = onChain
+		}
+		if v := formValue(r.MultipartForm, "payment_tx_hash"); v != nil {
+			req.PaymentTxHash = *v
+		}
+		for _, fh := range r.MultipartForm.File["attachments"] {
+			f, err := fh.Open()
+			if err != nil {
+				h.makeErrorResponse(w, r, err, code500)
+				return
+			}
+			defer f.Close()
+			uploads = append(uploads, &domain.AttachmentUpload{
+				FileName: fh.Filename,
+				Size:     fh.Size,
+				Content:  f,
+			})
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.makeErrorResponse(w, r, err, code500)
 		return
 	}
+	if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+		if req.ClientMessageID != "" && req.ClientMessageID != key {
+			h.makeErrorResponse(w, r, errors.New("idempotency key header doesn't match client_message_id"), code400)
+			return
+		}
+		req.ClientMessageID = key
+	}
 	if err := req.Validate(h.validationFormats); err != nil {
 		h.makeErrorResponse(w, r, makeValidationError("handleSendMessage", err), code400)
 		return
@@ -26,14 +100,13 @@ func (h *handler) SendMessage(w http.ResponseWriter, user *domain.UserWithTokenN
 	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
 	defer cancel()
 
-	err := h.service.SendMessage(ctx, &req, user.ID)
+	res, err := h.service.SendMessage(ctx, &req, uploads, user.ID)
 	if err != nil {
 		h.makeErrorResponse(w, r, err, code500)
 		return
 	}
 
-	result := true
-	if err := writeResponse(w, r, http.StatusOK, &models.SuccessResponse{Success: &result}); err != nil {
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
 		h.makeErrorResponse(w, r, err, code500)
 		return
 	}
@@ -42,10 +115,20 @@ func (h *handler) SendMessage(w http.ResponseWriter, user *domain.UserWithTokenN
 func (h *handler) GetDialogs(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
 	defer r.Body.Close()
 
+	folder := domain.DialogFolderInbox
+	switch r.URL.Query().Get("folder") {
+	case "", "inbox":
+	case "requests":
+		folder = domain.DialogFolderRequests
+	default:
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
 	defer cancel()
 
-	res, err := h.service.GetDialogs(ctx, user.ID)
+	res, err := h.service.GetDialogs(ctx, folder, user.ID)
 	if err != nil {
 		h.makeErrorResponse(w, r, err, code500)
 		return
@@ -66,7 +149,147 @@ func (h *handler) GetMessages(w http.ResponseWriter, user *domain.UserWithTokenN
 	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
 	defer cancel()
 
-	res, err := h.service.GetMessages(ctx, int64(dialogID))
+	res, err := h.service.GetMessages(ctx, int64(dialogID), user.ID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) EditMessage(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	messageID, err := strconv.Atoi(mux.Vars(r)["messageId"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	var req models.EditMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := req.Validate(h.validationFormats); err != nil {
+		h.makeErrorResponse(w, r, makeValidationError("handleEditMessage", err), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := h.service.EditMessage(ctx, &req, int64(dialogID), int64(messageID), user.ID); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+
+	result := true
+	if err := writeResponse(w, r, http.StatusOK, &models.SuccessResponse{Success: &result}); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) DeleteMessage(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	messageID, err := strconv.Atoi(mux.Vars(r)["messageId"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := h.service.DeleteMessage(ctx, int64(dialogID), int64(messageID), user.ID); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+
+	result := true
+	if err := writeResponse(w, r, http.StatusOK, &models.SuccessResponse{Success: &result}); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) MarkDialogRead(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	var req models.ReadDialogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := req.Validate(h.validationFormats); err != nil {
+		h.makeErrorResponse(w, r, makeValidationError("handleMarkDialogRead", err), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := h.service.MarkDialogRead(ctx, &req, int64(dialogID), user.ID); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+
+	result := true
+	if err := writeResponse(w, r, http.StatusOK, &models.SuccessResponse{Success: &result}); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) GetMessageThread(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	messageID, err := strconv.Atoi(mux.Vars(r)["messageId"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	query := r.URL.Query()
+
+	limit, offset := int64(defaultThreadLimit), int64(0)
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 || parsed > maxThreadLimit {
+			h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+			return
+		}
+		limit = parsed
+	}
+	if v := query.Get("offset"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+			return
+		}
+		offset = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.GetMessageThread(ctx, int64(dialogID), int64(messageID), limit, offset, user.ID)
 	if err != nil {
 		h.makeErrorResponse(w, r, err, code500)
 		return
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/gorilla/mux"
+)
+
+func (h *handler) PublishEncryptionKey(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	var req models.PublishEncryptionKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := req.Validate(h.validationFormats); err != nil {
+		h.makeErrorResponse(w, r, makeValidationError("handlePublishEncryptionKey", err), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.PublishEncryptionKey(ctx, &req, user.ID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) GetEncryptionKeys(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.GetEncryptionKeys(ctx, mux.Vars(r)["address"])
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
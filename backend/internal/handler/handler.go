@@ -35,7 +35,13 @@ func NewHandler(cfg *config.HandlerConfig, service service.Service, logging logg
 	}
 }
 
-const handlerIDPattern = "{id:[0-9]+}"
+const (
+	handlerIDPattern           = "{id:[0-9]+}"
+	handlerMessageIDPattern    = "{messageId:[0-9]+}"
+	handlerAttachmentIDPattern = "{attachmentId:[0-9]+}"
+	handlerSessionIDPattern    = "{sessionId:[0-9a-f]+}"
+	handlerAddressPattern      = "{address:0x[0-9a-fA-F]{40}}"
+)
 
 func (h *handler) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -64,6 +70,11 @@ func (h *handler) Init() http.Handler {
 	authRouter.Handle("/full_logout", h.CookieAuthMiddleware((HandlerFuncWithUser(h.FullLogout))))
 	authRouter.HandleFunc("/message", h.AuthMessage)
 	authRouter.HandleFunc("/by_signature", h.AuthByMessage)
+	authRouter.HandleFunc("/chains", h.SupportedChains).Methods(http.MethodGet)
+	authRouter.HandleFunc("/qr/session", h.CreateLoginSession).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc(fmt.Sprintf("/qr/session/%s", handlerSessionIDPattern), h.GetLoginSessionChallenge).Methods(http.MethodGet)
+	authRouter.HandleFunc(fmt.Sprintf("/qr/session/%s/signature", handlerSessionIDPattern), h.SignLoginSession).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc(fmt.Sprintf("/qr/session/%s/poll", handlerSessionIDPattern), h.PollLoginSession).Methods(http.MethodPost, http.MethodOptions)
 
 	rndRouter := router.PathPrefix("/rnd").Subrouter()
 	rndRouter.HandleFunc("", h.Rnd)
@@ -71,7 +82,60 @@ func (h *handler) Init() http.Handler {
 	dialogsRounter := router.PathPrefix("/g1/dialogs").Subrouter()
 	dialogsRounter.Handle("", h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetDialogs))))
 	dialogsRounter.Handle("/message", h.CookieAuthMiddleware((HandlerFuncWithUser(h.SendMessage))))
+	dialogsRounter.Handle("/pending", h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetPendingMessages))))
+	dialogsRounter.Handle("/relay", h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetRelayInfo)))).Methods(http.MethodGet)
 	dialogsRounter.Handle(fmt.Sprintf("/%s/messages", handlerIDPattern), h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetMessages))))
+	dialogsRounter.Handle(fmt.Sprintf("/%s/messages/%s", handlerIDPattern, handlerMessageIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.EditMessage)))).Methods(http.MethodPatch, http.MethodOptions)
+	dialogsRounter.Handle(fmt.Sprintf("/%s/messages/%s", handlerIDPattern, handlerMessageIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.DeleteMessage)))).Methods(http.MethodDelete)
+	dialogsRounter.Handle(fmt.Sprintf("/%s/read", handlerIDPattern), h.CookieAuthMiddleware((HandlerFuncWithUser(h.MarkDialogRead))))
+	dialogsRounter.Handle(fmt.Sprintf("/%s/request/accept", handlerIDPattern), h.CookieAuthMiddleware((HandlerFuncWithUser(h.AcceptMessageRequest))))
+	dialogsRounter.Handle(fmt.Sprintf("/%s/request/decline", handlerIDPattern), h.CookieAuthMiddleware((HandlerFuncWithUser(h.DeclineMessageRequest))))
+	dialogsRounter.Handle(fmt.Sprintf("/%s/request/report", handlerIDPattern), h.CookieAuthMiddleware((HandlerFuncWithUser(h.ReportMessageRequest))))
+	dialogsRounter.Handle(fmt.Sprintf("/%s/messages/%s/reactions", handlerIDPattern, handlerMessageIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.AddReaction)))).Methods(http.MethodPost, http.MethodOptions)
+	dialogsRounter.Handle(fmt.Sprintf("/%s/messages/%s/reactions", handlerIDPattern, handlerMessageIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.RemoveReaction)))).Methods(http.MethodDelete)
+	dialogsRounter.Handle(fmt.Sprintf("/%s/messages/%s/thread", handlerIDPattern, handlerMessageIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetMessageThread))))
+	dialogsRounter.Handle(fmt.Sprintf("/%s/reactions", handlerIDPattern), h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetReactionChanges))))
+	dialogsRounter.Handle(fmt.Sprintf("/%s/retention", handlerIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetDialogRetention)))).Methods(http.MethodGet)
+	dialogsRounter.Handle(fmt.Sprintf("/%s/retention", handlerIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.UpdateDialogRetention)))).Methods(http.MethodPut, http.MethodOptions)
+
+	dialogsRounter.Handle(fmt.Sprintf("/%s/attachments/%s/url", handlerIDPattern, handlerAttachmentIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetAttachmentURL))))
+
+	messagesRouter := router.PathPrefix("/g1/messages").Subrouter()
+	messagesRouter.Handle(fmt.Sprintf("/%s/proof", handlerIDPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetMessageProof)))).Methods(http.MethodGet)
+
+	attachmentsRouter := router.PathPrefix("/g1/attachments").Subrouter()
+	attachmentsRouter.HandleFunc(fmt.Sprintf("/%s", handlerAttachmentIDPattern), h.DownloadAttachment)
+
+	usersRouter := router.PathPrefix("/g1/users").Subrouter()
+	usersRouter.Handle("/blocks", h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetBlockedUsers)))).Methods(http.MethodGet)
+	usersRouter.Handle("/blocks", h.CookieAuthMiddleware((HandlerFuncWithUser(h.BlockUser)))).Methods(http.MethodPost, http.MethodOptions)
+	usersRouter.Handle(fmt.Sprintf("/blocks/%s", handlerAddressPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.UnblockUser)))).Methods(http.MethodDelete, http.MethodOptions)
+	usersRouter.Handle("/privacy", h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetPrivacySettings)))).Methods(http.MethodGet)
+	usersRouter.Handle("/privacy", h.CookieAuthMiddleware((HandlerFuncWithUser(h.UpdatePrivacySettings)))).Methods(http.MethodPut, http.MethodOptions)
+	usersRouter.Handle("/gates", h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetInboxGates)))).Methods(http.MethodGet)
+	usersRouter.Handle("/gates", h.CookieAuthMiddleware((HandlerFuncWithUser(h.UpdateInboxGates)))).Methods(http.MethodPut, http.MethodOptions)
+	usersRouter.Handle(fmt.Sprintf("/%s/gates", handlerAddressPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetUserInboxGates)))).Methods(http.MethodGet)
+	usersRouter.Handle("/price", h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetMessagePrice)))).Methods(http.MethodGet)
+	usersRouter.Handle("/price", h.CookieAuthMiddleware((HandlerFuncWithUser(h.UpdateMessagePrice)))).Methods(http.MethodPut, http.MethodOptions)
+	usersRouter.Handle(fmt.Sprintf("/%s/price", handlerAddressPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetUserMessagePrice)))).Methods(http.MethodGet)
+	usersRouter.Handle("/keys", h.CookieAuthMiddleware((HandlerFuncWithUser(h.PublishEncryptionKey)))).Methods(http.MethodPost, http.MethodOptions)
+	usersRouter.Handle(fmt.Sprintf("/%s/keys", handlerAddressPattern),
+		h.CookieAuthMiddleware((HandlerFuncWithUser(h.GetEncryptionKeys)))).Methods(http.MethodGet)
+
+	searchRouter := router.PathPrefix("/g1/search").Subrouter()
+	searchRouter.Handle("/messages", h.CookieAuthMiddleware((HandlerFuncWithUser(h.SearchMessages))))
 
 	router.Use(h.corsMiddleware)
 	return router
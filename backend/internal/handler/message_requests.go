@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/gorilla/mux"
+)
+
+func (h *handler) AcceptMessageRequest(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := h.service.AcceptMessageRequest(ctx, int64(dialogID), user.ID); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+
+	result := true
+	if err := writeResponse(w, r, http.StatusOK, &models.SuccessResponse{Success: &result}); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) DeclineMessageRequest(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := h.service.DeclineMessageRequest(ctx, int64(dialogID), user.ID); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+
+	result := true
+	if err := writeResponse(w, r, http.StatusOK, &models.SuccessResponse{Success: &result}); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
+
+func (h *handler) ReportMessageRequest(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	dialogID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+		return
+	}
+	// the reason is optional, so an empty body is fine
+	var req models.ReportMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := req.Validate(h.validationFormats); err != nil {
+		h.makeErrorResponse(w, r, makeValidationError("handleReportMessageRequest", err), code400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	if err := h.service.ReportMessageRequest(ctx, &req, int64(dialogID), user.ID); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+
+	result := true
+	if err := writeResponse(w, r, http.StatusOK, &models.SuccessResponse{Success: &result}); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+)
+
+func (h *handler) GetPendingMessages(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.GetPendingMessages(ctx, user.ID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+func (h *handler) SearchMessages(w http.ResponseWriter, user *domain.UserWithTokenNumber, r *http.Request) {
+	defer r.Body.Close()
+	query := r.URL.Query()
+
+	limit, offset := int64(defaultSearchLimit), int64(0)
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 || parsed > maxSearchLimit {
+			h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+			return
+		}
+		limit = parsed
+	}
+	if v := query.Get("offset"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			h.makeErrorResponse(w, r, errors.New("invalid parameter value"), code400)
+			return
+		}
+		offset = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	res, err := h.service.SearchMessages(ctx, query.Get("q"), limit, offset, user.ID)
+	if err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+	if err := writeResponse(w, r, http.StatusOK, res); err != nil {
+		h.makeErrorResponse(w, r, err, code500)
+		return
+	}
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type AnchorsRepo struct {
+}
+
+func NewAnchorsRepo() Anchors {
+	return &AnchorsRepo{}
+}
+
+// GetMessagesToAnchor returns the messages after the last anchored one up to the first that was created
+// after createdBefore. Batches only ever cover a prefix of the ids, so a message that settles after a
+// later one isn't skipped once the later one is anchored. The batches table is locked until the transaction ends, so concurrent workers can't build overlapping batches.
+func (repo *AnchorsRepo) GetMessagesToAnchor(ctx context.Context, transaction Transaction, createdBefore, limit int64) ([]*domain.Message, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetMessagesToAnchor: error: type assertion failed on interface Transaction")
+	}
+
+	if _, err := tx.Exec(ctx, `LOCK TABLE anchor_batches IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, fmt.Errorf("GetMessagesToAnchor/Lock: %w", err)
+	}
+
+	query := `
+		SELECT m.id, m.dialog_id, u_c.address, m.created_at, m.content
+		FROM messages AS m
+		JOIN users_chain AS u_c ON u_c.id = m.sender_id
+		WHERE m.id > COALESCE((SELECT max(last_message_id) FROM anchor_batches), 0)
+		ORDER BY m.id
+		LIMIT $1
+	`
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetMessagesToAnchor/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		var m domain.Message
+		if err := rows.Scan(&m.ID, &m.DialogID, &m.SenderAddress, &m.CreatedAt, &m.Content); err != nil {
+			return nil, fmt.Errorf("GetMessagesToAnchor/Scan: %w", err)
+		}
+		if m.CreatedAt > createdBefore {
+			break
+		}
+		messages = append(messages, &m)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetMessagesToAnchor/Rows: %w", rows.Err())
+	}
+
+	return messages, nil
+}
+
+func (repo *AnchorsRepo) InsertAnchorBatch(ctx context.Context, transaction Transaction, batch *domain.AnchorBatch, leaves []*domain.AnchorLeaf) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("InsertAnchorBatch: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO anchor_batches (root, first_message_id, last_message_id, size, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING id
+	`
+	var batchID int64
+	if err := tx.QueryRow(ctx, query, batch.Root, batch.FirstMessageID, batch.LastMessageID, batch.Size, batch.Status,
+		batch.CreatedAt).Scan(&batchID); err != nil {
+		return 0, fmt.Errorf("InsertAnchorBatch/Scan: %w", err)
+	}
+
+	var (
+		messageIDs = make([]int64, 0, len(leaves))
+		indexes    = make([]int64, 0, len(leaves))
+		hashes     = make([]string, 0, len(leaves))
+	)
+	for _, l := range leaves {
+		messageIDs = append(messageIDs, l.MessageID)
+		indexes = append(indexes, l.LeafIndex)
+		hashes = append(hashes, l.MessageHash)
+	}
+	query = `
+		INSERT INTO anchor_leaves (message_id, batch_id, leaf_index, message_hash)
+		SELECT message_id, $1, leaf_index, message_hash
+		FROM unnest($2::BIGINT[], $3::INT[], $4::TEXT[]) AS l(message_id, leaf_index, message_hash)
+	`
+	if _, err := tx.Exec(ctx, query, batchID, messageIDs, indexes, hashes); err != nil {
+		return 0, fmt.Errorf("InsertAnchorBatch/Exec: %w", err)
+	}
+
+	return batchID, nil
+}
+
+// GetAnchorBatchesByStatus returns the oldest batches in status after afterID. Rows are locked so concurrent workers skip them.
+func (repo *AnchorsRepo) GetAnchorBatchesByStatus(ctx context.Context, transaction Transaction, status domain.ChainTxStatus, afterID, limit int64) ([]*domain.AnchorBatch, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetAnchorBatchesByStatus: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT id, root, first_message_id, last_message_id, size, status, tx_hash, block_number, error, account_nonce,
+			gas_tip_cap, gas_fee_cap, submitted_at, replaced_tx_hashes, created_at, updated_at
+		FROM anchor_batches
+		WHERE status = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(ctx, query, status, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetAnchorBatchesByStatus/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*domain.AnchorBatch
+	for rows.Next() {
+		b, err := scanAnchorBatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("GetAnchorBatchesByStatus/Scan: %w", err)
+		}
+		batches = append(batches, b)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetAnchorBatchesByStatus/Rows: %w", rows.Err())
+	}
+
+	return batches, nil
+}
+
+func (repo *AnchorsRepo) GetAnchorBatch(ctx context.Context, transaction Transaction, batchID int64) (*domain.AnchorBatch, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetAnchorBatch: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT id, root, first_message_id, last_message_id, size, status, tx_hash, block_number, error, account_nonce,
+			gas_tip_cap, gas_fee_cap, submitted_at, replaced_tx_hashes, created_at, updated_at
+		FROM anchor_batches
+		WHERE id = $1
+	`
+	b, err := scanAnchorBatch(tx.QueryRow(ctx, query, batchID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetAnchorBatch/Scan: %w", err)
+	}
+
+	return b, nil
+}
+
+func (repo *AnchorsRepo) UpdateAnchorBatch(ctx context.Context, transaction Transaction, batch *domain.AnchorBatch) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateAnchorBatch: error: type assertion failed on interface Transaction")
+	}
+
+	var accountNonce, submittedAt *int64
+	var gasTipCap, gasFeeCap *string
+	if batch.Sent != nil {
+		accountNonce, submittedAt = &batch.Sent.AccountNonce, &batch.Sent.SubmittedAt
+		gasTipCap, gasFeeCap = &batch.Sent.GasTipCap, &batch.Sent.GasFeeCap
+	}
+	replaced := batch.ReplacedTxHashes
+	if replaced == nil {
+		replaced = []string{}
+	}
+	query := `
+		UPDATE anchor_batches SET status = $2, tx_hash = $3, block_number = $4, error = $5, account_nonce = $6,
+			gas_tip_cap = $7, gas_fee_cap = $8, submitted_at = $9, replaced_tx_hashes = $10, updated_at = $11
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, batch.ID, batch.Status, batch.TxHash, batch.BlockNumber, batch.Error, accountNonce,
+		gasTipCap, gasFeeCap, submittedAt, replaced, batch.UpdatedAt); err != nil {
+		return fmt.Errorf("UpdateAnchorBatch/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AnchorsRepo) GetAnchorLeaf(ctx context.Context, transaction Transaction, messageID int64) (*domain.AnchorLeaf, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetAnchorLeaf: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT message_id, batch_id, leaf_index, message_hash FROM anchor_leaves WHERE message_id = $1`
+	var l domain.AnchorLeaf
+	if err := tx.QueryRow(ctx, query, messageID).Scan(&l.MessageID, &l.BatchID, &l.LeafIndex, &l.MessageHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetAnchorLeaf/Scan: %w", err)
+	}
+
+	return &l, nil
+}
+
+// GetAnchorBatchHashes returns the message hashes of the batch in leaf order.
+func (repo *AnchorsRepo) GetAnchorBatchHashes(ctx context.Context, transaction Transaction, batchID int64) ([]string, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetAnchorBatchHashes: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT message_hash FROM anchor_leaves WHERE batch_id = $1 ORDER BY leaf_index`
+	rows, err := tx.Query(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("GetAnchorBatchHashes/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("GetAnchorBatchHashes/Scan: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetAnchorBatchHashes/Rows: %w", rows.Err())
+	}
+
+	return hashes, nil
+}
+
+func scanAnchorBatch(row pgx.Row) (*domain.AnchorBatch, error) {
+	var (
+		b                         domain.AnchorBatch
+		accountNonce, submittedAt *int64
+		gasTipCap, gasFeeCap      *string
+	)
+	if err := row.Scan(&b.ID, &b.Root, &b.FirstMessageID, &b.LastMessageID, &b.Size, &b.Status, &b.TxHash,
+		&b.BlockNumber, &b.Error, &accountNonce, &gasTipCap, &gasFeeCap, &submittedAt, &b.ReplacedTxHashes,
+		&b.CreatedAt, &b.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if accountNonce != nil {
+		b.Sent = &domain.SentChainTx{
+			AccountNonce: *accountNonce,
+			GasTipCap:    *gasTipCap,
+			GasFeeCap:    *gasFeeCap,
+			SubmittedAt:  *submittedAt,
+		}
+	}
+	return &b, nil
+}
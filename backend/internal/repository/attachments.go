@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type AttachmentsRepo struct {
+}
+
+func NewAttachmentsRepo() Attachments {
+	return &AttachmentsRepo{}
+}
+
+func (repo *AttachmentsRepo) InsertAttachment(ctx context.Context, transaction Transaction, attachment *domain.Attachment) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("InsertAttachment: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO attachments (message_id, storage_key, file_name, mime_type, size, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	row := tx.QueryRow(ctx, query, attachment.MessageID, attachment.StorageKey, attachment.FileName,
+		attachment.MimeType, attachment.Size, attachment.CreatedAt)
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("InsertAttachment/Scan: %w", err)
+	}
+
+	return id, nil
+}
+
+func (repo *AttachmentsRepo) GetAttachmentById(ctx context.Context, transaction Transaction, attachmentID int64) (*domain.Attachment, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetAttachmentById: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT a.id, a.message_id, m.dialog_id, a.storage_key, a.file_name, a.mime_type, a.size, a.created_at
+		FROM attachments AS a
+		JOIN messages AS m ON m.id = a.message_id
+		WHERE a.id = $1
+	`
+	row := tx.QueryRow(ctx, query, attachmentID)
+	var attachment domain.Attachment
+	if err := row.Scan(&attachment.ID, &attachment.MessageID, &attachment.DialogID, &attachment.StorageKey,
+		&attachment.FileName, &attachment.MimeType, &attachment.Size, &attachment.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetAttachmentById/Scan: %w", err)
+	}
+
+	return &attachment, nil
+}
+
+func (repo *AttachmentsRepo) GetAttachmentsByDialog(ctx context.Context, transaction Transaction, dialogID int64) ([]*domain.Attachment, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetAttachmentsByDialog: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT a.id, a.message_id, m.dialog_id, a.storage_key, a.file_name, a.mime_type, a.size, a.created_at
+		FROM attachments AS a
+		JOIN messages AS m ON m.id = a.message_id
+		WHERE m.dialog_id = $1 AND m.deleted_at IS NULL
+		ORDER BY a.id
+	`
+	rows, err := tx.Query(ctx, query, dialogID)
+	if err != nil {
+		return nil, fmt.Errorf("GetAttachmentsByDialog/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*domain.Attachment
+	for rows.Next() {
+		var attachment domain.Attachment
+		if err := rows.Scan(&attachment.ID, &attachment.MessageID, &attachment.DialogID, &attachment.StorageKey,
+			&attachment.FileName, &attachment.MimeType, &attachment.Size, &attachment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("GetAttachmentsByDialog/Scan: %w", err)
+		}
+		attachments = append(attachments, &attachment)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetAttachmentsByDialog/Rows: %w", rows.Err())
+	}
+
+	return attachments, nil
+}
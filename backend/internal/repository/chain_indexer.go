@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type ChainIndexerRepo struct {
+}
+
+func NewChainIndexerRepo() ChainIndexer {
+	return &ChainIndexerRepo{}
+}
+
+// LockChainCheckpoint returns the next block to index, creating the checkpoint at startBlock on the first run.
+// The row stays locked until the transaction ends so only one indexer advances it at a time.
+func (repo *ChainIndexerRepo) LockChainCheckpoint(ctx context.Context, transaction Transaction, name string, startBlock, now int64) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("LockChainCheckpoint: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO chain_checkpoints (name, next_block, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, name, startBlock, now); err != nil {
+		return 0, fmt.Errorf("LockChainCheckpoint/Exec: %w", err)
+	}
+
+	query = `SELECT next_block FROM chain_checkpoints WHERE name = $1 FOR UPDATE`
+	var nextBlock int64
+	if err := tx.QueryRow(ctx, query, name).Scan(&nextBlock); err != nil {
+		return 0, fmt.Errorf("LockChainCheckpoint/Scan: %w", err)
+	}
+
+	return nextBlock, nil
+}
+
+func (repo *ChainIndexerRepo) UpdateChainCheckpoint(ctx context.Context, transaction Transaction, name string, nextBlock, now int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateChainCheckpoint: error: type assertion failed on interface Transaction")
+	}
+
+	query := `UPDATE chain_checkpoints SET next_block = $2, updated_at = $3 WHERE name = $1`
+	if _, err := tx.Exec(ctx, query, name, nextBlock, now); err != nil {
+		return fmt.Errorf("UpdateChainCheckpoint/Exec: %w", err)
+	}
+
+	return nil
+}
+
+// IsChainLogIndexed reports whether the log is already stored as a message. Logs of transactions the
+// relayer submitted count as indexed, their messages were stored when they were sent. That includes
+// transactions the relayer meant to replace, the stuck one may still win.
+func (repo *ChainIndexerRepo) IsChainLogIndexed(ctx context.Context, transaction Transaction, txHash string, logIndex int64) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("IsChainLogIndexed: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT EXISTS (SELECT 1 FROM messages WHERE tx_hash = $1 AND log_index = $2)
+			OR EXISTS (SELECT 1 FROM message_chain_txs WHERE tx_hash = $1)
+			OR EXISTS (SELECT 1 FROM message_chain_txs WHERE replaced_tx_hashes @> ARRAY[$1::TEXT])
+	`
+	var indexed bool
+	if err := tx.QueryRow(ctx, query, txHash, logIndex).Scan(&indexed); err != nil {
+		return false, fmt.Errorf("IsChainLogIndexed/Scan: %w", err)
+	}
+
+	return indexed, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type ChainTxsRepo struct {
+}
+
+func NewChainTxsRepo() ChainTxs {
+	return &ChainTxsRepo{}
+}
+
+func (repo *ChainTxsRepo) InsertMessageChainTx(ctx context.Context, transaction Transaction, chainTx *domain.MessageChainTx) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("InsertMessageChainTx: error: type assertion failed on interface Transaction")
+	}
+
+	var senderAddress, relayNonce, relaySignature *string
+	var relayDeadline *int64
+	if chainTx.Relay != nil {
+		senderAddress, relayNonce = &chainTx.Relay.SenderAddress, &chainTx.Relay.Nonce
+		relayDeadline, relaySignature = &chainTx.Relay.Deadline, &chainTx.Relay.Signature
+	}
+	query := `
+		INSERT INTO message_chain_txs (message_id, recipient_address, status, sender_address, relay_nonce, relay_deadline,
+			relay_signature, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+	`
+	if _, err := tx.Exec(ctx, query, chainTx.MessageID, chainTx.RecipientAddress, chainTx.Status, senderAddress, relayNonce,
+		relayDeadline, relaySignature, chainTx.CreatedAt); err != nil {
+		return fmt.Errorf("InsertMessageChainTx/Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetMessageChainTxsByStatus returns the oldest transactions in status after afterMessageID along with the
+// message content. Rows are locked so concurrent workers skip them.
+func (repo *ChainTxsRepo) GetMessageChainTxsByStatus(ctx context.Context, transaction Transaction, status domain.ChainTxStatus, afterMessageID, limit int64) ([]*domain.MessageChainTx, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetMessageChainTxsByStatus: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT ct.message_id, ct.recipient_address, m.content, ct.sender_address, ct.relay_nonce, ct.relay_deadline,
+			ct.relay_signature, ct.status, ct.tx_hash, ct.error, ct.account_nonce, ct.gas_tip_cap, ct.gas_fee_cap,
+			ct.submitted_at, ct.replaced_tx_hashes, ct.attempts, ct.created_at, ct.updated_at
+		FROM message_chain_txs AS ct
+		JOIN messages AS m ON m.id = ct.message_id
+		WHERE ct.status = $1 AND ct.message_id > $2
+		ORDER BY ct.message_id
+		LIMIT $3
+		FOR UPDATE OF ct SKIP LOCKED
+	`
+	rows, err := tx.Query(ctx, query, status, afterMessageID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetMessageChainTxsByStatus/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var chainTxs []*domain.MessageChainTx
+	for rows.Next() {
+		var (
+			ct                                        domain.MessageChainTx
+			senderAddress, relayNonce, relaySignature *string
+			relayDeadline, accountNonce, submittedAt  *int64
+			gasTipCap, gasFeeCap                      *string
+		)
+		if err := rows.Scan(&ct.MessageID, &ct.RecipientAddress, &ct.Content, &senderAddress, &relayNonce, &relayDeadline,
+			&relaySignature, &ct.Status, &ct.TxHash, &ct.Error, &accountNonce, &gasTipCap, &gasFeeCap, &submittedAt,
+			&ct.ReplacedTxHashes, &ct.Attempts, &ct.CreatedAt, &ct.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("GetMessageChainTxsByStatus/Scan: %w", err)
+		}
+		if relayNonce != nil {
+			ct.Relay = &domain.RelaySignature{
+				SenderAddress: *senderAddress,
+				Nonce:         *relayNonce,
+				Deadline:      *relayDeadline,
+				Signature:     *relaySignature,
+			}
+		}
+		if accountNonce != nil {
+			ct.Sent = &domain.SentChainTx{
+				AccountNonce: *accountNonce,
+				GasTipCap:    *gasTipCap,
+				GasFeeCap:    *gasFeeCap,
+				SubmittedAt:  *submittedAt,
+			}
+		}
+		chainTxs = append(chainTxs, &ct)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetMessageChainTxsByStatus/Rows: %w", rows.Err())
+	}
+
+	return chainTxs, nil
+}
+
+func (repo *ChainTxsRepo) UpdateMessageChainTx(ctx context.Context, transaction Transaction, chainTx *domain.MessageChainTx) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateMessageChainTx: error: type assertion failed on interface Transaction")
+	}
+
+	var accountNonce, submittedAt *int64
+	var gasTipCap, gasFeeCap *string
+	if chainTx.Sent != nil {
+		accountNonce, submittedAt = &chainTx.Sent.AccountNonce, &chainTx.Sent.SubmittedAt
+		gasTipCap, gasFeeCap = &chainTx.Sent.GasTipCap, &chainTx.Sent.GasFeeCap
+	}
+	replaced := chainTx.ReplacedTxHashes
+	if replaced == nil {
+		replaced = []string{}
+	}
+	query := `
+		UPDATE message_chain_txs SET status = $2, tx_hash = $3, error = $4, account_nonce = $5, gas_tip_cap = $6,
+			gas_fee_cap = $7, submitted_at = $8, replaced_tx_hashes = $9, attempts = $10, updated_at = $11
+		WHERE message_id = $1
+	`
+	if _, err := tx.Exec(ctx, query, chainTx.MessageID, chainTx.Status, chainTx.TxHash, chainTx.Error, accountNonce,
+		gasTipCap, gasFeeCap, submittedAt, replaced, chainTx.Attempts, chainTx.UpdatedAt); err != nil {
+		return fmt.Errorf("UpdateMessageChainTx/Exec: %w", err)
+	}
+
+	return nil
+}
+
+// CountRelayedMessages counts the gasless messages senderAddress had relayed since createdAfter.
+func (repo *ChainTxsRepo) CountRelayedMessages(ctx context.Context, transaction Transaction, senderAddress string, createdAfter int64) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("CountRelayedMessages: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT count(*) FROM message_chain_txs
+		WHERE sender_address = $1 AND relay_nonce IS NOT NULL AND created_at > $2
+	`
+	var count int64
+	if err := tx.QueryRow(ctx, query, senderAddress, createdAfter).Scan(&count); err != nil {
+		return 0, fmt.Errorf("CountRelayedMessages/Scan: %w", err)
+	}
+
+	return count, nil
+}
+
+func (repo *ChainTxsRepo) IsRelayNonceUsed(ctx context.Context, transaction Transaction, senderAddress, nonce string) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("IsRelayNonceUsed: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT EXISTS (SELECT 1 FROM message_chain_txs WHERE sender_address = $1 AND relay_nonce = $2)`
+	var used bool
+	if err := tx.QueryRow(ctx, query, senderAddress, nonce).Scan(&used); err != nil {
+		return false, fmt.Errorf("IsRelayNonceUsed/Scan: %w", err)
+	}
+
+	return used, nil
+}
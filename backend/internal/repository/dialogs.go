@@ -4,16 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
+	"strings"
 
 	"github.com/Pyegorchik/bdd/backend/internal/domain"
 	"github.com/jackc/pgx/v5"
 )
 
 type DialogsRepo struct {
+	searchLanguage string
 }
 
-func NewDialogsRepo() Dialogs {
-	return &DialogsRepo{}
+const defaultSearchLanguage = "simple"
+
+// NewDialogsRepo creates the repo. searchLanguage is the Postgres text search
+// configuration for messages.content_tsv; rows keep the one they were written with.
+func NewDialogsRepo(searchLanguage string) Dialogs {
+	if searchLanguage == "" {
+		searchLanguage = defaultSearchLanguage
+	}
+	return &DialogsRepo{searchLanguage: searchLanguage}
 }
 
 func (repo *DialogsRepo) DialogExists(ctx context.Context, transaction Transaction, userOneID int64, userTwoID int64) (bool, error) {
@@ -96,36 +106,156 @@ func (repo *DialogsRepo) CreateDialog(ctx context.Context, transaction Transacti
 	return dialogID, nil
 }
 
-func (repo *DialogsRepo) CreateMessageInDialog(ctx context.Context, transaction Transaction, msg *domain.Message) error {
+func (repo *DialogsRepo) MarkDialogRequested(ctx context.Context, transaction Transaction, dialogID int64, requestedBy int64) error {
 	tx, ok := transaction.(pgx.Tx)
 	if !ok {
-		return errors.New("CreateMessageInDialog: error: type assertion failed on interface Transaction")
+		return errors.New("MarkDialogRequested: error: type assertion failed on interface Transaction")
 	}
 
-	query := `INSERT INTO messages (dialog_id, sender_id, content) VALUES ($1, $2, $3)`
-	_, err := tx.Exec(ctx, query, msg.DialogID, msg.SenderID, msg.Content)
-	if err != nil {
-		return fmt.Errorf("CreateMessageInDialog/Exec: %w", err)
+	query := `UPDATE dialogs SET status = $2, requested_by = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, dialogID, domain.DialogPending, requestedBy); err != nil {
+		return fmt.Errorf("MarkDialogRequested/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *DialogsRepo) GetDialogRequest(ctx context.Context, transaction Transaction, dialogID int64) (*domain.DialogRequest, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetDialogRequest: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT id, status, requested_by FROM dialogs WHERE id = $1`
+	var request domain.DialogRequest
+	if err := tx.QueryRow(ctx, query, dialogID).Scan(&request.DialogID, &request.Status, &request.RequestedBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetDialogRequest/Scan: %w", err)
+	}
+
+	return &request, nil
+}
+
+func (repo *DialogsRepo) UpdateDialogStatus(ctx context.Context, transaction Transaction, dialogID int64, status domain.DialogStatus) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateDialogStatus: error: type assertion failed on interface Transaction")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE dialogs SET status = $2 WHERE id = $1`, dialogID, status); err != nil {
+		return fmt.Errorf("UpdateDialogStatus/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *DialogsRepo) CountMessagesBySender(ctx context.Context, transaction Transaction, dialogID int64, senderID int64) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("CountMessagesBySender: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT count(*) FROM messages WHERE dialog_id = $1 AND sender_id = $2`
+	var count int64
+	if err := tx.QueryRow(ctx, query, dialogID, senderID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("CountMessagesBySender/Scan: %w", err)
+	}
+
+	return count, nil
+}
+
+func (repo *DialogsRepo) InsertDialogReport(ctx context.Context, transaction Transaction, report *domain.DialogReport) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("InsertDialogReport: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO dialog_reports (dialog_id, reporter_id, reported_user_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.Exec(ctx, query, report.DialogID, report.ReporterID, report.ReportedUserID, report.Reason, report.CreatedAt); err != nil {
+		return fmt.Errorf("InsertDialogReport/Exec: %w", err)
 	}
 
 	return nil
 }
 
-func (repo *DialogsRepo) GetAllDialogsByUser(ctx context.Context, transaction Transaction, userID int64) ([]*domain.DialogParticipant, error) {
+func (repo *DialogsRepo) CreateMessageInDialog(ctx context.Context, transaction Transaction, msg *domain.Message) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("CreateMessageInDialog: error: type assertion failed on interface Transaction")
+	}
+
+	// dialogs.last_message_id is kept in sync here so the dialog list doesn't have to aggregate messages
+	query := `
+		WITH inserted AS (
+			INSERT INTO messages (dialog_id, sender_id, content, created_at, content_tsv, reply_to_message_id, nonce,
+				kind, retention_ttl, expires_at, block_number, tx_hash, log_index)
+			VALUES ($1, $2, $3, $4, to_tsvector($5::regconfig, $3), $6, $7, $8, $9, $10, $11, $12, $13)
+			RETURNING id
+		)
+		UPDATE dialogs SET last_message_id = (SELECT id FROM inserted)
+		WHERE id = $1
+		RETURNING last_message_id
+	`
+	var (
+		blockNumber, logIndex *int64
+		txHash                *string
+	)
+	if msg.ChainLog != nil {
+		blockNumber, txHash, logIndex = &msg.ChainLog.BlockNumber, &msg.ChainLog.TxHash, &msg.ChainLog.LogIndex
+	}
+	row := tx.QueryRow(ctx, query, msg.DialogID, msg.SenderID, msg.Content, msg.CreatedAt, repo.searchLanguage,
+		msg.ReplyToMessageID, msg.Nonce, msg.Kind, msg.RetentionTTL, msg.ExpiresAt, blockNumber, txHash, logIndex)
+	var messageID int64
+	if err := row.Scan(&messageID); err != nil {
+		return 0, fmt.Errorf("CreateMessageInDialog/Scan: %w", err)
+	}
+
+	return messageID, nil
+}
+
+// GetAllDialogsByUser lists the dialogs in folder. The inbox holds accepted dialogs and the requests
+// userID sent, the requests folder holds pending requests sent to userID.
+func (repo *DialogsRepo) GetAllDialogsByUser(ctx context.Context, transaction Transaction, userID int64, folder domain.DialogFolder, now int64) ([]*domain.DialogParticipant, error) {
 	tx, ok := transaction.(pgx.Tx)
 	if !ok {
 		return nil, errors.New("GetAllDialogsByUser: error: type assertion failed on interface Transaction")
 	}
 
 	query := `
-		SELECT dp1.dialog_id, uc.address
+		SELECT dp1.dialog_id, uc.address,
+			COALESCE(dp1.last_read_message_id, 0), COALESCE(dp2.last_read_message_id, 0),
+			(
+				SELECT count(*)
+				FROM messages m
+				WHERE m.dialog_id = dp1.dialog_id
+					AND m.sender_id != $1
+					AND m.deleted_at IS NULL
+					AND (m.expires_at IS NULL OR m.expires_at > $4)
+					AND m.id > COALESCE(dp1.last_read_message_id, 0)
+			),
+			lm.id, lm.sender_id, lmu.address, LEFT(lm.content, $2), lm.created_at, lm.edited_at, lm.deleted_at,
+			d.status, d.requested_by, dp1.gate_failed_at
 		FROM dialog_participants dp1
 		JOIN dialog_participants dp2 ON dp1.dialog_id = dp2.dialog_id
 		JOIN users_chain uc ON dp2.user_id = uc.id
-		WHERE dp1.user_id = $1 AND dp2.user_id != $1
+		JOIN dialogs d ON d.id = dp1.dialog_id
+		LEFT JOIN messages lm ON lm.id = d.last_message_id AND (lm.expires_at IS NULL OR lm.expires_at > $4)
+		LEFT JOIN users_chain lmu ON lmu.id = lm.sender_id
+		WHERE dp1.user_id = $1 AND dp2.user_id != $1 AND %s
+		ORDER BY d.last_message_id DESC NULLS LAST, d.id DESC
 	`
 
-	rows, err := tx.Query(ctx, query, userID)
+	folderFilter, folderStatus := "(d.status = $3 OR d.requested_by = $1)", domain.DialogAccepted
+	if folder == domain.DialogFolderRequests {
+		folderFilter, folderStatus = "d.status = $3 AND d.requested_by IS DISTINCT FROM $1", domain.DialogPending
+	}
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(query, folderFilter), userID, domain.MessagePreviewLength, folderStatus, now)
 	if err != nil {
 		return nil, fmt.Errorf("GetAllDialogsByUser/Query: %w", err)
 	}
@@ -133,10 +263,32 @@ func (repo *DialogsRepo) GetAllDialogsByUser(ctx context.Context, transaction Tr
 
 	var participants []*domain.DialogParticipant
 	for rows.Next() {
-		var participant domain.DialogParticipant
-		if err := rows.Scan(&participant.DialogID, &participant.UserAdress); err != nil {
+		var (
+			participant domain.DialogParticipant
+			lastMessage domain.Message
+
+			lastMessageID       *int64
+			lastMessageSenderID *int64
+			lastMessageSender   *string
+			lastMessageContent  *string
+			lastMessageCreated  *int64
+		)
+		if err := rows.Scan(&participant.DialogID, &participant.UserAdress,
+			&participant.LastReadMessageID, &participant.RecipientLastReadMessageID, &participant.UnreadCount,
+			&lastMessageID, &lastMessageSenderID, &lastMessageSender, &lastMessageContent, &lastMessageCreated,
+			&lastMessage.EditedAt, &lastMessage.DeletedAt,
+			&participant.Status, &participant.RequestedBy, &participant.GateFailedAt); err != nil {
 			return nil, fmt.Errorf("GetAllDialogsByUser/Scan: %w", err)
 		}
+		if lastMessageID != nil {
+			lastMessage.ID = *lastMessageID
+			lastMessage.DialogID = participant.DialogID
+			lastMessage.SenderID = *lastMessageSenderID
+			lastMessage.SenderAddress = *lastMessageSender
+			lastMessage.Content = *lastMessageContent
+			lastMessage.CreatedAt = *lastMessageCreated
+			participant.LastMessage = &lastMessage
+		}
 		participants = append(participants, &participant)
 	}
 
@@ -147,20 +299,68 @@ func (repo *DialogsRepo) GetAllDialogsByUser(ctx context.Context, transaction Tr
 	return participants, nil
 }
 
-func (repo *DialogsRepo) GetAllMessagesWithinDialogById(ctx context.Context, transaction Transaction, dialogID int64) ([]*domain.Message, error) {
+func (repo *DialogsRepo) IsDialogParticipant(ctx context.Context, transaction Transaction, dialogID int64, userID int64) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("IsDialogParticipant: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT EXISTS (SELECT 1 FROM dialog_participants WHERE dialog_id = $1 AND user_id = $2)`
+
+	row := tx.QueryRow(ctx, query, dialogID, userID)
+	var exists bool
+	if err := row.Scan(&exists); err != nil {
+		return false, fmt.Errorf("IsDialogParticipant/Scan: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (repo *DialogsRepo) UpdateLastReadMessage(ctx context.Context, transaction Transaction, dialogID int64, userID int64, messageID int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateLastReadMessage: error: type assertion failed on interface Transaction")
+	}
+
+	// read position never moves backwards
+	query := `
+		UPDATE dialog_participants
+		SET last_read_message_id = GREATEST(COALESCE(last_read_message_id, 0), $3)
+		WHERE dialog_id = $1 AND user_id = $2
+	`
+	_, err := tx.Exec(ctx, query, dialogID, userID, messageID)
+	if err != nil {
+		return fmt.Errorf("UpdateLastReadMessage/Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetAllMessagesWithinDialogById returns the dialog's messages that haven't expired by now.
+func (repo *DialogsRepo) GetAllMessagesWithinDialogById(ctx context.Context, transaction Transaction, dialogID int64, now int64) ([]*domain.Message, error) {
 	tx, ok := transaction.(pgx.Tx)
 	if !ok {
 		return nil, errors.New("GetAllMessagesWithinDialogById: error: type assertion failed on interface Transaction")
 	}
 
 	query := `
-		SELECT m.id, m.dialog_id, m.sender_id, m.content, u_c.address
+		SELECT m.id, m.dialog_id, m.sender_id, m.content, u_c.address, m.created_at, m.edited_at, m.deleted_at,
+			m.reply_to_message_id, rp.sender_id, rpu.address, LEFT(rp.content, $2), rp.created_at, rp.deleted_at,
+			m.nonce, ms.signed_dialog_id, ms.recipient_address, ms.signed_at, ms.signature,
+			m.kind, m.retention_ttl, m.expires_at, ct.status, ct.tx_hash, m.tx_hash,
+			mp.tx_hash, mp.token_address, mp.amount, mp.deposit_id, mp.status, mp.refund_tx_hash
 		FROM messages AS m
 		JOIN users_chain as u_c ON m.sender_id = u_c.id
-		WHERE dialog_id = $1
+		LEFT JOIN messages AS rp ON rp.id = m.reply_to_message_id AND (rp.expires_at IS NULL OR rp.expires_at > $3)
+		LEFT JOIN users_chain AS rpu ON rpu.id = rp.sender_id
+		LEFT JOIN message_signatures AS ms ON ms.message_id = m.id
+		LEFT JOIN message_chain_txs AS ct ON ct.message_id = m.id
+		LEFT JOIN message_payments AS mp ON mp.message_id = m.id
+		WHERE m.dialog_id = $1 AND (m.expires_at IS NULL OR m.expires_at > $3)
+		ORDER BY m.id
 	`
 
-	rows, err := tx.Query(ctx, query, dialogID)
+	rows, err := tx.Query(ctx, query, dialogID, domain.MessagePreviewLength, now)
 	if err != nil {
 		return nil, fmt.Errorf("GetAllMessagesWithinDialogById/Query: %w", err)
 	}
@@ -168,10 +368,26 @@ func (repo *DialogsRepo) GetAllMessagesWithinDialogById(ctx context.Context, tra
 
 	var messages []*domain.Message
 	for rows.Next() {
-		var message domain.Message
-		if err := rows.Scan(&message.ID, &message.DialogID, &message.SenderID, &message.Content, &message.SenderAddress); err != nil {
+		var (
+			message   domain.Message
+			quote     replyQuote
+			signature messageSignature
+			chainTx   messageChainTx
+			payment   messagePayment
+		)
+		if err := rows.Scan(&message.ID, &message.DialogID, &message.SenderID, &message.Content, &message.SenderAddress,
+			&message.CreatedAt, &message.EditedAt, &message.DeletedAt,
+			&message.ReplyToMessageID, &quote.senderID, &quote.senderAddress, &quote.content, &quote.createdAt,
+			&quote.deletedAt, &message.Nonce, &signature.dialogID, &signature.recipientAddress, &signature.signedAt,
+			&signature.signature, &message.Kind, &message.RetentionTTL, &message.ExpiresAt,
+			&chainTx.status, &chainTx.txHash, &chainTx.logTxHash, &payment.txHash, &payment.tokenAddress,
+			&payment.amount, &payment.depositID, &payment.status, &payment.refundTxHash); err != nil {
 			return nil, fmt.Errorf("GetAllMessagesWithinDialogById/Scan: %w", err)
 		}
+		message.ReplyTo = quote.toMessage(&message)
+		message.Signature = signature.toSignature()
+		message.ChainTx = chainTx.toChainTx(message.ID)
+		message.Payment = payment.toPayment(message.ID)
 		messages = append(messages, &message)
 	}
 
@@ -181,3 +397,408 @@ func (repo *DialogsRepo) GetAllMessagesWithinDialogById(ctx context.Context, tra
 
 	return messages, nil
 }
+
+func (repo *DialogsRepo) GetMessageById(ctx context.Context, transaction Transaction, messageID int64) (*domain.Message, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetMessageById: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT m.id, m.dialog_id, m.sender_id, m.content, u_c.address, m.created_at, m.edited_at, m.deleted_at,
+			m.reply_to_message_id, m.nonce, m.kind, m.expires_at, ct.status, ct.tx_hash, m.tx_hash
+		FROM messages AS m
+		JOIN users_chain as u_c ON m.sender_id = u_c.id
+		LEFT JOIN message_chain_txs AS ct ON ct.message_id = m.id
+		WHERE m.id = $1
+	`
+
+	row := tx.QueryRow(ctx, query, messageID)
+	var (
+		message domain.Message
+		chainTx messageChainTx
+	)
+	if err := row.Scan(&message.ID, &message.DialogID, &message.SenderID, &message.Content, &message.SenderAddress,
+		&message.CreatedAt, &message.EditedAt, &message.DeletedAt, &message.ReplyToMessageID, &message.Nonce,
+		&message.Kind, &message.ExpiresAt, &chainTx.status, &chainTx.txHash, &chainTx.logTxHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetMessageById/Scan: %w", err)
+	}
+	message.ChainTx = chainTx.toChainTx(message.ID)
+
+	return &message, nil
+}
+
+func (repo *DialogsRepo) UpdateMessageContent(ctx context.Context, transaction Transaction, messageID int64, content string, editedAt int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateMessageContent: error: type assertion failed on interface Transaction")
+	}
+
+	query := `UPDATE messages SET content = $2, edited_at = $3, content_tsv = to_tsvector($4::regconfig, $2) WHERE id = $1`
+	_, err := tx.Exec(ctx, query, messageID, content, editedAt, repo.searchLanguage)
+	if err != nil {
+		return fmt.Errorf("UpdateMessageContent/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *DialogsRepo) InsertMessageSignature(ctx context.Context, transaction Transaction, messageID int64, signature *domain.MessageSignature) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("InsertMessageSignature: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO message_signatures (message_id, signed_dialog_id, recipient_address, signed_at, signature)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.Exec(ctx, query, messageID, signature.DialogID, signature.RecipientAddress, signature.SignedAt,
+		signature.Signature); err != nil {
+		return fmt.Errorf("InsertMessageSignature/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *DialogsRepo) DeleteMessageSignature(ctx context.Context, transaction Transaction, messageID int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("DeleteMessageSignature: error: type assertion failed on interface Transaction")
+	}
+
+	query := `DELETE FROM message_signatures WHERE message_id = $1`
+	if _, err := tx.Exec(ctx, query, messageID); err != nil {
+		return fmt.Errorf("DeleteMessageSignature/Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetDialogRetention returns the dialog's retention timer in milliseconds, 0 if messages don't expire.
+func (repo *DialogsRepo) GetDialogRetention(ctx context.Context, transaction Transaction, dialogID int64) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("GetDialogRetention: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT retention_ttl FROM dialogs WHERE id = $1`
+	var ttl int64
+	if err := tx.QueryRow(ctx, query, dialogID).Scan(&ttl); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNoRows
+		}
+		return 0, fmt.Errorf("GetDialogRetention/Scan: %w", err)
+	}
+
+	return ttl, nil
+}
+
+func (repo *DialogsRepo) UpdateDialogRetention(ctx context.Context, transaction Transaction, dialogID int64, ttl int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateDialogRetention: error: type assertion failed on interface Transaction")
+	}
+
+	query := `UPDATE dialogs SET retention_ttl = $2 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, dialogID, ttl); err != nil {
+		return fmt.Errorf("UpdateDialogRetention/Exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredMessages hard-deletes up to limit messages that expired by now. It returns the number of
+// deleted messages and the storage keys of their attachments, the blobs are left to the caller.
+func (repo *DialogsRepo) DeleteExpiredMessages(ctx context.Context, transaction Transaction, now, limit int64) (int64, []string, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, nil, errors.New("DeleteExpiredMessages: error: type assertion failed on interface Transaction")
+	}
+
+	// the attachments join reads the snapshot taken before the delete cascades to them
+	query := `
+		WITH deleted AS (
+			DELETE FROM messages
+			WHERE id IN (
+				SELECT id FROM messages
+				WHERE expires_at <= $1
+				ORDER BY expires_at
+				LIMIT $2
+			)
+			RETURNING id, dialog_id
+		)
+		SELECT d.id, d.dialog_id, a.storage_key
+		FROM deleted AS d
+		LEFT JOIN attachments AS a ON a.message_id = d.id
+	`
+	rows, err := tx.Query(ctx, query, now, limit)
+	if err != nil {
+		return 0, nil, fmt.Errorf("DeleteExpiredMessages/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		deleted     = make(map[int64]struct{})
+		dialogIDs   []int64
+		storageKeys []string
+	)
+	for rows.Next() {
+		var (
+			messageID, dialogID int64
+			storageKey          *string
+		)
+		if err := rows.Scan(&messageID, &dialogID, &storageKey); err != nil {
+			return 0, nil, fmt.Errorf("DeleteExpiredMessages/Scan: %w", err)
+		}
+		deleted[messageID] = struct{}{}
+		dialogIDs = append(dialogIDs, dialogID)
+		if storageKey != nil {
+			storageKeys = append(storageKeys, *storageKey)
+		}
+	}
+	if rows.Err() != nil {
+		return 0, nil, fmt.Errorf("DeleteExpiredMessages/Rows: %w", rows.Err())
+	}
+
+	// the last message reference was nulled by the delete, point it at what is left
+	query = `
+		UPDATE dialogs AS d
+		SET last_message_id = (SELECT max(m.id) FROM messages AS m WHERE m.dialog_id = d.id)
+		WHERE d.id = ANY($1) AND d.last_message_id IS NULL
+	`
+	if _, err := tx.Exec(ctx, query, dialogIDs); err != nil {
+		return 0, nil, fmt.Errorf("DeleteExpiredMessages/Exec: %w", err)
+	}
+
+	return int64(len(deleted)), storageKeys, nil
+}
+
+func (repo *DialogsRepo) MarkMessageDeleted(ctx context.Context, transaction Transaction, messageID int64, deletedAt int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("MarkMessageDeleted: error: type assertion failed on interface Transaction")
+	}
+
+	query := `UPDATE messages SET content = '', content_tsv = NULL, deleted_at = $2 WHERE id = $1`
+	_, err := tx.Exec(ctx, query, messageID, deletedAt)
+	if err != nil {
+		return fmt.Errorf("MarkMessageDeleted/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *DialogsRepo) InsertMessageRevision(ctx context.Context, transaction Transaction, revision *domain.MessageRevision) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("InsertMessageRevision: error: type assertion failed on interface Transaction")
+	}
+
+	query := `INSERT INTO message_revisions (message_id, editor_id, content, created_at) VALUES ($1, $2, $3, $4)`
+	_, err := tx.Exec(ctx, query, revision.MessageID, revision.EditorID, revision.Content, revision.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("InsertMessageRevision/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *DialogsRepo) SearchMessages(ctx context.Context, transaction Transaction, userID int64, query string, limit, offset, now int64) ([]*domain.MessageSearchResult, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("SearchMessages: error: type assertion failed on interface Transaction")
+	}
+
+	sqlQuery := `
+		WITH q AS (SELECT websearch_to_tsquery($2::regconfig, $3) AS query)
+		SELECT m.id, m.dialog_id, u_c.address, m.created_at,
+			ts_headline($2::regconfig, translate(m.content, E'\x02\x03', '  '), q.query,
+				E'StartSel=\x02, StopSel=\x03, MaxFragments=2'),
+			ts_rank(m.content_tsv, q.query) AS rank
+		FROM messages AS m
+		CROSS JOIN q
+		JOIN dialog_participants dp ON dp.dialog_id = m.dialog_id AND dp.user_id = $1
+		JOIN users_chain AS u_c ON m.sender_id = u_c.id
+		WHERE m.content_tsv @@ q.query AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > $6)
+		ORDER BY rank DESC, m.id DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := tx.Query(ctx, sqlQuery, userID, repo.searchLanguage, query, limit, offset, now)
+	if err != nil {
+		return nil, fmt.Errorf("SearchMessages/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*domain.MessageSearchResult
+	for rows.Next() {
+		var result domain.MessageSearchResult
+		if err := rows.Scan(&result.MessageID, &result.DialogID, &result.SenderAddress, &result.CreatedAt,
+			&result.Snippet, &result.Rank); err != nil {
+			return nil, fmt.Errorf("SearchMessages/Scan: %w", err)
+		}
+		result.Snippet = highlightSnippet(result.Snippet)
+		results = append(results, &result)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("SearchMessages/Rows: %w", rows.Err())
+	}
+
+	return results, nil
+}
+
+// snippetMarks turns the headline's control character delimiters into <mark> tags once the
+// content around them is escaped, so a snippet is safe to render as HTML.
+var snippetMarks = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+func highlightSnippet(headline string) string {
+	return snippetMarks.Replace(html.EscapeString(headline))
+}
+
+// GetMessageThread returns the replies to rootID, directly or transitively, in depth-first order.
+// The second value is the total number of replies in the thread.
+func (repo *DialogsRepo) GetMessageThread(ctx context.Context, transaction Transaction, rootID int64, limit, offset, now int64) ([]*domain.Message, int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, 0, errors.New("GetMessageThread: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		WITH RECURSIVE thread AS (
+			SELECT id, 1 AS depth, ARRAY[id] AS path
+			FROM messages
+			WHERE reply_to_message_id = $1
+			UNION ALL
+			SELECT m.id, t.depth + 1, t.path || m.id
+			FROM messages AS m
+			JOIN thread AS t ON m.reply_to_message_id = t.id
+		)
+		SELECT m.id, m.dialog_id, m.sender_id, m.content, u_c.address, m.created_at, m.edited_at, m.deleted_at,
+			m.reply_to_message_id, t.depth, COUNT(*) OVER ()
+		FROM thread AS t
+		JOIN messages AS m ON m.id = t.id
+		JOIN users_chain AS u_c ON m.sender_id = u_c.id
+		WHERE m.expires_at IS NULL OR m.expires_at > $4
+		ORDER BY t.path
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := tx.Query(ctx, query, rootID, limit, offset, now)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetMessageThread/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		messages []*domain.Message
+		total    int64
+	)
+	for rows.Next() {
+		var message domain.Message
+		if err := rows.Scan(&message.ID, &message.DialogID, &message.SenderID, &message.Content, &message.SenderAddress,
+			&message.CreatedAt, &message.EditedAt, &message.DeletedAt, &message.ReplyToMessageID, &message.Depth,
+			&total); err != nil {
+			return nil, 0, fmt.Errorf("GetMessageThread/Scan: %w", err)
+		}
+		messages = append(messages, &message)
+	}
+
+	if rows.Err() != nil {
+		return nil, 0, fmt.Errorf("GetMessageThread/Rows: %w", rows.Err())
+	}
+
+	return messages, total, nil
+}
+
+// replyQuote holds the nullable columns of a LEFT JOINed parent message.
+type replyQuote struct {
+	senderID      *int64
+	senderAddress *string
+	content       *string
+	createdAt     *int64
+	deletedAt     *int64
+}
+
+func (q *replyQuote) toMessage(reply *domain.Message) *domain.Message {
+	if reply.ReplyToMessageID == nil || q.senderID == nil {
+		return nil
+	}
+
+	return &domain.Message{
+		ID:            *reply.ReplyToMessageID,
+		DialogID:      reply.DialogID,
+		SenderID:      *q.senderID,
+		SenderAddress: *q.senderAddress,
+		Content:       *q.content,
+		CreatedAt:     *q.createdAt,
+		DeletedAt:     q.deletedAt,
+	}
+}
+
+type messageSignature struct {
+	dialogID         *int64
+	recipientAddress *string
+	signedAt         *int64
+	signature        *string
+}
+
+func (s *messageSignature) toSignature() *domain.MessageSignature {
+	if s.signature == nil {
+		return nil
+	}
+
+	return &domain.MessageSignature{
+		DialogID:         *s.dialogID,
+		RecipientAddress: *s.recipientAddress,
+		SignedAt:         *s.signedAt,
+		Signature:        *s.signature,
+	}
+}
+
+type messageChainTx struct {
+	status *domain.ChainTxStatus
+	txHash *string
+	// logTxHash is set for messages indexed from the contract logs
+	logTxHash *string
+}
+
+// toChainTx reports indexed messages as confirmed transactions, they were read from mined blocks.
+func (c *messageChainTx) toChainTx(messageID int64) *domain.MessageChainTx {
+	switch {
+	case c.status != nil:
+		return &domain.MessageChainTx{MessageID: messageID, Status: *c.status, TxHash: c.txHash}
+	case c.logTxHash != nil:
+		return &domain.MessageChainTx{MessageID: messageID, Status: domain.ChainTxConfirmed, TxHash: c.logTxHash}
+	default:
+		return nil
+	}
+}
+
+type messagePayment struct {
+	txHash       *string
+	tokenAddress *string
+	amount       *string
+	depositID    *string
+	status       *domain.PaymentStatus
+	refundTxHash *string
+}
+
+func (p *messagePayment) toPayment(messageID int64) *domain.MessagePayment {
+	if p.txHash == nil {
+		return nil
+	}
+	return &domain.MessagePayment{
+		TxHash:       *p.txHash,
+		MessageID:    &messageID,
+		TokenAddress: p.tokenAddress,
+		Amount:       *p.amount,
+		DepositID:    p.depositID,
+		Status:       *p.status,
+		RefundTxHash: p.refundTxHash,
+	}
+}
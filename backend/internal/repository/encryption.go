@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type EncryptionRepo struct {
+}
+
+func NewEncryptionRepo() Encryption {
+	return &EncryptionRepo{}
+}
+
+func (repo *EncryptionRepo) InsertEncryptionKey(ctx context.Context, transaction Transaction, key *domain.EncryptionKey) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("InsertEncryptionKey: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO encryption_keys (user_id, public_key, signature, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	var id int64
+	if err := tx.QueryRow(ctx, query, key.UserID, key.PublicKey, key.Signature, key.CreatedAt).Scan(&id); err != nil {
+		return 0, fmt.Errorf("InsertEncryptionKey/Scan: %w", err)
+	}
+
+	return id, nil
+}
+
+// RotateEncryptionKey retires the current key of userID. The key row is kept so
+// ciphertexts referring to it stay resolvable.
+func (repo *EncryptionRepo) RotateEncryptionKey(ctx context.Context, transaction Transaction, userID, rotatedAt int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("RotateEncryptionKey: error: type assertion failed on interface Transaction")
+	}
+
+	query := `UPDATE encryption_keys SET rotated_at = $2 WHERE user_id = $1 AND rotated_at IS NULL`
+	if _, err := tx.Exec(ctx, query, userID, rotatedAt); err != nil {
+		return fmt.Errorf("RotateEncryptionKey/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *EncryptionRepo) GetCurrentEncryptionKey(ctx context.Context, transaction Transaction, userID int64) (*domain.EncryptionKey, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetCurrentEncryptionKey: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT id, user_id, public_key, signature, created_at, rotated_at
+		FROM encryption_keys
+		WHERE user_id = $1 AND rotated_at IS NULL
+	`
+	var key domain.EncryptionKey
+	if err := tx.QueryRow(ctx, query, userID).Scan(&key.ID, &key.UserID, &key.PublicKey, &key.Signature,
+		&key.CreatedAt, &key.RotatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetCurrentEncryptionKey/Scan: %w", err)
+	}
+
+	return &key, nil
+}
+
+// GetEncryptionKeys returns all keys userID has published, the current one first.
+func (repo *EncryptionRepo) GetEncryptionKeys(ctx context.Context, transaction Transaction, userID int64) ([]*domain.EncryptionKey, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetEncryptionKeys: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT id, user_id, public_key, signature, created_at, rotated_at
+		FROM encryption_keys
+		WHERE user_id = $1
+		ORDER BY rotated_at DESC NULLS FIRST, id DESC
+	`
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetEncryptionKeys/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.EncryptionKey
+	for rows.Next() {
+		var key domain.EncryptionKey
+		if err := rows.Scan(&key.ID, &key.UserID, &key.PublicKey, &key.Signature, &key.CreatedAt, &key.RotatedAt); err != nil {
+			return nil, fmt.Errorf("GetEncryptionKeys/Scan: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetEncryptionKeys/Rows: %w", rows.Err())
+	}
+
+	return keys, nil
+}
+
+func (repo *EncryptionRepo) InsertMessageCiphertext(ctx context.Context, transaction Transaction, ciphertext *domain.MessageCiphertext) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("InsertMessageCiphertext: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO message_ciphertexts (message_id, recipient_id, key_id, ciphertext)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.Exec(ctx, query, ciphertext.MessageID, ciphertext.RecipientID, ciphertext.KeyID,
+		ciphertext.Ciphertext); err != nil {
+		return fmt.Errorf("InsertMessageCiphertext/Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetCiphertextsByDialog returns the ciphertexts addressed to recipientID in the dialog.
+func (repo *EncryptionRepo) GetCiphertextsByDialog(ctx context.Context, transaction Transaction, dialogID, recipientID int64) ([]*domain.MessageCiphertext, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetCiphertextsByDialog: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT c.message_id, c.recipient_id, c.key_id, c.ciphertext
+		FROM message_ciphertexts AS c
+		JOIN messages AS m ON m.id = c.message_id
+		WHERE m.dialog_id = $1 AND c.recipient_id = $2
+	`
+	rows, err := tx.Query(ctx, query, dialogID, recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("GetCiphertextsByDialog/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var ciphertexts []*domain.MessageCiphertext
+	for rows.Next() {
+		var c domain.MessageCiphertext
+		if err := rows.Scan(&c.MessageID, &c.RecipientID, &c.KeyID, &c.Ciphertext); err != nil {
+			return nil, fmt.Errorf("GetCiphertextsByDialog/Scan: %w", err)
+		}
+		ciphertexts = append(ciphertexts, &c)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetCiphertextsByDialog/Rows: %w", rows.Err())
+	}
+
+	return ciphertexts, nil
+}
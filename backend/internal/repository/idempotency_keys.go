@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type IdempotencyKeysRepo struct {
+}
+
+func NewIdempotencyKeysRepo() IdempotencyKeys {
+	return &IdempotencyKeysRepo{}
+}
+
+// ClaimIdempotencyKey stores the key and reports whether it was free. A concurrent claim of the
+// same key waits for the first transaction and then reports false.
+func (repo *IdempotencyKeysRepo) ClaimIdempotencyKey(ctx context.Context, transaction Transaction, key *domain.IdempotencyKey) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("ClaimIdempotencyKey: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO message_idempotency_keys (sender_id, idempotency_key, request_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, key.SenderID, key.Key, key.RequestHash, key.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("ClaimIdempotencyKey/Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (repo *IdempotencyKeysRepo) GetIdempotencyKey(ctx context.Context, transaction Transaction, senderID int64, key string) (*domain.IdempotencyKey, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetIdempotencyKey: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT sender_id, idempotency_key, request_hash, message_id, pending_message_id, created_at
+		FROM message_idempotency_keys
+		WHERE sender_id = $1 AND idempotency_key = $2
+	`
+	var k domain.IdempotencyKey
+	if err := tx.QueryRow(ctx, query, senderID, key).Scan(&k.SenderID, &k.Key, &k.RequestHash, &k.MessageID,
+		&k.PendingMessageID, &k.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetIdempotencyKey/Scan: %w", err)
+	}
+
+	return &k, nil
+}
+
+func (repo *IdempotencyKeysRepo) SetIdempotencyKeyResult(ctx context.Context, transaction Transaction, key *domain.IdempotencyKey) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("SetIdempotencyKeyResult: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		UPDATE message_idempotency_keys SET message_id = $3, pending_message_id = $4
+		WHERE sender_id = $1 AND idempotency_key = $2
+	`
+	if _, err := tx.Exec(ctx, query, key.SenderID, key.Key, key.MessageID, key.PendingMessageID); err != nil {
+		return fmt.Errorf("SetIdempotencyKeyResult/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *IdempotencyKeysRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, transaction Transaction, senderID, before int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("DeleteExpiredIdempotencyKeys: error: type assertion failed on interface Transaction")
+	}
+
+	query := `DELETE FROM message_idempotency_keys WHERE sender_id = $1 AND created_at < $2`
+	if _, err := tx.Exec(ctx, query, senderID, before); err != nil {
+		return fmt.Errorf("DeleteExpiredIdempotencyKeys/Exec: %w", err)
+	}
+
+	return nil
+}
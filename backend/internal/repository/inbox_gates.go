@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type InboxGatesRepo struct {
+}
+
+func NewInboxGatesRepo() InboxGates {
+	return &InboxGatesRepo{}
+}
+
+func (repo *InboxGatesRepo) GetInboxGates(ctx context.Context, transaction Transaction, userID int64) ([]*domain.InboxGate, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetInboxGates: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT id, user_id, token_address, standard, min_balance, created_at
+		FROM inbox_gates
+		WHERE user_id = $1
+		ORDER BY id
+	`
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetInboxGates/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var gates []*domain.InboxGate
+	for rows.Next() {
+		var g domain.InboxGate
+		if err := rows.Scan(&g.ID, &g.UserID, &g.TokenAddress, &g.Standard, &g.MinBalance, &g.CreatedAt); err != nil {
+			return nil, fmt.Errorf("GetInboxGates/Scan: %w", err)
+		}
+		gates = append(gates, &g)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetInboxGates/Rows: %w", rows.Err())
+	}
+
+	return gates, nil
+}
+
+// ReplaceInboxGates sets the user's gates to gates. The user's dialogs are queued for a recheck
+// against the new gates, their flags are cleared when no gates are left.
+func (repo *InboxGatesRepo) ReplaceInboxGates(ctx context.Context, transaction Transaction, userID int64, gates []*domain.InboxGate) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("ReplaceInboxGates: error: type assertion failed on interface Transaction")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM inbox_gates WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("ReplaceInboxGates/Delete: %w", err)
+	}
+
+	var (
+		tokens      = make([]string, 0, len(gates))
+		standards   = make([]int32, 0, len(gates))
+		minBalances = make([]string, 0, len(gates))
+		createdAts  = make([]int64, 0, len(gates))
+	)
+	for _, g := range gates {
+		tokens = append(tokens, g.TokenAddress)
+		standards = append(standards, int32(g.Standard))
+		minBalances = append(minBalances, g.MinBalance)
+		createdAts = append(createdAts, g.CreatedAt)
+	}
+	query := `
+		INSERT INTO inbox_gates (user_id, token_address, standard, min_balance, created_at)
+		SELECT $1, token_address, standard, min_balance, created_at
+		FROM unnest($2::TEXT[], $3::INT[], $4::TEXT[], $5::BIGINT[]) AS g(token_address, standard, min_balance, created_at)
+	`
+	if _, err := tx.Exec(ctx, query, userID, tokens, standards, minBalances, createdAts); err != nil {
+		return fmt.Errorf("ReplaceInboxGates/Insert: %w", err)
+	}
+
+	query = `
+		UPDATE dialog_participants
+		SET gate_checked_at = NULL,
+			gate_failed_at = CASE WHEN $2 THEN gate_failed_at END
+		WHERE user_id = $1
+	`
+	if _, err := tx.Exec(ctx, query, userID, len(gates) > 0); err != nil {
+		return fmt.Errorf("ReplaceInboxGates/Update: %w", err)
+	}
+
+	return nil
+}
+
+// GetGatedDialogsToCheck returns dialogs of users with inbox gates that weren't checked since checkedBefore,
+// never checked first. Dialogs the gate owner started are skipped, the other participant didn't pass a gate.
+// Rows are locked so concurrent workers skip them.
+func (repo *InboxGatesRepo) GetGatedDialogsToCheck(ctx context.Context, transaction Transaction, checkedBefore, limit int64) ([]*domain.GatedDialog, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetGatedDialogsToCheck: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT dp1.dialog_id, dp1.user_id, uc.address, dp1.gate_failed_at
+		FROM dialog_participants dp1
+		JOIN dialog_participants dp2 ON dp2.dialog_id = dp1.dialog_id AND dp2.user_id != dp1.user_id
+		JOIN users_chain uc ON uc.id = dp2.user_id
+		JOIN dialogs d ON d.id = dp1.dialog_id
+		WHERE EXISTS (SELECT 1 FROM inbox_gates g WHERE g.user_id = dp1.user_id)
+			AND d.requested_by IS DISTINCT FROM dp1.user_id
+			AND (dp1.gate_checked_at IS NULL OR dp1.gate_checked_at < $1)
+		ORDER BY dp1.gate_checked_at NULLS FIRST, dp1.dialog_id
+		LIMIT $2
+		FOR UPDATE OF dp1 SKIP LOCKED
+	`
+	rows, err := tx.Query(ctx, query, checkedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetGatedDialogsToCheck/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var dialogs []*domain.GatedDialog
+	for rows.Next() {
+		var d domain.GatedDialog
+		if err := rows.Scan(&d.DialogID, &d.OwnerID, &d.CounterpartAddress, &d.GateFailedAt); err != nil {
+			return nil, fmt.Errorf("GetGatedDialogsToCheck/Scan: %w", err)
+		}
+		dialogs = append(dialogs, &d)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetGatedDialogsToCheck/Rows: %w", rows.Err())
+	}
+
+	return dialogs, nil
+}
+
+func (repo *InboxGatesRepo) UpdateDialogGateCheck(ctx context.Context, transaction Transaction, dialog *domain.GatedDialog, checkedAt int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateDialogGateCheck: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		UPDATE dialog_participants
+		SET gate_checked_at = $3, gate_failed_at = $4
+		WHERE dialog_id = $1 AND user_id = $2
+	`
+	if _, err := tx.Exec(ctx, query, dialog.DialogID, dialog.OwnerID, checkedAt, dialog.GateFailedAt); err != nil {
+		return fmt.Errorf("UpdateDialogGateCheck/Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type LoginSessionsRepo struct {
+}
+
+func NewLoginSessionsRepo() LoginSessions {
+	return &LoginSessionsRepo{}
+}
+
+func (r *LoginSessionsRepo) InsertLoginSession(ctx context.Context, transaction Transaction, session *domain.LoginSession) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("InsertLoginSession: error: type assertion failed on interface Transaction")
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO login_sessions (id, poll_token_hash, message, status, created_at, expires_at, chain_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		session.ID, session.PollTokenHash, session.Message, session.Status, session.CreatedAt, session.ExpiresAt,
+		session.ChainID); err != nil {
+		return fmt.Errorf("InsertLoginSession/Exec: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginSessionsRepo) GetLoginSession(ctx context.Context, transaction Transaction, id string) (*domain.LoginSession, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetLoginSession: error: type assertion failed on interface Transaction")
+	}
+	row := tx.QueryRow(ctx, `SELECT id, poll_token_hash, message, status, user_id, created_at, expires_at, chain_id
+		FROM login_sessions WHERE id = $1`, id)
+
+	var session domain.LoginSession
+	if err := row.Scan(&session.ID, &session.PollTokenHash, &session.Message, &session.Status, &session.UserID,
+		&session.CreatedAt, &session.ExpiresAt, &session.ChainID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetLoginSession/Scan: %w", err)
+	}
+	return &session, nil
+}
+
+func (r *LoginSessionsRepo) VerifyLoginSession(ctx context.Context, transaction Transaction, id string, userID int64, now int64) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("VerifyLoginSession: error: type assertion failed on interface Transaction")
+	}
+	tag, err := tx.Exec(ctx, `UPDATE login_sessions SET status = $2, user_id = $3
+		WHERE id = $1 AND status = $4 AND expires_at > $5`,
+		id, domain.LoginSessionVerified, userID, domain.LoginSessionPending, now)
+	if err != nil {
+		return false, fmt.Errorf("VerifyLoginSession/Exec: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RedeemLoginSession moves a verified session to redeemed and returns its user.
+// It returns ErrNoRows if the session can't be redeemed, so a session is redeemed at most once.
+func (r *LoginSessionsRepo) RedeemLoginSession(ctx context.Context, transaction Transaction, id string, pollTokenHash string, now int64) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("RedeemLoginSession: error: type assertion failed on interface Transaction")
+	}
+	row := tx.QueryRow(ctx, `UPDATE login_sessions SET status = $3
+		WHERE id = $1 AND poll_token_hash = $2 AND status = $4 AND expires_at > $5
+		RETURNING user_id`,
+		id, pollTokenHash, domain.LoginSessionRedeemed, domain.LoginSessionVerified, now)
+
+	var userID int64
+	if err := row.Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNoRows
+		}
+		return 0, fmt.Errorf("RedeemLoginSession/Scan: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *LoginSessionsRepo) DeleteExpiredLoginSessions(ctx context.Context, transaction Transaction, now int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("DeleteExpiredLoginSessions: error: type assertion failed on interface Transaction")
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM login_sessions WHERE expires_at <= $1`, now); err != nil {
+		return fmt.Errorf("DeleteExpiredLoginSessions/Exec: %w", err)
+	}
+	return nil
+}
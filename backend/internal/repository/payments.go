@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type PaymentsRepo struct {
+}
+
+func NewPaymentsRepo() Payments {
+	return &PaymentsRepo{}
+}
+
+// GetMessagePrice returns nil when the user doesn't charge for first contact.
+func (repo *PaymentsRepo) GetMessagePrice(ctx context.Context, transaction Transaction, userID int64) (*domain.MessagePrice, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetMessagePrice: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT message_price_token, message_price_amount FROM users_chain WHERE id = $1`
+	var (
+		price  domain.MessagePrice
+		amount *string
+	)
+	if err := tx.QueryRow(ctx, query, userID).Scan(&price.TokenAddress, &amount); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("GetMessagePrice/Scan: %w", err)
+	}
+	if amount == nil {
+		return nil, nil
+	}
+	price.Amount = *amount
+
+	return &price, nil
+}
+
+// UpdateMessagePrice sets the user's price, a nil price removes it.
+func (repo *PaymentsRepo) UpdateMessagePrice(ctx context.Context, transaction Transaction, userID int64, price *domain.MessagePrice) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateMessagePrice: error: type assertion failed on interface Transaction")
+	}
+
+	var token, amount *string
+	if price != nil {
+		token, amount = price.TokenAddress, &price.Amount
+	}
+	query := `UPDATE users_chain SET message_price_token = $2, message_price_amount = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, userID, token, amount); err != nil {
+		return fmt.Errorf("UpdateMessagePrice/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *PaymentsRepo) IsPaymentTxUsed(ctx context.Context, transaction Transaction, txHash string) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("IsPaymentTxUsed: error: type assertion failed on interface Transaction")
+	}
+
+	var used bool
+	query := `SELECT EXISTS (SELECT 1 FROM message_payments WHERE tx_hash = $1)`
+	if err := tx.QueryRow(ctx, query, txHash).Scan(&used); err != nil {
+		return false, fmt.Errorf("IsPaymentTxUsed/Scan: %w", err)
+	}
+
+	return used, nil
+}
+
+// InsertMessagePayment returns false when the transaction already paid for another message.
+func (repo *PaymentsRepo) InsertMessagePayment(ctx context.Context, transaction Transaction, payment *domain.MessagePayment) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("InsertMessagePayment: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO message_payments (tx_hash, message_id, dialog_id, payer_id, recipient_id, token_address, amount,
+			deposit_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		ON CONFLICT (tx_hash) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, payment.TxHash, payment.MessageID, payment.DialogID, payment.PayerID,
+		payment.RecipientID, payment.TokenAddress, payment.Amount, payment.DepositID, payment.Status, payment.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("InsertMessagePayment/Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// QueueDialogRefunds queues refunds of the escrow deposits payerID holds in the dialog.
+func (repo *PaymentsRepo) QueueDialogRefunds(ctx context.Context, transaction Transaction, dialogID, payerID, now int64) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("QueueDialogRefunds: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		UPDATE message_payments SET status = $4, updated_at = $5
+		WHERE dialog_id = $1 AND payer_id = $2 AND status = $3
+	`
+	tag, err := tx.Exec(ctx, query, dialogID, payerID, domain.PaymentHeld, domain.PaymentRefundQueued, now)
+	if err != nil {
+		return 0, fmt.Errorf("QueueDialogRefunds/Exec: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetMessagePaymentsByStatus returns the oldest payments in status after afterID. Rows are locked so concurrent
+// workers skip them.
+func (repo *PaymentsRepo) GetMessagePaymentsByStatus(ctx context.Context, transaction Transaction, status domain.PaymentStatus, afterID, limit int64) ([]*domain.MessagePayment, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetMessagePaymentsByStatus: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT id, tx_hash, message_id, dialog_id, payer_id, recipient_id, token_address, amount, deposit_id, status,
+			refund_tx_hash, refund_account_nonce, refund_gas_tip_cap, refund_gas_fee_cap, refund_submitted_at,
+			replaced_refund_tx_hashes, error, attempts, created_at, updated_at
+		FROM message_payments
+		WHERE status = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(ctx, query, status, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetMessagePaymentsByStatus/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*domain.MessagePayment
+	for rows.Next() {
+		var (
+			p                         domain.MessagePayment
+			accountNonce, submittedAt *int64
+			gasTipCap, gasFeeCap      *string
+		)
+		if err := rows.Scan(&p.ID, &p.TxHash, &p.MessageID, &p.DialogID, &p.PayerID, &p.RecipientID, &p.TokenAddress,
+			&p.Amount, &p.DepositID, &p.Status, &p.RefundTxHash, &accountNonce, &gasTipCap, &gasFeeCap, &submittedAt,
+			&p.ReplacedRefundTxHashes, &p.Error, &p.Attempts, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("GetMessagePaymentsByStatus/Scan: %w", err)
+		}
+		if accountNonce != nil {
+			p.RefundSent = &domain.SentChainTx{
+				AccountNonce: *accountNonce,
+				GasTipCap:    *gasTipCap,
+				GasFeeCap:    *gasFeeCap,
+				SubmittedAt:  *submittedAt,
+			}
+		}
+		payments = append(payments, &p)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetMessagePaymentsByStatus/Rows: %w", rows.Err())
+	}
+
+	return payments, nil
+}
+
+func (repo *PaymentsRepo) UpdateMessagePayment(ctx context.Context, transaction Transaction, payment *domain.MessagePayment) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateMessagePayment: error: type assertion failed on interface Transaction")
+	}
+
+	var accountNonce, submittedAt *int64
+	var gasTipCap, gasFeeCap *string
+	if payment.RefundSent != nil {
+		accountNonce, submittedAt = &payment.RefundSent.AccountNonce, &payment.RefundSent.SubmittedAt
+		gasTipCap, gasFeeCap = &payment.RefundSent.GasTipCap, &payment.RefundSent.GasFeeCap
+	}
+	replaced := payment.ReplacedRefundTxHashes
+	if replaced == nil {
+		replaced = []string{}
+	}
+	query := `
+		UPDATE message_payments SET status = $2, refund_tx_hash = $3, refund_account_nonce = $4, refund_gas_tip_cap = $5,
+			refund_gas_fee_cap = $6, refund_submitted_at = $7, replaced_refund_tx_hashes = $8, error = $9, attempts = $10,
+			updated_at = $11
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, payment.ID, payment.Status, payment.RefundTxHash, accountNonce, gasTipCap, gasFeeCap,
+		submittedAt, replaced, payment.Error, payment.Attempts, payment.UpdatedAt); err != nil {
+		return fmt.Errorf("UpdateMessagePayment/Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type PendingMessagesRepo struct {
+}
+
+func NewPendingMessagesRepo() PendingMessages {
+	return &PendingMessagesRepo{}
+}
+
+func (repo *PendingMessagesRepo) InsertPendingMessage(ctx context.Context, transaction Transaction, msg *domain.PendingMessage) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("InsertPendingMessage: error: type assertion failed on interface Transaction")
+	}
+
+	var (
+		signedAt  *int64
+		signature *string
+	)
+	if msg.Signature != nil {
+		signedAt, signature = &msg.Signature.SignedAt, &msg.Signature.Signature
+	}
+
+	query := `
+		INSERT INTO pending_messages (sender_id, recipient_address, content, created_at, signed_at, signature)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	var id int64
+	if err := tx.QueryRow(ctx, query, msg.SenderID, msg.RecipientAddress, msg.Content, msg.CreatedAt,
+		signedAt, signature).Scan(&id); err != nil {
+		return 0, fmt.Errorf("InsertPendingMessage/Scan: %w", err)
+	}
+
+	return id, nil
+}
+
+func (repo *PendingMessagesRepo) CountPendingMessages(ctx context.Context, transaction Transaction, senderID int64, recipientAddress string) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("CountPendingMessages: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT COUNT(*) FROM pending_messages WHERE sender_id = $1 AND recipient_address = $2`
+	var count int64
+	if err := tx.QueryRow(ctx, query, senderID, recipientAddress).Scan(&count); err != nil {
+		return 0, fmt.Errorf("CountPendingMessages/Scan: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountPendingRecipients returns how many distinct addresses senderID started a pending conversation with since the given time.
+func (repo *PendingMessagesRepo) CountPendingRecipients(ctx context.Context, transaction Transaction, senderID, since int64) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("CountPendingRecipients: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT COUNT(*) FROM (
+			SELECT recipient_address
+			FROM pending_messages
+			WHERE sender_id = $1
+			GROUP BY recipient_address
+			HAVING MIN(created_at) >= $2
+		) AS recipients
+	`
+	var count int64
+	if err := tx.QueryRow(ctx, query, senderID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("CountPendingRecipients/Scan: %w", err)
+	}
+
+	return count, nil
+}
+
+func (repo *PendingMessagesRepo) GetPendingMessagesBySender(ctx context.Context, transaction Transaction, senderID int64) ([]*domain.PendingMessage, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetPendingMessagesBySender: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT id, sender_id, recipient_address, content, created_at, signed_at, signature
+		FROM pending_messages
+		WHERE sender_id = $1
+		ORDER BY id
+	`
+	rows, err := tx.Query(ctx, query, senderID)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingMessagesBySender/Query: %w", err)
+	}
+	defer rows.Close()
+
+	pending, err := scanPendingMessages(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingMessagesBySender/scanPendingMessages: %w", err)
+	}
+
+	return pending, nil
+}
+
+// TakePendingMessages deletes and returns everything waiting for recipientAddress in the order it was sent.
+func (repo *PendingMessagesRepo) TakePendingMessages(ctx context.Context, transaction Transaction, recipientAddress string) ([]*domain.PendingMessage, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("TakePendingMessages: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		WITH taken AS (
+			DELETE FROM pending_messages
+			WHERE recipient_address = $1
+			RETURNING id, sender_id, recipient_address, content, created_at, signed_at, signature
+		)
+		SELECT id, sender_id, recipient_address, content, created_at, signed_at, signature
+		FROM taken
+		ORDER BY id
+	`
+	rows, err := tx.Query(ctx, query, recipientAddress)
+	if err != nil {
+		return nil, fmt.Errorf("TakePendingMessages/Query: %w", err)
+	}
+	defer rows.Close()
+
+	pending, err := scanPendingMessages(rows)
+	if err != nil {
+		return nil, fmt.Errorf("TakePendingMessages/scanPendingMessages: %w", err)
+	}
+
+	return pending, nil
+}
+
+func scanPendingMessages(rows pgx.Rows) ([]*domain.PendingMessage, error) {
+	var pending []*domain.PendingMessage
+	for rows.Next() {
+		var (
+			msg       domain.PendingMessage
+			signedAt  *int64
+			signature *string
+		)
+		if err := rows.Scan(&msg.ID, &msg.SenderID, &msg.RecipientAddress, &msg.Content, &msg.CreatedAt,
+			&signedAt, &signature); err != nil {
+			return nil, fmt.Errorf("Scan: %w", err)
+		}
+		if signedAt != nil && signature != nil {
+			// pending messages always start a dialog, so they are signed for dialog 0
+			msg.Signature = &domain.MessageSignature{
+				RecipientAddress: msg.RecipientAddress,
+				SignedAt:         *signedAt,
+				Signature:        *signature,
+			}
+		}
+		pending = append(pending, &msg)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("Rows: %w", rows.Err())
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type PrivacyRepo struct {
+}
+
+func NewPrivacyRepo() Privacy {
+	return &PrivacyRepo{}
+}
+
+func (repo *PrivacyRepo) BlockUser(ctx context.Context, transaction Transaction, userID, blockedUserID, createdAt int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("BlockUser: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO user_blocks (user_id, blocked_user_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, userID, blockedUserID, createdAt); err != nil {
+		return fmt.Errorf("BlockUser/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *PrivacyRepo) UnblockUser(ctx context.Context, transaction Transaction, userID, blockedUserID int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UnblockUser: error: type assertion failed on interface Transaction")
+	}
+
+	query := `DELETE FROM user_blocks WHERE user_id = $1 AND blocked_user_id = $2`
+	if _, err := tx.Exec(ctx, query, userID, blockedUserID); err != nil {
+		return fmt.Errorf("UnblockUser/Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *PrivacyRepo) GetBlockedUsers(ctx context.Context, transaction Transaction, userID int64) ([]*domain.BlockedUser, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetBlockedUsers: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT b.blocked_user_id, u.address, b.created_at
+		FROM user_blocks AS b
+		JOIN users_chain AS u ON u.id = b.blocked_user_id
+		WHERE b.user_id = $1
+		ORDER BY b.created_at DESC
+	`
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockedUsers/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var blocked []*domain.BlockedUser
+	for rows.Next() {
+		var b domain.BlockedUser
+		if err := rows.Scan(&b.UserID, &b.Address, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("GetBlockedUsers/Scan: %w", err)
+		}
+		blocked = append(blocked, &b)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetBlockedUsers/Rows: %w", rows.Err())
+	}
+
+	return blocked, nil
+}
+
+// IsBlocked reports whether userID has blocked otherUserID.
+func (repo *PrivacyRepo) IsBlocked(ctx context.Context, transaction Transaction, userID, otherUserID int64) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("IsBlocked: error: type assertion failed on interface Transaction")
+	}
+
+	query := `SELECT EXISTS (SELECT 1 FROM user_blocks WHERE user_id = $1 AND blocked_user_id = $2)`
+	var blocked bool
+	if err := tx.QueryRow(ctx, query, userID, otherUserID).Scan(&blocked); err != nil {
+		return false, fmt.Errorf("IsBlocked/Scan: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// HasRepliedTo reports whether userID has sent at least one message in a dialog with otherUserID.
+func (repo *PrivacyRepo) HasRepliedTo(ctx context.Context, transaction Transaction, userID, otherUserID int64) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("HasRepliedTo: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM dialog_participants AS dp1
+			JOIN dialog_participants AS dp2 ON dp1.dialog_id = dp2.dialog_id
+			JOIN messages AS m ON m.dialog_id = dp1.dialog_id AND m.sender_id = dp1.user_id
+			WHERE dp1.user_id = $1 AND dp2.user_id = $2
+		)
+	`
+	var replied bool
+	if err := tx.QueryRow(ctx, query, userID, otherUserID).Scan(&replied); err != nil {
+		return false, fmt.Errorf("HasRepliedTo/Scan: %w", err)
+	}
+
+	return replied, nil
+}
+
+func (repo *PrivacyRepo) GetMessagePrivacy(ctx context.Context, transaction Transaction, userID int64) (domain.MessagePrivacy, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("GetMessagePrivacy: error: type assertion failed on interface Transaction")
+	}
+
+	var privacy domain.MessagePrivacy
+	if err := tx.QueryRow(ctx, `SELECT message_privacy FROM users_chain WHERE id = $1`, userID).Scan(&privacy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNoRows
+		}
+		return 0, fmt.Errorf("GetMessagePrivacy/Scan: %w", err)
+	}
+
+	return privacy, nil
+}
+
+func (repo *PrivacyRepo) UpdateMessagePrivacy(ctx context.Context, transaction Transaction, userID int64, privacy domain.MessagePrivacy) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("UpdateMessagePrivacy: error: type assertion failed on interface Transaction")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users_chain SET message_privacy = $2 WHERE id = $1`, userID, privacy); err != nil {
+		return fmt.Errorf("UpdateMessagePrivacy/Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReactionsRepo struct {
+}
+
+func NewReactionsRepo() Reactions {
+	return &ReactionsRepo{}
+}
+
+// AddReaction reports whether the reaction was added, false means the user had already reacted with this emoji.
+func (repo *ReactionsRepo) AddReaction(ctx context.Context, transaction Transaction, messageID, userID int64, emoji string, createdAt int64) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("AddReaction: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, messageID, userID, emoji, createdAt)
+	if err != nil {
+		return false, fmt.Errorf("AddReaction/Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// RemoveReaction reports whether the reaction existed.
+func (repo *ReactionsRepo) RemoveReaction(ctx context.Context, transaction Transaction, messageID, userID int64, emoji string) (bool, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return false, errors.New("RemoveReaction: error: type assertion failed on interface Transaction")
+	}
+
+	query := `DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3`
+	tag, err := tx.Exec(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return false, fmt.Errorf("RemoveReaction/Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (repo *ReactionsRepo) InsertReactionChange(ctx context.Context, transaction Transaction, change *domain.ReactionChange) (int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return 0, errors.New("InsertReactionChange: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		INSERT INTO message_reaction_events (dialog_id, message_id, user_id, emoji, added, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	row := tx.QueryRow(ctx, query, change.DialogID, change.MessageID, change.UserID, change.Emoji, change.Added, change.CreatedAt)
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("InsertReactionChange/Scan: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetReactionsByDialog aggregates reactions per message and emoji, marking the ones left by userID.
+func (repo *ReactionsRepo) GetReactionsByDialog(ctx context.Context, transaction Transaction, dialogID, userID int64) ([]*domain.ReactionSummary, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetReactionsByDialog: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT r.message_id, r.emoji, COUNT(*), BOOL_OR(r.user_id = $2)
+		FROM message_reactions AS r
+		JOIN messages AS m ON m.id = r.message_id
+		WHERE m.dialog_id = $1 AND m.deleted_at IS NULL
+		GROUP BY r.message_id, r.emoji
+		ORDER BY r.message_id, MIN(r.created_at), r.emoji
+	`
+	rows, err := tx.Query(ctx, query, dialogID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReactionsByDialog/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var reactions []*domain.ReactionSummary
+	for rows.Next() {
+		var reaction domain.ReactionSummary
+		if err := rows.Scan(&reaction.MessageID, &reaction.Emoji, &reaction.Count, &reaction.ReactedByMe); err != nil {
+			return nil, fmt.Errorf("GetReactionsByDialog/Scan: %w", err)
+		}
+		reactions = append(reactions, &reaction)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetReactionsByDialog/Rows: %w", rows.Err())
+	}
+
+	return reactions, nil
+}
+
+// GetReactionChanges returns the changes made in dialogID after the change with id after, oldest first.
+func (repo *ReactionsRepo) GetReactionChanges(ctx context.Context, transaction Transaction, dialogID, after, limit int64) ([]*domain.ReactionChange, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetReactionChanges: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT e.id, e.dialog_id, e.message_id, e.user_id, u.address, e.emoji, e.added, e.created_at
+		FROM message_reaction_events AS e
+		JOIN users_chain AS u ON u.id = e.user_id
+		WHERE e.dialog_id = $1 AND e.id > $2
+		ORDER BY e.id
+		LIMIT $3
+	`
+	rows, err := tx.Query(ctx, query, dialogID, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetReactionChanges/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*domain.ReactionChange
+	for rows.Next() {
+		var change domain.ReactionChange
+		if err := rows.Scan(&change.ID, &change.DialogID, &change.MessageID, &change.UserID, &change.UserAddress,
+			&change.Emoji, &change.Added, &change.CreatedAt); err != nil {
+			return nil, fmt.Errorf("GetReactionChanges/Scan: %w", err)
+		}
+		changes = append(changes, &change)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetReactionChanges/Rows: %w", rows.Err())
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReconcileRepo struct {
+}
+
+func NewReconcileRepo() Reconcile {
+	return &ReconcileRepo{}
+}
+
+// GetChainAddressPairs returns both directions of every dialog, and the relayer's pairs with the recipients
+// of the messages it sent. relayer is empty when it's unknown.
+func (repo *ReconcileRepo) GetChainAddressPairs(ctx context.Context, transaction Transaction, relayer string) ([]*domain.ChainAddressPair, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetChainAddressPairs: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT s.address, r.address
+		FROM dialog_participants AS dp1
+		JOIN dialog_participants AS dp2 ON dp2.dialog_id = dp1.dialog_id AND dp2.user_id != dp1.user_id
+		JOIN users_chain AS s ON s.id = dp1.user_id
+		JOIN users_chain AS r ON r.id = dp2.user_id
+		UNION
+		SELECT $1, ct.recipient_address
+		FROM message_chain_txs AS ct
+		WHERE $1 != '' AND ct.sender_address IS NULL AND ct.status = $2
+		ORDER BY 1, 2
+	`
+	rows, err := tx.Query(ctx, query, relayer, domain.ChainTxConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("GetChainAddressPairs/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []*domain.ChainAddressPair
+	for rows.Next() {
+		var p domain.ChainAddressPair
+		if err := rows.Scan(&p.Sender, &p.Receiver); err != nil {
+			return nil, fmt.Errorf("GetChainAddressPairs/Scan: %w", err)
+		}
+		pairs = append(pairs, &p)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetChainAddressPairs/Rows: %w", rows.Err())
+	}
+
+	return pairs, nil
+}
+
+// GetOnChainMessages returns the messages the contract should store under pair, oldest first. These are
+// the indexed messages and the confirmed ones of the chain worker, the relayer's own are stored under relayer.
+func (repo *ReconcileRepo) GetOnChainMessages(ctx context.Context, transaction Transaction, pair *domain.ChainAddressPair, relayer string) ([]*domain.OnChainMessage, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetOnChainMessages: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		SELECT m.id, m.dialog_id, m.content, m.deleted_at IS NOT NULL, m.tx_hash IS NOT NULL
+		FROM messages AS m
+		JOIN users_chain AS s ON s.id = m.sender_id
+		JOIN dialog_participants AS dp ON dp.dialog_id = m.dialog_id AND dp.user_id != m.sender_id
+		JOIN users_chain AS r ON r.id = dp.user_id
+		LEFT JOIN message_chain_txs AS ct ON ct.message_id = m.id
+		WHERE (m.tx_hash IS NOT NULL OR ct.status = $4)
+			AND CASE WHEN m.tx_hash IS NULL AND ct.sender_address IS NULL THEN $3 ELSE s.address END = $1
+			AND COALESCE(ct.recipient_address, r.address) = $2
+		ORDER BY m.id
+	`
+	rows, err := tx.Query(ctx, query, pair.Sender, pair.Receiver, relayer, domain.ChainTxConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("GetOnChainMessages/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.OnChainMessage
+	for rows.Next() {
+		var m domain.OnChainMessage
+		if err := rows.Scan(&m.MessageID, &m.DialogID, &m.Content, &m.Deleted, &m.Indexed); err != nil {
+			return nil, fmt.Errorf("GetOnChainMessages/Scan: %w", err)
+		}
+		messages = append(messages, &m)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetOnChainMessages/Rows: %w", rows.Err())
+	}
+
+	return messages, nil
+}
+
+// RequeueMessageChainTx resets the message's chain transaction, the chain worker sends it again.
+func (repo *ReconcileRepo) RequeueMessageChainTx(ctx context.Context, transaction Transaction, messageID, now int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("RequeueMessageChainTx: error: type assertion failed on interface Transaction")
+	}
+
+	query := `
+		UPDATE message_chain_txs SET status = $2, tx_hash = NULL, error = NULL, account_nonce = NULL, gas_tip_cap = NULL,
+			gas_fee_cap = NULL, submitted_at = NULL, replaced_tx_hashes = '{}', attempts = 0, updated_at = $3
+		WHERE message_id = $1
+	`
+	if _, err := tx.Exec(ctx, query, messageID, domain.ChainTxQueued, now); err != nil {
+		return fmt.Errorf("RequeueMessageChainTx/Exec: %w", err)
+	}
+
+	return nil
+}
@@ -20,6 +20,17 @@ type Users interface {
 	InsertAuthMessage(ctx context.Context, transaction Transaction, authMsg *domain.AuthMessage) error
 	GetAuthMessageByAddress(ctx context.Context, transaction Transaction, address string) (*domain.AuthMessage, error)
 	DeleteAuthMessage(ctx context.Context, transaction Transaction, address string) error
+
+	RecordUserChain(ctx context.Context, transaction Transaction, userID, chainID, now int64) error
+	GetUserChainIDs(ctx context.Context, transaction Transaction, userID int64) ([]int64, error)
+}
+
+type LoginSessions interface {
+	InsertLoginSession(ctx context.Context, transaction Transaction, session *domain.LoginSession) error
+	GetLoginSession(ctx context.Context, transaction Transaction, id string) (*domain.LoginSession, error)
+	VerifyLoginSession(ctx context.Context, transaction Transaction, id string, userID int64, now int64) (bool, error)
+	RedeemLoginSession(ctx context.Context, transaction Transaction, id string, pollTokenHash string, now int64) (int64, error)
+	DeleteExpiredLoginSessions(ctx context.Context, transaction Transaction, now int64) error
 }
 
 type JWTokens interface {
@@ -34,12 +45,127 @@ type Dialogs interface {
 	DialogExists(ctx context.Context, transaction Transaction, userOneID int64, userTwoID int64) (bool, error)
 	GetDialogByUsers(ctx context.Context, transaction Transaction, userOneID int64, userTwoID int64) (int64, error)
 	CreateDialogBetweenUsers(ctx context.Context, transaction Transaction, userOneID int64, userTwoID int64, dialodID int64) error
-	CreateMessageInDialog(ctx context.Context, transaction Transaction, msg *domain.Message) error
+	CreateMessageInDialog(ctx context.Context, transaction Transaction, msg *domain.Message) (int64, error)
 	CreateDialog(ctx context.Context, transaction Transaction, userOneID int64, userTwoID int64) (int64, error)
+	MarkDialogRequested(ctx context.Context, transaction Transaction, dialogID int64, requestedBy int64) error
+	GetDialogRequest(ctx context.Context, transaction Transaction, dialogID int64) (*domain.DialogRequest, error)
+	UpdateDialogStatus(ctx context.Context, transaction Transaction, dialogID int64, status domain.DialogStatus) error
+	CountMessagesBySender(ctx context.Context, transaction Transaction, dialogID int64, senderID int64) (int64, error)
+	InsertDialogReport(ctx context.Context, transaction Transaction, report *domain.DialogReport) error
+
+	GetAllDialogsByUser(ctx context.Context, transaction Transaction, userID int64, folder domain.DialogFolder, now int64) ([]*domain.DialogParticipant, error)
+	IsDialogParticipant(ctx context.Context, transaction Transaction, dialogID int64, userID int64) (bool, error)
+	UpdateLastReadMessage(ctx context.Context, transaction Transaction, dialogID int64, userID int64, messageID int64) error
+
+	GetAllMessagesWithinDialogById(ctx context.Context, transaction Transaction, dialogID int64, now int64) ([]*domain.Message, error)
+
+	GetMessageById(ctx context.Context, transaction Transaction, messageID int64) (*domain.Message, error)
+	UpdateMessageContent(ctx context.Context, transaction Transaction, messageID int64, content string, editedAt int64) error
+	MarkMessageDeleted(ctx context.Context, transaction Transaction, messageID int64, deletedAt int64) error
+	InsertMessageRevision(ctx context.Context, transaction Transaction, revision *domain.MessageRevision) error
+	InsertMessageSignature(ctx context.Context, transaction Transaction, messageID int64, signature *domain.MessageSignature) error
+	DeleteMessageSignature(ctx context.Context, transaction Transaction, messageID int64) error
+	GetDialogRetention(ctx context.Context, transaction Transaction, dialogID int64) (int64, error)
+	UpdateDialogRetention(ctx context.Context, transaction Transaction, dialogID int64, ttl int64) error
+	DeleteExpiredMessages(ctx context.Context, transaction Transaction, now, limit int64) (int64, []string, error)
+	GetMessageThread(ctx context.Context, transaction Transaction, rootID int64, limit, offset, now int64) ([]*domain.Message, int64, error)
+
+	SearchMessages(ctx context.Context, transaction Transaction, userID int64, query string, limit, offset, now int64) ([]*domain.MessageSearchResult, error)
+}
 
-	GetAllDialogsByUser(ctx context.Context, transaction Transaction, userID int64) ([]*domain.DialogParticipant, error)
+type Attachments interface {
+	InsertAttachment(ctx context.Context, transaction Transaction, attachment *domain.Attachment) (int64, error)
+	GetAttachmentById(ctx context.Context, transaction Transaction, attachmentID int64) (*domain.Attachment, error)
+	GetAttachmentsByDialog(ctx context.Context, transaction Transaction, dialogID int64) ([]*domain.Attachment, error)
+}
+
+type Reactions interface {
+	AddReaction(ctx context.Context, transaction Transaction, messageID, userID int64, emoji string, createdAt int64) (bool, error)
+	RemoveReaction(ctx context.Context, transaction Transaction, messageID, userID int64, emoji string) (bool, error)
+	InsertReactionChange(ctx context.Context, transaction Transaction, change *domain.ReactionChange) (int64, error)
+	GetReactionsByDialog(ctx context.Context, transaction Transaction, dialogID, userID int64) ([]*domain.ReactionSummary, error)
+	GetReactionChanges(ctx context.Context, transaction Transaction, dialogID, after, limit int64) ([]*domain.ReactionChange, error)
+}
+
+type Privacy interface {
+	BlockUser(ctx context.Context, transaction Transaction, userID, blockedUserID, createdAt int64) error
+	UnblockUser(ctx context.Context, transaction Transaction, userID, blockedUserID int64) error
+	GetBlockedUsers(ctx context.Context, transaction Transaction, userID int64) ([]*domain.BlockedUser, error)
+	IsBlocked(ctx context.Context, transaction Transaction, userID, otherUserID int64) (bool, error)
+	HasRepliedTo(ctx context.Context, transaction Transaction, userID, otherUserID int64) (bool, error)
+	GetMessagePrivacy(ctx context.Context, transaction Transaction, userID int64) (domain.MessagePrivacy, error)
+	UpdateMessagePrivacy(ctx context.Context, transaction Transaction, userID int64, privacy domain.MessagePrivacy) error
+}
+
+type InboxGates interface {
+	GetInboxGates(ctx context.Context, transaction Transaction, userID int64) ([]*domain.InboxGate, error)
+	ReplaceInboxGates(ctx context.Context, transaction Transaction, userID int64, gates []*domain.InboxGate) error
+	GetGatedDialogsToCheck(ctx context.Context, transaction Transaction, checkedBefore, limit int64) ([]*domain.GatedDialog, error)
+	UpdateDialogGateCheck(ctx context.Context, transaction Transaction, dialog *domain.GatedDialog, checkedAt int64) error
+}
+
+type Payments interface {
+	GetMessagePrice(ctx context.Context, transaction Transaction, userID int64) (*domain.MessagePrice, error)
+	UpdateMessagePrice(ctx context.Context, transaction Transaction, userID int64, price *domain.MessagePrice) error
+	IsPaymentTxUsed(ctx context.Context, transaction Transaction, txHash string) (bool, error)
+	InsertMessagePayment(ctx context.Context, transaction Transaction, payment *domain.MessagePayment) (bool, error)
+	QueueDialogRefunds(ctx context.Context, transaction Transaction, dialogID, payerID, now int64) (int64, error)
+	GetMessagePaymentsByStatus(ctx context.Context, transaction Transaction, status domain.PaymentStatus, afterID, limit int64) ([]*domain.MessagePayment, error)
+	UpdateMessagePayment(ctx context.Context, transaction Transaction, payment *domain.MessagePayment) error
+}
+
+type Encryption interface {
+	InsertEncryptionKey(ctx context.Context, transaction Transaction, key *domain.EncryptionKey) (int64, error)
+	RotateEncryptionKey(ctx context.Context, transaction Transaction, userID, rotatedAt int64) error
+	GetCurrentEncryptionKey(ctx context.Context, transaction Transaction, userID int64) (*domain.EncryptionKey, error)
+	GetEncryptionKeys(ctx context.Context, transaction Transaction, userID int64) ([]*domain.EncryptionKey, error)
+	InsertMessageCiphertext(ctx context.Context, transaction Transaction, ciphertext *domain.MessageCiphertext) error
+	GetCiphertextsByDialog(ctx context.Context, transaction Transaction, dialogID, recipientID int64) ([]*domain.MessageCiphertext, error)
+}
+
+type PendingMessages interface {
+	InsertPendingMessage(ctx context.Context, transaction Transaction, msg *domain.PendingMessage) (int64, error)
+	CountPendingMessages(ctx context.Context, transaction Transaction, senderID int64, recipientAddress string) (int64, error)
+	CountPendingRecipients(ctx context.Context, transaction Transaction, senderID, since int64) (int64, error)
+	GetPendingMessagesBySender(ctx context.Context, transaction Transaction, senderID int64) ([]*domain.PendingMessage, error)
+	TakePendingMessages(ctx context.Context, transaction Transaction, recipientAddress string) ([]*domain.PendingMessage, error)
+}
+
+type IdempotencyKeys interface {
+	ClaimIdempotencyKey(ctx context.Context, transaction Transaction, key *domain.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, transaction Transaction, senderID int64, key string) (*domain.IdempotencyKey, error)
+	SetIdempotencyKeyResult(ctx context.Context, transaction Transaction, key *domain.IdempotencyKey) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, transaction Transaction, senderID, before int64) error
+}
+
+type ChainTxs interface {
+	InsertMessageChainTx(ctx context.Context, transaction Transaction, chainTx *domain.MessageChainTx) error
+	GetMessageChainTxsByStatus(ctx context.Context, transaction Transaction, status domain.ChainTxStatus, afterMessageID, limit int64) ([]*domain.MessageChainTx, error)
+	UpdateMessageChainTx(ctx context.Context, transaction Transaction, chainTx *domain.MessageChainTx) error
+	CountRelayedMessages(ctx context.Context, transaction Transaction, senderAddress string, createdAfter int64) (int64, error)
+	IsRelayNonceUsed(ctx context.Context, transaction Transaction, senderAddress, nonce string) (bool, error)
+}
+
+type ChainIndexer interface {
+	LockChainCheckpoint(ctx context.Context, transaction Transaction, name string, startBlock, now int64) (int64, error)
+	UpdateChainCheckpoint(ctx context.Context, transaction Transaction, name string, nextBlock, now int64) error
+	IsChainLogIndexed(ctx context.Context, transaction Transaction, txHash string, logIndex int64) (bool, error)
+}
+
+type Reconcile interface {
+	GetChainAddressPairs(ctx context.Context, transaction Transaction, relayer string) ([]*domain.ChainAddressPair, error)
+	GetOnChainMessages(ctx context.Context, transaction Transaction, pair *domain.ChainAddressPair, relayer string) ([]*domain.OnChainMessage, error)
+	RequeueMessageChainTx(ctx context.Context, transaction Transaction, messageID, now int64) error
+}
 
-	GetAllMessagesWithinDialogById(ctx context.Context, transaction Transaction, dialogID int64) ([]*domain.Message, error)
+type Anchors interface {
+	GetMessagesToAnchor(ctx context.Context, transaction Transaction, createdBefore, limit int64) ([]*domain.Message, error)
+	InsertAnchorBatch(ctx context.Context, transaction Transaction, batch *domain.AnchorBatch, leaves []*domain.AnchorLeaf) (int64, error)
+	GetAnchorBatchesByStatus(ctx context.Context, transaction Transaction, status domain.ChainTxStatus, afterID, limit int64) ([]*domain.AnchorBatch, error)
+	GetAnchorBatch(ctx context.Context, transaction Transaction, batchID int64) (*domain.AnchorBatch, error)
+	UpdateAnchorBatch(ctx context.Context, transaction Transaction, batch *domain.AnchorBatch) error
+	GetAnchorLeaf(ctx context.Context, transaction Transaction, messageID int64) (*domain.AnchorLeaf, error)
+	GetAnchorBatchHashes(ctx context.Context, transaction Transaction, batchID int64) ([]string, error)
 }
 
 type Transaction interface {
@@ -52,17 +178,43 @@ type Transactions interface {
 
 type Repository struct {
 	Users
+	LoginSessions
 	JWTokens
 	Dialogs
+	Attachments
+	Reactions
+	Privacy
+	InboxGates
+	Payments
+	Encryption
+	PendingMessages
+	IdempotencyKeys
+	ChainTxs
+	ChainIndexer
+	Reconcile
+	Anchors
 
 	Transactions
 }
 
 func NewRepository(cfg *config.Config, pool *pgxpool.Pool) (*Repository, error) {
 	return &Repository{
-		Users:        NewUsersRepo(),
-		Dialogs:      NewDialogsRepo(),
-		JWTokens:     NewJWTokensRepo(),
-		Transactions: NewTransactionsRepo(pool),
+		Users:           NewUsersRepo(),
+		LoginSessions:   NewLoginSessionsRepo(),
+		Dialogs:         NewDialogsRepo(cfg.Service.SearchLanguage),
+		JWTokens:        NewJWTokensRepo(),
+		Attachments:     NewAttachmentsRepo(),
+		Reactions:       NewReactionsRepo(),
+		Privacy:         NewPrivacyRepo(),
+		InboxGates:      NewInboxGatesRepo(),
+		Payments:        NewPaymentsRepo(),
+		Encryption:      NewEncryptionRepo(),
+		PendingMessages: NewPendingMessagesRepo(),
+		IdempotencyKeys: NewIdempotencyKeysRepo(),
+		ChainTxs:        NewChainTxsRepo(),
+		ChainIndexer:    NewChainIndexerRepo(),
+		Reconcile:       NewReconcileRepo(),
+		Anchors:         NewAnchorsRepo(),
+		Transactions:    NewTransactionsRepo(pool),
 	}, nil
 }
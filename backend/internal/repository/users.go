@@ -99,9 +99,9 @@ func (r *UsersRepo) GetAuthMessageByAddress(
 	if !ok {
 		return nil, errors.New("GetAuthMessageByAddress: error: type assertion failed on interface Transaction")
 	}
-	row := tx.QueryRow(ctx, `SELECT address, created_at, code FROM auth_messages_chain WHERE address = $1`, strings.ToLower(address))
+	row := tx.QueryRow(ctx, `SELECT address, created_at, code, chain_id FROM auth_messages_chain WHERE address = $1`, strings.ToLower(address))
 	res := &domain.AuthMessage{}
-	if err := row.Scan(&res.Address, &res.CreatedAt, &res.Message); err != nil {
+	if err := row.Scan(&res.Address, &res.CreatedAt, &res.Message, &res.ChainID); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNoRows
 		}
@@ -119,8 +119,8 @@ func (r *UsersRepo) InsertAuthMessage(
 	if !ok {
 		return errors.New("InsertAuthMessage: error: type assertion failed on interface Transaction")
 	}
-	if _, err := tx.Exec(ctx, `INSERT INTO auth_messages_chain (address, code, created_at) VALUES ($1,$2,$3)`,
-		strings.ToLower(msg.Address), msg.Message, msg.CreatedAt); err != nil {
+	if _, err := tx.Exec(ctx, `INSERT INTO auth_messages_chain (address, code, created_at, chain_id) VALUES ($1,$2,$3,$4)`,
+		strings.ToLower(msg.Address), msg.Message, msg.CreatedAt, msg.ChainID); err != nil {
 		return fmt.Errorf("InsertAuthMessage/Exec: %w", err)
 	}
 	return nil
@@ -140,3 +140,48 @@ func (r *UsersRepo) DeleteAuthMessage(
 	}
 	return nil
 }
+
+// RecordUserChain stores that the user signed in from chainID.
+func (r *UsersRepo) RecordUserChain(ctx context.Context, transaction Transaction, userID, chainID, now int64) error {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return errors.New("RecordUserChain: error: type assertion failed on interface Transaction")
+	}
+	query := `
+		INSERT INTO user_chains (user_id, chain_id, first_signed_in_at, last_signed_in_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id, chain_id) DO UPDATE SET last_signed_in_at = EXCLUDED.last_signed_in_at
+	`
+	if _, err := tx.Exec(ctx, query, userID, chainID, now); err != nil {
+		return fmt.Errorf("RecordUserChain/Exec: %w", err)
+	}
+	return nil
+}
+
+// GetUserChainIDs returns the chains the user signed in from, the most recent first.
+func (r *UsersRepo) GetUserChainIDs(ctx context.Context, transaction Transaction, userID int64) ([]int64, error) {
+	tx, ok := transaction.(pgx.Tx)
+	if !ok {
+		return nil, errors.New("GetUserChainIDs: error: type assertion failed on interface Transaction")
+	}
+	rows, err := tx.Query(ctx, `SELECT chain_id FROM user_chains WHERE user_id = $1 ORDER BY last_signed_in_at DESC, chain_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserChainIDs/Query: %w", err)
+	}
+	defer rows.Close()
+
+	var chainIDs []int64
+	for rows.Next() {
+		var chainID int64
+		if err := rows.Scan(&chainID); err != nil {
+			return nil, fmt.Errorf("GetUserChainIDs/Scan: %w", err)
+		}
+		chainIDs = append(chainIDs, chainID)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("GetUserChainIDs/Rows: %w", rows.Err())
+	}
+
+	return chainIDs, nil
+}
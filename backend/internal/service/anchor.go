@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/merkle"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AnchorWorker batches settled messages, builds a Merkle tree over their hashes and commits the root
+// to the MessageAnchor contract. Messages are hashed once they're past cfg.SettleDelay, later edits
+// and deletions don't change what was anchored.
+type AnchorWorker struct {
+	cfg              *config.AnchorConfig
+	gas              *config.GasConfig
+	anchorer         chain.Anchorer
+	repoAnchors      repository.Anchors
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewAnchorWorker(
+	cfg *config.AnchorConfig,
+	gas *config.GasConfig,
+	anchorer chain.Anchorer,
+	repoAnchors repository.Anchors,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) *AnchorWorker {
+
+	return &AnchorWorker{
+		cfg:              cfg,
+		gas:              gas,
+		anchorer:         anchorer,
+		repoAnchors:      repoAnchors,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+func (w *AnchorWorker) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := w.createBatch(); err != nil {
+				w.logging.Errorf("AnchorWorker/createBatch: %v", err)
+			}
+			if err := w.submitQueued(); err != nil {
+				w.logging.Errorf("AnchorWorker/submitQueued: %v", err)
+			}
+			if err := w.checkSubmitted(); err != nil {
+				w.logging.Errorf("AnchorWorker/checkSubmitted: %v", err)
+			}
+		}
+	}
+}
+
+// createBatch queues a batch of the settled messages that follow the last batch.
+func (w *AnchorWorker) createBatch() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.WorkerInterval)
+	defer cancel()
+
+	tx, err := w.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("createBatch/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	createdAt := now.Now()
+	messages, err := w.repoAnchors.GetMessagesToAnchor(ctx, tx, createdAt.Add(-w.cfg.SettleDelay).UnixMilli(), w.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("createBatch/GetMessagesToAnchor: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	var (
+		leaves = make([]*domain.AnchorLeaf, 0, len(messages))
+		hashes = make([]common.Hash, 0, len(messages))
+	)
+	for i, m := range messages {
+		hash, err := domain.AnchoredMessageHash(m)
+		if err != nil {
+			return fmt.Errorf("createBatch/AnchoredMessageHash: %w", err)
+		}
+		leaves = append(leaves, &domain.AnchorLeaf{
+			MessageID:   m.ID,
+			LeafIndex:   int64(i),
+			MessageHash: hash.Hex(),
+		})
+		hashes = append(hashes, domain.AnchorLeafHash(hash))
+	}
+
+	batch := &domain.AnchorBatch{
+		Root:           merkle.New(hashes).Root().Hex(),
+		FirstMessageID: messages[0].ID,
+		LastMessageID:  messages[len(messages)-1].ID,
+		Size:           int64(len(messages)),
+		Status:         domain.ChainTxQueued,
+		CreatedAt:      createdAt.UnixMilli(),
+	}
+	if _, err := w.repoAnchors.InsertAnchorBatch(ctx, tx, batch, leaves); err != nil {
+		return fmt.Errorf("createBatch/InsertAnchorBatch: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("createBatch/Commit: %w", err)
+	}
+
+	return nil
+}
+
+// submitQueued sends the anchor transactions of queued batches. A batch whose transaction
+// can't be sent stays queued and is retried on the next tick.
+func (w *AnchorWorker) submitQueued() error {
+	return w.eachBatch(domain.ChainTxQueued, w.submit)
+}
+
+// checkSubmitted follows the receipts of the submitted anchors and replaces the stuck ones.
+func (w *AnchorWorker) checkSubmitted() error {
+	return w.eachBatch(domain.ChainTxSubmitted, w.check)
+}
+
+// eachBatch handles up to cfg.BatchSize batches in status, each in a database transaction of its own,
+// so the hash of a broadcast anchor transaction is committed before the next batch is sent. handle
+// returns false when the batch is left unchanged.
+func (w *AnchorWorker) eachBatch(status domain.ChainTxStatus, handle func(context.Context, *domain.AnchorBatch) bool) error {
+	var afterID int64
+	for i := int64(0); i < w.cfg.BatchSize; i++ {
+		b, err := w.handleNext(status, afterID, handle)
+		if err != nil {
+			return fmt.Errorf("eachBatch/handleNext: %w", err)
+		}
+		if b == nil {
+			return nil
+		}
+		afterID = b.ID
+	}
+	return nil
+}
+
+func (w *AnchorWorker) handleNext(status domain.ChainTxStatus, afterID int64, handle func(context.Context, *domain.AnchorBatch) bool) (*domain.AnchorBatch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.WorkerInterval)
+	defer cancel()
+
+	tx, err := w.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("handleNext/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batches, err := w.repoAnchors.GetAnchorBatchesByStatus(ctx, tx, status, afterID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("handleNext/GetAnchorBatchesByStatus: %w", err)
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+	b := batches[0]
+	if !handle(ctx, b) {
+		return b, nil
+	}
+
+	// the row lock is held until the outcome of the broadcast is stored, even past the RPC deadline
+	storeCtx := context.WithoutCancel(ctx)
+	b.UpdatedAt = now.Now().UnixMilli()
+	if err := w.repoAnchors.UpdateAnchorBatch(storeCtx, tx, b); err != nil {
+		return nil, fmt.Errorf("handleNext/UpdateAnchorBatch: batch %d: %w", b.ID, err)
+	}
+	if err := tx.Commit(storeCtx); err != nil {
+		return nil, fmt.Errorf("handleNext/Commit: batch %d: %w", b.ID, err)
+	}
+
+	return b, nil
+}
+
+func (w *AnchorWorker) submit(ctx context.Context, b *domain.AnchorBatch) bool {
+	sent, err := w.anchorer.Anchor(ctx, b.ID, common.HexToHash(b.Root), nil)
+	if err != nil {
+		w.logging.Errorf("AnchorWorker/submit: batch %d: %v", b.ID, err)
+		// an anchor whose hash wasn't stored may have gone through, the contract then rejects this one
+		return w.anchoredBefore(ctx, b)
+	}
+	b.Status, b.Error = domain.ChainTxSubmitted, nil
+	b.TxHash, b.Sent = newSentChainTx(sent)
+	return true
+}
+
+// check follows the receipts of the batch's anchor and the ones it replaced. An anchor unmined for
+// gas.ReplaceAfter is replaced with the same nonce and higher fees, it holds up every later relayer
+// transaction.
+func (w *AnchorWorker) check(ctx context.Context, b *domain.AnchorBatch) bool {
+	status, block, minedHash, err := w.status(ctx, b)
+	if err != nil {
+		w.logging.Errorf("AnchorWorker/check: batch %d: %v", b.ID, err)
+		return false
+	}
+	switch status {
+	case chain.TxConfirmed:
+		blockNumber := int64(block)
+		b.Status, b.BlockNumber = domain.ChainTxConfirmed, &blockNumber
+		b.TxHash, b.ReplacedTxHashes = minedTx(*b.TxHash, b.ReplacedTxHashes, minedHash)
+	case chain.TxFailed:
+		b.TxHash, b.ReplacedTxHashes = minedTx(*b.TxHash, b.ReplacedTxHashes, minedHash)
+		return w.reverted(ctx, b)
+	default:
+		if !stuckTx(b.Sent, w.gas) {
+			return false
+		}
+		replacement, err := w.anchorer.Anchor(ctx, b.ID, common.HexToHash(b.Root), toSentTx(*b.TxHash, b.Sent))
+		if err != nil {
+			// the stuck anchor may have been mined meanwhile, its receipt shows up next time
+			w.logging.Errorf("AnchorWorker/check: replace %s: %v", *b.TxHash, err)
+			return false
+		}
+		b.ReplacedTxHashes = append(b.ReplacedTxHashes, *b.TxHash)
+		b.TxHash, b.Sent = newSentChainTx(replacement)
+	}
+	return true
+}
+
+// status checks the current anchor and the ones it replaced, whichever got mined decides.
+func (w *AnchorWorker) status(ctx context.Context, b *domain.AnchorBatch) (chain.TxStatus, uint64, string, error) {
+	for _, hash := range append([]string{*b.TxHash}, b.ReplacedTxHashes...) {
+		status, block, err := w.anchorer.TxReceipt(ctx, common.HexToHash(hash))
+		if err != nil {
+			return chain.TxPending, 0, "", err
+		}
+		if status != chain.TxPending {
+			return status, block, hash, nil
+		}
+	}
+	return chain.TxPending, 0, "", nil
+}
+
+// anchoredBefore confirms a batch the contract already holds the root of.
+func (w *AnchorWorker) anchoredBefore(ctx context.Context, b *domain.AnchorBatch) bool {
+	root, block, err := w.anchorer.Anchored(ctx, b.ID)
+	if err != nil || root != common.HexToHash(b.Root) {
+		return false
+	}
+	blockNumber := int64(block)
+	b.Status, b.BlockNumber, b.Error = domain.ChainTxConfirmed, &blockNumber, nil
+	return true
+}
+
+// reverted settles a batch whose anchor transaction reverted by what the contract holds for it. A batch
+// that isn't anchored is queued again, one the contract holds another root for can never be anchored
+// and fails, its messages have no proof.
+func (w *AnchorWorker) reverted(ctx context.Context, b *domain.AnchorBatch) bool {
+	root, block, err := w.anchorer.Anchored(ctx, b.ID)
+	if err != nil {
+		w.logging.Errorf("AnchorWorker/reverted: batch %d: %v", b.ID, err)
+		return false
+	}
+	switch root {
+	case common.HexToHash(b.Root):
+		blockNumber := int64(block)
+		b.Status, b.BlockNumber = domain.ChainTxConfirmed, &blockNumber
+	case common.Hash{}:
+		reason := "transaction reverted"
+		b.Status, b.Error = domain.ChainTxQueued, &reason
+		b.TxHash, b.Sent, b.ReplacedTxHashes = nil, nil, nil
+	default:
+		reason := fmt.Sprintf("batch is anchored with root %s", root.Hex())
+		b.Status, b.Error = domain.ChainTxFailed, &reason
+	}
+	return true
+}
+
+type AnchoringService struct {
+	cfg              *config.ServiceConfig
+	repoDialogs      repository.Dialogs
+	repoAnchors      repository.Anchors
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewAnchoringService(
+	cfg *config.ServiceConfig,
+	repoDialogs repository.Dialogs,
+	repoAnchors repository.Anchors,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) Anchoring {
+
+	return &AnchoringService{
+		cfg:              cfg,
+		repoDialogs:      repoDialogs,
+		repoAnchors:      repoAnchors,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+// GetMessageProof returns the inclusion proof of the message once its batch is confirmed on-chain. The tree
+// is rebuilt from the stored message hashes, so the proof outlives edits and deletions of the batch's messages.
+func (a *AnchoringService) GetMessageProof(ctx context.Context, messageID, userID int64) (*models.MessageProof, error) {
+	tx, err := a.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageProof/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	msg, err := a.repoDialogs.GetMessageById(ctx, tx, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("GetMessageProof/GetMessageById: %w", err), MessageNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageProof/GetMessageById: %w", err), InternalError, "")
+	}
+	if msg.Expired(now.Now().UnixMilli()) {
+		return nil, newServiceError(code404, fmt.Errorf("GetMessageProof: %s", MessageNotExist), MessageNotExist, "")
+	}
+
+	isParticipant, err := a.repoDialogs.IsDialogParticipant(ctx, tx, msg.DialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageProof/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("GetMessageProof: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	leaf, err := a.repoAnchors.GetAnchorLeaf(ctx, tx, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("GetMessageProof/GetAnchorLeaf: %w", err), MessageNotAnchored, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageProof/GetAnchorLeaf: %w", err), InternalError, "")
+	}
+
+	batch, err := a.repoAnchors.GetAnchorBatch(ctx, tx, leaf.BatchID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageProof/GetAnchorBatch: %w", err), InternalError, "")
+	}
+	// a proof is only of use once its root can be read from the contract
+	if batch.Status != domain.ChainTxConfirmed {
+		return nil, newServiceError(code404, fmt.Errorf("GetMessageProof: batch %d is %s", batch.ID, batch.Status), MessageNotAnchored, "")
+	}
+
+	messageHashes, err := a.repoAnchors.GetAnchorBatchHashes(ctx, tx, leaf.BatchID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageProof/GetAnchorBatchHashes: %w", err), InternalError, "")
+	}
+	leaves := make([]common.Hash, 0, len(messageHashes))
+	for _, h := range messageHashes {
+		leaves = append(leaves, domain.AnchorLeafHash(common.HexToHash(h)))
+	}
+	tree := merkle.New(leaves)
+	if tree.Root().Hex() != batch.Root {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageProof: batch %d root mismatch", batch.ID), InternalError, "")
+	}
+
+	return domain.MessageProofToResponse(leaf, batch, tree.Proof(int(leaf.LeafIndex)), a.cfg.Anchor.ContractAddress,
+		a.cfg.Chain.ChainID), nil
+}
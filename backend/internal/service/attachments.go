@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/blobstore"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+const attachmentURLFormat = "/g1/attachments/%d?expires=%d&signature=%s"
+
+type AttachmentsService struct {
+	cfg              *config.ServiceConfig
+	repoDialogs      repository.Dialogs
+	repoAttachments  repository.Attachments
+	repoTransactions repository.Transactions
+	blobStore        blobstore.BlobStore
+
+	logging logger.Logger
+}
+
+func NewAttachmentsService(
+	cfg *config.ServiceConfig,
+	repoDialogs repository.Dialogs,
+	repoAttachments repository.Attachments,
+	repoTransactions repository.Transactions,
+	blobStore blobstore.BlobStore,
+
+	logging logger.Logger) Attachments {
+
+	return &AttachmentsService{
+		cfg:              cfg,
+		repoDialogs:      repoDialogs,
+		repoAttachments:  repoAttachments,
+		repoTransactions: repoTransactions,
+		blobStore:        blobStore,
+
+		logging: logging,
+	}
+}
+
+// GetAttachmentURL issues a short-lived download link for a participant of the attachment's dialog.
+func (a *AttachmentsService) GetAttachmentURL(ctx context.Context, dialogID, attachmentID, userID int64) (*models.AttachmentURLResponse, error) {
+	tx, err := a.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetAttachmentURL/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	isParticipant, err := a.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetAttachmentURL/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("GetAttachmentURL: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	attachment, err := a.getActiveAttachment(ctx, tx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.DialogID != dialogID {
+		return nil, newServiceError(code404, fmt.Errorf("GetAttachmentURL: %s", AttachmentNotExist), AttachmentNotExist, "")
+	}
+
+	expiresAt := now.Now().Add(a.cfg.Attachments.URLTTL)
+	signature := signAttachmentURL(a.cfg.Attachments.SigningKey, attachment.ID, expiresAt.Unix())
+
+	return &models.AttachmentURLResponse{
+		URL:       fmt.Sprintf(attachmentURLFormat, attachment.ID, expiresAt.Unix(), signature),
+		ExpiresAt: expiresAt.UnixMilli(),
+	}, nil
+}
+
+// OpenAttachment checks a link issued by GetAttachmentURL and opens the stored blob.
+func (a *AttachmentsService) OpenAttachment(ctx context.Context, attachmentID, expires int64, signature string) (*domain.Attachment, io.ReadCloser, error) {
+	expected := signAttachmentURL(a.cfg.Attachments.SigningKey, attachmentID, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) || now.Now().Unix() >= expires {
+		return nil, nil, newServiceError(code403, fmt.Errorf("OpenAttachment: %s", AttachmentLinkInvalid), AttachmentLinkInvalid, "")
+	}
+
+	tx, err := a.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, nil, newServiceError(code500, fmt.Errorf("OpenAttachment/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	attachment, err := a.getActiveAttachment(ctx, tx, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := a.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, nil, newServiceError(code404, fmt.Errorf("OpenAttachment/Get: %w", err), AttachmentNotExist, "")
+		}
+		return nil, nil, newServiceError(code500, fmt.Errorf("OpenAttachment/Get: %w", err), InternalError, "")
+	}
+
+	return attachment, content, nil
+}
+
+// getActiveAttachment hides attachments of deleted messages and of expired ones that aren't purged yet.
+func (a *AttachmentsService) getActiveAttachment(ctx context.Context, tx repository.Transaction, attachmentID int64) (*domain.Attachment, error) {
+	attachment, err := a.repoAttachments.GetAttachmentById(ctx, tx, attachmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("getActiveAttachment/GetAttachmentById: %w", err), AttachmentNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("getActiveAttachment/GetAttachmentById: %w", err), InternalError, "")
+	}
+
+	msg, err := a.repoDialogs.GetMessageById(ctx, tx, attachment.MessageID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("getActiveAttachment/GetMessageById: %w", err), InternalError, "")
+	}
+	if msg.DeletedAt != nil || msg.Expired(now.Now().UnixMilli()) {
+		return nil, newServiceError(code404, fmt.Errorf("getActiveAttachment: %s", AttachmentNotExist), AttachmentNotExist, "")
+	}
+
+	return attachment, nil
+}
+
+func signAttachmentURL(key string, attachmentID, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strconv.FormatInt(attachmentID, 10) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkUploads enforces the configured limits and sniffs the MIME type of every upload.
+// The type declared by the client is ignored.
+func (d *DialogsService) checkUploads(uploads []*domain.AttachmentUpload) ([]string, error) {
+	cfg := d.cfg.Attachments
+	if len(uploads) > cfg.MaxCount {
+		return nil, newServiceError(code400, fmt.Errorf("checkUploads: %s", TooManyAttachments), TooManyAttachments,
+			fmt.Sprintf("at most %d attachments are allowed", cfg.MaxCount))
+	}
+
+	mimeTypes := make([]string, 0, len(uploads))
+	for _, u := range uploads {
+		if u.Size > cfg.MaxSize {
+			return nil, newServiceError(code400, fmt.Errorf("checkUploads: %s", AttachmentTooLarge), AttachmentTooLarge,
+				fmt.Sprintf("%s exceeds %d bytes", u.FileName, cfg.MaxSize))
+		}
+
+		head := make([]byte, 512)
+		n, err := io.ReadFull(u.Content, head)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return nil, newServiceError(code500, fmt.Errorf("checkUploads/ReadFull: %w", err), InternalError, "")
+		}
+		if _, err := u.Content.Seek(0, io.SeekStart); err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("checkUploads/Seek: %w", err), InternalError, "")
+		}
+
+		mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+		if err != nil || !mimeTypeAllowed(cfg.AllowedMimeTypes, mimeType) {
+			return nil, newServiceError(code400, fmt.Errorf("checkUploads: %s", AttachmentTypeNotAllowed), AttachmentTypeNotAllowed,
+				fmt.Sprintf("%s has unsupported type %s", u.FileName, mimeType))
+		}
+		mimeTypes = append(mimeTypes, mimeType)
+	}
+
+	return mimeTypes, nil
+}
+
+func mimeTypeAllowed(allowed []string, mimeType string) bool {
+	for _, a := range allowed {
+		if a == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// storeAttachments uploads blobs and links them to msg. It returns the keys written so far,
+// so the caller can clean them up if the transaction doesn't commit.
+func (d *DialogsService) storeAttachments(
+	ctx context.Context,
+	tx repository.Transaction,
+	msg *domain.Message,
+	uploads []*domain.AttachmentUpload,
+	mimeTypes []string,
+) ([]string, error) {
+	var keys []string
+	for i, u := range uploads {
+		key, err := newStorageKey(msg.DialogID)
+		if err != nil {
+			return keys, fmt.Errorf("storeAttachments/newStorageKey: %w", err)
+		}
+		if err := d.blobStore.Put(ctx, key, u.Content, u.Size, mimeTypes[i]); err != nil {
+			return keys, fmt.Errorf("storeAttachments/Put: %w", err)
+		}
+		keys = append(keys, key)
+
+		_, err = d.repoAttachments.InsertAttachment(ctx, tx, &domain.Attachment{
+			MessageID:  msg.ID,
+			StorageKey: key,
+			FileName:   u.FileName,
+			MimeType:   mimeTypes[i],
+			Size:       u.Size,
+			CreatedAt:  msg.CreatedAt,
+		})
+		if err != nil {
+			return keys, fmt.Errorf("storeAttachments/InsertAttachment: %w", err)
+		}
+	}
+
+	return keys, nil
+}
+
+func (d *DialogsService) dropBlobs(keys []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := d.blobStore.Delete(ctx, key); err != nil {
+			d.logging.Errorf("dropBlobs: key %s: %v", key, err)
+		}
+	}
+}
+
+func newStorageKey(dialogID int64) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("dialogs/%d/%s", dialogID, hex.EncodeToString(b)), nil
+}
@@ -24,38 +24,47 @@ import (
 )
 
 type AuthService struct {
-	cfg              *config.ServiceConfig
-	repoUsers        repository.Users
-	repoJWTokens     repository.JWTokens
-	repoTransactions repository.Transactions
-	jwtManager       jwtoken.JWTokenManager
-	hashManager      hash.HashManager
-	logging          logger.Logger
+	cfg               *config.ServiceConfig
+	repoUsers         repository.Users
+	repoLoginSessions repository.LoginSessions
+	repoJWTokens      repository.JWTokens
+	repoDialogs       repository.Dialogs
+	repoPending       repository.PendingMessages
+	repoTransactions  repository.Transactions
+	jwtManager        jwtoken.JWTokenManager
+	hashManager       hash.HashManager
+	logging           logger.Logger
 }
 
 func NewAuthService(
 	cfg *config.ServiceConfig,
 	repoUsers repository.Users,
+	repoLoginSessions repository.LoginSessions,
 	repoJWTokens repository.JWTokens,
+	repoDialogs repository.Dialogs,
+	repoPending repository.PendingMessages,
 	repoTransactions repository.Transactions,
 	jwtManager jwtoken.JWTokenManager,
 	hashManager hash.HashManager,
 	logging logger.Logger) Auth {
 
 	return &AuthService{
-		cfg:              cfg,
-		repoUsers:        repoUsers,
-		repoJWTokens:     repoJWTokens,
-		repoTransactions: repoTransactions,
-		jwtManager:       jwtManager,
-		hashManager:      hashManager,
-		logging:          logging,
+		cfg:               cfg,
+		repoUsers:         repoUsers,
+		repoLoginSessions: repoLoginSessions,
+		repoJWTokens:      repoJWTokens,
+		repoDialogs:       repoDialogs,
+		repoPending:       repoPending,
+		repoTransactions:  repoTransactions,
+		jwtManager:        jwtManager,
+		hashManager:       hashManager,
+		logging:           logging,
 	}
 }
 
 const (
 	alphabet    = "abcdefghijklmnopqrstuvwxyz1234567890"
-	authMessage = "Hello, %s! Please, sign this message with random param %s to sign in!"
+	authMessage = "Hello, %s! Please, sign this message with random param %s to sign in on chain %d!"
 )
 
 func (s *AuthService) GetUserById(
@@ -219,12 +228,17 @@ func (s *AuthService) GetAuthMessage(
 	}
 	defer tx.Rollback(context.Background())
 
+	chainID, err := s.signInChain(req.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
 	msg, err := s.repoUsers.GetAuthMessageByAddress(ctx, tx, *req.Address)
 	if err != nil && !errors.Is(err, repository.ErrNoRows) {
 		return nil, newServiceError(code500,
 			fmt.Errorf("GetAuthMessage/GetAuthMessageByAddress: %w", err), InternalError, "")
 	}
-	if msg != nil {
+	if msg != nil && msg.ChainID == chainID {
 		if now.Now().Sub(time.UnixMilli(msg.CreatedAt)) < 5*time.Minute {
 			return &models.AuthMessageResponse{
 				Message: &msg.Message,
@@ -235,11 +249,12 @@ func (s *AuthService) GetAuthMessage(
 		return nil, newServiceError(code500,
 			fmt.Errorf("GetAuthMessage/DeleteAuthMessage: %w", err), InternalError, "")
 	}
-	message := fmt.Sprintf(authMessage, strings.ToLower(*req.Address), randomString(64))
+	message := fmt.Sprintf(authMessage, strings.ToLower(*req.Address), randomString(64), chainID)
 	if err := s.repoUsers.InsertAuthMessage(ctx, tx, &domain.AuthMessage{
 		Address:   strings.ToLower(*req.Address),
 		Message:   message,
 		CreatedAt: now.Now().UnixMilli(),
+		ChainID:   chainID,
 	}); err != nil {
 		return nil, newServiceError(code500,
 			fmt.Errorf("GetAuthMessage/InsertAuthMessage: %w", err), InternalError, "")
@@ -279,46 +294,24 @@ func (s *AuthService) AuthByMessage(
 			fmt.Errorf("AuthByMessage: %s", AuthMessageExpired), AuthMessageExpired, "")
 	}
 
-	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(msg.Message), msg.Message)))
-	sig := common.FromHex(*req.Signature)
-	if len(sig) > 0 && sig[len(sig)-1] > 4 {
-		sig[len(sig)-1] -= 27
-	}
-	pubKey, err := crypto.Ecrecover(hash, sig)
-	if err != nil {
-		return nil, nil, nil, newServiceError(code401,
-			fmt.Errorf("AuthByMessage: %s", EcrecoverFailed), EcrecoverFailed, "")
-	}
-	pkey, err := crypto.UnmarshalPubkey(pubKey)
-	if err != nil {
-		return nil, nil, nil, newServiceError(code401,
-			fmt.Errorf("AuthByMessage/UnmarshalPubkey: %w", err), EcrecoverFailed, "")
-	}
-	signedAddress := crypto.PubkeyToAddress(*pkey)
-	if !strings.EqualFold(signedAddress.Hex(), *req.Address) {
-		return nil, nil, nil, newServiceError(code401,
-			fmt.Errorf("AuthByMessage: %s", WrongSignature), WrongSignature, "")
+	if err := verifySignature(msg.Message, *req.Signature, *req.Address); err != nil {
+		return nil, nil, nil, err
 	}
 
-	user, err := s.repoUsers.GetUserByAddress(ctx, tx, strings.ToLower(*req.Address))
+	user, err := s.getOrCreateUser(ctx, tx, *req.Address)
 	if err != nil {
-		if errors.Is(err, repository.ErrNoRows) {
-			user, err = createUser(ctx, tx, s.repoUsers, strings.ToLower(*req.Address), 0)
-			if err != nil {
-				return nil, nil, nil, newServiceError(code400,
-					fmt.Errorf("AuthByMessage/createUser: %w", err), InternalError, "")
-			}
-		} else {
-			return nil, nil, nil, newServiceError(code400,
-				fmt.Errorf("AuthByMessage/GetUserByAddress: %w", err), InternalError, "")
-		}
-
+		return nil, nil, nil, err
+	}
+	if err := s.repoUsers.RecordUserChain(ctx, tx, user.ID, msg.ChainID, now.Now().UnixMilli()); err != nil {
+		return nil, nil, nil, newServiceError(code500,
+			fmt.Errorf("AuthByMessage/RecordUserChain: %w", err), InternalError, "")
 	}
 
 	resp, err := s.getAuthRespWithUserById(ctx, tx, user.Role, user.ID)
 	if err != nil {
 		return nil, nil, nil, newServiceError(code500, fmt.Errorf("AuthByMessage/getAuthRespWithUserById: %w", err), InternalError, "")
 	}
+	resp.ChainID = msg.ChainID
 
 	accessToken, refreshToken, err := s.generateJWTokens(ctx, tx, user.ID, user.Role)
 	if err != nil {
@@ -333,6 +326,79 @@ func (s *AuthService) AuthByMessage(
 	return resp, accessToken, refreshToken, nil
 }
 
+// GetSupportedChains lists the chains users can sign in from, the Messaging contract is on the primary one.
+func (s *AuthService) GetSupportedChains(ctx context.Context) models.SupportedChainsResponse {
+	chains := make(models.SupportedChainsResponse, 0, len(s.cfg.Chains))
+	for _, c := range s.cfg.Chains {
+		chains = append(chains, &models.SupportedChain{
+			ChainID: c.ChainID,
+			Name:    c.Name,
+			Primary: c.ChainID == s.cfg.Chain.ChainID,
+		})
+	}
+	return chains
+}
+
+// signInChain defaults to the primary chain, a user is the same whichever chain they sign in from.
+func (s *AuthService) signInChain(chainID int64) (int64, error) {
+	if chainID == 0 {
+		chainID = s.cfg.Chain.ChainID
+	}
+	if s.cfg.SupportedChain(chainID) == nil {
+		return 0, newServiceError(code400,
+			fmt.Errorf("signInChain: %s: %d", ChainNotSupported, chainID), ChainNotSupported, "")
+	}
+	return chainID, nil
+}
+
+// verifySignature checks that signature is an EIP-191 personal_sign of message made by address.
+func verifySignature(message, signature, address string) error {
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	sig := common.FromHex(signature)
+	if len(sig) > 0 && sig[len(sig)-1] > 4 {
+		sig[len(sig)-1] -= 27
+	}
+	pubKey, err := crypto.Ecrecover(hash, sig)
+	if err != nil {
+		return newServiceError(code401,
+			fmt.Errorf("verifySignature: %s", EcrecoverFailed), EcrecoverFailed, "")
+	}
+	pkey, err := crypto.UnmarshalPubkey(pubKey)
+	if err != nil {
+		return newServiceError(code401,
+			fmt.Errorf("verifySignature/UnmarshalPubkey: %w", err), EcrecoverFailed, "")
+	}
+	signedAddress := crypto.PubkeyToAddress(*pkey)
+	if !strings.EqualFold(signedAddress.Hex(), address) {
+		return newServiceError(code401,
+			fmt.Errorf("verifySignature: %s", WrongSignature), WrongSignature, "")
+	}
+
+	return nil
+}
+
+func (s *AuthService) getOrCreateUser(ctx context.Context, tx repository.Transaction, address string) (*domain.UserChain, error) {
+	user, err := s.repoUsers.GetUserByAddress(ctx, tx, strings.ToLower(address))
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			user, err = createUser(ctx, tx, s.repoUsers, strings.ToLower(address), 0)
+			if err != nil {
+				return nil, newServiceError(code400,
+					fmt.Errorf("getOrCreateUser/createUser: %w", err), InternalError, "")
+			}
+			if err := deliverPendingMessages(ctx, tx, s.repoDialogs, s.repoPending, user); err != nil {
+				return nil, newServiceError(code500,
+					fmt.Errorf("getOrCreateUser/deliverPendingMessages: %w", err), InternalError, "")
+			}
+		} else {
+			return nil, newServiceError(code400,
+				fmt.Errorf("getOrCreateUser/GetUserByAddress: %w", err), InternalError, "")
+		}
+	}
+
+	return user, nil
+}
+
 func createUser(
 	ctx context.Context,
 	tx repository.Transaction,
@@ -361,6 +427,11 @@ func (s *AuthService) getAuthRespWithUserById(ctx context.Context, tx repository
 	}
 	resp.User = domain.UserToModel(user)
 
+	resp.User.ChainIDs, err = s.repoUsers.GetUserChainIDs(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getAuthRespWithUserById/GetUserChainIDs: %w", err)
+	}
+
 	return &resp, nil
 }
 
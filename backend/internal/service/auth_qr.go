@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/jwtoken"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+const (
+	loginSessionMessage      = "Please, sign this message to sign in on another device. Session %s on chain %d, random param %s"
+	loginSessionPollInterval = time.Second
+)
+
+// CreateLoginSession starts a cross-device login on chainID, 0 for the primary chain. The poll token
+// is returned only here, so only the device that created the session can redeem it.
+func (s *AuthService) CreateLoginSession(ctx context.Context, chainID int64) (*models.LoginSessionResponse, error) {
+	chainID, err := s.signInChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("CreateLoginSession/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(context.Background())
+
+	createdAt := now.Now()
+	if err := s.repoLoginSessions.DeleteExpiredLoginSessions(ctx, tx, createdAt.UnixMilli()); err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("CreateLoginSession/DeleteExpiredLoginSessions: %w", err), InternalError, "")
+	}
+
+	sessionID, err := randomHex(16)
+	if err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("CreateLoginSession/randomHex: %w", err), InternalError, "")
+	}
+	pollToken, err := randomHex(32)
+	if err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("CreateLoginSession/randomHex: %w", err), InternalError, "")
+	}
+
+	session := &domain.LoginSession{
+		ID:            sessionID,
+		PollTokenHash: s.hashManager.HashSha256(pollToken),
+		Message:       fmt.Sprintf(loginSessionMessage, sessionID, chainID, randomString(32)),
+		Status:        domain.LoginSessionPending,
+		CreatedAt:     createdAt.UnixMilli(),
+		ExpiresAt:     createdAt.Add(s.cfg.QRLogin.SessionTTL).UnixMilli(),
+		ChainID:       chainID,
+	}
+	if err := s.repoLoginSessions.InsertLoginSession(ctx, tx, session); err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("CreateLoginSession/InsertLoginSession: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("CreateLoginSession/Commit: %w", err), InternalError, "")
+	}
+	return &models.LoginSessionResponse{
+		SessionID: sessionID,
+		PollToken: pollToken,
+		URI:       fmt.Sprintf("%s?session=%s", s.cfg.QRLogin.URIBase, url.QueryEscape(sessionID)),
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) GetLoginSessionChallenge(ctx context.Context, sessionID string) (*models.LoginSessionChallengeResponse, error) {
+	tx, err := s.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("GetLoginSessionChallenge/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(context.Background())
+
+	session, err := s.getActiveLoginSession(ctx, tx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.LoginSessionPending {
+		return nil, newServiceError(code400,
+			fmt.Errorf("GetLoginSessionChallenge: %s", LoginSessionUsed), LoginSessionUsed, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("GetLoginSessionChallenge/Commit: %w", err), InternalError, "")
+	}
+	return &models.LoginSessionChallengeResponse{
+		Message:   session.Message,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) SignLoginSession(ctx context.Context, sessionID string, req *models.AuthBySignatureRequest) error {
+	tx, err := s.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("SignLoginSession/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(context.Background())
+
+	session, err := s.getActiveLoginSession(ctx, tx, sessionID)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(session.Message, *req.Signature, *req.Address); err != nil {
+		return err
+	}
+
+	user, err := s.getOrCreateUser(ctx, tx, *req.Address)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.repoLoginSessions.VerifyLoginSession(ctx, tx, sessionID, user.ID, now.Now().UnixMilli())
+	if err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("SignLoginSession/VerifyLoginSession: %w", err), InternalError, "")
+	}
+	if !ok {
+		return newServiceError(code400,
+			fmt.Errorf("SignLoginSession: %s", LoginSessionUsed), LoginSessionUsed, "")
+	}
+	if err := s.repoUsers.RecordUserChain(ctx, tx, user.ID, session.ChainID, now.Now().UnixMilli()); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("SignLoginSession/RecordUserChain: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("SignLoginSession/Commit: %w", err), InternalError, "")
+	}
+	return nil
+}
+
+// PollLoginSession waits up to the configured poll timeout for the session to be signed.
+// A pending session is reported as such and the client is expected to poll again.
+func (s *AuthService) PollLoginSession(
+	ctx context.Context,
+	sessionID string,
+	req *models.LoginSessionPollRequest,
+) (*models.LoginSessionPollResponse, *jwtoken.JWTokenData, *jwtoken.JWTokenData, error) {
+	pollTokenHash := s.hashManager.HashSha256(*req.PollToken)
+
+	waitCtx, cancel := context.WithTimeout(ctx, s.cfg.QRLogin.PollTimeout)
+	defer cancel()
+	ticker := time.NewTicker(loginSessionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, accessToken, refreshToken, err := s.redeemLoginSession(ctx, sessionID, pollTokenHash)
+		if err != nil || resp.Status != models.LoginSessionPollResponseStatusPending {
+			return resp, accessToken, refreshToken, err
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return resp, nil, nil, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *AuthService) redeemLoginSession(
+	ctx context.Context,
+	sessionID string,
+	pollTokenHash string,
+) (*models.LoginSessionPollResponse, *jwtoken.JWTokenData, *jwtoken.JWTokenData, error) {
+	tx, err := s.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, nil, nil, newServiceError(code500,
+			fmt.Errorf("redeemLoginSession/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(context.Background())
+
+	session, err := s.getActiveLoginSession(ctx, tx, sessionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if session.PollTokenHash != pollTokenHash {
+		return nil, nil, nil, newServiceError(code403,
+			fmt.Errorf("redeemLoginSession: %s", LoginSessionWrongToken), LoginSessionWrongToken, "")
+	}
+	switch session.Status {
+	case domain.LoginSessionPending:
+		return &models.LoginSessionPollResponse{Status: models.LoginSessionPollResponseStatusPending}, nil, nil, nil
+	case domain.LoginSessionRedeemed:
+		return nil, nil, nil, newServiceError(code400,
+			fmt.Errorf("redeemLoginSession: %s", LoginSessionUsed), LoginSessionUsed, "")
+	}
+
+	userID, err := s.repoLoginSessions.RedeemLoginSession(ctx, tx, sessionID, pollTokenHash, now.Now().UnixMilli())
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, nil, nil, newServiceError(code400,
+				fmt.Errorf("redeemLoginSession/RedeemLoginSession: %w", err), LoginSessionUsed, "")
+		}
+		return nil, nil, nil, newServiceError(code500,
+			fmt.Errorf("redeemLoginSession/RedeemLoginSession: %w", err), InternalError, "")
+	}
+
+	user, err := s.repoUsers.GetUserById(ctx, tx, userID)
+	if err != nil {
+		return nil, nil, nil, newServiceError(code500,
+			fmt.Errorf("redeemLoginSession/GetUserById: %w", err), InternalError, "")
+	}
+
+	auth, err := s.getAuthRespWithUserById(ctx, tx, user.Role, user.ID)
+	if err != nil {
+		return nil, nil, nil, newServiceError(code500,
+			fmt.Errorf("redeemLoginSession/getAuthRespWithUserById: %w", err), InternalError, "")
+	}
+	auth.ServerTime = now.Now().UnixMilli()
+	auth.ChainID = session.ChainID
+
+	accessToken, refreshToken, err := s.generateJWTokens(ctx, tx, user.ID, user.Role)
+	if err != nil {
+		return nil, nil, nil, newServiceError(code500,
+			fmt.Errorf("redeemLoginSession/generateJWTokens: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, newServiceError(code500,
+			fmt.Errorf("redeemLoginSession/Commit: %w", err), InternalError, "")
+	}
+	return &models.LoginSessionPollResponse{
+		Status: models.LoginSessionPollResponseStatusRedeemed,
+		Auth:   auth,
+	}, accessToken, refreshToken, nil
+}
+
+func (s *AuthService) getActiveLoginSession(ctx context.Context, tx repository.Transaction, sessionID string) (*domain.LoginSession, error) {
+	session, err := s.repoLoginSessions.GetLoginSession(ctx, tx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404,
+				fmt.Errorf("getActiveLoginSession/GetLoginSession: %w", err), LoginSessionNotExist, "")
+		}
+		return nil, newServiceError(code500,
+			fmt.Errorf("getActiveLoginSession/GetLoginSession: %w", err), InternalError, "")
+	}
+	if session.ExpiresAt <= now.Now().UnixMilli() {
+		return nil, newServiceError(code400,
+			fmt.Errorf("getActiveLoginSession: %s", LoginSessionExpired), LoginSessionExpired, "")
+	}
+	return session, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ChainWorker submits queued on-chain messages through the relayer and follows their receipts.
+// Messages are stored before they go on-chain, so a node outage only delays them.
+type ChainWorker struct {
+	cfg              *config.ChainConfig
+	client           chain.Client
+	repoChainTxs     repository.ChainTxs
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewChainWorker(
+	cfg *config.ChainConfig,
+	client chain.Client,
+	repoChainTxs repository.ChainTxs,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) *ChainWorker {
+
+	return &ChainWorker{
+		cfg:              cfg,
+		client:           client,
+		repoChainTxs:     repoChainTxs,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+func (w *ChainWorker) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := w.submitQueued(); err != nil {
+				w.logging.Errorf("ChainWorker/submitQueued: %v", err)
+			}
+			if err := w.checkSubmitted(); err != nil {
+				w.logging.Errorf("ChainWorker/checkSubmitted: %v", err)
+			}
+		}
+	}
+}
+
+// submitQueued broadcasts the queued transactions. A transaction the node rejects stays queued
+// until it has failed cfg.Gas.MaxSendAttempts times.
+func (w *ChainWorker) submitQueued() error {
+	return w.eachChainTx(domain.ChainTxQueued, w.submit)
+}
+
+// checkSubmitted follows the receipts of the submitted transactions and replaces the ones that stay
+// unmined for cfg.Gas.ReplaceAfter with the same nonce and higher fees.
+func (w *ChainWorker) checkSubmitted() error {
+	return w.eachChainTx(domain.ChainTxSubmitted, w.check)
+}
+
+// eachChainTx handles up to cfg.BatchSize transactions in status, each in a database transaction of
+// its own. A broadcast can't be taken back, so what was sent is committed before the next row is
+// picked up and a failing row doesn't roll back the others. handle returns false when the row is
+// left unchanged.
+func (w *ChainWorker) eachChainTx(status domain.ChainTxStatus, handle func(context.Context, *domain.MessageChainTx) bool) error {
+	var afterID int64
+	for i := int64(0); i < w.cfg.BatchSize; i++ {
+		ct, err := w.handleNext(status, afterID, handle)
+		if err != nil {
+			return fmt.Errorf("eachChainTx/handleNext: %w", err)
+		}
+		if ct == nil {
+			return nil
+		}
+		afterID = ct.MessageID
+	}
+	return nil
+}
+
+func (w *ChainWorker) handleNext(status domain.ChainTxStatus, afterID int64, handle func(context.Context, *domain.MessageChainTx) bool) (*domain.MessageChainTx, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.WorkerInterval)
+	defer cancel()
+
+	tx, err := w.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("handleNext/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	chainTxs, err := w.repoChainTxs.GetMessageChainTxsByStatus(ctx, tx, status, afterID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("handleNext/GetMessageChainTxsByStatus: %w", err)
+	}
+	if len(chainTxs) == 0 {
+		return nil, nil
+	}
+	ct := chainTxs[0]
+	if !handle(ctx, ct) {
+		return ct, nil
+	}
+
+	// the row lock is held until the outcome of the broadcast is stored, even past the RPC deadline
+	storeCtx := context.WithoutCancel(ctx)
+	ct.UpdatedAt = now.Now().UnixMilli()
+	if err := w.repoChainTxs.UpdateMessageChainTx(storeCtx, tx, ct); err != nil {
+		return nil, fmt.Errorf("handleNext/UpdateMessageChainTx: message %d: %w", ct.MessageID, err)
+	}
+	if err := tx.Commit(storeCtx); err != nil {
+		return nil, fmt.Errorf("handleNext/Commit: message %d: %w", ct.MessageID, err)
+	}
+
+	return ct, nil
+}
+
+func (w *ChainWorker) submit(ctx context.Context, ct *domain.MessageChainTx) bool {
+	if relayExpired(ct) {
+		reason := "relay signature expired"
+		ct.Status, ct.Error = domain.ChainTxFailed, &reason
+	} else if sent, err := w.send(ctx, ct, nil); err != nil {
+		reason := err.Error()
+		ct.Error = &reason
+		ct.Attempts++
+		if ct.Attempts >= w.cfg.Gas.MaxSendAttempts {
+			ct.Status = domain.ChainTxFailed
+		}
+	} else {
+		ct.Status, ct.Error = domain.ChainTxSubmitted, nil
+		setSentTx(ct, sent)
+	}
+	return true
+}
+
+func (w *ChainWorker) check(ctx context.Context, ct *domain.MessageChainTx) bool {
+	status, minedHash, err := w.status(ctx, ct)
+	if err != nil {
+		// a node error only delays this transaction, the next one may be answered by a healthy endpoint
+		w.logging.Errorf("ChainWorker/check: message %d: %v", ct.MessageID, err)
+		return false
+	}
+	switch status {
+	case chain.TxConfirmed:
+		ct.Status = domain.ChainTxConfirmed
+		setMinedTx(ct, minedHash)
+	case chain.TxFailed:
+		reason := "transaction reverted"
+		ct.Status, ct.Error = domain.ChainTxFailed, &reason
+		setMinedTx(ct, minedHash)
+	default:
+		if !w.stuck(ct) {
+			return false
+		}
+		replacement, err := w.send(ctx, ct, sentTx(ct))
+		if err != nil {
+			// the stuck transaction may have been mined meanwhile, its receipt shows up next time
+			w.logging.Errorf("ChainWorker/check: replace %s: %v", *ct.TxHash, err)
+			return false
+		}
+		ct.ReplacedTxHashes = append(ct.ReplacedTxHashes, *ct.TxHash)
+		setSentTx(ct, replacement)
+	}
+	return true
+}
+
+// send broadcasts ct from the relayer's account, or through sendMessageBySig for gasless messages.
+func (w *ChainWorker) send(ctx context.Context, ct *domain.MessageChainTx, replace *chain.SentTx) (*chain.SentTx, error) {
+	receiver := common.HexToAddress(ct.RecipientAddress)
+	if ct.Relay == nil {
+		return w.client.SendMessage(ctx, receiver, ct.Content, replace)
+	}
+
+	nonce, _ := new(big.Int).SetString(ct.Relay.Nonce, 10)
+	return w.client.RelayMessage(ctx, &chain.SignedMessage{
+		Sender:    common.HexToAddress(ct.Relay.SenderAddress),
+		Receiver:  receiver,
+		Content:   ct.Content,
+		Nonce:     nonce,
+		Deadline:  big.NewInt(ct.Relay.Deadline),
+		Signature: common.FromHex(ct.Relay.Signature),
+	}, replace)
+}
+
+// status checks the current transaction and the ones it replaced, whichever got mined decides.
+func (w *ChainWorker) status(ctx context.Context, ct *domain.MessageChainTx) (chain.TxStatus, string, error) {
+	for _, hash := range append([]string{*ct.TxHash}, ct.ReplacedTxHashes...) {
+		status, err := w.client.TxStatus(ctx, common.HexToHash(hash))
+		if err != nil {
+			return chain.TxPending, "", err
+		}
+		if status != chain.TxPending {
+			return status, hash, nil
+		}
+	}
+	return chain.TxPending, "", nil
+}
+
+// stuck reports whether ct waited long enough to be replaced. Expired gasless messages aren't, a
+// replacement would revert like the original.
+func (w *ChainWorker) stuck(ct *domain.MessageChainTx) bool {
+	if relayExpired(ct) {
+		return false
+	}
+	return stuckTx(ct.Sent, w.cfg.Gas)
+}
+
+func relayExpired(ct *domain.MessageChainTx) bool {
+	return ct.Relay != nil && ct.Relay.Deadline <= now.Now().Unix()
+}
+
+func setSentTx(ct *domain.MessageChainTx, sent *chain.SentTx) {
+	ct.TxHash, ct.Sent = newSentChainTx(sent)
+}
+
+// sentTx is the transaction ct.TxHash points to, as the relayer needs it for a replacement.
+func sentTx(ct *domain.MessageChainTx) *chain.SentTx {
+	return toSentTx(*ct.TxHash, ct.Sent)
+}
+
+// setMinedTx points ct.TxHash to the transaction that got mined, the others stay in ReplacedTxHashes.
+func setMinedTx(ct *domain.MessageChainTx, minedHash string) {
+	ct.TxHash, ct.ReplacedTxHashes = minedTx(*ct.TxHash, ct.ReplacedTxHashes, minedHash)
+}
+
+// The relayer's transactions share its nonce, so a stuck one holds up every later one whichever worker
+// sent it. The helpers below keep what each worker needs to replace its own.
+
+// stuckTx reports whether the transaction sent waited cfg.ReplaceAfter without being mined.
+func stuckTx(sent *domain.SentChainTx, cfg *config.GasConfig) bool {
+	if sent == nil {
+		return false
+	}
+	return now.Now().Sub(time.UnixMilli(sent.SubmittedAt)) >= cfg.ReplaceAfter
+}
+
+func newSentChainTx(sent *chain.SentTx) (*string, *domain.SentChainTx) {
+	txHash := sent.Hash.Hex()
+	return &txHash, &domain.SentChainTx{
+		AccountNonce: int64(sent.Nonce),
+		GasTipCap:    sent.GasTipCap.String(),
+		GasFeeCap:    sent.GasFeeCap.String(),
+		SubmittedAt:  now.Now().UnixMilli(),
+	}
+}
+
+func toSentTx(txHash string, sent *domain.SentChainTx) *chain.SentTx {
+	tipCap, _ := new(big.Int).SetString(sent.GasTipCap, 10)
+	feeCap, _ := new(big.Int).SetString(sent.GasFeeCap, 10)
+	return &chain.SentTx{
+		Hash:      common.HexToHash(txHash),
+		Nonce:     uint64(sent.AccountNonce),
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+	}
+}
+
+// minedTx moves minedHash to the front, txHash joins the replaced ones unless it's the mined one.
+func minedTx(txHash string, replaced []string, minedHash string) (*string, []string) {
+	if minedHash == txHash {
+		return &txHash, replaced
+	}
+	others := []string{txHash}
+	for _, hash := range replaced {
+		if hash != minedHash {
+			others = append(others, hash)
+		}
+	}
+	return &minedHash, others
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// messageSentCheckpoint names the chain_checkpoints row of the MessageSent indexer.
+const messageSentCheckpoint = "messaging.MessageSent"
+
+// ChainIndexer ingests MessageSent logs of messages users sent straight from their wallets, so they
+// show up in the dialogs like the ones sent through the API. Only blocks with cfg.Confirmations
+// confirmations are read, the indexer doesn't handle reorgs.
+type ChainIndexer struct {
+	cfg    *config.ChainConfig
+	events chain.EventSource
+	// relayer sends the API's on-chain messages, they are stored already. It's the zero address
+	// when on-chain sending is disabled.
+	relayer          common.Address
+	repoIndexer      repository.ChainIndexer
+	repoUsers        repository.Users
+	repoDialogs      repository.Dialogs
+	repoPrivacy      repository.Privacy
+	repoInboxGates   repository.InboxGates
+	repoPayments     repository.Payments
+	repoPending      repository.PendingMessages
+	repoTransactions repository.Transactions
+	holdings         chain.Holdings
+
+	logging logger.Logger
+}
+
+func NewChainIndexer(
+	cfg *config.ChainConfig,
+	events chain.EventSource,
+	relayer common.Address,
+	repoIndexer repository.ChainIndexer,
+	repoUsers repository.Users,
+	repoDialogs repository.Dialogs,
+	repoPrivacy repository.Privacy,
+	repoInboxGates repository.InboxGates,
+	repoPayments repository.Payments,
+	repoPending repository.PendingMessages,
+	repoTransactions repository.Transactions,
+	holdings chain.Holdings,
+
+	logging logger.Logger) *ChainIndexer {
+
+	return &ChainIndexer{
+		cfg:              cfg,
+		events:           events,
+		relayer:          relayer,
+		repoIndexer:      repoIndexer,
+		repoUsers:        repoUsers,
+		repoDialogs:      repoDialogs,
+		repoPrivacy:      repoPrivacy,
+		repoInboxGates:   repoInboxGates,
+		repoPayments:     repoPayments,
+		repoPending:      repoPending,
+		repoTransactions: repoTransactions,
+		holdings:         holdings,
+
+		logging: logging,
+	}
+}
+
+func (i *ChainIndexer) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(i.cfg.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			// catch up batch by batch, stopping early if asked to
+			for {
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+				done, err := i.indexBatch()
+				if err != nil {
+					i.logging.Errorf("ChainIndexer/indexBatch: %v", err)
+				}
+				if done || err != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// indexBatch stores the logs of up to cfg.IndexerBatchBlocks confirmed blocks past the checkpoint
+// and moves the checkpoint in the same transaction. It reports whether the indexer caught up.
+func (i *ChainIndexer) indexBatch() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), i.cfg.WorkerInterval)
+	defer cancel()
+
+	tx, err := i.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return false, fmt.Errorf("indexBatch/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	from, err := i.repoIndexer.LockChainCheckpoint(ctx, tx, messageSentCheckpoint, i.cfg.IndexerStartBlock, now.Now().UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("indexBatch/LockChainCheckpoint: %w", err)
+	}
+
+	latest, err := i.events.LatestBlock(ctx)
+	if err != nil {
+		return false, fmt.Errorf("indexBatch/LatestBlock: %w", err)
+	}
+	confirmed := int64(latest) - i.cfg.Confirmations
+	if confirmed < from {
+		return true, nil
+	}
+	to := min(from+i.cfg.IndexerBatchBlocks-1, confirmed)
+
+	events, err := i.events.MessageSent(ctx, uint64(from), uint64(to))
+	if err != nil {
+		return false, fmt.Errorf("indexBatch/MessageSent: %w", err)
+	}
+
+	for _, e := range events {
+		if e.Sender == i.relayer {
+			continue
+		}
+		if err := i.indexMessage(ctx, tx, e); err != nil {
+			return false, fmt.Errorf("indexBatch/indexMessage: %w", err)
+		}
+	}
+
+	if err := i.repoIndexer.UpdateChainCheckpoint(ctx, tx, messageSentCheckpoint, to+1, now.Now().UnixMilli()); err != nil {
+		return false, fmt.Errorf("indexBatch/UpdateChainCheckpoint: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("indexBatch/Commit: %w", err)
+	}
+
+	return to == confirmed, nil
+}
+
+// indexMessage stores the log as a message, creating the users and the dialog when needed.
+// Like API messages, first contact lands in the receiver's requests folder. Messages the API
+// would have refused are dropped, see canDeliver.
+func (i *ChainIndexer) indexMessage(ctx context.Context, tx repository.Transaction, e *chain.MessageSent) error {
+	chainLog := &domain.ChainLog{
+		BlockNumber: int64(e.BlockNumber),
+		TxHash:      e.TxHash.Hex(),
+		LogIndex:    int64(e.LogIndex),
+	}
+	indexed, err := i.repoIndexer.IsChainLogIndexed(ctx, tx, chainLog.TxHash, chainLog.LogIndex)
+	if err != nil {
+		return fmt.Errorf("indexMessage/IsChainLogIndexed: %w", err)
+	}
+	if indexed {
+		return nil
+	}
+
+	sender, err := i.getOrCreateUser(ctx, tx, e.Sender.Hex())
+	if err != nil {
+		return err
+	}
+	receiver, err := i.getOrCreateUser(ctx, tx, e.Receiver.Hex())
+	if err != nil {
+		return err
+	}
+
+	dialogExists, err := i.repoDialogs.DialogExists(ctx, tx, receiver.ID, sender.ID)
+	if err != nil {
+		return fmt.Errorf("indexMessage/DialogExists: %w", err)
+	}
+	deliver, err := i.canDeliver(ctx, tx, sender, receiver, dialogExists)
+	if err != nil {
+		return fmt.Errorf("indexMessage/canDeliver: %w", err)
+	}
+	if !deliver {
+		return nil
+	}
+
+	var (
+		dialogID     int64
+		retentionTTL int64
+	)
+	if dialogExists {
+		dialogID, err = i.repoDialogs.GetDialogByUsers(ctx, tx, receiver.ID, sender.ID)
+		if err != nil {
+			return fmt.Errorf("indexMessage/GetDialogByUsers: %w", err)
+		}
+		retentionTTL, err = i.repoDialogs.GetDialogRetention(ctx, tx, dialogID)
+		if err != nil {
+			return fmt.Errorf("indexMessage/GetDialogRetention: %w", err)
+		}
+	} else {
+		dialogID, err = i.repoDialogs.CreateDialog(ctx, tx, receiver.ID, sender.ID)
+		if err != nil {
+			return fmt.Errorf("indexMessage/CreateDialog: %w", err)
+		}
+		if err := i.repoDialogs.CreateDialogBetweenUsers(ctx, tx, receiver.ID, sender.ID, dialogID); err != nil {
+			return fmt.Errorf("indexMessage/CreateDialogBetweenUsers: %w", err)
+		}
+		if err := i.repoDialogs.MarkDialogRequested(ctx, tx, dialogID, sender.ID); err != nil {
+			return fmt.Errorf("indexMessage/MarkDialogRequested: %w", err)
+		}
+	}
+
+	msg := &domain.Message{
+		DialogID:  dialogID,
+		SenderID:  sender.ID,
+		Content:   e.Content,
+		CreatedAt: e.BlockTime,
+		ChainLog:  chainLog,
+	}
+	if retentionTTL > 0 {
+		expiresAt := msg.CreatedAt + retentionTTL
+		msg.ExpiresAt = &expiresAt
+	}
+	if _, err := i.repoDialogs.CreateMessageInDialog(ctx, tx, msg); err != nil {
+		return fmt.Errorf("indexMessage/CreateMessageInDialog: %w", err)
+	}
+
+	return nil
+}
+
+// canDeliver applies the checks of API messages: blocks, the receiver's privacy setting and, on first
+// contact, the inbox gates and the price. The contract takes any message, so a log failing them is
+// dropped. A log can't carry a payment, first contact with a receiver who set a price never passes.
+func (i *ChainIndexer) canDeliver(ctx context.Context, tx repository.Transaction, sender, receiver *domain.UserChain, dialogExists bool) (bool, error) {
+	err := checkCanMessage(ctx, tx, i.repoPrivacy, sender.ID, receiver.ID, dialogExists)
+	if err == nil && !dialogExists {
+		err = checkInboxGates(ctx, tx, i.repoInboxGates, i.holdings, sender.Address, receiver.ID)
+	}
+	if err != nil {
+		var serviceErr *ServiceError
+		if errors.As(err, &serviceErr) && serviceErr.Code < code500 {
+			return false, nil
+		}
+		return false, err
+	}
+	if dialogExists {
+		return true, nil
+	}
+
+	price, err := i.repoPayments.GetMessagePrice(ctx, tx, receiver.ID)
+	if err != nil {
+		return false, fmt.Errorf("canDeliver/GetMessagePrice: %w", err)
+	}
+	return price == nil, nil
+}
+
+// getOrCreateUser registers addresses that never signed in, their pending messages are delivered
+// right away since the pending inbox is only checked for unregistered addresses.
+func (i *ChainIndexer) getOrCreateUser(ctx context.Context, tx repository.Transaction, address string) (*domain.UserChain, error) {
+	user, err := i.repoUsers.GetUserByAddress(ctx, tx, strings.ToLower(address))
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrNoRows) {
+		return nil, fmt.Errorf("getOrCreateUser/GetUserByAddress: %w", err)
+	}
+
+	user, err = createUser(ctx, tx, i.repoUsers, strings.ToLower(address), 0)
+	if err != nil {
+		return nil, fmt.Errorf("getOrCreateUser/createUser: %w", err)
+	}
+	if err := deliverPendingMessages(ctx, tx, i.repoDialogs, i.repoPending, user); err != nil {
+		return nil, fmt.Errorf("getOrCreateUser/deliverPendingMessages: %w", err)
+	}
+
+	return user, nil
+}
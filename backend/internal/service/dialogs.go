@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Pyegorchik/bdd/backend/internal/config"
 	"github.com/Pyegorchik/bdd/backend/internal/domain"
 	"github.com/Pyegorchik/bdd/backend/internal/repository"
 	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/blobstore"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
 	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -18,7 +23,24 @@ type DialogsService struct {
 	repoUsers        repository.Users
 	repoDialogs      repository.Dialogs
 	repoJWTokens     repository.JWTokens
+	repoAttachments  repository.Attachments
+	repoReactions    repository.Reactions
+	repoPrivacy      repository.Privacy
+	repoInboxGates   repository.InboxGates
+	repoPayments     repository.Payments
+	repoEncryption   repository.Encryption
+	repoPending      repository.PendingMessages
+	repoIdempotency  repository.IdempotencyKeys
+	repoChainTxs     repository.ChainTxs
 	repoTransactions repository.Transactions
+	blobStore        blobstore.BlobStore
+	// chainClient is nil when on-chain sending isn't configured
+	chainClient chain.Client
+	// holdings and payments are nil when no chain node is configured
+	holdings chain.Holdings
+	payments chain.Payments
+	// names is nil when no name registry is configured
+	names chain.Names
 
 	logging logger.Logger
 }
@@ -28,7 +50,21 @@ func NewDialogsService(
 	repoUsers repository.Users,
 	repoDialogs repository.Dialogs,
 	repoJWTokens repository.JWTokens,
+	repoAttachments repository.Attachments,
+	repoReactions repository.Reactions,
+	repoPrivacy repository.Privacy,
+	repoInboxGates repository.InboxGates,
+	repoPayments repository.Payments,
+	repoEncryption repository.Encryption,
+	repoPending repository.PendingMessages,
+	repoIdempotency repository.IdempotencyKeys,
+	repoChainTxs repository.ChainTxs,
 	repoTransactions repository.Transactions,
+	blobStore blobstore.BlobStore,
+	chainClient chain.Client,
+	holdings chain.Holdings,
+	payments chain.Payments,
+	names chain.Names,
 
 	logging logger.Logger) Dialogs {
 
@@ -37,55 +73,160 @@ func NewDialogsService(
 		repoDialogs:      repoDialogs,
 		repoUsers:        repoUsers,
 		repoJWTokens:     repoJWTokens,
+		repoAttachments:  repoAttachments,
+		repoReactions:    repoReactions,
+		repoPrivacy:      repoPrivacy,
+		repoInboxGates:   repoInboxGates,
+		repoPayments:     repoPayments,
+		repoEncryption:   repoEncryption,
+		repoPending:      repoPending,
+		repoIdempotency:  repoIdempotency,
+		repoChainTxs:     repoChainTxs,
 		repoTransactions: repoTransactions,
+		blobStore:        blobStore,
+		chainClient:      chainClient,
+		holdings:         holdings,
+		payments:         payments,
+		names:            names,
 
 		logging: logging,
 	}
 }
 
-func (d *DialogsService) SendMessage(ctx context.Context, req *models.SendMessageRequest, userID int64) error {
+func (d *DialogsService) SendMessage(ctx context.Context, req *models.SendMessageRequest, uploads []*domain.AttachmentUpload, userID int64) (*models.SendMessageResponse, error) {
+	mimeTypes, err := d.checkUploads(uploads)
+	if err != nil {
+		return nil, err
+	}
+	if req.Relay != nil && !req.OnChain {
+		return nil, newServiceError(code400, fmt.Errorf("SendMessage: %s", RelayNotOnChain), RelayNotOnChain, "")
+	}
+	if req.OnChain {
+		if err := d.checkOnChain(req, uploads); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.resolveRecipient(ctx, req); err != nil {
+		return nil, err
+	}
+
 	tx, err := d.repoTransactions.BeginTransaction(ctx)
 	if err != nil {
-		return newServiceError(code500, fmt.Errorf("SendMessage/BeginTransaction: %w", err), InternalError, "")
+		return nil, newServiceError(code500, fmt.Errorf("SendMessage/BeginTransaction: %w", err), InternalError, "")
 	}
 	defer tx.Rollback(ctx)
 
+	if req.ClientMessageID != "" {
+		res, err := d.claimIdempotencyKey(ctx, tx, req, uploads, userID)
+		if err != nil {
+			return nil, err
+		}
+		if res != nil {
+			return res, nil
+		}
+	}
+
+	success := true
+	sender, err := d.repoUsers.GetUserById(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("SendMessage/GetUserById: %w", err), InternalError, "")
+	}
+
 	recepeint, err := d.repoUsers.GetUserByAddress(ctx, tx, *req.RecipientID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return newServiceError(code400, fmt.Errorf("SendMessage/GetUserByAddress: %w", err),
-				BadRequest, fmt.Sprintf("user with address %v is not registered", *req.RecipientID))
+			// the message waits in the pending inbox until the address signs in
+			pendingID, err := d.queuePendingMessage(ctx, tx, req, uploads, sender)
+			if err != nil {
+				return nil, err
+			}
+			res := &models.SendMessageResponse{Success: &success, PendingMessageID: pendingID}
+			if err := d.saveIdempotencyResult(ctx, tx, req, res, userID); err != nil {
+				return nil, err
+			}
+			if err := tx.Commit(ctx); err != nil {
+				return nil, newServiceError(code500,
+					fmt.Errorf("SendMessage/Commit: %w", err), InternalError, "")
+			}
+			return res, nil
 		} else {
-			return newServiceError(code500, fmt.Errorf("SendMessage/GetUserByAddress: %w", err), InternalError, "")
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/GetUserByAddress: %w", err), InternalError, "")
 		}
 
 	}
 
-	sender, err := d.repoUsers.GetUserById(ctx, tx, userID)
+	dialogExists, err := d.repoDialogs.DialogExists(ctx, tx, recepeint.ID, userID)
 	if err != nil {
-		return newServiceError(code500, fmt.Errorf("SendMessage/GetUserById: %w", err), InternalError, "")
+		return nil, newServiceError(code500, fmt.Errorf("SendMessage/DialogExists: %w", err), InternalError, "")
 	}
 
-	dialogExists, err := d.repoDialogs.DialogExists(ctx, tx, recepeint.ID, userID)
-	if err != nil {
-		return newServiceError(code500, fmt.Errorf("SendMessage/DialogExists: %w", err), InternalError, "")
+	if err := checkCanMessage(ctx, tx, d.repoPrivacy, userID, recepeint.ID, dialogExists); err != nil {
+		return nil, err
+	}
+	var payment *domain.MessagePayment
+	if !dialogExists {
+		if err := checkInboxGates(ctx, tx, d.repoInboxGates, d.holdings, sender.Address, recepeint.ID); err != nil {
+			return nil, err
+		}
+		payment, err = checkMessagePayment(ctx, tx, d.repoPayments, d.payments, d.cfg.Payments, req.PaymentTxHash, sender, recepeint)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	var dialogId int64
+	var (
+		dialogId     int64
+		retentionTTL int64
+	)
 	if dialogExists {
 		dialogId, err = d.repoDialogs.GetDialogByUsers(ctx, tx, recepeint.ID, userID)
 		if err != nil {
-			return newServiceError(code500, fmt.Errorf("SendMessage/GetDialogByUsers: %w", err), InternalError, "")
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/GetDialogByUsers: %w", err), InternalError, "")
+		}
+
+		retentionTTL, err = d.repoDialogs.GetDialogRetention(ctx, tx, dialogId)
+		if err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/GetDialogRetention: %w", err), InternalError, "")
+		}
+
+		if err := d.checkMessageRequest(ctx, tx, dialogId, userID); err != nil {
+			return nil, err
 		}
 	} else {
 		dialogId, err = d.repoDialogs.CreateDialog(ctx, tx, recepeint.ID, userID)
 		if err != nil {
-			return newServiceError(code500, fmt.Errorf("SendMessage/CreateDialog: %w", err), InternalError, "")
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/CreateDialog: %w", err), InternalError, "")
 		}
 
 		err = d.repoDialogs.CreateDialogBetweenUsers(ctx, tx, recepeint.ID, userID, dialogId)
 		if err != nil {
-			return newServiceError(code500, fmt.Errorf("SendMessage/CreateDialogBetweenUsers: %w", err), InternalError, "")
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/CreateDialogBetweenUsers: %w", err), InternalError, "")
+		}
+
+		// first contact lands in the recipient's requests folder
+		err = d.repoDialogs.MarkDialogRequested(ctx, tx, dialogId, userID)
+		if err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/MarkDialogRequested: %w", err), InternalError, "")
+		}
+	}
+
+	var signature *domain.MessageSignature
+	if req.Signature != "" {
+		signedDialogID := int64(0)
+		if dialogExists {
+			signedDialogID = dialogId
+		}
+		signature, err = d.checkMessageSignature(req, signedDialogID, sender, recepeint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var relay *domain.RelaySignature
+	if req.Relay != nil {
+		relay, err = d.checkRelaySignature(ctx, tx, req, sender, recepeint)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -94,51 +235,440 @@ func (d *DialogsService) SendMessage(ctx context.Context, req *models.SendMessag
 		SenderAddress: sender.Address.String(),
 		SenderID:      userID,
 		Content:       *req.Content,
+		CreatedAt:     now.Now().UnixMilli(),
+	}
+	if retentionTTL > 0 {
+		expiresAt := msg.CreatedAt + retentionTTL
+		msg.ExpiresAt = &expiresAt
+	}
+
+	var ciphertexts []*domain.MessageCiphertext
+	if req.Encrypted != nil {
+		ciphertexts, err = d.checkEncryptedPayload(ctx, tx, req, sender, recepeint)
+		if err != nil {
+			return nil, err
+		}
+		msg.Nonce = req.Encrypted.Nonce
+	}
+
+	if req.ReplyToMessageID != 0 {
+		parent, err := d.repoDialogs.GetMessageById(ctx, tx, req.ReplyToMessageID)
+		if err != nil && !errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/GetMessageById: %w", err), InternalError, "")
+		}
+		if parent == nil || parent.DialogID != dialogId || parent.Expired(msg.CreatedAt) {
+			return nil, newServiceError(code400, fmt.Errorf("SendMessage: %s", ReplyNotInDialog), ReplyNotInDialog, "")
+		}
+		msg.ReplyToMessageID = &parent.ID
+	}
+
+	msg.ID, err = d.repoDialogs.CreateMessageInDialog(ctx, tx, msg)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("SendMessage/CreateMessageInDialog: %w", err), InternalError, "")
+	}
+
+	if req.OnChain {
+		// the chain worker picks it up once the message is committed
+		if err := d.repoChainTxs.InsertMessageChainTx(ctx, tx, &domain.MessageChainTx{
+			MessageID:        msg.ID,
+			RecipientAddress: strings.ToLower(recepeint.Address.Hex()),
+			Relay:            relay,
+			Status:           domain.ChainTxQueued,
+			CreatedAt:        msg.CreatedAt,
+		}); err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/InsertMessageChainTx: %w", err), InternalError, "")
+		}
 	}
 
-	err = d.repoDialogs.CreateMessageInDialog(ctx, tx, msg)
+	if payment != nil {
+		payment.MessageID, payment.DialogID = &msg.ID, dialogId
+		payment.CreatedAt = msg.CreatedAt
+		inserted, err := d.repoPayments.InsertMessagePayment(ctx, tx, payment)
+		if err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/InsertMessagePayment: %w", err), InternalError, "")
+		}
+		if !inserted {
+			return nil, newServiceError(code409, fmt.Errorf("SendMessage: %s", PaymentTxUsed), PaymentTxUsed, "")
+		}
+	} else if dialogExists && d.payments != nil && d.payments.CanRefund() {
+		// a reply refunds the deposits the other participant paid to reach the sender
+		if _, err := d.repoPayments.QueueDialogRefunds(ctx, tx, dialogId, recepeint.ID, msg.CreatedAt); err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/QueueDialogRefunds: %w", err), InternalError, "")
+		}
+	}
+
+	if signature != nil {
+		if err := d.repoDialogs.InsertMessageSignature(ctx, tx, msg.ID, signature); err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/InsertMessageSignature: %w", err), InternalError, "")
+		}
+	}
+
+	for _, c := range ciphertexts {
+		c.MessageID = msg.ID
+		if err := d.repoEncryption.InsertMessageCiphertext(ctx, tx, c); err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("SendMessage/InsertMessageCiphertext: %w", err), InternalError, "")
+		}
+	}
+
+	storedKeys, err := d.storeAttachments(ctx, tx, msg, uploads, mimeTypes)
 	if err != nil {
-		return newServiceError(code500, fmt.Errorf("SendMessage/CreateMessageInDialog: %w", err), InternalError, "")
+		d.dropBlobs(storedKeys)
+		return nil, newServiceError(code500, fmt.Errorf("SendMessage/storeAttachments: %w", err), InternalError, "")
+	}
+
+	res := &models.SendMessageResponse{Success: &success, MessageID: msg.ID}
+	if err := d.saveIdempotencyResult(ctx, tx, req, res, userID); err != nil {
+		d.dropBlobs(storedKeys)
+		return nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return newServiceError(code500,
+		d.dropBlobs(storedKeys)
+		return nil, newServiceError(code500,
 			fmt.Errorf("SendMessage/Commit: %w", err), InternalError, "")
 	}
 
-	return nil
+	return res, nil
 }
 
-func (d *DialogsService) GetDialogs(ctx context.Context, userID int64) ([]*models.DialogsResponseItems0, error) {
+func (d *DialogsService) GetDialogs(ctx context.Context, folder domain.DialogFolder, userID int64) ([]*models.DialogsResponseItems0, error) {
 	tx, err := d.repoTransactions.BeginTransaction(ctx)
 	if err != nil {
 		return nil, newServiceError(code500, fmt.Errorf("GetDialogs/BeginTransaction: %w", err), InternalError, "")
 	}
 	defer tx.Rollback(ctx)
 
-	recepients, err := d.repoDialogs.GetAllDialogsByUser(ctx, tx, userID)
+	recepients, err := d.repoDialogs.GetAllDialogsByUser(ctx, tx, userID, folder, now.Now().UnixMilli())
 	if err != nil {
 		return nil, newServiceError(code500, fmt.Errorf("GetDialogs/CreateMessageInDialog: %w", err), InternalError, "")
 	}
 
+	// names come from the chain, the connection isn't held while they are looked up
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetDialogs/Commit: %w", err), InternalError, "")
+	}
+
 	res := domain.RecepientsToRecepinetsResponce(recepients)
+	d.fillDialogNames(ctx, res)
 
 	return res, nil
 }
 
-func (d *DialogsService) GetMessages(ctx context.Context, dialogID int64) ([]*models.MessagesResponseItems0, error) {
+func (d *DialogsService) GetMessages(ctx context.Context, dialogID, userID int64) ([]*models.MessagesResponseItems0, error) {
 	tx, err := d.repoTransactions.BeginTransaction(ctx)
 	if err != nil {
 		return nil, newServiceError(code500, fmt.Errorf("GetMessages/BeginTransaction: %w", err), InternalError, "")
 	}
 	defer tx.Rollback(ctx)
 
-	msgs, err := d.repoDialogs.GetAllMessagesWithinDialogById(ctx, tx, dialogID)
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessages/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("GetMessages: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	msgs, err := d.repoDialogs.GetAllMessagesWithinDialogById(ctx, tx, dialogID, now.Now().UnixMilli())
 	if err != nil {
 		return nil, newServiceError(code500, fmt.Errorf("GetMessages/CreateMessageInDialog: %w", err), InternalError, "")
 	}
 
+	attachments, err := d.repoAttachments.GetAttachmentsByDialog(ctx, tx, dialogID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessages/GetAttachmentsByDialog: %w", err), InternalError, "")
+	}
+	byMessage := make(map[int64][]*domain.Attachment, len(attachments))
+	for _, a := range attachments {
+		byMessage[a.MessageID] = append(byMessage[a.MessageID], a)
+	}
+	reactions, err := d.repoReactions.GetReactionsByDialog(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessages/GetReactionsByDialog: %w", err), InternalError, "")
+	}
+	reactionsByMessage := make(map[int64][]*domain.ReactionSummary, len(reactions))
+	for _, r := range reactions {
+		reactionsByMessage[r.MessageID] = append(reactionsByMessage[r.MessageID], r)
+	}
+
+	ciphertexts, err := d.repoEncryption.GetCiphertextsByDialog(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessages/GetCiphertextsByDialog: %w", err), InternalError, "")
+	}
+	ciphertextByMessage := make(map[int64]*domain.MessageCiphertext, len(ciphertexts))
+	for _, c := range ciphertexts {
+		ciphertextByMessage[c.MessageID] = c
+	}
+
+	for _, m := range msgs {
+		m.Attachments = byMessage[m.ID]
+		m.Reactions = reactionsByMessage[m.ID]
+		if c, ok := ciphertextByMessage[m.ID]; ok {
+			m.Ciphertexts = []*domain.MessageCiphertext{c}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessages/Commit: %w", err), InternalError, "")
+	}
+
 	res := domain.MessageToMessageResponse(msgs)
+	d.fillMessageNames(ctx, res)
 
 	return res, nil
 }
+
+func (d *DialogsService) EditMessage(ctx context.Context, req *models.EditMessageRequest, dialogID, messageID, userID int64) error {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("EditMessage/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	msg, err := d.getEditableMessage(ctx, tx, dialogID, messageID, userID)
+	if err != nil {
+		return err
+	}
+	if msg.Nonce != nil {
+		return newServiceError(code400, fmt.Errorf("EditMessage: %s", EncryptedMessageNotEditable), EncryptedMessageNotEditable, "")
+	}
+	if msg.ChainTx != nil {
+		return newServiceError(code400, fmt.Errorf("EditMessage: %s", OnChainMessageNotEditable), OnChainMessageNotEditable, "")
+	}
+
+	editedAt := now.Now().UnixMilli()
+	err = d.repoDialogs.InsertMessageRevision(ctx, tx, &domain.MessageRevision{
+		MessageID: msg.ID,
+		EditorID:  userID,
+		Content:   msg.Content,
+		CreatedAt: editedAt,
+	})
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("EditMessage/InsertMessageRevision: %w", err), InternalError, "")
+	}
+
+	err = d.repoDialogs.UpdateMessageContent(ctx, tx, msg.ID, *req.Content, editedAt)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("EditMessage/UpdateMessageContent: %w", err), InternalError, "")
+	}
+
+	// the signature covers the original content only
+	if err := d.repoDialogs.DeleteMessageSignature(ctx, tx, msg.ID); err != nil {
+		return newServiceError(code500, fmt.Errorf("EditMessage/DeleteMessageSignature: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("EditMessage/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+func (d *DialogsService) DeleteMessage(ctx context.Context, dialogID, messageID, userID int64) error {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("DeleteMessage/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	msg, err := d.getEditableMessage(ctx, tx, dialogID, messageID, userID)
+	if err != nil {
+		return err
+	}
+
+	deletedAt := now.Now().UnixMilli()
+	err = d.repoDialogs.InsertMessageRevision(ctx, tx, &domain.MessageRevision{
+		MessageID: msg.ID,
+		EditorID:  userID,
+		Content:   msg.Content,
+		CreatedAt: deletedAt,
+	})
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("DeleteMessage/InsertMessageRevision: %w", err), InternalError, "")
+	}
+
+	err = d.repoDialogs.MarkMessageDeleted(ctx, tx, msg.ID, deletedAt)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("DeleteMessage/MarkMessageDeleted: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("DeleteMessage/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+func (d *DialogsService) MarkDialogRead(ctx context.Context, req *models.ReadDialogRequest, dialogID, userID int64) error {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("MarkDialogRead/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("MarkDialogRead/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return newServiceError(code403, fmt.Errorf("MarkDialogRead: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	msg, err := d.repoDialogs.GetMessageById(ctx, tx, *req.MessageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return newServiceError(code404, fmt.Errorf("MarkDialogRead/GetMessageById: %w", err), MessageNotExist, "")
+		}
+		return newServiceError(code500, fmt.Errorf("MarkDialogRead/GetMessageById: %w", err), InternalError, "")
+	}
+	if msg.DialogID != dialogID {
+		return newServiceError(code404, fmt.Errorf("MarkDialogRead: %s", MessageNotExist), MessageNotExist, "")
+	}
+
+	err = d.repoDialogs.UpdateLastReadMessage(ctx, tx, dialogID, userID, msg.ID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("MarkDialogRead/UpdateLastReadMessage: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("MarkDialogRead/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+func (d *DialogsService) SearchMessages(ctx context.Context, query string, limit, offset, userID int64) ([]*models.SearchMessagesResponseItems0, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, newServiceError(code400, fmt.Errorf("SearchMessages: %s", InvalidQuery), InvalidQuery, "empty search query")
+	}
+
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("SearchMessages/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	results, err := d.repoDialogs.SearchMessages(ctx, tx, userID, query, limit, offset, now.Now().UnixMilli())
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("SearchMessages/SearchMessages: %w", err), InternalError, "")
+	}
+
+	return domain.SearchResultsToResponse(results), nil
+}
+
+// GetMessageThread returns messageID with a page of the replies below it. A deleted root
+// is returned as a stub so the thread stays reachable.
+func (d *DialogsService) GetMessageThread(ctx context.Context, dialogID, messageID, limit, offset, userID int64) (*models.ThreadResponse, error) {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageThread/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageThread/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("GetMessageThread: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	root, err := d.repoDialogs.GetMessageById(ctx, tx, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("GetMessageThread/GetMessageById: %w", err), MessageNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageThread/GetMessageById: %w", err), InternalError, "")
+	}
+	readAt := now.Now().UnixMilli()
+	if root.DialogID != dialogID || root.Expired(readAt) {
+		return nil, newServiceError(code404, fmt.Errorf("GetMessageThread: %s", MessageNotExist), MessageNotExist, "")
+	}
+
+	replies, total, err := d.repoDialogs.GetMessageThread(ctx, tx, root.ID, limit, offset, readAt)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessageThread/GetMessageThread: %w", err), InternalError, "")
+	}
+
+	return domain.ThreadToResponse(root, replies, total), nil
+}
+
+// checkMessageSignature verifies the sender's wallet signature over the canonical encoding of the message.
+// signedDialogID is 0 when the message starts a new dialog.
+func (d *DialogsService) checkMessageSignature(
+	req *models.SendMessageRequest,
+	signedDialogID int64,
+	sender, recepeint *domain.UserChain,
+) (*domain.MessageSignature, error) {
+	skew := now.Now().Sub(time.UnixMilli(req.SignedAt))
+	if skew < 0 {
+		skew = -skew
+	}
+	if req.SignedAt == 0 || skew > d.cfg.SignedMessageMaxSkew {
+		return nil, newServiceError(code400, fmt.Errorf("checkMessageSignature: %s", SignedAtInvalid), SignedAtInvalid, "")
+	}
+
+	signature := &domain.MessageSignature{
+		DialogID:         signedDialogID,
+		RecipientAddress: strings.ToLower(recepeint.Address.String()),
+		SignedAt:         req.SignedAt,
+		Signature:        req.Signature,
+	}
+	text := domain.SignedMessageText(signature.DialogID, signature.RecipientAddress, signature.SignedAt, *req.Content)
+	if err := verifySignature(text, req.Signature, sender.Address.String()); err != nil {
+		// the session is fine, only the message is rejected
+		return nil, newServiceError(code400, fmt.Errorf("checkMessageSignature/verifySignature: %w", err), WrongSignature, "")
+	}
+
+	return signature, nil
+}
+
+// checkOnChain rejects on-chain messages the contract can't carry. The contract only stores public text.
+func (d *DialogsService) checkOnChain(req *models.SendMessageRequest, uploads []*domain.AttachmentUpload) error {
+	if d.chainClient == nil {
+		return newServiceError(code400, fmt.Errorf("checkOnChain: %s", OnChainDisabled), OnChainDisabled, "")
+	}
+	if *req.Content == "" || req.Encrypted != nil || len(uploads) > 0 {
+		return newServiceError(code400, fmt.Errorf("checkOnChain: %s", OnChainUnsupported), OnChainUnsupported, "")
+	}
+	if req.Relay != nil && !d.cfg.Chain.Relay.Enabled {
+		return newServiceError(code400, fmt.Errorf("checkOnChain: %s", RelayDisabled), RelayDisabled, "")
+	}
+	return nil
+}
+
+// getEditableMessage returns the message only if userID sent it to dialogID,
+// it isn't deleted yet and the configured edit window hasn't passed.
+func (d *DialogsService) getEditableMessage(
+	ctx context.Context,
+	tx repository.Transaction,
+	dialogID, messageID, userID int64,
+) (*domain.Message, error) {
+	msg, err := d.repoDialogs.GetMessageById(ctx, tx, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("getEditableMessage/GetMessageById: %w", err), MessageNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("getEditableMessage/GetMessageById: %w", err), InternalError, "")
+	}
+	if msg.DialogID != dialogID || msg.Expired(now.Now().UnixMilli()) {
+		return nil, newServiceError(code404, fmt.Errorf("getEditableMessage: %s", MessageNotExist), MessageNotExist, "")
+	}
+	if msg.Kind != domain.MessageRegular {
+		return nil, newServiceError(code400, fmt.Errorf("getEditableMessage: %s", SystemMessage), SystemMessage, "")
+	}
+	if msg.SenderID != userID {
+		return nil, newServiceError(code403, fmt.Errorf("getEditableMessage: %s", NotMessageSender), NotMessageSender, "")
+	}
+	if msg.DeletedAt != nil {
+		return nil, newServiceError(code400, fmt.Errorf("getEditableMessage: %s", MessageDeleted), MessageDeleted, "")
+	}
+	if now.Now().Sub(time.UnixMilli(msg.CreatedAt)) >= d.cfg.MessageEditWindow {
+		return nil, newServiceError(code400, fmt.Errorf("getEditableMessage: %s", EditWindowExpired), EditWindowExpired, "")
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/e2e"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+type EncryptionService struct {
+	cfg              *config.ServiceConfig
+	repoUsers        repository.Users
+	repoEncryption   repository.Encryption
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewEncryptionService(
+	cfg *config.ServiceConfig,
+	repoUsers repository.Users,
+	repoEncryption repository.Encryption,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) Encryption {
+
+	return &EncryptionService{
+		cfg:              cfg,
+		repoUsers:        repoUsers,
+		repoEncryption:   repoEncryption,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+// PublishEncryptionKey makes the key the user's current messaging key. The previous key is kept
+// as rotated so messages encrypted to it can still be matched with it.
+func (e *EncryptionService) PublishEncryptionKey(
+	ctx context.Context,
+	req *models.PublishEncryptionKeyRequest,
+	userID int64,
+) (*models.EncryptionKey, error) {
+	if _, err := e2e.ParsePublicKey(*req.PublicKey); err != nil {
+		return nil, newServiceError(code400, fmt.Errorf("PublishEncryptionKey/ParsePublicKey: %w", err), EncryptionKeyInvalid, "")
+	}
+
+	tx, err := e.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("PublishEncryptionKey/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := e.repoUsers.GetUserById(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("PublishEncryptionKey/GetUserById: %w", err), InternalError, "")
+	}
+
+	publicKey := strings.ToLower(*req.PublicKey)
+	if err := verifySignature(e2e.SignedKeyMessage(publicKey), *req.Signature, user.Address.String()); err != nil {
+		return nil, err
+	}
+
+	key := &domain.EncryptionKey{
+		UserID:    userID,
+		PublicKey: publicKey,
+		Signature: *req.Signature,
+		CreatedAt: now.Now().UnixMilli(),
+	}
+	if err := e.repoEncryption.RotateEncryptionKey(ctx, tx, userID, key.CreatedAt); err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("PublishEncryptionKey/RotateEncryptionKey: %w", err), InternalError, "")
+	}
+	key.ID, err = e.repoEncryption.InsertEncryptionKey(ctx, tx, key)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("PublishEncryptionKey/InsertEncryptionKey: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("PublishEncryptionKey/Commit: %w", err), InternalError, "")
+	}
+
+	return domain.EncryptionKeysToResponse([]*domain.EncryptionKey{key})[0], nil
+}
+
+func (e *EncryptionService) GetEncryptionKeys(ctx context.Context, address string) ([]*models.EncryptionKey, error) {
+	tx, err := e.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetEncryptionKeys/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := e.repoUsers.GetUserByAddress(ctx, tx, strings.ToLower(address))
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("GetEncryptionKeys/GetUserByAddress: %w", err), UserNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("GetEncryptionKeys/GetUserByAddress: %w", err), InternalError, "")
+	}
+
+	keys, err := e.repoEncryption.GetEncryptionKeys(ctx, tx, user.ID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetEncryptionKeys/GetEncryptionKeys: %w", err), InternalError, "")
+	}
+
+	return domain.EncryptionKeysToResponse(keys), nil
+}
+
+// checkEncryptedPayload validates an encrypted message before it is stored. The server never sees the
+// plaintext, so it only checks that both participants get a ciphertext under their current key.
+func (d *DialogsService) checkEncryptedPayload(
+	ctx context.Context,
+	tx repository.Transaction,
+	req *models.SendMessageRequest,
+	sender, recepeint *domain.UserChain,
+) ([]*domain.MessageCiphertext, error) {
+	invalid := func(detail string) error {
+		return newServiceError(code400, fmt.Errorf("checkEncryptedPayload: %s: %s", EncryptedPayloadInvalid, detail),
+			EncryptedPayloadInvalid, detail)
+	}
+
+	if *req.Content != "" {
+		return nil, invalid("content must be empty for encrypted messages")
+	}
+	if err := e2e.ValidateNonce(*req.Encrypted.Nonce); err != nil {
+		return nil, invalid(err.Error())
+	}
+
+	participants := map[string]*domain.UserChain{
+		strings.ToLower(sender.Address.String()):    sender,
+		strings.ToLower(recepeint.Address.String()): recepeint,
+	}
+	if len(req.Encrypted.Ciphertexts) != len(participants) {
+		return nil, invalid("a ciphertext is required for every dialog participant")
+	}
+
+	ciphertexts := make([]*domain.MessageCiphertext, 0, len(participants))
+	for _, c := range req.Encrypted.Ciphertexts {
+		if c == nil {
+			return nil, invalid("empty ciphertext")
+		}
+		address := strings.ToLower(*c.Address)
+		user, ok := participants[address]
+		if !ok {
+			return nil, invalid(fmt.Sprintf("unexpected or repeated ciphertext for %s", *c.Address))
+		}
+		delete(participants, address)
+
+		key, err := d.repoEncryption.GetCurrentEncryptionKey(ctx, tx, user.ID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNoRows) {
+				return nil, newServiceError(code400, fmt.Errorf("checkEncryptedPayload/GetCurrentEncryptionKey: %w", err),
+					EncryptionKeyNotExist, *c.Address)
+			}
+			return nil, newServiceError(code500, fmt.Errorf("checkEncryptedPayload/GetCurrentEncryptionKey: %w", err), InternalError, "")
+		}
+		if key.ID != *c.KeyID {
+			return nil, invalid(fmt.Sprintf("key %d is not the current key of %s", *c.KeyID, *c.Address))
+		}
+
+		ciphertexts = append(ciphertexts, &domain.MessageCiphertext{
+			RecipientID: user.ID,
+			KeyID:       key.ID,
+			Ciphertext:  *c.Ciphertext,
+		})
+	}
+
+	return ciphertexts, nil
+}
@@ -8,6 +8,11 @@ const (
 	code500 = http.StatusInternalServerError
 	code400 = http.StatusBadRequest
 	code401 = http.StatusUnauthorized
+	code402 = http.StatusPaymentRequired
+	code403 = http.StatusForbidden
+	code404 = http.StatusNotFound
+	code409 = http.StatusConflict
+	code429 = http.StatusTooManyRequests
 
 	InternalError       = "internal error"
 	UserNotExist        = "user doesn't exist"
@@ -20,10 +25,76 @@ const (
 	AuthMessageExpired = "auth message expired"
 	WrongSignature     = "wrong signature"
 	EcrecoverFailed    = "ecrecover failed"
+	ChainNotSupported  = "chain is not supported"
 	CreateUserFailed   = "create user failed"
 	InvalidBody        = "invalid body data"
 	BadRequest         = "Bad Request"
 	InvalidQuery       = "invalid query data"
+
+	MessageNotExist      = "message doesn't exist"
+	MessageDeleted       = "message is deleted"
+	NotMessageSender     = "only the sender can change the message"
+	EditWindowExpired    = "message edit window expired"
+	NotDialogParticipant = "not a dialog participant"
+	ReactionNotAllowed   = "reaction is not allowed"
+	ReplyNotInDialog     = "replied message is not in this dialog"
+	SignedAtInvalid      = "signed message timestamp is missing or too far from the server time"
+	SystemMessage        = "system messages can't be changed"
+	RetentionInvalid     = "invalid retention timer"
+
+	MessageNotDelivered = "message can't be delivered to this user"
+	RecipientBlocked    = "you have blocked this user"
+	CannotBlockSelf     = "you can't block yourself"
+
+	TokenGatesDisabled = "token gates are not configured"
+	TokenGateInvalid   = "invalid token gate"
+	TokenGateNotMet    = "this user only accepts new dialogs from holders of their tokens"
+
+	PaymentsDisabled    = "message payments are not configured"
+	MessagePriceInvalid = "invalid message price"
+	PaymentRequired     = "this user only accepts new dialogs with a payment"
+	PaymentInvalid      = "payment transaction doesn't pay this user's price"
+	PaymentNotConfirmed = "payment transaction is not confirmed yet"
+	PaymentTxUsed       = "payment transaction was already used"
+
+	RecipientAddressInvalid   = "invalid recipient address"
+	PendingMessageUnsupported = "only non-empty text messages can be sent to unregistered addresses"
+	PendingInboxQuotaExceeded = "too many messages to unregistered addresses"
+	IdempotencyKeyReused      = "client message id was already used for a different message"
+
+	NamesDisabled         = "names are not configured"
+	RecipientNameInvalid  = "invalid recipient name"
+	RecipientNameNotFound = "recipient name doesn't resolve to an address"
+
+	EncryptionKeyNotExist       = "user has no messaging key"
+	EncryptionKeyInvalid        = "invalid messaging key"
+	EncryptedPayloadInvalid     = "invalid encrypted payload"
+	EncryptedMessageNotEditable = "encrypted messages can't be edited"
+
+	OnChainDisabled           = "on-chain sending is not configured"
+	OnChainUnsupported        = "only plain text messages can be sent on-chain"
+	OnChainMessageNotEditable = "on-chain messages can't be edited"
+	MessageNotAnchored        = "message is not anchored yet"
+
+	RelayDisabled        = "gasless messages are not configured"
+	RelayNotOnChain      = "relay is only accepted for on-chain messages"
+	RelayDeadlineInvalid = "relay signature deadline has passed or is too close"
+	RelayNonceUsed       = "relay nonce was already used"
+	RelayQuotaExceeded   = "too many gasless messages"
+
+	NotMessageRequest   = "dialog is not a message request"
+	MessageRequestLimit = "message request is not accepted yet"
+
+	AttachmentNotExist       = "attachment doesn't exist"
+	AttachmentLinkInvalid    = "attachment link is invalid or expired"
+	AttachmentTooLarge       = "attachment is too large"
+	AttachmentTypeNotAllowed = "attachment type is not allowed"
+	TooManyAttachments       = "too many attachments"
+
+	LoginSessionNotExist   = "login session doesn't exist"
+	LoginSessionExpired    = "login session expired"
+	LoginSessionUsed       = "login session is already used"
+	LoginSessionWrongToken = "wrong login session poll token"
 )
 
 // error struct
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+// claimIdempotencyKey reserves req.ClientMessageID for userID. If the key was already used it returns
+// the original result, or 409 when the retry doesn't carry the same request.
+func (d *DialogsService) claimIdempotencyKey(
+	ctx context.Context,
+	tx repository.Transaction,
+	req *models.SendMessageRequest,
+	uploads []*domain.AttachmentUpload,
+	userID int64,
+) (*models.SendMessageResponse, error) {
+	requestHash, err := sendMessageFingerprint(req, uploads)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("claimIdempotencyKey/sendMessageFingerprint: %w", err), InternalError, "")
+	}
+
+	createdAt := now.Now()
+	if err := d.repoIdempotency.DeleteExpiredIdempotencyKeys(ctx, tx, userID, createdAt.Add(-d.cfg.IdempotencyKeyTTL).UnixMilli()); err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("claimIdempotencyKey/DeleteExpiredIdempotencyKeys: %w", err), InternalError, "")
+	}
+
+	claimed, err := d.repoIdempotency.ClaimIdempotencyKey(ctx, tx, &domain.IdempotencyKey{
+		SenderID:    userID,
+		Key:         req.ClientMessageID,
+		RequestHash: requestHash,
+		CreatedAt:   createdAt.UnixMilli(),
+	})
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("claimIdempotencyKey/ClaimIdempotencyKey: %w", err), InternalError, "")
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	key, err := d.repoIdempotency.GetIdempotencyKey(ctx, tx, userID, req.ClientMessageID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("claimIdempotencyKey/GetIdempotencyKey: %w", err), InternalError, "")
+	}
+	if key.RequestHash != requestHash {
+		return nil, newServiceError(code409, fmt.Errorf("claimIdempotencyKey: %s", IdempotencyKeyReused), IdempotencyKeyReused, "")
+	}
+
+	success := true
+	res := &models.SendMessageResponse{Success: &success}
+	if key.MessageID != nil {
+		res.MessageID = *key.MessageID
+	}
+	if key.PendingMessageID != nil {
+		res.PendingMessageID = *key.PendingMessageID
+	}
+
+	return res, nil
+}
+
+// saveIdempotencyResult records the outcome of the send under the claimed key, it's a no-op without one.
+func (d *DialogsService) saveIdempotencyResult(
+	ctx context.Context,
+	tx repository.Transaction,
+	req *models.SendMessageRequest,
+	res *models.SendMessageResponse,
+	userID int64,
+) error {
+	if req.ClientMessageID == "" {
+		return nil
+	}
+
+	key := &domain.IdempotencyKey{
+		SenderID: userID,
+		Key:      req.ClientMessageID,
+	}
+	if res.MessageID != 0 {
+		key.MessageID = &res.MessageID
+	}
+	if res.PendingMessageID != 0 {
+		key.PendingMessageID = &res.PendingMessageID
+	}
+	if err := d.repoIdempotency.SetIdempotencyKeyResult(ctx, tx, key); err != nil {
+		return newServiceError(code500, fmt.Errorf("saveIdempotencyResult/SetIdempotencyKeyResult: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+// sendMessageFingerprint hashes everything the client sent except the idempotency key itself.
+func sendMessageFingerprint(req *models.SendMessageRequest, uploads []*domain.AttachmentUpload) (string, error) {
+	body := *req
+	body.ClientMessageID = ""
+	data, err := json.Marshal(&body)
+	if err != nil {
+		return "", fmt.Errorf("Marshal: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(data)
+	for _, u := range uploads {
+		fmt.Fprintf(h, "\x00%s\x00%d\x00", u.FileName, u.Size)
+		if _, err := io.Copy(h, u.Content); err != nil {
+			return "", fmt.Errorf("Copy: %w", err)
+		}
+		if _, err := u.Content.Seek(0, io.SeekStart); err != nil {
+			return "", fmt.Errorf("Seek: %w", err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
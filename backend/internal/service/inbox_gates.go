@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type InboxGatesService struct {
+	cfg              *config.ServiceConfig
+	repoUsers        repository.Users
+	repoInboxGates   repository.InboxGates
+	repoTransactions repository.Transactions
+	// holdings is nil when no chain node is configured, gates can't be set then
+	holdings chain.Holdings
+
+	logging logger.Logger
+}
+
+func NewInboxGatesService(
+	cfg *config.ServiceConfig,
+	repoUsers repository.Users,
+	repoInboxGates repository.InboxGates,
+	repoTransactions repository.Transactions,
+	holdings chain.Holdings,
+
+	logging logger.Logger) InboxGates {
+
+	return &InboxGatesService{
+		cfg:              cfg,
+		repoUsers:        repoUsers,
+		repoInboxGates:   repoInboxGates,
+		repoTransactions: repoTransactions,
+		holdings:         holdings,
+
+		logging: logging,
+	}
+}
+
+func (g *InboxGatesService) GetInboxGates(ctx context.Context, userID int64) ([]*models.InboxGate, error) {
+	tx, err := g.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetInboxGates/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	gates, err := g.repoInboxGates.GetInboxGates(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetInboxGates/GetInboxGates: %w", err), InternalError, "")
+	}
+
+	return domain.InboxGatesToResponse(gates), nil
+}
+
+// GetUserInboxGates lets a sender see which tokens a user requires before messaging them.
+func (g *InboxGatesService) GetUserInboxGates(ctx context.Context, address string) ([]*models.InboxGate, error) {
+	tx, err := g.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetUserInboxGates/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := g.repoUsers.GetUserByAddress(ctx, tx, strings.ToLower(address))
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("GetUserInboxGates/GetUserByAddress: %w", err), UserNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("GetUserInboxGates/GetUserByAddress: %w", err), InternalError, "")
+	}
+
+	gates, err := g.repoInboxGates.GetInboxGates(ctx, tx, user.ID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetUserInboxGates/GetInboxGates: %w", err), InternalError, "")
+	}
+
+	return domain.InboxGatesToResponse(gates), nil
+}
+
+// UpdateInboxGates replaces the user's gates, an empty list opens the inbox again. Every token
+// has to answer balanceOf, so a typo in an address doesn't lock the inbox for everyone.
+func (g *InboxGatesService) UpdateInboxGates(ctx context.Context, req *models.InboxGatesRequest, userID int64) error {
+	if g.holdings == nil {
+		return newServiceError(code400, fmt.Errorf("UpdateInboxGates: %s", TokenGatesDisabled), TokenGatesDisabled, "")
+	}
+	if int64(len(req.Gates)) > g.cfg.TokenGate.MaxGates {
+		return newServiceError(code400, fmt.Errorf("UpdateInboxGates: %s", TokenGateInvalid), TokenGateInvalid,
+			fmt.Sprintf("at most %d gates are allowed", g.cfg.TokenGate.MaxGates))
+	}
+
+	var (
+		createdAt = now.Now().UnixMilli()
+		gates     = make([]*domain.InboxGate, 0, len(req.Gates))
+		seen      = make(map[common.Address]bool, len(req.Gates))
+	)
+	for _, r := range req.Gates {
+		gate, err := parseInboxGate(r)
+		if err != nil {
+			return newServiceError(code400, fmt.Errorf("UpdateInboxGates/parseInboxGate: %w", err), TokenGateInvalid, err.Error())
+		}
+		token := common.HexToAddress(gate.TokenAddress)
+		if seen[token] {
+			return newServiceError(code400, fmt.Errorf("UpdateInboxGates: duplicate token %s", token), TokenGateInvalid,
+				fmt.Sprintf("token %s is gated twice", token))
+		}
+		seen[token] = true
+
+		// ERC-721 reverts balanceOf of the zero address, the token's own address is a holder both standards answer for
+		if _, err := g.holdings.BalanceOf(ctx, token, token); err != nil {
+			return newServiceError(code400, fmt.Errorf("UpdateInboxGates/BalanceOf: %w", err), TokenGateInvalid,
+				fmt.Sprintf("%s doesn't answer balanceOf", token))
+		}
+		gate.UserID, gate.CreatedAt = userID, createdAt
+		gates = append(gates, gate)
+	}
+
+	tx, err := g.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateInboxGates/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := g.repoInboxGates.ReplaceInboxGates(ctx, tx, userID, gates); err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateInboxGates/ReplaceInboxGates: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("UpdateInboxGates/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+// parseInboxGate checks the token address and min_balance, which defaults to a single token.
+func parseInboxGate(req *models.InboxGate) (*domain.InboxGate, error) {
+	if !common.IsHexAddress(*req.TokenAddress) {
+		return nil, fmt.Errorf("invalid token address %q", *req.TokenAddress)
+	}
+	standard, ok := domain.ParseTokenStandard(*req.Standard)
+	if !ok {
+		return nil, fmt.Errorf("unknown token standard %q", *req.Standard)
+	}
+
+	minBalance := big.NewInt(1)
+	if req.MinBalance != "" {
+		if _, ok := minBalance.SetString(req.MinBalance, 10); !ok || minBalance.Sign() <= 0 || minBalance.BitLen() > 256 {
+			return nil, fmt.Errorf("min_balance %q is not a positive integer", req.MinBalance)
+		}
+	}
+
+	return &domain.InboxGate{
+		TokenAddress: common.HexToAddress(*req.TokenAddress).Hex(),
+		Standard:     standard,
+		MinBalance:   minBalance.String(),
+	}, nil
+}
+
+// checkInboxGates rejects a sender opening a dialog with a recipient whose gates they don't meet.
+// Unlike privacy rejections the error is explicit: gates are public and the sender can still qualify.
+func checkInboxGates(
+	ctx context.Context,
+	tx repository.Transaction,
+	repoInboxGates repository.InboxGates,
+	holdings chain.Holdings,
+	sender common.Address,
+	recipientID int64,
+) error {
+	gates, err := repoInboxGates.GetInboxGates(ctx, tx, recipientID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("checkInboxGates/GetInboxGates: %w", err), InternalError, "")
+	}
+	if len(gates) == 0 {
+		return nil
+	}
+	if holdings == nil {
+		return newServiceError(code500, fmt.Errorf("checkInboxGates: %s", TokenGatesDisabled), InternalError, "")
+	}
+
+	ok, err := meetsInboxGates(ctx, holdings, gates, sender)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("checkInboxGates/meetsInboxGates: %w", err), InternalError, "")
+	}
+	if !ok {
+		return newServiceError(code403, fmt.Errorf("checkInboxGates: %s", TokenGateNotMet), TokenGateNotMet, "")
+	}
+
+	return nil
+}
+
+// meetsInboxGates reports whether holder has at least the minimum balance of any of the gated tokens.
+func meetsInboxGates(ctx context.Context, holdings chain.Holdings, gates []*domain.InboxGate, holder common.Address) (bool, error) {
+	for _, g := range gates {
+		minBalance, ok := new(big.Int).SetString(g.MinBalance, 10)
+		if !ok {
+			return false, fmt.Errorf("gate %d: invalid min balance %q", g.ID, g.MinBalance)
+		}
+		balance, err := holdings.BalanceOf(ctx, common.HexToAddress(g.TokenAddress), holder)
+		if err != nil {
+			return false, fmt.Errorf("gate %d: %w", g.ID, err)
+		}
+		if balance.Cmp(minBalance) >= 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// TokenGateWorker rechecks the other participants of gated users' dialogs. A dialog whose
+// participant no longer meets the gates is flagged for the gate owner, the flag is cleared once they qualify again.
+type TokenGateWorker struct {
+	cfg              *config.TokenGateConfig
+	holdings         chain.Holdings
+	repoInboxGates   repository.InboxGates
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewTokenGateWorker(
+	cfg *config.TokenGateConfig,
+	holdings chain.Holdings,
+	repoInboxGates repository.InboxGates,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) *TokenGateWorker {
+
+	return &TokenGateWorker{
+		cfg:              cfg,
+		holdings:         holdings,
+		repoInboxGates:   repoInboxGates,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+func (w *TokenGateWorker) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := w.recheck(); err != nil {
+				w.logging.Errorf("TokenGateWorker/recheck: %v", err)
+			}
+		}
+	}
+}
+
+// recheck checks a batch of the dialogs checked longest ago. A dialog whose balances can't be
+// read is left for the next tick.
+func (w *TokenGateWorker) recheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.WorkerInterval)
+	defer cancel()
+
+	tx, err := w.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("recheck/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	checkedAt := now.Now().UnixMilli()
+	dialogs, err := w.repoInboxGates.GetGatedDialogsToCheck(ctx, tx, checkedAt-w.cfg.RecheckInterval.Milliseconds(), w.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("recheck/GetGatedDialogsToCheck: %w", err)
+	}
+
+	gatesByOwner := make(map[int64][]*domain.InboxGate)
+	for _, d := range dialogs {
+		gates, ok := gatesByOwner[d.OwnerID]
+		if !ok {
+			gates, err = w.repoInboxGates.GetInboxGates(ctx, tx, d.OwnerID)
+			if err != nil {
+				return fmt.Errorf("recheck/GetInboxGates: %w", err)
+			}
+			gatesByOwner[d.OwnerID] = gates
+		}
+
+		qualifies, err := meetsInboxGates(ctx, w.holdings, gates, common.HexToAddress(d.CounterpartAddress))
+		if err != nil {
+			w.logging.Errorf("TokenGateWorker/recheck: dialog %d: %v", d.DialogID, err)
+			continue
+		}
+		switch {
+		case qualifies:
+			d.GateFailedAt = nil
+		case d.GateFailedAt == nil:
+			d.GateFailedAt = &checkedAt
+		}
+		if err := w.repoInboxGates.UpdateDialogGateCheck(ctx, tx, d, checkedAt); err != nil {
+			return fmt.Errorf("recheck/UpdateDialogGateCheck: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("recheck/Commit: %w", err)
+	}
+
+	return nil
+}
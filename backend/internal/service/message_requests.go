@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+func (d *DialogsService) AcceptMessageRequest(ctx context.Context, dialogID, userID int64) error {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("AcceptMessageRequest/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := d.getIncomingMessageRequest(ctx, tx, dialogID, userID); err != nil {
+		return err
+	}
+
+	if err := d.repoDialogs.UpdateDialogStatus(ctx, tx, dialogID, domain.DialogAccepted); err != nil {
+		return newServiceError(code500, fmt.Errorf("AcceptMessageRequest/UpdateDialogStatus: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("AcceptMessageRequest/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+// DeclineMessageRequest hides the request from the recipient. The sender isn't notified, but their later messages
+// are refused with the error of a block or a privacy rejection, which they can't tell a decline from.
+func (d *DialogsService) DeclineMessageRequest(ctx context.Context, dialogID, userID int64) error {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("DeclineMessageRequest/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := d.getIncomingMessageRequest(ctx, tx, dialogID, userID); err != nil {
+		return err
+	}
+
+	if err := d.repoDialogs.UpdateDialogStatus(ctx, tx, dialogID, domain.DialogDeclined); err != nil {
+		return newServiceError(code500, fmt.Errorf("DeclineMessageRequest/UpdateDialogStatus: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("DeclineMessageRequest/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+// ReportMessageRequest records a report against the sender, declines the request and blocks the sender.
+func (d *DialogsService) ReportMessageRequest(ctx context.Context, req *models.ReportMessageRequest, dialogID, userID int64) error {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("ReportMessageRequest/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	request, err := d.getIncomingMessageRequest(ctx, tx, dialogID, userID)
+	if err != nil {
+		return err
+	}
+
+	reportedAt := now.Now().UnixMilli()
+	err = d.repoDialogs.InsertDialogReport(ctx, tx, &domain.DialogReport{
+		DialogID:       dialogID,
+		ReporterID:     userID,
+		ReportedUserID: *request.RequestedBy,
+		Reason:         req.Reason,
+		CreatedAt:      reportedAt,
+	})
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("ReportMessageRequest/InsertDialogReport: %w", err), InternalError, "")
+	}
+
+	if err := d.repoDialogs.UpdateDialogStatus(ctx, tx, dialogID, domain.DialogDeclined); err != nil {
+		return newServiceError(code500, fmt.Errorf("ReportMessageRequest/UpdateDialogStatus: %w", err), InternalError, "")
+	}
+
+	if err := d.repoPrivacy.BlockUser(ctx, tx, userID, *request.RequestedBy, reportedAt); err != nil {
+		return newServiceError(code500, fmt.Errorf("ReportMessageRequest/BlockUser: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("ReportMessageRequest/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+// getIncomingMessageRequest returns the dialog's request if userID is the one who received it
+// and hasn't accepted it yet.
+func (d *DialogsService) getIncomingMessageRequest(
+	ctx context.Context,
+	tx repository.Transaction,
+	dialogID, userID int64,
+) (*domain.DialogRequest, error) {
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("getIncomingMessageRequest/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("getIncomingMessageRequest: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	request, err := d.repoDialogs.GetDialogRequest(ctx, tx, dialogID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("getIncomingMessageRequest/GetDialogRequest: %w", err), NotMessageRequest, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("getIncomingMessageRequest/GetDialogRequest: %w", err), InternalError, "")
+	}
+	if request.Status == domain.DialogAccepted || request.RequestedBy == nil || *request.RequestedBy == userID {
+		return nil, newServiceError(code400, fmt.Errorf("getIncomingMessageRequest: %s", NotMessageRequest), NotMessageRequest, "")
+	}
+
+	return request, nil
+}
+
+// checkMessageRequest is called before userID sends a message to an existing dialog. Until the request is
+// accepted its sender can send at most MaxPendingMessages messages, a reply from the recipient accepts it.
+func (d *DialogsService) checkMessageRequest(ctx context.Context, tx repository.Transaction, dialogID, userID int64) error {
+	request, err := d.repoDialogs.GetDialogRequest(ctx, tx, dialogID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("checkMessageRequest/GetDialogRequest: %w", err), InternalError, "")
+	}
+	if request.Status == domain.DialogAccepted {
+		return nil
+	}
+
+	if request.RequestedBy == nil || *request.RequestedBy != userID {
+		if err := d.repoDialogs.UpdateDialogStatus(ctx, tx, dialogID, domain.DialogAccepted); err != nil {
+			return newServiceError(code500, fmt.Errorf("checkMessageRequest/UpdateDialogStatus: %w", err), InternalError, "")
+		}
+		return nil
+	}
+
+	if request.Status == domain.DialogDeclined {
+		return newServiceError(code403, fmt.Errorf("checkMessageRequest: request declined: %s", MessageNotDelivered),
+			MessageNotDelivered, "")
+	}
+
+	sent, err := d.repoDialogs.CountMessagesBySender(ctx, tx, dialogID, userID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("checkMessageRequest/CountMessagesBySender: %w", err), InternalError, "")
+	}
+	if sent >= d.cfg.MaxPendingMessages {
+		return newServiceError(code403, fmt.Errorf("checkMessageRequest: %s", MessageRequestLimit), MessageRequestLimit,
+			fmt.Sprintf("at most %d messages can be sent until the recipient accepts the request", d.cfg.MaxPendingMessages))
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// resolveRecipient replaces a name in recipient_id with the address it resolves to, so the rest of
+// SendMessage only deals with addresses. Anything that isn't an address is taken for a name.
+func (d *DialogsService) resolveRecipient(ctx context.Context, req *models.SendMessageRequest) error {
+	if common.IsHexAddress(*req.RecipientID) || !strings.Contains(*req.RecipientID, ".") {
+		return nil
+	}
+	if d.names == nil {
+		return newServiceError(code400, fmt.Errorf("resolveRecipient: %s", NamesDisabled), NamesDisabled, "")
+	}
+
+	address, err := d.names.Resolve(ctx, *req.RecipientID)
+	if err != nil {
+		switch {
+		case errors.Is(err, chain.ErrInvalidName):
+			return newServiceError(code400, fmt.Errorf("resolveRecipient/Resolve: %w", err), RecipientNameInvalid, "")
+		case errors.Is(err, chain.ErrNameNotFound):
+			return newServiceError(code404, fmt.Errorf("resolveRecipient/Resolve: %w", err), RecipientNameNotFound, "")
+		}
+		return newServiceError(code500, fmt.Errorf("resolveRecipient/Resolve: %w", err), InternalError, "")
+	}
+
+	recipient := strings.ToLower(address.Hex())
+	req.RecipientID = &recipient
+	return nil
+}
+
+// nameLookupConcurrency bounds the reverse lookups of one request that run at the same time.
+const nameLookupConcurrency = 8
+
+// lookupNames returns the primary names of addresses, addresses without a verified name are left out.
+// Names are only decoration, so the lookups that fail or don't finish within cfg.Names.LookupTimeout
+// are logged and left out instead of failing the request.
+func (d *DialogsService) lookupNames(ctx context.Context, addresses []string) map[string]string {
+	res := make(map[string]string)
+	if d.names == nil {
+		return res
+	}
+
+	unique := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		address = strings.ToLower(address)
+		if common.IsHexAddress(address) {
+			unique[address] = struct{}{}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Names.LookupTimeout)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		sem      = make(chan struct{}, nameLookupConcurrency)
+		failed   int
+		firstErr error
+	)
+	for address := range unique {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			name, err := d.names.Lookup(ctx, common.HexToAddress(address))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				res[address] = name
+			case !errors.Is(err, chain.ErrNameNotFound):
+				if failed == 0 {
+					firstErr = fmt.Errorf("%s: %w", address, err)
+				}
+				failed++
+			}
+		}(address)
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		d.logging.Errorf("lookupNames/Lookup: %d of %d addresses failed: %v", failed, len(unique), firstErr)
+	}
+
+	return res
+}
+
+func (d *DialogsService) fillDialogNames(ctx context.Context, dialogs []*models.DialogsResponseItems0) {
+	var addresses []string
+	for _, v := range dialogs {
+		addresses = append(addresses, v.RecepeintAddress)
+		if v.LastMessage != nil {
+			addresses = append(addresses, v.LastMessage.SenderAddress)
+		}
+	}
+
+	names := d.lookupNames(ctx, addresses)
+	for _, v := range dialogs {
+		v.RecepeintName = names[strings.ToLower(v.RecepeintAddress)]
+		if v.LastMessage != nil {
+			v.LastMessage.SenderName = names[strings.ToLower(v.LastMessage.SenderAddress)]
+		}
+	}
+}
+
+func (d *DialogsService) fillMessageNames(ctx context.Context, msgs []*models.MessagesResponseItems0) {
+	var addresses []string
+	for _, v := range msgs {
+		addresses = append(addresses, v.SenderAddress)
+		if v.ReplyTo != nil {
+			addresses = append(addresses, v.ReplyTo.SenderAddress)
+		}
+	}
+
+	names := d.lookupNames(ctx, addresses)
+	for _, v := range msgs {
+		v.SenderName = names[strings.ToLower(v.SenderAddress)]
+		if v.ReplyTo != nil {
+			v.ReplyTo.SenderName = names[strings.ToLower(v.ReplyTo.SenderAddress)]
+		}
+	}
+}
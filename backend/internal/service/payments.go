@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type PaymentsService struct {
+	cfg              *config.ServiceConfig
+	repoUsers        repository.Users
+	repoPayments     repository.Payments
+	repoTransactions repository.Transactions
+	// payments and holdings are nil when no chain node is configured, prices can't be set then
+	payments chain.Payments
+	holdings chain.Holdings
+
+	logging logger.Logger
+}
+
+func NewPaymentsService(
+	cfg *config.ServiceConfig,
+	repoUsers repository.Users,
+	repoPayments repository.Payments,
+	repoTransactions repository.Transactions,
+	payments chain.Payments,
+	holdings chain.Holdings,
+
+	logging logger.Logger) Payments {
+
+	return &PaymentsService{
+		cfg:              cfg,
+		repoUsers:        repoUsers,
+		repoPayments:     repoPayments,
+		repoTransactions: repoTransactions,
+		payments:         payments,
+		holdings:         holdings,
+
+		logging: logging,
+	}
+}
+
+func (p *PaymentsService) GetMessagePrice(ctx context.Context, userID int64) (*models.MessagePrice, error) {
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessagePrice/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	price, err := p.repoPayments.GetMessagePrice(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetMessagePrice/GetMessagePrice: %w", err), InternalError, "")
+	}
+
+	return p.priceToResponse(price), nil
+}
+
+// GetUserMessagePrice tells a sender what to pay, and where to, before messaging a user.
+func (p *PaymentsService) GetUserMessagePrice(ctx context.Context, address string) (*models.MessagePrice, error) {
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetUserMessagePrice/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := p.repoUsers.GetUserByAddress(ctx, tx, strings.ToLower(address))
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("GetUserMessagePrice/GetUserByAddress: %w", err), UserNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("GetUserMessagePrice/GetUserByAddress: %w", err), InternalError, "")
+	}
+
+	price, err := p.repoPayments.GetMessagePrice(ctx, tx, user.ID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetUserMessagePrice/GetMessagePrice: %w", err), InternalError, "")
+	}
+
+	return p.priceToResponse(price), nil
+}
+
+// UpdateMessagePrice sets the price strangers pay to open a dialog, an amount of "0" removes it.
+// A token has to answer balanceOf, like inbox gates, so a typo doesn't make the inbox unpayable.
+func (p *PaymentsService) UpdateMessagePrice(ctx context.Context, req *models.MessagePriceRequest, userID int64) error {
+	if p.payments == nil {
+		return newServiceError(code400, fmt.Errorf("UpdateMessagePrice: %s", PaymentsDisabled), PaymentsDisabled, "")
+	}
+
+	amount, ok := new(big.Int).SetString(*req.Amount, 10)
+	if !ok || amount.Sign() < 0 || amount.BitLen() > 256 {
+		return newServiceError(code400, fmt.Errorf("UpdateMessagePrice: invalid amount %q", *req.Amount), MessagePriceInvalid,
+			"amount should be a non-negative integer in wei or the token's base units")
+	}
+
+	var price *domain.MessagePrice
+	if amount.Sign() > 0 {
+		price = &domain.MessagePrice{Amount: amount.String()}
+		if req.TokenAddress != "" {
+			if !common.IsHexAddress(req.TokenAddress) {
+				return newServiceError(code400, fmt.Errorf("UpdateMessagePrice: invalid token address %q", req.TokenAddress),
+					MessagePriceInvalid, fmt.Sprintf("invalid token address %q", req.TokenAddress))
+			}
+			token := common.HexToAddress(req.TokenAddress)
+			if _, err := p.holdings.BalanceOf(ctx, token, token); err != nil {
+				return newServiceError(code400, fmt.Errorf("UpdateMessagePrice/BalanceOf: %w", err), MessagePriceInvalid,
+					fmt.Sprintf("%s doesn't answer balanceOf", token))
+			}
+			tokenAddress := token.Hex()
+			price.TokenAddress = &tokenAddress
+		}
+	}
+
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateMessagePrice/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := p.repoPayments.UpdateMessagePrice(ctx, tx, userID, price); err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateMessagePrice/UpdateMessagePrice: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("UpdateMessagePrice/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+func (p *PaymentsService) priceToResponse(price *domain.MessagePrice) *models.MessagePrice {
+	var escrow string
+	if p.payments != nil && p.payments.Escrow() != (common.Address{}) {
+		escrow = p.payments.Escrow().Hex()
+	}
+	return domain.MessagePriceToResponse(price, escrow, p.cfg.Payments.Confirmations)
+}
+
+// checkMessagePayment makes a sender opening a dialog with a recipient who has a price pay it first.
+// The payment is returned unsaved, it's stored along with the message.
+func checkMessagePayment(
+	ctx context.Context,
+	tx repository.Transaction,
+	repoPayments repository.Payments,
+	payments chain.Payments,
+	cfg *config.PaymentsConfig,
+	txHash string,
+	sender, recipient *domain.UserChain,
+) (*domain.MessagePayment, error) {
+	price, err := repoPayments.GetMessagePrice(ctx, tx, recipient.ID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("checkMessagePayment/GetMessagePrice: %w", err), InternalError, "")
+	}
+	if price == nil {
+		return nil, nil
+	}
+	if payments == nil {
+		return nil, newServiceError(code500, fmt.Errorf("checkMessagePayment: %s", PaymentsDisabled), InternalError, "")
+	}
+	if txHash == "" {
+		return nil, newServiceError(code402, fmt.Errorf("checkMessagePayment: %s", PaymentRequired), PaymentRequired, "")
+	}
+	if hashBytes, err := hexutil.Decode(txHash); err != nil || len(hashBytes) != common.HashLength {
+		return nil, newServiceError(code400, fmt.Errorf("checkMessagePayment: invalid tx hash %q", txHash), InvalidBody,
+			"payment_tx_hash should be a 0x-prefixed 32 byte hex string")
+	}
+	hash := common.HexToHash(txHash)
+
+	used, err := repoPayments.IsPaymentTxUsed(ctx, tx, hash.Hex())
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("checkMessagePayment/IsPaymentTxUsed: %w", err), InternalError, "")
+	}
+	if used {
+		return nil, newServiceError(code409, fmt.Errorf("checkMessagePayment: %s", PaymentTxUsed), PaymentTxUsed, "")
+	}
+
+	payment, err := payments.Payment(ctx, hash)
+	switch {
+	case errors.Is(err, chain.ErrTxNotMined):
+		return nil, newServiceError(code409, fmt.Errorf("checkMessagePayment/Payment: %w", err), PaymentNotConfirmed, "")
+	case errors.Is(err, chain.ErrTxNotFound), errors.Is(err, chain.ErrTxReverted):
+		return nil, newServiceError(code402, fmt.Errorf("checkMessagePayment/Payment: %w", err), PaymentInvalid, err.Error())
+	case err != nil:
+		return nil, newServiceError(code500, fmt.Errorf("checkMessagePayment/Payment: %w", err), InternalError, "")
+	}
+	if payment.From != sender.Address {
+		return nil, newServiceError(code402, fmt.Errorf("checkMessagePayment: sent by %s", payment.From), PaymentInvalid,
+			"the transaction was sent from another address")
+	}
+
+	transfer, err := paymentTransfer(payment, price, sender.Address, recipient.Address)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("checkMessagePayment/paymentTransfer: %w", err), InternalError, "")
+	}
+	if transfer == nil {
+		return nil, newServiceError(code402, fmt.Errorf("checkMessagePayment: %s", PaymentInvalid), PaymentInvalid,
+			"the transaction doesn't transfer the price to the recipient")
+	}
+	if int64(payment.Confirmations) < cfg.Confirmations {
+		return nil, newServiceError(code409, fmt.Errorf("checkMessagePayment: %d confirmations", payment.Confirmations),
+			PaymentNotConfirmed, fmt.Sprintf("%d of %d confirmations", payment.Confirmations, cfg.Confirmations))
+	}
+
+	res := &domain.MessagePayment{
+		TxHash:       hash.Hex(),
+		PayerID:      sender.ID,
+		RecipientID:  recipient.ID,
+		TokenAddress: price.TokenAddress,
+		Amount:       transfer.Amount.String(),
+		Status:       domain.PaymentPaid,
+	}
+	if transfer.DepositID != nil {
+		depositID := transfer.DepositID.String()
+		res.DepositID, res.Status = &depositID, domain.PaymentHeld
+	}
+
+	return res, nil
+}
+
+// paymentTransfer finds a transfer of at least price from sender to recipient. Escrow deposits held
+// for the recipient count, their To is the recipient.
+func paymentTransfer(payment *chain.Payment, price *domain.MessagePrice, sender, recipient common.Address) (*chain.Transfer, error) {
+	amount, ok := new(big.Int).SetString(price.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid price amount %q", price.Amount)
+	}
+	var token common.Address
+	if price.TokenAddress != nil {
+		token = common.HexToAddress(*price.TokenAddress)
+	}
+
+	for _, t := range payment.Transfers {
+		if t.Token == token && t.From == sender && t.To == recipient && t.Amount.Cmp(amount) >= 0 {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// PaymentRefundWorker refunds the escrow deposits of dialogs the recipient replied to. It needs the
+// relayer to be the MessageEscrow operator, only the operator can refund.
+type PaymentRefundWorker struct {
+	cfg              *config.PaymentsConfig
+	gas              *config.GasConfig
+	payments         chain.Payments
+	repoPayments     repository.Payments
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewPaymentRefundWorker(
+	cfg *config.PaymentsConfig,
+	gas *config.GasConfig,
+	payments chain.Payments,
+	repoPayments repository.Payments,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) *PaymentRefundWorker {
+
+	return &PaymentRefundWorker{
+		cfg:              cfg,
+		gas:              gas,
+		payments:         payments,
+		repoPayments:     repoPayments,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+func (w *PaymentRefundWorker) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := w.submitQueued(); err != nil {
+				w.logging.Errorf("PaymentRefundWorker/submitQueued: %v", err)
+			}
+			if err := w.checkSubmitted(); err != nil {
+				w.logging.Errorf("PaymentRefundWorker/checkSubmitted: %v", err)
+			}
+		}
+	}
+}
+
+// submitQueued broadcasts the queued refunds. A refund the node rejects stays queued until it has
+// failed cfg.MaxRefundAttempts times.
+func (w *PaymentRefundWorker) submitQueued() error {
+	return w.eachPayment(domain.PaymentRefundQueued, w.submit)
+}
+
+// checkSubmitted follows the refunds' receipts and replaces the ones that stay unmined for
+// gas.ReplaceAfter, a stuck refund holds up every later relayer transaction. A refund that reverts
+// is final, the deposit was most likely claimed by the recipient in the meantime.
+func (w *PaymentRefundWorker) checkSubmitted() error {
+	return w.eachPayment(domain.PaymentRefundSubmitted, w.check)
+}
+
+// eachPayment handles up to cfg.BatchSize payments in status, each in a database transaction of its
+// own, so a sent refund is stored before the next one is picked up. handle returns false when the
+// payment is left unchanged.
+func (w *PaymentRefundWorker) eachPayment(status domain.PaymentStatus, handle func(context.Context, *domain.MessagePayment) bool) error {
+	var afterID int64
+	for i := int64(0); i < w.cfg.BatchSize; i++ {
+		p, err := w.handleNext(status, afterID, handle)
+		if err != nil {
+			return fmt.Errorf("eachPayment/handleNext: %w", err)
+		}
+		if p == nil {
+			return nil
+		}
+		afterID = p.ID
+	}
+	return nil
+}
+
+func (w *PaymentRefundWorker) handleNext(status domain.PaymentStatus, afterID int64, handle func(context.Context, *domain.MessagePayment) bool) (*domain.MessagePayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.WorkerInterval)
+	defer cancel()
+
+	tx, err := w.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("handleNext/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	payments, err := w.repoPayments.GetMessagePaymentsByStatus(ctx, tx, status, afterID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("handleNext/GetMessagePaymentsByStatus: %w", err)
+	}
+	if len(payments) == 0 {
+		return nil, nil
+	}
+	p := payments[0]
+	if !handle(ctx, p) {
+		return p, nil
+	}
+
+	// the outcome of the broadcast is stored even past the RPC deadline
+	storeCtx := context.WithoutCancel(ctx)
+	p.UpdatedAt = now.Now().UnixMilli()
+	if err := w.repoPayments.UpdateMessagePayment(storeCtx, tx, p); err != nil {
+		return nil, fmt.Errorf("handleNext/UpdateMessagePayment: payment %d: %w", p.ID, err)
+	}
+	if err := tx.Commit(storeCtx); err != nil {
+		return nil, fmt.Errorf("handleNext/Commit: payment %d: %w", p.ID, err)
+	}
+
+	return p, nil
+}
+
+func (w *PaymentRefundWorker) submit(ctx context.Context, p *domain.MessagePayment) bool {
+	depositID, _ := new(big.Int).SetString(*p.DepositID, 10)
+	if sent, err := w.payments.RefundDeposit(ctx, depositID, nil); err != nil {
+		reason := err.Error()
+		p.Error = &reason
+		p.Attempts++
+		if p.Attempts >= w.cfg.MaxRefundAttempts {
+			p.Status = domain.PaymentRefundFailed
+		}
+	} else {
+		p.Status, p.Error = domain.PaymentRefundSubmitted, nil
+		p.RefundTxHash, p.RefundSent = newSentChainTx(sent)
+	}
+	return true
+}
+
+func (w *PaymentRefundWorker) check(ctx context.Context, p *domain.MessagePayment) bool {
+	status, minedHash, err := w.status(ctx, p)
+	if err != nil {
+		w.logging.Errorf("PaymentRefundWorker/check: payment %d: %v", p.ID, err)
+		return false
+	}
+	switch status {
+	case chain.TxConfirmed:
+		p.Status = domain.PaymentRefunded
+		p.RefundTxHash, p.ReplacedRefundTxHashes = minedTx(*p.RefundTxHash, p.ReplacedRefundTxHashes, minedHash)
+	case chain.TxFailed:
+		reason := "transaction reverted"
+		p.Status, p.Error = domain.PaymentRefundFailed, &reason
+		p.RefundTxHash, p.ReplacedRefundTxHashes = minedTx(*p.RefundTxHash, p.ReplacedRefundTxHashes, minedHash)
+	default:
+		if !stuckTx(p.RefundSent, w.gas) {
+			return false
+		}
+		depositID, _ := new(big.Int).SetString(*p.DepositID, 10)
+		replacement, err := w.payments.RefundDeposit(ctx, depositID, toSentTx(*p.RefundTxHash, p.RefundSent))
+		if err != nil {
+			// the stuck refund may have been mined meanwhile, its receipt shows up next time
+			w.logging.Errorf("PaymentRefundWorker/check: replace %s: %v", *p.RefundTxHash, err)
+			return false
+		}
+		p.ReplacedRefundTxHashes = append(p.ReplacedRefundTxHashes, *p.RefundTxHash)
+		p.RefundTxHash, p.RefundSent = newSentChainTx(replacement)
+	}
+	return true
+}
+
+// status checks the current refund and the ones it replaced, whichever got mined decides.
+func (w *PaymentRefundWorker) status(ctx context.Context, p *domain.MessagePayment) (chain.TxStatus, string, error) {
+	for _, hash := range append([]string{*p.RefundTxHash}, p.ReplacedRefundTxHashes...) {
+		status, err := w.payments.TxStatus(ctx, common.HexToHash(hash))
+		if err != nil {
+			return chain.TxPending, "", err
+		}
+		if status != chain.TxPending {
+			return status, hash, nil
+		}
+	}
+	return chain.TxPending, "", nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func (d *DialogsService) GetPendingMessages(ctx context.Context, userID int64) ([]*models.PendingMessage, error) {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetPendingMessages/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	pending, err := d.repoPending.GetPendingMessagesBySender(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetPendingMessages/GetPendingMessagesBySender: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("GetPendingMessages/Commit: %w", err), InternalError, "")
+	}
+
+	return domain.PendingMessagesToResponse(pending), nil
+}
+
+// queuePendingMessage stores a message for an address that hasn't signed in yet.
+// Only plain text is accepted since attachments, replies and ciphertexts need a dialog and recipient keys.
+func (d *DialogsService) queuePendingMessage(
+	ctx context.Context,
+	tx repository.Transaction,
+	req *models.SendMessageRequest,
+	uploads []*domain.AttachmentUpload,
+	sender *domain.UserChain,
+) (int64, error) {
+	if !common.IsHexAddress(*req.RecipientID) {
+		return 0, newServiceError(code400, fmt.Errorf("queuePendingMessage: %s", RecipientAddressInvalid), RecipientAddressInvalid, "")
+	}
+	if *req.Content == "" || len(uploads) > 0 || req.ReplyToMessageID != 0 || req.Encrypted != nil || req.OnChain {
+		return 0, newServiceError(code400, fmt.Errorf("queuePendingMessage: %s", PendingMessageUnsupported), PendingMessageUnsupported, "")
+	}
+
+	msg := &domain.PendingMessage{
+		SenderID:         sender.ID,
+		RecipientAddress: strings.ToLower(*req.RecipientID),
+		Content:          *req.Content,
+		CreatedAt:        now.Now().UnixMilli(),
+	}
+
+	sent, err := d.repoPending.CountPendingMessages(ctx, tx, sender.ID, msg.RecipientAddress)
+	if err != nil {
+		return 0, newServiceError(code500, fmt.Errorf("queuePendingMessage/CountPendingMessages: %w", err), InternalError, "")
+	}
+	// the messages land in a message request, so they are capped the same way
+	if sent >= d.cfg.MaxPendingMessages {
+		return 0, newServiceError(code429, fmt.Errorf("queuePendingMessage: %s", PendingInboxQuotaExceeded), PendingInboxQuotaExceeded, "")
+	}
+	if sent == 0 {
+		since := now.Now().Add(-d.cfg.PendingInbox.Window).UnixMilli()
+		recipients, err := d.repoPending.CountPendingRecipients(ctx, tx, sender.ID, since)
+		if err != nil {
+			return 0, newServiceError(code500, fmt.Errorf("queuePendingMessage/CountPendingRecipients: %w", err), InternalError, "")
+		}
+		if recipients >= d.cfg.PendingInbox.MaxRecipients {
+			return 0, newServiceError(code429, fmt.Errorf("queuePendingMessage: %s", PendingInboxQuotaExceeded), PendingInboxQuotaExceeded, "")
+		}
+	}
+
+	if req.Signature != "" {
+		recepeint := &domain.UserChain{Address: common.HexToAddress(*req.RecipientID)}
+		msg.Signature, err = d.checkMessageSignature(req, 0, sender, recepeint)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	id, err := d.repoPending.InsertPendingMessage(ctx, tx, msg)
+	if err != nil {
+		return 0, newServiceError(code500, fmt.Errorf("queuePendingMessage/InsertPendingMessage: %w", err), InternalError, "")
+	}
+
+	return id, nil
+}
+
+// deliverPendingMessages moves everything sent to a freshly created user into dialogs.
+// Each sender gets one dialog that starts as a message request, like any other first contact.
+func deliverPendingMessages(
+	ctx context.Context,
+	tx repository.Transaction,
+	repoDialogs repository.Dialogs,
+	repoPending repository.PendingMessages,
+	user *domain.UserChain,
+) error {
+	pending, err := repoPending.TakePendingMessages(ctx, tx, strings.ToLower(user.Address.String()))
+	if err != nil {
+		return fmt.Errorf("deliverPendingMessages/TakePendingMessages: %w", err)
+	}
+
+	dialogs := make(map[int64]int64)
+	for _, p := range pending {
+		dialogID, ok := dialogs[p.SenderID]
+		if !ok {
+			dialogID, err = repoDialogs.CreateDialog(ctx, tx, user.ID, p.SenderID)
+			if err != nil {
+				return fmt.Errorf("deliverPendingMessages/CreateDialog: %w", err)
+			}
+			if err := repoDialogs.CreateDialogBetweenUsers(ctx, tx, user.ID, p.SenderID, dialogID); err != nil {
+				return fmt.Errorf("deliverPendingMessages/CreateDialogBetweenUsers: %w", err)
+			}
+			if err := repoDialogs.MarkDialogRequested(ctx, tx, dialogID, p.SenderID); err != nil {
+				return fmt.Errorf("deliverPendingMessages/MarkDialogRequested: %w", err)
+			}
+			dialogs[p.SenderID] = dialogID
+		}
+
+		messageID, err := repoDialogs.CreateMessageInDialog(ctx, tx, &domain.Message{
+			DialogID:  dialogID,
+			SenderID:  p.SenderID,
+			Content:   p.Content,
+			CreatedAt: p.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("deliverPendingMessages/CreateMessageInDialog: %w", err)
+		}
+
+		if p.Signature != nil {
+			if err := repoDialogs.InsertMessageSignature(ctx, tx, messageID, p.Signature); err != nil {
+				return fmt.Errorf("deliverPendingMessages/InsertMessageSignature: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+type PrivacyService struct {
+	cfg              *config.ServiceConfig
+	repoUsers        repository.Users
+	repoPrivacy      repository.Privacy
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewPrivacyService(
+	cfg *config.ServiceConfig,
+	repoUsers repository.Users,
+	repoPrivacy repository.Privacy,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) Privacy {
+
+	return &PrivacyService{
+		cfg:              cfg,
+		repoUsers:        repoUsers,
+		repoPrivacy:      repoPrivacy,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+func (p *PrivacyService) BlockUser(ctx context.Context, req *models.BlockUserRequest, userID int64) error {
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("BlockUser/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	blocked, err := p.getUserByAddress(ctx, tx, *req.Address)
+	if err != nil {
+		return err
+	}
+	if blocked.ID == userID {
+		return newServiceError(code400, fmt.Errorf("BlockUser: %s", CannotBlockSelf), CannotBlockSelf, "")
+	}
+
+	if err := p.repoPrivacy.BlockUser(ctx, tx, userID, blocked.ID, now.Now().UnixMilli()); err != nil {
+		return newServiceError(code500, fmt.Errorf("BlockUser/BlockUser: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("BlockUser/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+func (p *PrivacyService) UnblockUser(ctx context.Context, address string, userID int64) error {
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UnblockUser/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	blocked, err := p.getUserByAddress(ctx, tx, address)
+	if err != nil {
+		return err
+	}
+
+	if err := p.repoPrivacy.UnblockUser(ctx, tx, userID, blocked.ID); err != nil {
+		return newServiceError(code500, fmt.Errorf("UnblockUser/UnblockUser: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("UnblockUser/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+func (p *PrivacyService) GetBlockedUsers(ctx context.Context, userID int64) ([]*models.BlockedUser, error) {
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetBlockedUsers/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	blocked, err := p.repoPrivacy.GetBlockedUsers(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetBlockedUsers/GetBlockedUsers: %w", err), InternalError, "")
+	}
+
+	return domain.BlockedUsersToResponse(blocked), nil
+}
+
+func (p *PrivacyService) GetPrivacySettings(ctx context.Context, userID int64) (*models.PrivacySettings, error) {
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetPrivacySettings/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	privacy, err := p.repoPrivacy.GetMessagePrivacy(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetPrivacySettings/GetMessagePrivacy: %w", err), InternalError, "")
+	}
+
+	whoCanMessage := privacy.String()
+	return &models.PrivacySettings{WhoCanMessage: &whoCanMessage}, nil
+}
+
+func (p *PrivacyService) UpdatePrivacySettings(ctx context.Context, req *models.PrivacySettings, userID int64) error {
+	privacy, ok := domain.ParseMessagePrivacy(*req.WhoCanMessage)
+	if !ok {
+		return newServiceError(code400, fmt.Errorf("UpdatePrivacySettings: %s", InvalidBody), InvalidBody, "unknown who_can_message value")
+	}
+
+	tx, err := p.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdatePrivacySettings/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := p.repoPrivacy.UpdateMessagePrivacy(ctx, tx, userID, privacy); err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdatePrivacySettings/UpdateMessagePrivacy: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("UpdatePrivacySettings/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+func (p *PrivacyService) getUserByAddress(ctx context.Context, tx repository.Transaction, address string) (*domain.UserChain, error) {
+	user, err := p.repoUsers.GetUserByAddress(ctx, tx, strings.ToLower(address))
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code400, fmt.Errorf("getUserByAddress/GetUserByAddress: %w", err),
+				BadRequest, fmt.Sprintf("user with address %v is not registered", address))
+		}
+		return nil, newServiceError(code500, fmt.Errorf("getUserByAddress/GetUserByAddress: %w", err), InternalError, "")
+	}
+
+	return user, nil
+}
+
+// checkCanMessage enforces blocks and the recipient's privacy setting. Rejections caused by the
+// recipient all return the same error so the sender can't tell a block from a privacy setting.
+func checkCanMessage(
+	ctx context.Context,
+	tx repository.Transaction,
+	repoPrivacy repository.Privacy,
+	senderID, recipientID int64,
+	dialogExists bool,
+) error {
+	senderBlocked, err := repoPrivacy.IsBlocked(ctx, tx, senderID, recipientID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("checkCanMessage/IsBlocked: %w", err), InternalError, "")
+	}
+	if senderBlocked {
+		return newServiceError(code400, fmt.Errorf("checkCanMessage: %s", RecipientBlocked), RecipientBlocked, "")
+	}
+
+	recipientBlocked, err := repoPrivacy.IsBlocked(ctx, tx, recipientID, senderID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("checkCanMessage/IsBlocked: %w", err), InternalError, "")
+	}
+	if recipientBlocked {
+		return newServiceError(code403, fmt.Errorf("checkCanMessage: sender is blocked: %s", MessageNotDelivered), MessageNotDelivered, "")
+	}
+
+	privacy, err := repoPrivacy.GetMessagePrivacy(ctx, tx, recipientID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("checkCanMessage/GetMessagePrivacy: %w", err), InternalError, "")
+	}
+
+	allowed := true
+	switch privacy {
+	case domain.MessagePrivacyContacts:
+		allowed = dialogExists
+	case domain.MessagePrivacyNobody:
+		if dialogExists {
+			allowed, err = repoPrivacy.HasRepliedTo(ctx, tx, recipientID, senderID)
+			if err != nil {
+				return newServiceError(code500, fmt.Errorf("checkCanMessage/HasRepliedTo: %w", err), InternalError, "")
+			}
+		} else {
+			allowed = false
+		}
+	}
+	if !allowed {
+		return newServiceError(code403, fmt.Errorf("checkCanMessage: rejected by privacy %s: %s", privacy, MessageNotDelivered),
+			MessageNotDelivered, "")
+	}
+
+	return nil
+}
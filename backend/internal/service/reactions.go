@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+const reactionChangesLimit = 500
+
+func (d *DialogsService) AddReaction(ctx context.Context, req *models.ReactionRequest, dialogID, messageID, userID int64) (*models.ReactionChange, error) {
+	return d.changeReaction(ctx, *req.Emoji, true, dialogID, messageID, userID)
+}
+
+func (d *DialogsService) RemoveReaction(ctx context.Context, emoji string, dialogID, messageID, userID int64) (*models.ReactionChange, error) {
+	return d.changeReaction(ctx, emoji, false, dialogID, messageID, userID)
+}
+
+// GetReactionChanges returns reaction changes in the dialog made after the change with id after, at most
+// reactionChangesLimit of them. Clients continue from the change_id of the last returned change, ids are
+// unique where timestamps are not.
+func (d *DialogsService) GetReactionChanges(ctx context.Context, dialogID, after, userID int64) ([]*models.ReactionChange, error) {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetReactionChanges/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetReactionChanges/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("GetReactionChanges: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	changes, err := d.repoReactions.GetReactionChanges(ctx, tx, dialogID, after, reactionChangesLimit)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetReactionChanges/GetReactionChanges: %w", err), InternalError, "")
+	}
+
+	return domain.ReactionChangesToResponse(changes), nil
+}
+
+func (d *DialogsService) changeReaction(ctx context.Context, emoji string, add bool, dialogID, messageID, userID int64) (*models.ReactionChange, error) {
+	if _, ok := domain.AllowedReactions[emoji]; !ok {
+		return nil, newServiceError(code400, fmt.Errorf("changeReaction: %s", ReactionNotAllowed), ReactionNotAllowed, "")
+	}
+
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("changeReaction/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("changeReaction/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("changeReaction: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	msg, err := d.repoDialogs.GetMessageById(ctx, tx, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, newServiceError(code404, fmt.Errorf("changeReaction/GetMessageById: %w", err), MessageNotExist, "")
+		}
+		return nil, newServiceError(code500, fmt.Errorf("changeReaction/GetMessageById: %w", err), InternalError, "")
+	}
+	if msg.DialogID != dialogID {
+		return nil, newServiceError(code404, fmt.Errorf("changeReaction: %s", MessageNotExist), MessageNotExist, "")
+	}
+	if msg.DeletedAt != nil {
+		return nil, newServiceError(code400, fmt.Errorf("changeReaction: %s", MessageDeleted), MessageDeleted, "")
+	}
+
+	user, err := d.repoUsers.GetUserById(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("changeReaction/GetUserById: %w", err), InternalError, "")
+	}
+
+	change := &domain.ReactionChange{
+		DialogID:    dialogID,
+		MessageID:   messageID,
+		UserID:      userID,
+		UserAddress: strings.ToLower(user.Address.String()),
+		Emoji:       emoji,
+		Added:       add,
+		CreatedAt:   now.Now().UnixMilli(),
+	}
+
+	var changed bool
+	if add {
+		changed, err = d.repoReactions.AddReaction(ctx, tx, messageID, userID, emoji, change.CreatedAt)
+	} else {
+		changed, err = d.repoReactions.RemoveReaction(ctx, tx, messageID, userID, emoji)
+	}
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("changeReaction/ChangeReaction: %w", err), InternalError, "")
+	}
+
+	// repeating the same change is a no-op and isn't recorded
+	if changed {
+		change.ID, err = d.repoReactions.InsertReactionChange(ctx, tx, change)
+		if err != nil {
+			return nil, newServiceError(code500, fmt.Errorf("changeReaction/InsertReactionChange: %w", err), InternalError, "")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newServiceError(code500,
+			fmt.Errorf("changeReaction/Commit: %w", err), InternalError, "")
+	}
+
+	return domain.ReactionChangeToModel(change), nil
+}
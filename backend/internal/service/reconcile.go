@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reconciler compares the messages the database has as on-chain with the Messaging contract's
+// dialogues mapping. Messages the chain worker hasn't confirmed yet aren't compared, so it's best
+// run while the workers are caught up.
+type Reconciler struct {
+	cfg       *config.ChainConfig
+	dialogues chain.DialogueReader
+	// relayer is the account the chain worker sends from, the zero address when it's unknown
+	relayer          common.Address
+	repoReconcile    repository.Reconcile
+	repoDialogs      repository.Dialogs
+	repoIndexer      repository.ChainIndexer
+	repoTransactions repository.Transactions
+
+	logging logger.Logger
+}
+
+func NewReconciler(
+	cfg *config.ChainConfig,
+	dialogues chain.DialogueReader,
+	relayer common.Address,
+	repoReconcile repository.Reconcile,
+	repoDialogs repository.Dialogs,
+	repoIndexer repository.ChainIndexer,
+	repoTransactions repository.Transactions,
+
+	logging logger.Logger) *Reconciler {
+
+	return &Reconciler{
+		cfg:              cfg,
+		dialogues:        dialogues,
+		relayer:          relayer,
+		repoReconcile:    repoReconcile,
+		repoDialogs:      repoDialogs,
+		repoIndexer:      repoIndexer,
+		repoTransactions: repoTransactions,
+
+		logging: logging,
+	}
+}
+
+// Reconcile compares every known address pair. With repair the database side is fixed:
+//   - content mismatches take the on-chain content,
+//   - messages missing on-chain are queued for the chain worker again,
+//   - for messages missing in the database the indexer's checkpoint is rewound, so it reads the logs again.
+//
+// Indexed messages missing on-chain and the relayer's messages missing in the database can't be repaired.
+// Each pair is repaired in a transaction of its own.
+func (r *Reconciler) Reconcile(ctx context.Context, repair bool) (*domain.ReconcileReport, error) {
+	relayer := ""
+	if r.relayer != (common.Address{}) {
+		relayer = strings.ToLower(r.relayer.Hex())
+	}
+
+	pairs, err := r.chainAddressPairs(ctx, relayer)
+	if err != nil {
+		return nil, fmt.Errorf("Reconcile/chainAddressPairs: %w", err)
+	}
+
+	report := &domain.ReconcileReport{Pairs: int64(len(pairs))}
+	rewind := false
+	for _, pair := range pairs {
+		diffs, err := r.reconcilePair(ctx, report, pair, relayer, repair)
+		if err != nil {
+			return nil, fmt.Errorf("Reconcile/reconcilePair: %s -> %s: %w", pair.Sender, pair.Receiver, err)
+		}
+		for _, d := range diffs {
+			if d.Kind == domain.ReconcileMissingInDB && d.Repaired {
+				rewind = true
+			}
+		}
+		report.Diffs = append(report.Diffs, diffs...)
+	}
+
+	if rewind {
+		if err := r.rewindIndexer(ctx); err != nil {
+			return nil, fmt.Errorf("Reconcile/rewindIndexer: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func (r *Reconciler) chainAddressPairs(ctx context.Context, relayer string) ([]*domain.ChainAddressPair, error) {
+	tx, err := r.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chainAddressPairs/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	pairs, err := r.repoReconcile.GetChainAddressPairs(ctx, tx, relayer)
+	if err != nil {
+		return nil, fmt.Errorf("chainAddressPairs/GetChainAddressPairs: %w", err)
+	}
+
+	return pairs, nil
+}
+
+func (r *Reconciler) reconcilePair(ctx context.Context, report *domain.ReconcileReport, pair *domain.ChainAddressPair, relayer string, repair bool) ([]*domain.ReconcileDiff, error) {
+	tx, err := r.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reconcilePair/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	messages, err := r.repoReconcile.GetOnChainMessages(ctx, tx, pair, relayer)
+	if err != nil {
+		return nil, fmt.Errorf("reconcilePair/GetOnChainMessages: %w", err)
+	}
+	stored, err := r.dialogues.Dialogue(ctx, common.HexToAddress(pair.Sender), common.HexToAddress(pair.Receiver))
+	if err != nil {
+		return nil, fmt.Errorf("reconcilePair/Dialogue: %w", err)
+	}
+	report.Messages += int64(len(messages))
+	report.ChainMessages += int64(len(stored))
+
+	diffs := diffDialogue(pair, messages, stored)
+	if !repair || len(diffs) == 0 {
+		return diffs, nil
+	}
+
+	for _, d := range diffs {
+		if err := r.repairDiff(ctx, tx, d, relayer); err != nil {
+			return nil, fmt.Errorf("reconcilePair/repairDiff: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("reconcilePair/Commit: %w", err)
+	}
+
+	return diffs, nil
+}
+
+func (r *Reconciler) repairDiff(ctx context.Context, tx repository.Transaction, d *domain.ReconcileDiff, relayer string) error {
+	switch d.Kind {
+	case domain.ReconcileContentMismatch:
+		if err := r.repoDialogs.UpdateMessageContent(ctx, tx, d.Message.MessageID, *d.ChainContent, now.Now().UnixMilli()); err != nil {
+			return fmt.Errorf("repairDiff/UpdateMessageContent: %w", err)
+		}
+		d.Repaired, d.Note = true, "content replaced with the on-chain content"
+	case domain.ReconcileMissingOnChain:
+		if d.Message.Indexed {
+			d.Note = "the message was indexed from a log the chain no longer has"
+			return nil
+		}
+		if err := r.repoReconcile.RequeueMessageChainTx(ctx, tx, d.Message.MessageID, now.Now().UnixMilli()); err != nil {
+			return fmt.Errorf("repairDiff/RequeueMessageChainTx: %w", err)
+		}
+		d.Repaired, d.Note = true, "queued for the chain worker again"
+	case domain.ReconcileMissingInDB:
+		if d.Pair.Sender == relayer {
+			d.Note = "the relayer's messages don't name their sender"
+			return nil
+		}
+		d.Repaired, d.Note = true, fmt.Sprintf("the indexer reads the logs again from block %d", r.cfg.IndexerStartBlock)
+	}
+	return nil
+}
+
+// rewindIndexer moves the MessageSent checkpoint back to the start block. Logs that are indexed
+// already are skipped, so only the missing messages are stored again.
+func (r *Reconciler) rewindIndexer(ctx context.Context) error {
+	tx, err := r.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("rewindIndexer/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updatedAt := now.Now().UnixMilli()
+	if _, err := r.repoIndexer.LockChainCheckpoint(ctx, tx, messageSentCheckpoint, r.cfg.IndexerStartBlock, updatedAt); err != nil {
+		return fmt.Errorf("rewindIndexer/LockChainCheckpoint: %w", err)
+	}
+	if err := r.repoIndexer.UpdateChainCheckpoint(ctx, tx, messageSentCheckpoint, r.cfg.IndexerStartBlock, updatedAt); err != nil {
+		return fmt.Errorf("rewindIndexer/UpdateChainCheckpoint: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("rewindIndexer/Commit: %w", err)
+	}
+
+	return nil
+}
+
+// diffDialogue aligns the database messages with the contract's dialogue by the longest common
+// subsequence of their contents. Deleted messages lost their content and match any on-chain message.
+// Between two matches the remaining messages are paired up as content mismatches, the surplus
+// on either side is missing on the other.
+func diffDialogue(pair *domain.ChainAddressPair, messages []*domain.OnChainMessage, stored []*chain.DialogueMessage) []*domain.ReconcileDiff {
+	matches := func(i, j int) bool {
+		return messages[i].Deleted || messages[i].Content == stored[j].Content
+	}
+
+	// the common prefix and suffix are matched directly, usually that's all of it
+	prefix := 0
+	for prefix < len(messages) && prefix < len(stored) && matches(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(messages)-prefix && suffix < len(stored)-prefix &&
+		matches(len(messages)-1-suffix, len(stored)-1-suffix) {
+		suffix++
+	}
+	n, m := len(messages)-prefix-suffix, len(stored)-prefix-suffix
+
+	// lcs[i][j] is the LCS length of messages[prefix+i:] and stored[prefix+j:] within the middle
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if matches(prefix+i, prefix+j) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var (
+		diffs           []*domain.ReconcileDiff
+		gapDB, gapChain []int
+	)
+	flushGap := func() {
+		for k := 0; k < len(gapDB) || k < len(gapChain); k++ {
+			d := &domain.ReconcileDiff{Pair: *pair, ChainIndex: -1}
+			switch {
+			case k < len(gapDB) && k < len(gapChain):
+				d.Kind = domain.ReconcileContentMismatch
+			case k < len(gapDB):
+				d.Kind = domain.ReconcileMissingOnChain
+			default:
+				d.Kind = domain.ReconcileMissingInDB
+			}
+			if k < len(gapDB) {
+				d.Message = messages[gapDB[k]]
+			}
+			if k < len(gapChain) {
+				content := stored[gapChain[k]].Content
+				d.ChainIndex, d.ChainContent = int64(gapChain[k]), &content
+			}
+			diffs = append(diffs, d)
+		}
+		gapDB, gapChain = gapDB[:0], gapChain[:0]
+	}
+	for i, j := 0, 0; i < n || j < m; {
+		switch {
+		case i < n && j < m && matches(prefix+i, prefix+j) && lcs[i][j] == lcs[i+1][j+1]+1:
+			flushGap()
+			i, j = i+1, j+1
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			gapDB = append(gapDB, prefix+i)
+			i++
+		default:
+			gapChain = append(gapChain, prefix+j)
+			j++
+		}
+	}
+	flushGap()
+
+	return diffs
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// GetRelayInfo returns the EIP-712 domain gasless messages are signed in and what's left of the user's quota.
+func (d *DialogsService) GetRelayInfo(ctx context.Context, userID int64) (*models.RelayInfo, error) {
+	if d.chainClient == nil || !d.cfg.Chain.Relay.Enabled {
+		return nil, newServiceError(code400, fmt.Errorf("GetRelayInfo: %s", RelayDisabled), RelayDisabled, "")
+	}
+
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetRelayInfo/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := d.repoUsers.GetUserById(ctx, tx, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetRelayInfo/GetUserById: %w", err), InternalError, "")
+	}
+	relayed, err := d.repoChainTxs.CountRelayedMessages(ctx, tx, strings.ToLower(user.Address.Hex()),
+		now.Now().Add(-d.cfg.Chain.Relay.Window).UnixMilli())
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetRelayInfo/CountRelayedMessages: %w", err), InternalError, "")
+	}
+
+	relayDomain := d.chainClient.Domain()
+	return &models.RelayInfo{
+		Domain: &models.RelayDomain{
+			Name:              relayDomain.Name,
+			Version:           relayDomain.Version,
+			ChainID:           relayDomain.ChainID.Int64(),
+			VerifyingContract: relayDomain.VerifyingContract.Hex(),
+		},
+		Quota:     d.cfg.Chain.Relay.Quota,
+		Remaining: max(d.cfg.Chain.Relay.Quota-relayed, 0),
+		Window:    d.cfg.Chain.Relay.Window.Milliseconds(),
+	}, nil
+}
+
+// checkRelaySignature verifies the sender's EIP-712 SendMessage signature before the message is queued for
+// the relayer, so the contract doesn't revert it later. Each nonce is relayed once and senders are held
+// to the relay quota, the relayer pays for their gas.
+func (d *DialogsService) checkRelaySignature(
+	ctx context.Context,
+	tx repository.Transaction,
+	req *models.SendMessageRequest,
+	sender, recepeint *domain.UserChain,
+) (*domain.RelaySignature, error) {
+	nonce, ok := new(big.Int).SetString(*req.Relay.Nonce, 10)
+	if !ok || nonce.Sign() < 0 || nonce.Cmp(math.MaxBig256) > 0 {
+		return nil, newServiceError(code400, fmt.Errorf("checkRelaySignature: invalid nonce %q", *req.Relay.Nonce), InvalidBody, "")
+	}
+	// leave the worker a tick to submit the message
+	if *req.Relay.Deadline <= now.Now().Add(d.cfg.Chain.WorkerInterval).Unix() {
+		return nil, newServiceError(code400, fmt.Errorf("checkRelaySignature: %s", RelayDeadlineInvalid), RelayDeadlineInvalid, "")
+	}
+
+	signer, err := d.chainClient.Domain().Signer(&chain.SignedMessage{
+		Sender:    sender.Address,
+		Receiver:  recepeint.Address,
+		Content:   *req.Content,
+		Nonce:     nonce,
+		Deadline:  big.NewInt(*req.Relay.Deadline),
+		Signature: common.FromHex(*req.Relay.Signature),
+	})
+	if err != nil || signer != sender.Address {
+		return nil, newServiceError(code400, fmt.Errorf("checkRelaySignature: %s", WrongSignature), WrongSignature, "")
+	}
+
+	relay := &domain.RelaySignature{
+		SenderAddress: strings.ToLower(sender.Address.Hex()),
+		Nonce:         nonce.String(),
+		Deadline:      *req.Relay.Deadline,
+		Signature:     *req.Relay.Signature,
+	}
+	used, err := d.repoChainTxs.IsRelayNonceUsed(ctx, tx, relay.SenderAddress, relay.Nonce)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("checkRelaySignature/IsRelayNonceUsed: %w", err), InternalError, "")
+	}
+	if used {
+		return nil, newServiceError(code409, fmt.Errorf("checkRelaySignature: %s", RelayNonceUsed), RelayNonceUsed, "")
+	}
+
+	relayed, err := d.repoChainTxs.CountRelayedMessages(ctx, tx, relay.SenderAddress,
+		now.Now().Add(-d.cfg.Chain.Relay.Window).UnixMilli())
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("checkRelaySignature/CountRelayedMessages: %w", err), InternalError, "")
+	}
+	if relayed >= d.cfg.Chain.Relay.Quota {
+		return nil, newServiceError(code429, fmt.Errorf("checkRelaySignature: %s", RelayQuotaExceeded), RelayQuotaExceeded, "")
+	}
+
+	return relay, nil
+}
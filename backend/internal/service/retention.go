@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/internal/config"
+	"github.com/Pyegorchik/bdd/backend/internal/domain"
+	"github.com/Pyegorchik/bdd/backend/internal/repository"
+	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/blobstore"
+	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/Pyegorchik/bdd/backend/pkg/now"
+)
+
+const minRetentionTTL = time.Minute
+
+func (d *DialogsService) GetDialogRetention(ctx context.Context, dialogID, userID int64) (*models.RetentionSettings, error) {
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetDialogRetention/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetDialogRetention/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return nil, newServiceError(code403, fmt.Errorf("GetDialogRetention: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	ttl, err := d.repoDialogs.GetDialogRetention(ctx, tx, dialogID)
+	if err != nil {
+		return nil, newServiceError(code500, fmt.Errorf("GetDialogRetention/GetDialogRetention: %w", err), InternalError, "")
+	}
+
+	return domain.RetentionToModel(time.Duration(ttl) * time.Millisecond), nil
+}
+
+// UpdateDialogRetention changes the timer for messages sent from now on and records the change
+// as a system message. Either participant can change it.
+func (d *DialogsService) UpdateDialogRetention(ctx context.Context, req *models.RetentionSettings, dialogID, userID int64) error {
+	ttl := domain.RetentionFromModel(req)
+	if *req.Mode == models.RetentionSettingsModeCustom && (ttl < minRetentionTTL || ttl > d.cfg.Retention.MaxTTL) {
+		return newServiceError(code400, fmt.Errorf("UpdateDialogRetention: %s", RetentionInvalid), RetentionInvalid,
+			fmt.Sprintf("custom ttl should be between %d and %d seconds",
+				int64(minRetentionTTL/time.Second), int64(d.cfg.Retention.MaxTTL/time.Second)))
+	}
+
+	tx, err := d.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateDialogRetention/BeginTransaction: %w", err), InternalError, "")
+	}
+	defer tx.Rollback(ctx)
+
+	isParticipant, err := d.repoDialogs.IsDialogParticipant(ctx, tx, dialogID, userID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateDialogRetention/IsDialogParticipant: %w", err), InternalError, "")
+	}
+	if !isParticipant {
+		return newServiceError(code403, fmt.Errorf("UpdateDialogRetention: %s", NotDialogParticipant), NotDialogParticipant, "")
+	}
+
+	current, err := d.repoDialogs.GetDialogRetention(ctx, tx, dialogID)
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateDialogRetention/GetDialogRetention: %w", err), InternalError, "")
+	}
+	ttlMillis := ttl.Milliseconds()
+	if current == ttlMillis {
+		return nil
+	}
+
+	if err := d.repoDialogs.UpdateDialogRetention(ctx, tx, dialogID, ttlMillis); err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateDialogRetention/UpdateDialogRetention: %w", err), InternalError, "")
+	}
+
+	_, err = d.repoDialogs.CreateMessageInDialog(ctx, tx, &domain.Message{
+		DialogID:     dialogID,
+		SenderID:     userID,
+		CreatedAt:    now.Now().UnixMilli(),
+		Kind:         domain.MessageRetentionChanged,
+		RetentionTTL: &ttlMillis,
+	})
+	if err != nil {
+		return newServiceError(code500, fmt.Errorf("UpdateDialogRetention/CreateMessageInDialog: %w", err), InternalError, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newServiceError(code500,
+			fmt.Errorf("UpdateDialogRetention/Commit: %w", err), InternalError, "")
+	}
+
+	return nil
+}
+
+// RetentionWorker hard-deletes expired messages in batches. Reads already skip expired
+// messages, so the worker only has to catch up eventually.
+type RetentionWorker struct {
+	cfg              *config.RetentionConfig
+	repoDialogs      repository.Dialogs
+	repoTransactions repository.Transactions
+	blobStore        blobstore.BlobStore
+
+	logging logger.Logger
+}
+
+func NewRetentionWorker(
+	cfg *config.RetentionConfig,
+	repoDialogs repository.Dialogs,
+	repoTransactions repository.Transactions,
+	blobStore blobstore.BlobStore,
+
+	logging logger.Logger) *RetentionWorker {
+
+	return &RetentionWorker{
+		cfg:              cfg,
+		repoDialogs:      repoDialogs,
+		repoTransactions: repoTransactions,
+		blobStore:        blobStore,
+
+		logging: logging,
+	}
+}
+
+func (w *RetentionWorker) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			w.purge()
+		}
+	}
+}
+
+// purge deletes batches until fewer than a full batch is left.
+func (w *RetentionWorker) purge() {
+	for {
+		deleted, err := w.purgeBatch()
+		if err != nil {
+			w.logging.Errorf("RetentionWorker/purge: %v", err)
+			return
+		}
+		if deleted < w.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (w *RetentionWorker) purgeBatch() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.WorkerInterval)
+	defer cancel()
+
+	tx, err := w.repoTransactions.BeginTransaction(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("purgeBatch/BeginTransaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, storageKeys, err := w.repoDialogs.DeleteExpiredMessages(ctx, tx, now.Now().UnixMilli(), w.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("purgeBatch/DeleteExpiredMessages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("purgeBatch/Commit: %w", err)
+	}
+
+	// blobs go only after the rows are gone, a failure here leaves an orphan blob rather than a broken message
+	for _, key := range storageKeys {
+		if err := w.blobStore.Delete(ctx, key); err != nil {
+			w.logging.Errorf("purgeBatch: key %s: %v", key, err)
+		}
+	}
+
+	return deleted, nil
+}
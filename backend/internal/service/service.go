@@ -2,15 +2,19 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/Pyegorchik/bdd/backend/internal/config"
 	"github.com/Pyegorchik/bdd/backend/internal/domain"
 	"github.com/Pyegorchik/bdd/backend/internal/repository"
 	"github.com/Pyegorchik/bdd/backend/models"
+	"github.com/Pyegorchik/bdd/backend/pkg/blobstore"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain"
 	"github.com/Pyegorchik/bdd/backend/pkg/hash"
 	"github.com/Pyegorchik/bdd/backend/pkg/jwtoken"
 	"github.com/Pyegorchik/bdd/backend/pkg/logger"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type Auth interface {
@@ -21,24 +25,92 @@ type Auth interface {
 	FullLogout(ctx context.Context, id int64, role domain.Role) error
 	GetAuthMessage(ctx context.Context, req *models.AuthMessageRequest) (*models.AuthMessageResponse, error)
 	AuthByMessage(ctx context.Context, req *models.AuthBySignatureRequest) (*models.AuthResponse, *jwtoken.JWTokenData, *jwtoken.JWTokenData, error)
+	GetSupportedChains(ctx context.Context) models.SupportedChainsResponse
+	CreateLoginSession(ctx context.Context, chainID int64) (*models.LoginSessionResponse, error)
+	GetLoginSessionChallenge(ctx context.Context, sessionID string) (*models.LoginSessionChallengeResponse, error)
+	SignLoginSession(ctx context.Context, sessionID string, req *models.AuthBySignatureRequest) error
+	PollLoginSession(ctx context.Context, sessionID string, req *models.LoginSessionPollRequest) (*models.LoginSessionPollResponse, *jwtoken.JWTokenData, *jwtoken.JWTokenData, error)
 }
 
 type Dialogs interface {
-	SendMessage(ctx context.Context, req *models.SendMessageRequest, userID int64) error
-	GetDialogs(ctx context.Context, userID int64) ([]*models.DialogsResponseItems0, error)
-	GetMessages(ctx context.Context, dialogID int64) ([]*models.MessagesResponseItems0, error)
+	SendMessage(ctx context.Context, req *models.SendMessageRequest, uploads []*domain.AttachmentUpload, userID int64) (*models.SendMessageResponse, error)
+	GetDialogs(ctx context.Context, folder domain.DialogFolder, userID int64) ([]*models.DialogsResponseItems0, error)
+	GetMessages(ctx context.Context, dialogID, userID int64) ([]*models.MessagesResponseItems0, error)
+	EditMessage(ctx context.Context, req *models.EditMessageRequest, dialogID, messageID, userID int64) error
+	DeleteMessage(ctx context.Context, dialogID, messageID, userID int64) error
+	MarkDialogRead(ctx context.Context, req *models.ReadDialogRequest, dialogID, userID int64) error
+	SearchMessages(ctx context.Context, query string, limit, offset, userID int64) ([]*models.SearchMessagesResponseItems0, error)
+	GetMessageThread(ctx context.Context, dialogID, messageID, limit, offset, userID int64) (*models.ThreadResponse, error)
+	AcceptMessageRequest(ctx context.Context, dialogID, userID int64) error
+	DeclineMessageRequest(ctx context.Context, dialogID, userID int64) error
+	ReportMessageRequest(ctx context.Context, req *models.ReportMessageRequest, dialogID, userID int64) error
+	AddReaction(ctx context.Context, req *models.ReactionRequest, dialogID, messageID, userID int64) (*models.ReactionChange, error)
+	RemoveReaction(ctx context.Context, emoji string, dialogID, messageID, userID int64) (*models.ReactionChange, error)
+	GetReactionChanges(ctx context.Context, dialogID, after, userID int64) ([]*models.ReactionChange, error)
+	GetDialogRetention(ctx context.Context, dialogID, userID int64) (*models.RetentionSettings, error)
+	UpdateDialogRetention(ctx context.Context, req *models.RetentionSettings, dialogID, userID int64) error
+	GetPendingMessages(ctx context.Context, userID int64) ([]*models.PendingMessage, error)
+	GetRelayInfo(ctx context.Context, userID int64) (*models.RelayInfo, error)
+}
+
+type Attachments interface {
+	GetAttachmentURL(ctx context.Context, dialogID, attachmentID, userID int64) (*models.AttachmentURLResponse, error)
+	OpenAttachment(ctx context.Context, attachmentID, expires int64, signature string) (*domain.Attachment, io.ReadCloser, error)
+}
+
+type Privacy interface {
+	BlockUser(ctx context.Context, req *models.BlockUserRequest, userID int64) error
+	UnblockUser(ctx context.Context, address string, userID int64) error
+	GetBlockedUsers(ctx context.Context, userID int64) ([]*models.BlockedUser, error)
+	GetPrivacySettings(ctx context.Context, userID int64) (*models.PrivacySettings, error)
+	UpdatePrivacySettings(ctx context.Context, req *models.PrivacySettings, userID int64) error
+}
+
+type InboxGates interface {
+	GetInboxGates(ctx context.Context, userID int64) ([]*models.InboxGate, error)
+	GetUserInboxGates(ctx context.Context, address string) ([]*models.InboxGate, error)
+	UpdateInboxGates(ctx context.Context, req *models.InboxGatesRequest, userID int64) error
+}
+
+type Payments interface {
+	GetMessagePrice(ctx context.Context, userID int64) (*models.MessagePrice, error)
+	GetUserMessagePrice(ctx context.Context, address string) (*models.MessagePrice, error)
+	UpdateMessagePrice(ctx context.Context, req *models.MessagePriceRequest, userID int64) error
+}
+
+type Encryption interface {
+	PublishEncryptionKey(ctx context.Context, req *models.PublishEncryptionKeyRequest, userID int64) (*models.EncryptionKey, error)
+	GetEncryptionKeys(ctx context.Context, address string) ([]*models.EncryptionKey, error)
+}
+
+type Anchoring interface {
+	GetMessageProof(ctx context.Context, messageID, userID int64) (*models.MessageProof, error)
 }
 
 type Service interface {
 	Auth
 	Dialogs
+	Attachments
+	Privacy
+	InboxGates
+	Payments
+	Encryption
+	Anchoring
 	Shutdown()
 }
 
 type service struct {
 	Auth
 	Dialogs
+	Attachments
+	Privacy
+	InboxGates
+	Payments
+	Encryption
+	Anchoring
 	stopCh chan struct{}
+	// workers is the number of background workers started by NewService
+	workers int
 
 	cfg     *config.ServiceConfig
 	logging logger.Logger
@@ -48,24 +120,74 @@ func NewService(
 	repo *repository.Repository,
 	jwttokenManager jwtoken.JWTokenManager,
 	hashManager hash.HashManager,
+	blobStore blobstore.BlobStore,
+	chainClient chain.Client,
+	chainEvents chain.EventSource,
+	anchorer chain.Anchorer,
+	holdings chain.Holdings,
+	payments chain.Payments,
+	names chain.Names,
 	cfg *config.ServiceConfig,
 	logging logger.Logger,
 ) (Service, error) {
 	var (
 		stopCh = make(chan struct{})
 
-		Auth = NewAuthService(cfg, repo.Users, repo.JWTokens, repo.Transactions, jwttokenManager,
-			hashManager, logging)
-		Dialogs = NewDialogsService(cfg, repo.Users, repo.Dialogs, repo.JWTokens, repo.Transactions, logging)
+		Auth = NewAuthService(cfg, repo.Users, repo.LoginSessions, repo.JWTokens, repo.Dialogs, repo.PendingMessages,
+			repo.Transactions, jwttokenManager, hashManager, logging)
+		Dialogs = NewDialogsService(cfg, repo.Users, repo.Dialogs, repo.JWTokens, repo.Attachments, repo.Reactions,
+			repo.Privacy, repo.InboxGates, repo.Payments, repo.Encryption, repo.PendingMessages, repo.IdempotencyKeys,
+			repo.ChainTxs, repo.Transactions, blobStore, chainClient, holdings, payments, names, logging)
+		Attachments = NewAttachmentsService(cfg, repo.Dialogs, repo.Attachments, repo.Transactions, blobStore, logging)
+		Privacy     = NewPrivacyService(cfg, repo.Users, repo.Privacy, repo.Transactions, logging)
+		InboxGates  = NewInboxGatesService(cfg, repo.Users, repo.InboxGates, repo.Transactions, holdings, logging)
+		Payments    = NewPaymentsService(cfg, repo.Users, repo.Payments, repo.Transactions, payments, holdings, logging)
+		Encryption  = NewEncryptionService(cfg, repo.Users, repo.Encryption, repo.Transactions, logging)
+		Anchoring   = NewAnchoringService(cfg, repo.Dialogs, repo.Anchors, repo.Transactions, logging)
 	)
 
+	workers := 1
+	go NewRetentionWorker(cfg.Retention, repo.Dialogs, repo.Transactions, blobStore, logging).Run(stopCh)
+	if chainClient != nil {
+		workers++
+		go NewChainWorker(cfg.Chain, chainClient, repo.ChainTxs, repo.Transactions, logging).Run(stopCh)
+	}
+	if chainEvents != nil {
+		var relayer common.Address
+		if chainClient != nil {
+			relayer = chainClient.Relayer()
+		}
+		workers++
+		go NewChainIndexer(cfg.Chain, chainEvents, relayer, repo.ChainIndexer, repo.Users, repo.Dialogs, repo.Privacy,
+			repo.InboxGates, repo.Payments, repo.PendingMessages, repo.Transactions, holdings, logging).Run(stopCh)
+	}
+	if anchorer != nil {
+		workers++
+		go NewAnchorWorker(cfg.Anchor, cfg.Chain.Gas, anchorer, repo.Anchors, repo.Transactions, logging).Run(stopCh)
+	}
+	if holdings != nil {
+		workers++
+		go NewTokenGateWorker(cfg.TokenGate, holdings, repo.InboxGates, repo.Transactions, logging).Run(stopCh)
+	}
+	if payments != nil && payments.CanRefund() {
+		workers++
+		go NewPaymentRefundWorker(cfg.Payments, cfg.Chain.Gas, payments, repo.Payments, repo.Transactions, logging).Run(stopCh)
+	}
+
 	res := &service{
-		Auth:    Auth,
-		Dialogs: Dialogs,
+		Auth:        Auth,
+		Dialogs:     Dialogs,
+		Attachments: Attachments,
+		Privacy:     Privacy,
+		InboxGates:  InboxGates,
+		Payments:    Payments,
+		Encryption:  Encryption,
+		Anchoring:   Anchoring,
 
 		cfg:     cfg,
 		logging: logging,
 		stopCh:  stopCh,
+		workers: workers,
 	}
 
 	return res, nil
@@ -73,7 +195,7 @@ func NewService(
 
 func (s *service) Shutdown() {
 	time.Sleep(1 * time.Second)
-	for i := 0; i < 0; i++ {
+	for i := 0; i < s.workers; i++ {
 		s.stopCh <- struct{}{}
 	}
 
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.messages
+    ADD COLUMN created_at BIGINT NOT NULL DEFAULT (EXTRACT(EPOCH FROM now()) * 1000)::BIGINT,
+    ADD COLUMN edited_at  BIGINT,
+    ADD COLUMN deleted_at BIGINT;
+
+CREATE TABLE message_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL,
+    editor_id BIGINT NOT NULL,
+    content TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_message_revisions_message_id ON message_revisions(message_id);
+
+ALTER TABLE public.message_revisions
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_message_revisions_message_id;
+DROP TABLE IF EXISTS public.message_revisions;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS edited_at,
+    DROP COLUMN IF EXISTS deleted_at;
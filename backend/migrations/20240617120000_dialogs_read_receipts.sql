@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.dialog_participants
+    ADD COLUMN last_read_message_id BIGINT;
+
+CREATE INDEX idx_messages_dialog_id_id ON messages(dialog_id, id);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_messages_dialog_id_id;
+
+ALTER TABLE public.dialog_participants
+    DROP COLUMN IF EXISTS last_read_message_id;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.dialogs
+    ADD COLUMN last_message_id BIGINT,
+    ADD FOREIGN KEY (last_message_id) REFERENCES messages(id) ON DELETE SET NULL;
+
+UPDATE dialogs d
+SET last_message_id = (SELECT max(m.id) FROM messages m WHERE m.dialog_id = d.id);
+
+CREATE INDEX idx_dialogs_last_message_id ON dialogs(last_message_id DESC NULLS LAST);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_dialogs_last_message_id;
+
+ALTER TABLE public.dialogs
+    DROP COLUMN IF EXISTS last_message_id;
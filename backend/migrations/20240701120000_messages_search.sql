@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.messages
+    ADD COLUMN content_tsv tsvector;
+
+UPDATE messages
+SET content_tsv = to_tsvector('simple', content)
+WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_messages_content_tsv ON messages USING GIN (content_tsv);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_messages_content_tsv;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS content_tsv;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE attachments (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at BIGINT NOT NULL,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    UNIQUE (storage_key)
+);
+
+CREATE INDEX idx_attachments_message_id ON attachments(message_id);
+
+ALTER TABLE public.attachments
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_attachments_message_id;
+DROP TABLE IF EXISTS public.attachments;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE public.login_sessions
+(
+    id              VARCHAR(64) NOT NULL,
+    poll_token_hash VARCHAR(64) NOT NULL,
+    message         TEXT        NOT NULL,
+    status          INT         NOT NULL,
+    user_id         BIGINT,
+    created_at      BIGINT      NOT NULL,
+    expires_at      BIGINT      NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_login_sessions_expires_at ON login_sessions(expires_at);
+
+ALTER TABLE public.login_sessions
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_login_sessions_expires_at;
+DROP TABLE IF EXISTS public.login_sessions;
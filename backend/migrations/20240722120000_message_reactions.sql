@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE message_reactions (
+    message_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    emoji VARCHAR(32) NOT NULL,
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (message_id, user_id, emoji),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.message_reactions
+    OWNER TO bdd;
+
+CREATE TABLE message_reaction_events (
+    id BIGSERIAL PRIMARY KEY,
+    dialog_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    emoji VARCHAR(32) NOT NULL,
+    added BOOLEAN NOT NULL,
+    created_at BIGINT NOT NULL,
+    FOREIGN KEY (dialog_id) REFERENCES dialogs(id) ON DELETE CASCADE,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_message_reaction_events_dialog_id ON message_reaction_events(dialog_id, created_at);
+
+ALTER TABLE public.message_reaction_events
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_message_reaction_events_dialog_id;
+DROP TABLE IF EXISTS public.message_reaction_events;
+DROP TABLE IF EXISTS public.message_reactions;
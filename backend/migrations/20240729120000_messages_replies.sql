@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.messages
+    ADD COLUMN reply_to_message_id BIGINT,
+    ADD FOREIGN KEY (reply_to_message_id) REFERENCES messages(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_messages_reply_to_message_id ON messages(reply_to_message_id);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_messages_reply_to_message_id;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS reply_to_message_id;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.users_chain
+    ADD COLUMN message_privacy INT NOT NULL DEFAULT 0;
+
+CREATE TABLE user_blocks (
+    user_id BIGINT NOT NULL,
+    blocked_user_id BIGINT NOT NULL,
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (user_id, blocked_user_id),
+    FOREIGN KEY (user_id) REFERENCES users_chain(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.user_blocks
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE IF EXISTS public.user_blocks;
+
+ALTER TABLE public.users_chain
+    DROP COLUMN IF EXISTS message_privacy;
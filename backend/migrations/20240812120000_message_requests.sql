@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.dialogs
+    ADD COLUMN status INT NOT NULL DEFAULT 0,
+    ADD COLUMN requested_by BIGINT,
+    ADD FOREIGN KEY (requested_by) REFERENCES users_chain(id) ON DELETE SET NULL;
+
+CREATE TABLE dialog_reports (
+    id BIGSERIAL PRIMARY KEY,
+    dialog_id BIGINT NOT NULL,
+    reporter_id BIGINT NOT NULL,
+    reported_user_id BIGINT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    FOREIGN KEY (dialog_id) REFERENCES dialogs(id) ON DELETE CASCADE,
+    FOREIGN KEY (reporter_id) REFERENCES users_chain(id) ON DELETE CASCADE,
+    FOREIGN KEY (reported_user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_dialog_reports_reported_user_id ON dialog_reports(reported_user_id);
+
+ALTER TABLE public.dialog_reports
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_dialog_reports_reported_user_id;
+DROP TABLE IF EXISTS public.dialog_reports;
+
+ALTER TABLE public.dialogs
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS requested_by;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE encryption_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    public_key TEXT NOT NULL,
+    signature TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    rotated_at BIGINT,
+    FOREIGN KEY (user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_encryption_keys_current ON encryption_keys(user_id) WHERE rotated_at IS NULL;
+
+ALTER TABLE public.encryption_keys
+    OWNER TO bdd;
+
+ALTER TABLE public.messages
+    ADD COLUMN nonce TEXT;
+
+CREATE TABLE message_ciphertexts (
+    message_id BIGINT NOT NULL,
+    recipient_id BIGINT NOT NULL,
+    key_id BIGINT NOT NULL,
+    ciphertext TEXT NOT NULL,
+    PRIMARY KEY (message_id, recipient_id),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    FOREIGN KEY (recipient_id) REFERENCES users_chain(id) ON DELETE CASCADE,
+    FOREIGN KEY (key_id) REFERENCES encryption_keys(id)
+);
+
+CREATE INDEX idx_message_ciphertexts_recipient_id ON message_ciphertexts(recipient_id);
+
+ALTER TABLE public.message_ciphertexts
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_message_ciphertexts_recipient_id;
+DROP TABLE IF EXISTS public.message_ciphertexts;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS nonce;
+
+DROP INDEX IF EXISTS idx_encryption_keys_current;
+DROP TABLE IF EXISTS public.encryption_keys;
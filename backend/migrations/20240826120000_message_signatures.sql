@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE message_signatures (
+    message_id BIGINT PRIMARY KEY,
+    signed_dialog_id BIGINT NOT NULL,
+    recipient_address TEXT NOT NULL,
+    signed_at BIGINT NOT NULL,
+    signature TEXT NOT NULL,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.message_signatures
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE IF EXISTS public.message_signatures;
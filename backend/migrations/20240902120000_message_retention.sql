@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.dialogs
+    ADD COLUMN retention_ttl BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE public.messages
+    ADD COLUMN kind INT NOT NULL DEFAULT 0,
+    ADD COLUMN retention_ttl BIGINT,
+    ADD COLUMN expires_at BIGINT;
+
+CREATE INDEX idx_messages_expires_at ON messages(expires_at) WHERE expires_at IS NOT NULL;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_messages_expires_at;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS kind,
+    DROP COLUMN IF EXISTS retention_ttl,
+    DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE public.dialogs
+    DROP COLUMN IF EXISTS retention_ttl;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE pending_messages (
+    id BIGSERIAL PRIMARY KEY,
+    sender_id BIGINT NOT NULL,
+    recipient_address TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    signed_at BIGINT,
+    signature TEXT,
+    FOREIGN KEY (sender_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_pending_messages_recipient_address ON pending_messages(recipient_address);
+CREATE INDEX idx_pending_messages_sender_id ON pending_messages(sender_id, created_at);
+
+ALTER TABLE public.pending_messages
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_pending_messages_sender_id;
+DROP INDEX IF EXISTS idx_pending_messages_recipient_address;
+DROP TABLE IF EXISTS public.pending_messages;
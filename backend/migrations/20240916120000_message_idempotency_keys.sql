@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE message_idempotency_keys (
+    sender_id BIGINT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    message_id BIGINT,
+    pending_message_id BIGINT,
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (sender_id, idempotency_key),
+    FOREIGN KEY (sender_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.message_idempotency_keys
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE IF EXISTS public.message_idempotency_keys;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE message_chain_txs (
+    message_id BIGINT PRIMARY KEY,
+    recipient_address TEXT NOT NULL,
+    status INT NOT NULL DEFAULT 0,
+    tx_hash TEXT,
+    error TEXT,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_message_chain_txs_status ON message_chain_txs(status, message_id) WHERE status IN (0, 1);
+
+ALTER TABLE public.message_chain_txs
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_message_chain_txs_status;
+DROP TABLE IF EXISTS public.message_chain_txs;
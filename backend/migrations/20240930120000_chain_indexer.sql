@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.messages
+    ADD COLUMN block_number BIGINT,
+    ADD COLUMN tx_hash TEXT,
+    ADD COLUMN log_index INT;
+
+CREATE UNIQUE INDEX idx_messages_chain_log ON messages(tx_hash, log_index) WHERE tx_hash IS NOT NULL;
+
+CREATE INDEX idx_message_chain_txs_tx_hash ON message_chain_txs(tx_hash) WHERE tx_hash IS NOT NULL;
+
+CREATE TABLE chain_checkpoints (
+    name TEXT PRIMARY KEY,
+    next_block BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+ALTER TABLE public.chain_checkpoints
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE IF EXISTS public.chain_checkpoints;
+
+DROP INDEX IF EXISTS idx_message_chain_txs_tx_hash;
+DROP INDEX IF EXISTS idx_messages_chain_log;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS block_number,
+    DROP COLUMN IF EXISTS tx_hash,
+    DROP COLUMN IF EXISTS log_index;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE anchor_batches (
+    id BIGSERIAL PRIMARY KEY,
+    root TEXT NOT NULL,
+    first_message_id BIGINT NOT NULL,
+    last_message_id BIGINT NOT NULL,
+    size INT NOT NULL,
+    status INT NOT NULL DEFAULT 0,
+    tx_hash TEXT,
+    block_number BIGINT,
+    error TEXT,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_anchor_batches_status ON anchor_batches(status, id) WHERE status IN (0, 1);
+
+-- no foreign key on message_id: the leaves of deleted messages are needed to prove the rest of the batch
+CREATE TABLE anchor_leaves (
+    message_id BIGINT PRIMARY KEY,
+    batch_id BIGINT NOT NULL,
+    leaf_index INT NOT NULL,
+    message_hash TEXT NOT NULL,
+    FOREIGN KEY (batch_id) REFERENCES anchor_batches(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_anchor_leaves_batch ON anchor_leaves(batch_id, leaf_index);
+
+ALTER TABLE public.anchor_batches
+    OWNER TO bdd;
+ALTER TABLE public.anchor_leaves
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_anchor_leaves_batch;
+DROP TABLE IF EXISTS public.anchor_leaves;
+DROP INDEX IF EXISTS idx_anchor_batches_status;
+DROP TABLE IF EXISTS public.anchor_batches;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.message_chain_txs
+    ADD COLUMN sender_address TEXT,
+    ADD COLUMN relay_nonce TEXT,
+    ADD COLUMN relay_deadline BIGINT,
+    ADD COLUMN relay_signature TEXT,
+    ADD COLUMN account_nonce BIGINT,
+    ADD COLUMN gas_tip_cap TEXT,
+    ADD COLUMN gas_fee_cap TEXT,
+    ADD COLUMN submitted_at BIGINT,
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN replaced_tx_hashes TEXT[] NOT NULL DEFAULT '{}';
+
+-- a signature can be relayed once
+CREATE UNIQUE INDEX idx_message_chain_txs_relay_nonce ON message_chain_txs(sender_address, relay_nonce) WHERE relay_nonce IS NOT NULL;
+CREATE INDEX idx_message_chain_txs_relay_sender ON message_chain_txs(sender_address, created_at) WHERE relay_nonce IS NOT NULL;
+CREATE INDEX idx_message_chain_txs_replaced ON message_chain_txs USING GIN (replaced_tx_hashes);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_message_chain_txs_replaced;
+DROP INDEX IF EXISTS idx_message_chain_txs_relay_sender;
+DROP INDEX IF EXISTS idx_message_chain_txs_relay_nonce;
+
+ALTER TABLE public.message_chain_txs
+    DROP COLUMN IF EXISTS sender_address,
+    DROP COLUMN IF EXISTS relay_nonce,
+    DROP COLUMN IF EXISTS relay_deadline,
+    DROP COLUMN IF EXISTS relay_signature,
+    DROP COLUMN IF EXISTS account_nonce,
+    DROP COLUMN IF EXISTS gas_tip_cap,
+    DROP COLUMN IF EXISTS gas_fee_cap,
+    DROP COLUMN IF EXISTS submitted_at,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS replaced_tx_hashes;
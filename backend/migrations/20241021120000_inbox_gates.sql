@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE inbox_gates (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_address TEXT NOT NULL,
+    standard INT NOT NULL,
+    min_balance TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    UNIQUE (user_id, token_address),
+    FOREIGN KEY (user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.inbox_gates
+    OWNER TO bdd;
+
+-- set on the gate owner's row, the flag is about the other participant
+ALTER TABLE public.dialog_participants
+    ADD COLUMN gate_checked_at BIGINT,
+    ADD COLUMN gate_failed_at BIGINT;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.dialog_participants
+    DROP COLUMN IF EXISTS gate_checked_at,
+    DROP COLUMN IF EXISTS gate_failed_at;
+
+DROP TABLE IF EXISTS public.inbox_gates;
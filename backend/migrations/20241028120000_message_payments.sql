@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.users_chain
+    ADD COLUMN message_price_token TEXT,
+    ADD COLUMN message_price_amount TEXT;
+
+-- rows outlive their message so a transaction can't pay twice
+CREATE TABLE message_payments (
+    id BIGSERIAL PRIMARY KEY,
+    tx_hash TEXT NOT NULL UNIQUE,
+    message_id BIGINT,
+    dialog_id BIGINT NOT NULL,
+    payer_id BIGINT NOT NULL,
+    recipient_id BIGINT NOT NULL,
+    token_address TEXT,
+    amount TEXT NOT NULL,
+    deposit_id TEXT,
+    status INT NOT NULL,
+    refund_tx_hash TEXT,
+    attempts INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE SET NULL,
+    FOREIGN KEY (dialog_id) REFERENCES dialogs(id) ON DELETE CASCADE,
+    FOREIGN KEY (payer_id) REFERENCES users_chain(id) ON DELETE CASCADE,
+    FOREIGN KEY (recipient_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_message_payments_message_id ON message_payments(message_id);
+CREATE INDEX idx_message_payments_dialog_payer ON message_payments(dialog_id, payer_id);
+CREATE INDEX idx_message_payments_status ON message_payments(status, id);
+
+ALTER TABLE public.message_payments
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_message_payments_status;
+DROP INDEX IF EXISTS idx_message_payments_dialog_payer;
+DROP INDEX IF EXISTS idx_message_payments_message_id;
+DROP TABLE IF EXISTS public.message_payments;
+
+ALTER TABLE public.users_chain
+    DROP COLUMN IF EXISTS message_price_token,
+    DROP COLUMN IF EXISTS message_price_amount;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+-- messages issued before chains were recorded match no chain and are replaced on the next request
+ALTER TABLE public.auth_messages_chain
+    ADD COLUMN chain_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE public.login_sessions
+    ADD COLUMN chain_id BIGINT NOT NULL DEFAULT 0;
+
+-- the chains a user signed in from, the user is the same on all of them
+CREATE TABLE user_chains (
+    user_id BIGINT NOT NULL,
+    chain_id BIGINT NOT NULL,
+    first_signed_in_at BIGINT NOT NULL,
+    last_signed_in_at BIGINT NOT NULL,
+    PRIMARY KEY (user_id, chain_id),
+    FOREIGN KEY (user_id) REFERENCES users_chain(id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.user_chains
+    OWNER TO bdd;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE IF EXISTS public.user_chains;
+
+ALTER TABLE public.login_sessions
+    DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE public.auth_messages_chain
+    DROP COLUMN IF EXISTS chain_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+-- reaction changes are synced by id, timestamps of concurrent changes collide
+DROP INDEX IF EXISTS idx_message_reaction_events_dialog_id;
+CREATE INDEX idx_message_reaction_events_dialog_id ON message_reaction_events(dialog_id, id);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_message_reaction_events_dialog_id;
+CREATE INDEX idx_message_reaction_events_dialog_id ON message_reaction_events(dialog_id, created_at);
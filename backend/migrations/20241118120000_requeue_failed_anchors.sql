@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+-- reverted anchors used to fail their batch for good, the worker sends them again or confirms the ones
+-- the contract already holds
+UPDATE anchor_batches SET status = 0, tx_hash = NULL WHERE status = 3;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.anchor_batches
+    ADD COLUMN account_nonce BIGINT,
+    ADD COLUMN gas_tip_cap TEXT,
+    ADD COLUMN gas_fee_cap TEXT,
+    ADD COLUMN submitted_at BIGINT,
+    ADD COLUMN replaced_tx_hashes TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.anchor_batches
+    DROP COLUMN IF EXISTS account_nonce,
+    DROP COLUMN IF EXISTS gas_tip_cap,
+    DROP COLUMN IF EXISTS gas_fee_cap,
+    DROP COLUMN IF EXISTS submitted_at,
+    DROP COLUMN IF EXISTS replaced_tx_hashes;
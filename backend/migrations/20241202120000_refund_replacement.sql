@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.message_payments
+    ADD COLUMN refund_account_nonce BIGINT,
+    ADD COLUMN refund_gas_tip_cap TEXT,
+    ADD COLUMN refund_gas_fee_cap TEXT,
+    ADD COLUMN refund_submitted_at BIGINT,
+    ADD COLUMN replaced_refund_tx_hashes TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+ALTER TABLE public.message_payments
+    DROP COLUMN IF EXISTS refund_account_nonce,
+    DROP COLUMN IF EXISTS refund_gas_tip_cap,
+    DROP COLUMN IF EXISTS refund_gas_fee_cap,
+    DROP COLUMN IF EXISTS refund_submitted_at,
+    DROP COLUMN IF EXISTS replaced_refund_tx_hashes;
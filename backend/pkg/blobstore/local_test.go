@@ -0,0 +1,34 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	err = store.Put(ctx, "dialogs/1/abc", strings.NewReader("hello"), 5, "text/plain")
+	require.NoError(t, err)
+
+	r, err := store.Get(ctx, "dialogs/1/abc")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(data))
+
+	require.NoError(t, store.Delete(ctx, "dialogs/1/abc"))
+	_, err = store.Get(ctx, "dialogs/1/abc")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// keys can't escape the root directory
+	err = store.Put(ctx, "../escape", strings.NewReader("x"), 1, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3UnsignedBody  = "UNSIGNED-PAYLOAD"
+	s3AmzDateLayout = "20060102T150405Z"
+	s3DateLayout    = "20060102"
+)
+
+type S3Config struct {
+	// Endpoint is the base URL of the S3 compatible API, e.g. https://s3.eu-central-1.amazonaws.com
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+type s3Store struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store talks to an S3 compatible API using path-style addressing and SigV4 signed requests.
+func NewS3Store(cfg S3Config, client *http.Client) (BlobStore, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("NewS3Store: endpoint and bucket are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &s3Store{
+		cfg:    cfg,
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+func (s *s3Store) objectURL(key string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimRight(s.cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("objectURL/Parse: %w", err)
+	}
+	u.Path = u.Path + "/" + s.cfg.Bucket + "/" + key
+
+	return u, nil
+}
+
+func (s *s3Store) do(ctx context.Context, method, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("do/NewRequestWithContext: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	signS3Request(req, s.cfg, s.now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do/Do: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, contentType)
+	if err != nil {
+		return fmt.Errorf("Put: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Put: %s", readS3Error(resp))
+	}
+
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, fmt.Errorf("Get: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("Get: %s", readS3Error(resp))
+	}
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Delete: %s", readS3Error(resp))
+	}
+
+	return nil
+}
+
+func readS3Error(resp *http.Response) string {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Sprintf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
+
+// signS3Request adds SigV4 headers. The payload is left unsigned so uploads can be streamed.
+func signS3Request(req *http.Request, cfg S3Config, t time.Time) {
+	amzDate := t.Format(s3AmzDateLayout)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedBody)
+
+	signature, signedHeaders, scope := s3Signature(req, cfg, t)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func s3Signature(req *http.Request, cfg S3Config, t time.Time) (signature, signedHeaders, scope string) {
+	headers := map[string]string{"host": req.Host}
+	if headers["host"] == "" {
+		headers["host"] = req.URL.Host
+	}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders = strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	date := t.Format(s3DateLayout)
+	scope = strings.Join([]string{date, cfg.Region, s3Service, "aws4_request"}, "/")
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3AmzDateLayout),
+		scope,
+		hex.EncodeToString(hashedRequest[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign)), signedHeaders, scope
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
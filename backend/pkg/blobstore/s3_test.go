@@ -0,0 +1,106 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3 compatible API that checks SigV4 signatures.
+type fakeS3 struct {
+	cfg S3Config
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t, err := time.Parse(s3AmzDateLayout, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		http.Error(w, "missing date", http.StatusForbidden)
+		return
+	}
+	signature, _, _ := s3Signature(r, f.cfg, t)
+	if !strings.HasSuffix(r.Header.Get("Authorization"), "Signature="+signature) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	prefix := "/" + f.cfg.Bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = data
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	cfg := S3Config{
+		Region:    "eu-central-1",
+		Bucket:    "attachments",
+		AccessKey: "access",
+		SecretKey: "secret",
+	}
+	fake := &fakeS3{cfg: cfg, objects: make(map[string][]byte)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	cfg.Endpoint = srv.URL
+
+	store, err := NewS3Store(cfg, srv.Client())
+	require.NoError(t, err)
+
+	err = store.Put(ctx, "dialogs/1/abc", strings.NewReader("hello"), 5, "text/plain")
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(fake.objects["dialogs/1/abc"]))
+
+	r, err := store.Get(ctx, "dialogs/1/abc")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(data))
+
+	require.NoError(t, store.Delete(ctx, "dialogs/1/abc"))
+	_, err = store.Get(ctx, "dialogs/1/abc")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// requests signed with a wrong secret are rejected
+	wrongKey := cfg
+	wrongKey.SecretKey = "wrong"
+	badStore, err := NewS3Store(wrongKey, srv.Client())
+	require.NoError(t, err)
+	err = badStore.Put(ctx, "dialogs/1/abc", strings.NewReader("x"), 1, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/anchor"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Anchorer commits Merkle roots of message batches to the MessageAnchor contract. Passing a SentTx as
+// replace resends a stuck anchor with the same nonce and higher fees.
+type Anchorer interface {
+	Anchor(ctx context.Context, batchID int64, root common.Hash, replace *SentTx) (*SentTx, error)
+	// TxReceipt reports the status of an anchor transaction and the block it was mined in.
+	TxReceipt(ctx context.Context, hash common.Hash) (TxStatus, uint64, error)
+	// Anchored returns the root committed for batchID and the block it was committed in, a zero root
+	// if the batch isn't anchored.
+	Anchored(ctx context.Context, batchID int64) (common.Hash, uint64, error)
+	Contract() common.Address
+}
+
+type anchorer struct {
+	address  common.Address
+	contract *anchor.MessageAnchor
+	relayer  *Relayer
+}
+
+func NewAnchorer(backend Backend, contract common.Address, relayer *Relayer) (Anchorer, error) {
+	bound, err := anchor.NewMessageAnchor(contract, backend)
+	if err != nil {
+		return nil, fmt.Errorf("NewAnchorer/NewMessageAnchor: %w", err)
+	}
+
+	return &anchorer{
+		address:  contract,
+		contract: bound,
+		relayer:  relayer,
+	}, nil
+}
+
+// Anchor broadcasts MessageAnchor.anchor and returns without waiting for it to be mined.
+func (a *anchorer) Anchor(ctx context.Context, batchID int64, root common.Hash, replace *SentTx) (*SentTx, error) {
+	sent, err := a.relayer.transact(ctx, replace, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return a.contract.Anchor(opts, big.NewInt(batchID), root)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Anchor: %w", err)
+	}
+	return sent, nil
+}
+
+func (a *anchorer) TxReceipt(ctx context.Context, hash common.Hash) (TxStatus, uint64, error) {
+	return a.relayer.receipt(ctx, hash)
+}
+
+func (a *anchorer) Anchored(ctx context.Context, batchID int64) (common.Hash, uint64, error) {
+	it, err := a.contract.FilterAnchored(&bind.FilterOpts{Context: ctx}, []*big.Int{big.NewInt(batchID)})
+	if err != nil {
+		return common.Hash{}, 0, fmt.Errorf("Anchored/FilterAnchored: %w", err)
+	}
+	defer it.Close()
+
+	// the contract takes a root once per batch, so there is at most one event
+	if !it.Next() {
+		if it.Error() != nil {
+			return common.Hash{}, 0, fmt.Errorf("Anchored/Next: %w", it.Error())
+		}
+		return common.Hash{}, 0, nil
+	}
+	return it.Event.Root, it.Event.Raw.BlockNumber, nil
+}
+
+func (a *anchorer) Contract() common.Address {
+	return a.address
+}
@@ -0,0 +1,4 @@
+// Package anchor holds the Go bindings of the MessageAnchor contract from contracts/anchor.sol.
+package anchor
+
+//go:generate abigen --abi MessageAnchor.abi --bin MessageAnchor.bin --pkg anchor --type MessageAnchor --out anchor.go
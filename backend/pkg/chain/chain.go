@@ -0,0 +1,119 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/messaging"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/relayed"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrInvalidRelayerKey = errors.New("invalid relayer key")
+
+type TxStatus int
+
+const (
+	TxPending TxStatus = iota
+	TxConfirmed
+	TxFailed
+)
+
+// Backend is what the client needs from a node. Both *ethclient.Client and
+// go-ethereum's simulated.Client satisfy it.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+// Client submits messages to the Messaging contract on behalf of the backend's relayer account.
+// Passing a SentTx as replace resends a stuck transaction with the same nonce and higher fees.
+type Client interface {
+	SendMessage(ctx context.Context, receiver common.Address, content string, replace *SentTx) (*SentTx, error)
+	// RelayMessage submits a message its sender signed through RelayedMessaging.sendMessageBySig,
+	// the relayer pays the gas and the log names the signer as the sender.
+	RelayMessage(ctx context.Context, m *SignedMessage, replace *SentTx) (*SentTx, error)
+	TxStatus(ctx context.Context, hash common.Hash) (TxStatus, error)
+	Relayer() common.Address
+	// Domain is the EIP-712 domain RelayMessage signatures are made in.
+	Domain() *Domain
+}
+
+type client struct {
+	contract *messaging.Messaging
+	relayed  *relayed.RelayedMessaging
+	domain   *Domain
+	relayer  *Relayer
+}
+
+func NewClient(backend Backend, contract common.Address, relayer *Relayer) (Client, error) {
+	bound, err := messaging.NewMessaging(contract, backend)
+	if err != nil {
+		return nil, fmt.Errorf("NewClient/NewMessaging: %w", err)
+	}
+	boundRelayed, err := relayed.NewRelayedMessaging(contract, backend)
+	if err != nil {
+		return nil, fmt.Errorf("NewClient/NewRelayedMessaging: %w", err)
+	}
+
+	return &client{
+		contract: bound,
+		relayed:  boundRelayed,
+		domain:   NewDomain(relayer.ChainID(), contract),
+		relayer:  relayer,
+	}, nil
+}
+
+// ParseRelayerKey decodes a hex encoded secp256k1 private key, with or without the 0x prefix.
+func ParseRelayerKey(hexKey string) (*ecdsa.PrivateKey, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, ErrInvalidRelayerKey
+	}
+	return key, nil
+}
+
+// SendMessage broadcasts Messaging.sendMessage and returns without waiting for it to be mined.
+func (c *client) SendMessage(ctx context.Context, receiver common.Address, content string, replace *SentTx) (*SentTx, error) {
+	sent, err := c.relayer.transact(ctx, replace, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.SendMessage(opts, receiver, content)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SendMessage: %w", err)
+	}
+	return sent, nil
+}
+
+func (c *client) RelayMessage(ctx context.Context, m *SignedMessage, replace *SentTx) (*SentTx, error) {
+	v, r, s, err := splitSignature(m.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("RelayMessage/splitSignature: %w", err)
+	}
+	sent, err := c.relayer.transact(ctx, replace, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.relayed.SendMessageBySig(opts, m.Sender, m.Receiver, m.Content, m.Nonce, m.Deadline, v, r, s)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RelayMessage: %w", err)
+	}
+	return sent, nil
+}
+
+// TxStatus reports TxPending until the transaction has a receipt.
+func (c *client) TxStatus(ctx context.Context, hash common.Hash) (TxStatus, error) {
+	status, _, err := c.relayer.receipt(ctx, hash)
+	return status, err
+}
+
+func (c *client) Relayer() common.Address {
+	return c.relayer.Address()
+}
+
+func (c *client) Domain() *Domain {
+	return c.domain
+}
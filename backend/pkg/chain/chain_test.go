@@ -0,0 +1,874 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/anchor"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/ens"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/escrow"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/messaging"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/relayed"
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/tokentest"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+// testBackend is the simulated node's client with the failures of a real node: broadcasts fail
+// while sendErr is set, and with legacy set headers have no base fee like before London.
+type testBackend struct {
+	simulated.Client
+	sendErr error
+	legacy  bool
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+	return b.Client.SendTransaction(ctx, tx)
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, err := b.Client.HeaderByNumber(ctx, number)
+	if err == nil && b.legacy {
+		header.BaseFee = nil
+	}
+	return header, err
+}
+
+// testChain is a simulated node with the keys funded in the genesis block. Transactions stay
+// pending until mine commits a block.
+type testChain struct {
+	t       *testing.T
+	sim     *simulated.Backend
+	backend *testBackend
+	// chainID is the one every simulated backend uses
+	chainID *big.Int
+}
+
+func newTestChain(t *testing.T, keys ...*ecdsa.PrivateKey) *testChain {
+	alloc := make(types.GenesisAlloc, len(keys))
+	for _, key := range keys {
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: new(big.Int).Lsh(big.NewInt(1), 100)}
+	}
+	sim := simulated.NewBackend(alloc)
+	t.Cleanup(func() { sim.Close() })
+	// the block pending on top of the genesis doesn't run the Shanghai rules the contracts need
+	sim.Commit()
+
+	return &testChain{t: t, sim: sim, backend: &testBackend{Client: sim.Client()}, chainID: big.NewInt(1337)}
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return key
+}
+
+func (c *testChain) transactor(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts, err := bind.NewKeyedTransactorWithChainID(key, c.chainID)
+	require.NoError(c.t, err)
+	return opts
+}
+
+// mine commits a block with the pending transactions and requires txs to have succeeded in it.
+func (c *testChain) mine(txs ...*types.Transaction) {
+	c.sim.Commit()
+	for _, tx := range txs {
+		receipt, err := c.backend.TransactionReceipt(context.Background(), tx.Hash())
+		require.NoError(c.t, err)
+		require.Equal(c.t, types.ReceiptStatusSuccessful, receipt.Status)
+	}
+}
+
+// transfer sends value wei from key to `to` in a plain transaction.
+func (c *testChain) transfer(key *ecdsa.PrivateKey, to common.Address, value int64) *types.Transaction {
+	ctx := context.Background()
+	nonce, err := c.backend.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	require.NoError(c.t, err)
+	gasPrice, err := c.backend.SuggestGasPrice(ctx)
+	require.NoError(c.t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(c.chainID), &types.LegacyTx{
+		Nonce: nonce, To: &to, Value: big.NewInt(value), Gas: 21000, GasPrice: gasPrice,
+	})
+	require.NoError(c.t, err)
+	require.NoError(c.t, c.backend.SendTransaction(ctx, tx))
+	return tx
+}
+
+func (c *testChain) head() *types.Header {
+	head, err := c.backend.HeaderByNumber(context.Background(), nil)
+	require.NoError(c.t, err)
+	return head
+}
+
+func TestSendMessage(t *testing.T) {
+	ctx := context.Background()
+	relayerKey := newKey(t)
+	c := newTestChain(t, relayerKey)
+	contract, deploy, _, err := messaging.DeployMessaging(c.transactor(relayerKey), c.backend)
+	require.NoError(t, err)
+	c.mine(deploy)
+
+	relayer, err := NewRelayer(c.backend, relayerKey, c.chainID, GasPolicy{})
+	require.NoError(t, err)
+	client, err := NewClient(c.backend, contract, relayer)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(relayerKey.PublicKey), client.Relayer())
+
+	receiver := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	sent, err := client.SendMessage(ctx, receiver, "hello", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), sent.Nonce)
+
+	tx, pending, err := c.backend.TransactionByHash(ctx, sent.Hash)
+	require.NoError(t, err)
+	require.True(t, pending)
+	require.Equal(t, contract, *tx.To())
+	from, err := types.Sender(types.LatestSignerForChainID(c.chainID), tx)
+	require.NoError(t, err)
+	require.Equal(t, client.Relayer(), from)
+
+	status, err := client.TxStatus(ctx, sent.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxPending, status)
+
+	c.mine()
+	status, err = client.TxStatus(ctx, sent.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+}
+
+func TestAnchor(t *testing.T) {
+	ctx := context.Background()
+	relayerKey := newKey(t)
+	c := newTestChain(t, relayerKey)
+	contract, deploy, bound, err := anchor.DeployMessageAnchor(c.transactor(relayerKey), c.backend)
+	require.NoError(t, err)
+	messagingContract, deployMessaging, _, err := messaging.DeployMessaging(c.transactor(relayerKey), c.backend)
+	require.NoError(t, err)
+	c.mine(deploy, deployMessaging)
+
+	relayer, err := NewRelayer(c.backend, relayerKey, c.chainID, GasPolicy{BumpPercent: 12})
+	require.NoError(t, err)
+	messages, err := NewClient(c.backend, messagingContract, relayer)
+	require.NoError(t, err)
+	a, err := NewAnchorer(c.backend, contract, relayer)
+	require.NoError(t, err)
+	require.Equal(t, contract, a.Contract())
+
+	// the clients sharing the relayer take turns with the nonce, both transactions are mined together
+	sent, err := messages.SendMessage(ctx, common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"), "hello", nil)
+	require.NoError(t, err)
+	root := crypto.Keccak256Hash([]byte("root"))
+	sentAnchor, err := a.Anchor(ctx, 7, root, nil)
+	require.NoError(t, err)
+
+	status, _, err := a.TxReceipt(ctx, sentAnchor.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxPending, status)
+	c.mine()
+	status, block, err := a.TxReceipt(ctx, sentAnchor.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+	require.Equal(t, c.head().Number.Uint64(), block)
+	status, err = messages.TxStatus(ctx, sent.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+
+	stored, err := bound.Roots(&bind.CallOpts{Context: ctx}, big.NewInt(7))
+	require.NoError(t, err)
+	require.Equal(t, root, common.Hash(stored))
+
+	// both anchors of a batch pass the gas estimate, the one mined second reverts
+	first, err := a.Anchor(ctx, 8, root, nil)
+	require.NoError(t, err)
+	second, err := a.Anchor(ctx, 8, crypto.Keccak256Hash([]byte("other root")), nil)
+	require.NoError(t, err)
+	c.mine()
+	status, _, err = a.TxReceipt(ctx, first.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+	status, _, err = a.TxReceipt(ctx, second.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxFailed, status)
+
+	// the batch keeps the root of the anchor that went through
+	anchored, block, err := a.Anchored(ctx, 8)
+	require.NoError(t, err)
+	require.Equal(t, root, anchored)
+	require.Equal(t, c.head().Number.Uint64(), block)
+	anchored, _, err = a.Anchored(ctx, 11)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, anchored)
+
+	// after a failed send the nonce is fetched again, the key may have been used elsewhere meanwhile
+	c.backend.sendErr = errors.New("connection refused")
+	_, err = a.Anchor(ctx, 9, root, nil)
+	require.Error(t, err)
+	c.backend.sendErr = nil
+	elsewhere, err := bound.Anchor(c.transactor(relayerKey), big.NewInt(9), root)
+	require.NoError(t, err)
+	c.mine(elsewhere)
+
+	stuck, err := a.Anchor(ctx, 10, root, nil)
+	require.NoError(t, err)
+
+	// a stuck anchor is replaced with the same nonce, only the replacement is mined
+	replacement, err := a.Anchor(ctx, 10, root, stuck)
+	require.NoError(t, err)
+	require.Equal(t, stuck.Nonce, replacement.Nonce)
+	require.NotEqual(t, stuck.Hash, replacement.Hash)
+	c.mine()
+	status, _, err = a.TxReceipt(ctx, replacement.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+	status, _, err = a.TxReceipt(ctx, stuck.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxPending, status)
+}
+
+func TestRelayMessage(t *testing.T) {
+	ctx := context.Background()
+	relayerKey := newKey(t)
+	c := newTestChain(t, relayerKey)
+	contract, deploy, bound, err := relayed.DeployRelayedMessaging(c.transactor(relayerKey), c.backend)
+	require.NoError(t, err)
+	c.mine(deploy)
+
+	relayer, err := NewRelayer(c.backend, relayerKey, c.chainID, GasPolicy{})
+	require.NoError(t, err)
+	client, err := NewClient(c.backend, contract, relayer)
+	require.NoError(t, err)
+	require.Equal(t, NewDomain(c.chainID, contract), client.Domain())
+
+	// the contract builds the same domain
+	separator, err := bound.DOMAINSEPARATOR(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	require.Equal(t, common.Hash(separator), client.Domain().Separator())
+
+	senderKey := newKey(t)
+	m := &SignedMessage{
+		Sender:   crypto.PubkeyToAddress(senderKey.PublicKey),
+		Receiver: common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		Content:  "hello without gas",
+		Nonce:    big.NewInt(42),
+		Deadline: big.NewInt(time.Now().Add(time.Hour).Unix()),
+	}
+
+	// wallets sign what eth_signTypedData_v4 hashes
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"SendMessage": {
+				{Name: "receiver", Type: "address"},
+				{Name: "content", Type: "string"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SendMessage",
+		Domain: apitypes.TypedDataDomain{
+			Name:              DomainName,
+			Version:           DomainVersion,
+			ChainId:           (*math.HexOrDecimal256)(c.chainID),
+			VerifyingContract: contract.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"receiver": m.Receiver.Hex(),
+			"content":  m.Content,
+			"nonce":    "42",
+			"deadline": m.Deadline.String(),
+		},
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typed)
+	require.NoError(t, err)
+	require.Equal(t, common.BytesToHash(digest), client.Domain().Digest(m))
+
+	sig, err := crypto.Sign(digest, senderKey)
+	require.NoError(t, err)
+	m.Signature = sig
+	signer, err := client.Domain().Signer(m)
+	require.NoError(t, err)
+	require.Equal(t, m.Sender, signer)
+	sig[64] += 27
+	signer, err = client.Domain().Signer(m)
+	require.NoError(t, err)
+	require.Equal(t, m.Sender, signer)
+
+	tampered := *m
+	tampered.Content = "hello with gas"
+	signer, err = client.Domain().Signer(&tampered)
+	require.NoError(t, err)
+	require.NotEqual(t, m.Sender, signer)
+
+	// the contract rejects it as well, the gas estimate fails before anything is sent
+	_, err = client.RelayMessage(ctx, &tampered, nil)
+	require.Error(t, err)
+
+	tampered.Signature = sig[:64]
+	_, err = client.Domain().Signer(&tampered)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// the same signature sent twice passes the gas estimate, the one mined second reverts
+	sent, err := client.RelayMessage(ctx, m, nil)
+	require.NoError(t, err)
+	replayed, err := client.RelayMessage(ctx, m, nil)
+	require.NoError(t, err)
+	c.mine()
+	status, err := client.TxStatus(ctx, sent.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+	status, err = client.TxStatus(ctx, replayed.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxFailed, status)
+
+	// the log names the signer, not the relayer who paid for it
+	events, err := NewEventSource(c.backend, contract)
+	require.NoError(t, err)
+	head := c.head().Number.Uint64()
+	logs, err := events.MessageSent(ctx, head, head)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, m.Sender, logs[0].Sender)
+	require.Equal(t, m.Receiver, logs[0].Receiver)
+	require.Equal(t, sent.Hash, logs[0].TxHash)
+}
+
+// bumped is fee raised by percent, rounded up.
+func bumped(fee *big.Int, percent int64) *big.Int {
+	b := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	b.Add(b, big.NewInt(99))
+	return b.Div(b, big.NewInt(100))
+}
+
+func TestReplaceStuckTx(t *testing.T) {
+	ctx := context.Background()
+	relayerKey, cappedKey := newKey(t), newKey(t)
+	c := newTestChain(t, relayerKey, cappedKey)
+	contract, deploy, _, err := messaging.DeployMessaging(c.transactor(relayerKey), c.backend)
+	require.NoError(t, err)
+	c.mine(deploy)
+
+	baseFee := c.head().BaseFee
+	tip, err := c.backend.SuggestGasTipCap(ctx)
+	require.NoError(t, err)
+	feeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+
+	relayer, err := NewRelayer(c.backend, relayerKey, c.chainID, GasPolicy{MaxFeePerGas: bumped(feeCap, 12), BumpPercent: 12})
+	require.NoError(t, err)
+	client, err := NewClient(c.backend, contract, relayer)
+	require.NoError(t, err)
+	receiver := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	// twice the base fee plus the tip
+	stuck, err := client.SendMessage(ctx, receiver, "hello", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), stuck.Nonce)
+	require.Equal(t, tip, stuck.GasTipCap)
+	require.Equal(t, feeCap, stuck.GasFeeCap)
+
+	// the replacement keeps the nonce and outbids both fees, the node takes it in place of the stuck one
+	replacement, err := client.SendMessage(ctx, receiver, "hello", stuck)
+	require.NoError(t, err)
+	require.Equal(t, stuck.Nonce, replacement.Nonce)
+	require.Equal(t, bumped(tip, 12), replacement.GasTipCap)
+	require.Equal(t, bumped(feeCap, 12), replacement.GasFeeCap)
+	require.NotEqual(t, stuck.Hash, replacement.Hash)
+
+	// a transaction at the fee cap can't be outbid
+	_, err = client.SendMessage(ctx, receiver, "hello", replacement)
+	require.ErrorIs(t, err, ErrFeeCapReached)
+
+	// replacing doesn't move the tracked nonce
+	next, err := client.SendMessage(ctx, receiver, "next", nil)
+	require.NoError(t, err)
+	require.Equal(t, stuck.Nonce+1, next.Nonce)
+
+	c.mine()
+	for hash, want := range map[common.Hash]TxStatus{stuck.Hash: TxPending, replacement.Hash: TxConfirmed, next.Hash: TxConfirmed} {
+		status, err := client.TxStatus(ctx, hash)
+		require.NoError(t, err)
+		require.Equal(t, want, status)
+	}
+
+	// fees over the cap are capped, the tip too when it's higher than the cap
+	baseFee = c.head().BaseFee
+	maxFee := new(big.Int).Add(baseFee, tip)
+	cappedRelayer, err := NewRelayer(c.backend, cappedKey, c.chainID, GasPolicy{MaxFeePerGas: maxFee, BumpPercent: 12})
+	require.NoError(t, err)
+	capped, err := NewClient(c.backend, contract, cappedRelayer)
+	require.NoError(t, err)
+	sent, err := capped.SendMessage(ctx, receiver, "capped", nil)
+	require.NoError(t, err)
+	require.Equal(t, maxFee, sent.GasFeeCap)
+	require.Equal(t, tip, sent.GasTipCap)
+
+	// chains without a base fee get legacy transactions at the suggested gas price
+	c.backend.legacy = true
+	gasPrice, err := c.backend.SuggestGasPrice(ctx)
+	require.NoError(t, err)
+	legacy, err := client.SendMessage(ctx, receiver, "legacy", nil)
+	require.NoError(t, err)
+	require.Equal(t, next.Nonce+1, legacy.Nonce)
+	tx, _, err := c.backend.TransactionByHash(ctx, legacy.Hash)
+	require.NoError(t, err)
+	require.Equal(t, uint8(types.LegacyTxType), tx.Type())
+	require.Equal(t, gasPrice, tx.GasPrice())
+
+	c.mine()
+	for _, hash := range []common.Hash{sent.Hash, legacy.Hash} {
+		status, err := client.TxStatus(ctx, hash)
+		require.NoError(t, err)
+		require.Equal(t, TxConfirmed, status)
+	}
+}
+
+func TestParseRelayerKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	hexKey := common.Bytes2Hex(crypto.FromECDSA(key))
+
+	for _, s := range []string{hexKey, "0x" + hexKey} {
+		parsed, err := ParseRelayerKey(s)
+		require.NoError(t, err)
+		require.Equal(t, key.D, parsed.D)
+	}
+
+	_, err = ParseRelayerKey("0x1234")
+	require.ErrorIs(t, err, ErrInvalidRelayerKey)
+}
+
+func TestMessageSent(t *testing.T) {
+	ctx := context.Background()
+	deployerKey, senderKey := newKey(t), newKey(t)
+	c := newTestChain(t, deployerKey, senderKey)
+	contract, deploy, bound, err := messaging.DeployMessaging(c.transactor(deployerKey), c.backend)
+	require.NoError(t, err)
+	c.mine(deploy)
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+	receiver := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	// wallets call the contract themselves, two messages share a block
+	opts := c.transactor(senderKey)
+	first, err := bound.SendMessage(opts, receiver, "first")
+	require.NoError(t, err)
+	second, err := bound.SendMessage(opts, receiver, "second")
+	require.NoError(t, err)
+	c.mine(first, second)
+	secondBlock := c.head()
+	c.mine()
+	third, err := bound.SendMessage(opts, receiver, "third")
+	require.NoError(t, err)
+	c.mine(third)
+	thirdBlock := c.head().Number.Uint64()
+
+	events, err := NewEventSource(c.backend, contract)
+	require.NoError(t, err)
+
+	latest, err := events.LatestBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, thirdBlock, latest)
+
+	receipt, err := c.backend.TransactionReceipt(ctx, second.Hash())
+	require.NoError(t, err)
+	sent, err := events.MessageSent(ctx, 0, thirdBlock-1)
+	require.NoError(t, err)
+	require.Len(t, sent, 2)
+	require.Equal(t, "first", sent[0].Content)
+	require.Equal(t, &MessageSent{
+		Sender:      sender,
+		Receiver:    receiver,
+		Content:     "second",
+		BlockNumber: secondBlock.Number.Uint64(),
+		BlockTime:   int64(secondBlock.Time) * 1000,
+		TxHash:      second.Hash(),
+		LogIndex:    receipt.Logs[0].Index,
+	}, sent[1])
+	require.NotEqual(t, sent[0].LogIndex, sent[1].LogIndex)
+
+	sent, err = events.MessageSent(ctx, thirdBlock, thirdBlock)
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	require.Equal(t, "third", sent[0].Content)
+	require.Equal(t, third.Hash(), sent[0].TxHash)
+}
+
+func TestDialogue(t *testing.T) {
+	ctx := context.Background()
+	senderKey := newKey(t)
+	c := newTestChain(t, senderKey)
+	contract, deploy, bound, err := messaging.DeployMessaging(c.transactor(senderKey), c.backend)
+	require.NoError(t, err)
+	c.mine(deploy)
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+
+	opts := c.transactor(senderKey)
+	first, err := bound.SendMessage(opts, receiver, "first")
+	require.NoError(t, err)
+	second, err := bound.SendMessage(opts, receiver, "second")
+	require.NoError(t, err)
+	c.mine(first, second)
+
+	reader, err := NewDialogueReader(c.backend, contract)
+	require.NoError(t, err)
+
+	dialogue, err := reader.Dialogue(ctx, sender, receiver)
+	require.NoError(t, err)
+	require.Equal(t, []*DialogueMessage{
+		{Sender: sender, Receiver: receiver, Content: "first"},
+		{Sender: sender, Receiver: receiver, Content: "second"},
+	}, dialogue)
+
+	// The mapping is keyed by direction
+	dialogue, err = reader.Dialogue(ctx, receiver, sender)
+	require.NoError(t, err)
+	require.Empty(t, dialogue)
+}
+
+func TestCachedHoldings(t *testing.T) {
+	ctx := context.Background()
+	holderKey := newKey(t)
+	c := newTestChain(t, holderKey)
+	holder := crypto.PubkeyToAddress(holderKey.PublicKey)
+	stranger := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+
+	opts := c.transactor(holderKey)
+	collection, deploy, bound, err := tokentest.DeployTestCollection(opts, c.backend)
+	require.NoError(t, err)
+	c.mine(deploy)
+	for id := int64(1); id <= 3; id++ {
+		minted, err := bound.Mint(opts, holder, big.NewInt(id))
+		require.NoError(t, err)
+		c.mine(minted)
+	}
+
+	cached := NewCachedHoldings(NewHoldings(c.backend), time.Second)
+
+	balance, err := cached.BalanceOf(ctx, collection, holder)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), balance.Int64())
+	balance, err = cached.BalanceOf(ctx, collection, stranger)
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+
+	// The holder sold a token, the cache serves the old balances until they expire
+	sold, err := bound.TransferFrom(opts, holder, stranger, big.NewInt(2))
+	require.NoError(t, err)
+	c.mine(sold)
+	balance, err = cached.BalanceOf(ctx, collection, holder)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), balance.Int64())
+	balance, err = cached.BalanceOf(ctx, collection, stranger)
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+
+	// Callers can't change the cached balance
+	balance, err = cached.BalanceOf(ctx, collection, holder)
+	require.NoError(t, err)
+	balance.SetInt64(100)
+	balance, err = cached.BalanceOf(ctx, collection, holder)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), balance.Int64())
+
+	time.Sleep(1100 * time.Millisecond)
+	balance, err = cached.BalanceOf(ctx, collection, holder)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), balance.Int64())
+	balance, err = cached.BalanceOf(ctx, collection, stranger)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), balance.Int64())
+}
+
+func TestNamehash(t *testing.T) {
+	require.Equal(t, common.Hash{}, Namehash(""))
+	require.Equal(t, common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), Namehash("eth"))
+	require.Equal(t, common.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"), Namehash("foo.eth"))
+
+	name, err := NormalizeName(" Alice.ETH ")
+	require.NoError(t, err)
+	require.Equal(t, "alice.eth", name)
+	for _, invalid := range []string{"", "alice..eth", ".eth", "al ice.eth"} {
+		_, err := NormalizeName(invalid)
+		require.ErrorIs(t, err, ErrInvalidName, invalid)
+	}
+}
+
+// testNames is a NameRegistry and NameResolver deployed by owner, who owns every node.
+type testNames struct {
+	c               *testChain
+	owner           *bind.TransactOpts
+	address         common.Address
+	registry        *ens.Registry
+	resolver        *ens.Resolver
+	resolverAddress common.Address
+}
+
+func newTestNames(c *testChain, ownerKey *ecdsa.PrivateKey) *testNames {
+	n := &testNames{c: c, owner: c.transactor(ownerKey)}
+	var deploy *types.Transaction
+	var err error
+	n.address, deploy, n.registry, err = ens.DeployRegistry(n.owner, c.backend)
+	require.NoError(c.t, err)
+	c.mine(deploy)
+	n.resolverAddress, deploy, n.resolver, err = ens.DeployResolver(n.owner, c.backend, n.address)
+	require.NoError(c.t, err)
+	c.mine(deploy)
+	return n
+}
+
+// claim makes the owner own name and points it to the resolver.
+func (n *testNames) claim(name string) common.Hash {
+	labels := strings.Split(name, ".")
+	node := common.Hash{}
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256Hash([]byte(labels[i]))
+		tx, err := n.registry.SetSubnodeOwner(n.owner, node, label, n.owner.From)
+		require.NoError(n.c.t, err)
+		n.c.mine(tx)
+		node = crypto.Keccak256Hash(node.Bytes(), label.Bytes())
+	}
+	tx, err := n.registry.SetResolver(n.owner, node, n.resolverAddress)
+	require.NoError(n.c.t, err)
+	n.c.mine(tx)
+	return node
+}
+
+func (n *testNames) setAddr(name string, address common.Address) {
+	tx, err := n.resolver.SetAddr(n.owner, n.claim(name), address)
+	require.NoError(n.c.t, err)
+	n.c.mine(tx)
+}
+
+func (n *testNames) setReverse(address common.Address, name string) {
+	node := n.claim(strings.ToLower(strings.TrimPrefix(address.Hex(), "0x")) + ".addr.reverse")
+	require.Equal(n.c.t, ReverseNode(address), node)
+	tx, err := n.resolver.SetName(n.owner, node, name)
+	require.NoError(n.c.t, err)
+	n.c.mine(tx)
+}
+
+func TestNames(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		ownerKey = newKey(t)
+		c        = newTestChain(t, ownerKey)
+		registry = newTestNames(c, ownerKey)
+		alice    = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+		bob      = common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	)
+	registry.setAddr("alice.eth", alice)
+	registry.setReverse(alice, "alice.eth")
+	// bob claims alice's name in reverse
+	registry.setReverse(bob, "alice.eth")
+
+	names, err := NewNames(c.backend, registry.address)
+	require.NoError(t, err)
+
+	address, err := names.Resolve(ctx, "Alice.eth")
+	require.NoError(t, err)
+	require.Equal(t, alice, address)
+	_, err = names.Resolve(ctx, "bob.eth")
+	require.ErrorIs(t, err, ErrNameNotFound)
+
+	name, err := names.Lookup(ctx, alice)
+	require.NoError(t, err)
+	require.Equal(t, "alice.eth", name)
+	_, err = names.Lookup(ctx, bob)
+	require.ErrorIs(t, err, ErrNameNotFound)
+	_, err = names.Lookup(ctx, common.HexToAddress("0x00000000000000000000000000000000000000a3"))
+	require.ErrorIs(t, err, ErrNameNotFound)
+}
+
+func TestCachedNames(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		ownerKey = newKey(t)
+		c        = newTestChain(t, ownerKey)
+		registry = newTestNames(c, ownerKey)
+		alice    = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+		bob      = common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	)
+	registry.setAddr("alice.eth", alice)
+
+	names, err := NewNames(c.backend, registry.address)
+	require.NoError(t, err)
+	cached := NewCachedNames(names, time.Second)
+
+	// Addresses and names that aren't found are cached too
+	_, err = cached.Lookup(ctx, alice)
+	require.ErrorIs(t, err, ErrNameNotFound)
+	_, err = cached.Resolve(ctx, "bob.eth")
+	require.ErrorIs(t, err, ErrNameNotFound)
+	registry.setReverse(alice, "alice.eth")
+	registry.setAddr("bob.eth", bob)
+	_, err = cached.Lookup(ctx, alice)
+	require.ErrorIs(t, err, ErrNameNotFound)
+	// the normalized name shares the entry
+	_, err = cached.Resolve(ctx, "BOB.eth")
+	require.ErrorIs(t, err, ErrNameNotFound)
+
+	time.Sleep(1100 * time.Millisecond)
+	name, err := cached.Lookup(ctx, alice)
+	require.NoError(t, err)
+	require.Equal(t, "alice.eth", name)
+	address, err := cached.Resolve(ctx, "bob.eth")
+	require.NoError(t, err)
+	require.Equal(t, bob, address)
+}
+
+func TestPayment(t *testing.T) {
+	ctx := context.Background()
+	payerKey, relayerKey := newKey(t), newKey(t)
+	c := newTestChain(t, payerKey, relayerKey)
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+	recipient := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	// the relayer operates the escrow it deploys
+	escrowAt, deployEscrow, bound, err := escrow.DeployMessageEscrow(c.transactor(relayerKey), c.backend)
+	require.NoError(t, err)
+	payerOpts := c.transactor(payerKey)
+	coin, deployCoin, token, err := tokentest.DeployTestToken(payerOpts, c.backend)
+	require.NoError(t, err)
+	collectionAt, deployCollection, collection, err := tokentest.DeployTestCollection(payerOpts, c.backend)
+	require.NoError(t, err)
+	c.mine(deployEscrow, deployCoin, deployCollection)
+	minted, err := token.Mint(payerOpts, payer, big.NewInt(10000))
+	require.NoError(t, err)
+	mintedNFT, err := collection.Mint(payerOpts, payer, big.NewInt(9))
+	require.NoError(t, err)
+	c.mine(minted, mintedNFT)
+	require.NotEqual(t, common.Address{}, collectionAt)
+
+	p, err := NewPayments(c.backend, c.chainID, escrowAt, nil)
+	require.NoError(t, err)
+
+	paid := c.transfer(payerKey, recipient, 1000)
+	c.mine(paid)
+	payment, err := p.Payment(ctx, paid.Hash())
+	require.NoError(t, err)
+	require.Equal(t, payer, payment.From)
+	require.Equal(t, c.head().Number.Uint64(), payment.BlockNumber)
+	require.Equal(t, uint64(1), payment.Confirmations)
+	require.Len(t, payment.Transfers, 1)
+	require.Equal(t, &Transfer{From: payer, To: recipient, Amount: big.NewInt(1000)}, payment.Transfers[0])
+	c.mine()
+	c.mine()
+	payment, err = p.Payment(ctx, paid.Hash())
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), payment.Confirmations)
+
+	tx, err := token.Transfer(payerOpts, recipient, big.NewInt(500))
+	require.NoError(t, err)
+	c.mine(tx)
+	payment, err = p.Payment(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Len(t, payment.Transfers, 1)
+	require.Equal(t, &Transfer{Token: coin, From: payer, To: recipient, Amount: big.NewInt(500)}, payment.Transfers[0])
+
+	// ERC-721 transfers share the event signature, they index the token id
+	tx, err = collection.TransferFrom(payerOpts, payer, recipient, big.NewInt(9))
+	require.NoError(t, err)
+	c.mine(tx)
+	payment, err = p.Payment(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Empty(t, payment.Transfers)
+
+	// An escrow deposit is a transfer to the recipient it's held for
+	depositOpts := c.transactor(payerKey)
+	depositOpts.Value = big.NewInt(700)
+	tx, err = bound.Deposit(depositOpts, recipient)
+	require.NoError(t, err)
+	c.mine(tx)
+	payment, err = p.Payment(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Len(t, payment.Transfers, 1)
+	// the first deposit has id 0, which the ABI decodes to a big.Int unequal to big.NewInt(0)
+	require.Zero(t, payment.Transfers[0].DepositID.Sign())
+	payment.Transfers[0].DepositID = nil
+	require.Equal(t, &Transfer{From: payer, To: recipient, Amount: big.NewInt(700)}, payment.Transfers[0])
+
+	// so is a token deposit, the token's own transfer goes to the escrow
+	approved, err := token.Approve(payerOpts, escrowAt, big.NewInt(300))
+	require.NoError(t, err)
+	c.mine(approved)
+	tx, err = bound.DepositToken(payerOpts, recipient, coin, big.NewInt(300))
+	require.NoError(t, err)
+	c.mine(tx)
+	payment, err = p.Payment(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Len(t, payment.Transfers, 1)
+	require.Equal(t, &Transfer{Token: coin, From: payer, To: recipient, Amount: big.NewInt(300), DepositID: big.NewInt(1)}, payment.Transfers[0])
+
+	// an empty deposit reverts, the gas limit skips the estimate that would refuse it
+	reverting := c.transactor(payerKey)
+	reverting.GasLimit = 100000
+	tx, err = bound.Deposit(reverting, recipient)
+	require.NoError(t, err)
+	c.mine()
+	_, err = p.Payment(ctx, tx.Hash())
+	require.ErrorIs(t, err, ErrTxReverted)
+	_, err = p.Payment(ctx, common.HexToHash("0x01"))
+	require.ErrorIs(t, err, ErrTxNotFound)
+	pending := c.transfer(payerKey, recipient, 1000)
+	_, err = p.Payment(ctx, pending.Hash())
+	require.ErrorIs(t, err, ErrTxNotMined)
+
+	// Refunds are signed by the relayer, which operates the escrow
+	require.False(t, p.CanRefund())
+	_, err = p.RefundDeposit(ctx, big.NewInt(0), nil)
+	require.ErrorIs(t, err, ErrNoOperator)
+
+	relayer, err := NewRelayer(c.backend, relayerKey, c.chainID, GasPolicy{BumpPercent: 12})
+	require.NoError(t, err)
+	p, err = NewPayments(c.backend, c.chainID, escrowAt, relayer)
+	require.NoError(t, err)
+	require.True(t, p.CanRefund())
+	refund, err := p.RefundDeposit(ctx, big.NewInt(0), nil)
+	require.NoError(t, err)
+	c.mine()
+	status, err := p.TxStatus(ctx, refund.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+	deposit, err := bound.Deposits(&bind.CallOpts{Context: ctx}, big.NewInt(0))
+	require.NoError(t, err)
+	require.True(t, deposit.Settled)
+	deposit, err = bound.Deposits(&bind.CallOpts{Context: ctx}, big.NewInt(1))
+	require.NoError(t, err)
+	require.False(t, deposit.Settled)
+
+	// a stuck refund is replaced with the same nonce, the replacement settles the deposit
+	stuck, err := p.RefundDeposit(ctx, big.NewInt(1), nil)
+	require.NoError(t, err)
+	replacement, err := p.RefundDeposit(ctx, big.NewInt(1), stuck)
+	require.NoError(t, err)
+	require.Equal(t, stuck.Nonce, replacement.Nonce)
+	c.mine()
+	status, err = p.TxStatus(ctx, replacement.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxConfirmed, status)
+	status, err = p.TxStatus(ctx, stuck.Hash)
+	require.NoError(t, err)
+	require.Equal(t, TxPending, status)
+	deposit, err = bound.Deposits(&bind.CallOpts{Context: ctx}, big.NewInt(1))
+	require.NoError(t, err)
+	require.True(t, deposit.Settled)
+}
@@ -0,0 +1,49 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/messaging"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DialogueMessage is an entry of the Messaging contract's dialogues mapping.
+type DialogueMessage struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}
+
+// DialogueReader reads the Messaging contract's storage. Messages the relayer sent are stored under
+// the relayer's address, gasless and wallet-sent messages under their sender's.
+type DialogueReader interface {
+	// Dialogue returns what sender sent receiver through the contract, oldest first.
+	Dialogue(ctx context.Context, sender, receiver common.Address) ([]*DialogueMessage, error)
+}
+
+type dialogueReader struct {
+	contract *messaging.MessagingCaller
+}
+
+func NewDialogueReader(backend bind.ContractCaller, contract common.Address) (DialogueReader, error) {
+	bound, err := messaging.NewMessagingCaller(contract, backend)
+	if err != nil {
+		return nil, fmt.Errorf("NewDialogueReader/NewMessagingCaller: %w", err)
+	}
+	return &dialogueReader{contract: bound}, nil
+}
+
+func (d *dialogueReader) Dialogue(ctx context.Context, sender, receiver common.Address) ([]*DialogueMessage, error) {
+	stored, err := d.contract.GetDialogue(&bind.CallOpts{Context: ctx}, sender, receiver)
+	if err != nil {
+		return nil, fmt.Errorf("Dialogue/GetDialogue: %w", err)
+	}
+
+	messages := make([]*DialogueMessage, 0, len(stored))
+	for _, m := range stored {
+		messages = append(messages, &DialogueMessage{Sender: m.Sender, Receiver: m.Receiver, Content: m.Content})
+	}
+	return messages, nil
+}
@@ -0,0 +1,116 @@
+package chain
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The EIP-712 domain name and version RelayedMessaging is deployed with.
+const (
+	DomainName    = "BddMessaging"
+	DomainVersion = "1"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	domainTypeHash      = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	sendMessageTypeHash = crypto.Keccak256Hash([]byte("SendMessage(address receiver,string content,uint256 nonce,uint256 deadline)"))
+)
+
+// Domain is the EIP-712 domain of the RelayedMessaging contract.
+type Domain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract common.Address
+}
+
+func NewDomain(chainID *big.Int, contract common.Address) *Domain {
+	return &Domain{
+		Name:              DomainName,
+		Version:           DomainVersion,
+		ChainID:           chainID,
+		VerifyingContract: contract,
+	}
+}
+
+// SignedMessage is a message its sender signed as the typed data
+// SendMessage{address receiver, string content, uint256 nonce, uint256 deadline}.
+type SignedMessage struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+	// Nonce is any number the sender hasn't used yet, nonces don't have to be sequential
+	Nonce *big.Int
+	// Deadline is the unix time in seconds after which the contract rejects the signature
+	Deadline  *big.Int
+	Signature []byte
+}
+
+func (d *Domain) Separator() common.Hash {
+	return crypto.Keccak256Hash(
+		domainTypeHash[:],
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		math.U256Bytes(new(big.Int).Set(d.ChainID)),
+		common.LeftPadBytes(d.VerifyingContract.Bytes(), 32),
+	)
+}
+
+// Digest is the hash the sender signs, the one sendMessageBySig recovers the signer from.
+func (d *Domain) Digest(m *SignedMessage) common.Hash {
+	structHash := crypto.Keccak256(
+		sendMessageTypeHash[:],
+		common.LeftPadBytes(m.Receiver.Bytes(), 32),
+		crypto.Keccak256([]byte(m.Content)),
+		math.U256Bytes(new(big.Int).Set(m.Nonce)),
+		math.U256Bytes(new(big.Int).Set(m.Deadline)),
+	)
+	separator := d.Separator()
+	return crypto.Keccak256Hash([]byte("\x19\x01"), separator[:], structHash)
+}
+
+// Signer recovers the address that signed m. Recovery ids 27/28 and 0/1 are both accepted.
+func (d *Domain) Signer(m *SignedMessage) (common.Address, error) {
+	v, r, s, err := splitSignature(m.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig[:32], r[:])
+	copy(sig[32:64], s[:])
+	sig[64] = v - 27
+	if !crypto.ValidateSignatureValues(sig[64], new(big.Int).SetBytes(r[:]), new(big.Int).SetBytes(s[:]), true) {
+		return common.Address{}, ErrInvalidSignature
+	}
+
+	digest := d.Digest(m)
+	pubKey, err := crypto.SigToPub(digest[:], sig)
+	if err != nil {
+		return common.Address{}, ErrInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// splitSignature returns the v, r, s sendMessageBySig takes, v is 27 or 28.
+func splitSignature(signature []byte) (uint8, [32]byte, [32]byte, error) {
+	var r, s [32]byte
+	if len(signature) != crypto.SignatureLength {
+		return 0, r, s, ErrInvalidSignature
+	}
+	v := signature[64]
+	if v < 27 {
+		v += 27
+	}
+	if v != 27 && v != 28 {
+		return 0, r, s, ErrInvalidSignature
+	}
+	copy(r[:], signature[:32])
+	copy(s[:], signature[32:64])
+	return v, r, s, nil
+}
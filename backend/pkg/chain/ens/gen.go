@@ -0,0 +1,6 @@
+// Package ens holds the Go bindings of NameRegistry and NameResolver from contracts/name_registry.sol,
+// a minimal ENS. Names are only resolved through the resolver, addr and name views the real ENS shares.
+package ens
+
+//go:generate abigen --abi Registry.abi --bin Registry.bin --pkg ens --type Registry --out registry.go
+//go:generate abigen --abi Resolver.abi --bin Resolver.bin --pkg ens --type Resolver --out resolver.go
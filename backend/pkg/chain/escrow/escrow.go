@@ -0,0 +1,687 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package escrow
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// MessageEscrowMetaData contains all meta data concerning the MessageEscrow contract.
+var MessageEscrowMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"payer\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"Deposited\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"}],\"name\":\"Settled\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"}],\"name\":\"claim\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"}],\"name\":\"deposit\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"depositToken\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"deposits\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"payer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"settled\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"operator\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"}],\"name\":\"refund\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+	Bin: "0x6080604052348015600e575f5ffd5b505f80546001600160a01b0319163317905561092d8061002d5f395ff3fe608060405260043610610054575f3560e01c8063278ecde114610058578063379607f514610079578063570ca73514610098578063b02c43d0146100d3578063f340fa011461012b578063fb0f97a81461014c575b5f5ffd5b348015610063575f5ffd5b506100776100723660046107f0565b61016b565b005b348015610084575f5ffd5b506100776100933660046107f0565b610201565b3480156100a3575f5ffd5b505f546100b6906001600160a01b031681565b6040516001600160a01b0390911681526020015b60405180910390f35b3480156100de575f5ffd5b506100f26100ed3660046107f0565b6102b6565b604080516001600160a01b039687168152948616602086015292909416918301919091526060820152901515608082015260a0016100ca565b61013e610139366004610822565b610306565b6040519081526020016100ca565b348015610157575f5ffd5b5061013e610166366004610842565b61035c565b5f546001600160a01b031633146101c95760405162461bcd60e51b815260206004820152601c60248201527f4f6e6c7920746865206f70657261746f722063616e20726566756e640000000060448201526064015b60405180910390fd5b6101fe81600183815481106101e0576101e061087c565b5f9182526020909120600590910201546001600160a01b0316610458565b50565b600181815481106102145761021461087c565b5f9182526020909120600590910201600101546001600160a01b0316331461027e5760405162461bcd60e51b815260206004820152601c60248201527f4f6e6c792074686520726563697069656e742063616e20636c61696d0000000060448201526064016101c0565b6101fe81600183815481106102955761029561087c565b5f9182526020909120600160059092020101546001600160a01b0316610458565b600181815481106102c5575f80fd5b5f918252602090912060059091020180546001820154600283015460038401546004909401546001600160a01b039384169550918316939216919060ff1685565b5f5f341161034b5760405162461bcd60e51b8152602060048201526012602482015271139bdd1a1a5b99c81d1bc819195c1bdcda5d60721b60448201526064016101c0565b610356825f34610645565b92915050565b5f6001600160a01b0383161580159061037457505f82115b6103b55760405162461bcd60e51b8152602060048201526012602482015271139bdd1a1a5b99c81d1bc819195c1bdcda5d60721b60448201526064016101c0565b6040516323b872dd60e01b8152336004820152306024820152604481018390526001600160a01b038416906323b872dd906064016020604051808303815f875af1158015610405573d5f5f3e3d5ffd5b505050506040513d601f19601f820116820180604052508101906104299190610890565b6104455760405162461bcd60e51b81526004016101c0906108af565b610450848484610645565b949350505050565b5f6001838154811061046c5761046c61087c565b5f9182526020909120600590910201600481015490915060ff16156104d35760405162461bcd60e51b815260206004820152601a60248201527f4465706f73697420697320616c726561647920736574746c656400000000000060448201526064016101c0565b60048101805460ff1916600117905560028101546001600160a01b031661056b5760038101546040515f916001600160a01b038516918381818185875af1925050503d805f811461053f576040519150601f19603f3d011682016040523d82523d5f602084013e610544565b606091505b50509050806105655760405162461bcd60e51b81526004016101c0906108af565b50610602565b6002810154600382015460405163a9059cbb60e01b81526001600160a01b038581166004830152602482019290925291169063a9059cbb906044016020604051808303815f875af11580156105c2573d5f5f3e3d5ffd5b505050506040513d601f19601f820116820180604052508101906105e69190610890565b6106025760405162461bcd60e51b81526004016101c0906108af565b6040516001600160a01b038316815283907f1ddc75d9b5fc6d37e23b3284e94f0db77b95dfe06b38540ac86c16e6ee3f72389060200160405180910390a2505050565b6040805160a0810182523381526001600160a01b0385811660208301908152858216938301938452606083018581525f60808501818152600180548082018255818452965160059097027fb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cf6810180549888166001600160a01b0319998a1617905594517fb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cf78601805491881691891691909117905596517fb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cf88501805491909616961695909517909355517fb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cf982015591517fb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cfa909201805492151560ff199093169290921790915581549091829161079a91906108d8565b604080516001600160a01b0387811682526020820187905292935091871691339184917f7a449f26bcadb0ab23332b882f7c3cc37827ee0b22aac3c0dc80e7713c5933e9910160405180910390a4949350505050565b5f60208284031215610800575f5ffd5b5035919050565b80356001600160a01b038116811461081d575f5ffd5b919050565b5f60208284031215610832575f5ffd5b61083b82610807565b9392505050565b5f5f5f60608486031215610854575f5ffd5b61085d84610807565b925061086b60208501610807565b929592945050506040919091013590565b634e487b7160e01b5f52603260045260245ffd5b5f602082840312156108a0575f5ffd5b8151801515811461083b575f5ffd5b6020808252600f908201526e151c985b9cd9995c8819985a5b1959608a1b604082015260600190565b8181038181111561035657634e487b7160e01b5f52601160045260245ffdfea26469706673582212209d35e1060db94a5915bd9b34033570d41f9a12ad286e3f37c026654142222e1864736f6c634300081e0033",
+}
+
+// MessageEscrowABI is the input ABI used to generate the binding from.
+// Deprecated: Use MessageEscrowMetaData.ABI instead.
+var MessageEscrowABI = MessageEscrowMetaData.ABI
+
+// MessageEscrowBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use MessageEscrowMetaData.Bin instead.
+var MessageEscrowBin = MessageEscrowMetaData.Bin
+
+// DeployMessageEscrow deploys a new Ethereum contract, binding an instance of MessageEscrow to it.
+func DeployMessageEscrow(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *MessageEscrow, error) {
+	parsed, err := MessageEscrowMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(MessageEscrowBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &MessageEscrow{MessageEscrowCaller: MessageEscrowCaller{contract: contract}, MessageEscrowTransactor: MessageEscrowTransactor{contract: contract}, MessageEscrowFilterer: MessageEscrowFilterer{contract: contract}}, nil
+}
+
+// MessageEscrow is an auto generated Go binding around an Ethereum contract.
+type MessageEscrow struct {
+	MessageEscrowCaller     // Read-only binding to the contract
+	MessageEscrowTransactor // Write-only binding to the contract
+	MessageEscrowFilterer   // Log filterer for contract events
+}
+
+// MessageEscrowCaller is an auto generated read-only Go binding around an Ethereum contract.
+type MessageEscrowCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MessageEscrowTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type MessageEscrowTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MessageEscrowFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type MessageEscrowFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MessageEscrowSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type MessageEscrowSession struct {
+	Contract     *MessageEscrow    // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// MessageEscrowCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type MessageEscrowCallerSession struct {
+	Contract *MessageEscrowCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts        // Call options to use throughout this session
+}
+
+// MessageEscrowTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type MessageEscrowTransactorSession struct {
+	Contract     *MessageEscrowTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts        // Transaction auth options to use throughout this session
+}
+
+// MessageEscrowRaw is an auto generated low-level Go binding around an Ethereum contract.
+type MessageEscrowRaw struct {
+	Contract *MessageEscrow // Generic contract binding to access the raw methods on
+}
+
+// MessageEscrowCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type MessageEscrowCallerRaw struct {
+	Contract *MessageEscrowCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// MessageEscrowTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type MessageEscrowTransactorRaw struct {
+	Contract *MessageEscrowTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewMessageEscrow creates a new instance of MessageEscrow, bound to a specific deployed contract.
+func NewMessageEscrow(address common.Address, backend bind.ContractBackend) (*MessageEscrow, error) {
+	contract, err := bindMessageEscrow(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageEscrow{MessageEscrowCaller: MessageEscrowCaller{contract: contract}, MessageEscrowTransactor: MessageEscrowTransactor{contract: contract}, MessageEscrowFilterer: MessageEscrowFilterer{contract: contract}}, nil
+}
+
+// NewMessageEscrowCaller creates a new read-only instance of MessageEscrow, bound to a specific deployed contract.
+func NewMessageEscrowCaller(address common.Address, caller bind.ContractCaller) (*MessageEscrowCaller, error) {
+	contract, err := bindMessageEscrow(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageEscrowCaller{contract: contract}, nil
+}
+
+// NewMessageEscrowTransactor creates a new write-only instance of MessageEscrow, bound to a specific deployed contract.
+func NewMessageEscrowTransactor(address common.Address, transactor bind.ContractTransactor) (*MessageEscrowTransactor, error) {
+	contract, err := bindMessageEscrow(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageEscrowTransactor{contract: contract}, nil
+}
+
+// NewMessageEscrowFilterer creates a new log filterer instance of MessageEscrow, bound to a specific deployed contract.
+func NewMessageEscrowFilterer(address common.Address, filterer bind.ContractFilterer) (*MessageEscrowFilterer, error) {
+	contract, err := bindMessageEscrow(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageEscrowFilterer{contract: contract}, nil
+}
+
+// bindMessageEscrow binds a generic wrapper to an already deployed contract.
+func bindMessageEscrow(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := MessageEscrowMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_MessageEscrow *MessageEscrowRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _MessageEscrow.Contract.MessageEscrowCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_MessageEscrow *MessageEscrowRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.MessageEscrowTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_MessageEscrow *MessageEscrowRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.MessageEscrowTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_MessageEscrow *MessageEscrowCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _MessageEscrow.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_MessageEscrow *MessageEscrowTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_MessageEscrow *MessageEscrowTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.contract.Transact(opts, method, params...)
+}
+
+// Deposits is a free data retrieval call binding the contract method 0xb02c43d0.
+//
+// Solidity: function deposits(uint256 ) view returns(address payer, address recipient, address token, uint256 amount, bool settled)
+func (_MessageEscrow *MessageEscrowCaller) Deposits(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	Payer     common.Address
+	Recipient common.Address
+	Token     common.Address
+	Amount    *big.Int
+	Settled   bool
+}, error) {
+	var out []interface{}
+	err := _MessageEscrow.contract.Call(opts, &out, "deposits", arg0)
+
+	outstruct := new(struct {
+		Payer     common.Address
+		Recipient common.Address
+		Token     common.Address
+		Amount    *big.Int
+		Settled   bool
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Payer = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Recipient = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Token = *abi.ConvertType(out[2], new(common.Address)).(*common.Address)
+	outstruct.Amount = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.Settled = *abi.ConvertType(out[4], new(bool)).(*bool)
+
+	return *outstruct, err
+
+}
+
+// Deposits is a free data retrieval call binding the contract method 0xb02c43d0.
+//
+// Solidity: function deposits(uint256 ) view returns(address payer, address recipient, address token, uint256 amount, bool settled)
+func (_MessageEscrow *MessageEscrowSession) Deposits(arg0 *big.Int) (struct {
+	Payer     common.Address
+	Recipient common.Address
+	Token     common.Address
+	Amount    *big.Int
+	Settled   bool
+}, error) {
+	return _MessageEscrow.Contract.Deposits(&_MessageEscrow.CallOpts, arg0)
+}
+
+// Deposits is a free data retrieval call binding the contract method 0xb02c43d0.
+//
+// Solidity: function deposits(uint256 ) view returns(address payer, address recipient, address token, uint256 amount, bool settled)
+func (_MessageEscrow *MessageEscrowCallerSession) Deposits(arg0 *big.Int) (struct {
+	Payer     common.Address
+	Recipient common.Address
+	Token     common.Address
+	Amount    *big.Int
+	Settled   bool
+}, error) {
+	return _MessageEscrow.Contract.Deposits(&_MessageEscrow.CallOpts, arg0)
+}
+
+// Operator is a free data retrieval call binding the contract method 0x570ca735.
+//
+// Solidity: function operator() view returns(address)
+func (_MessageEscrow *MessageEscrowCaller) Operator(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _MessageEscrow.contract.Call(opts, &out, "operator")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Operator is a free data retrieval call binding the contract method 0x570ca735.
+//
+// Solidity: function operator() view returns(address)
+func (_MessageEscrow *MessageEscrowSession) Operator() (common.Address, error) {
+	return _MessageEscrow.Contract.Operator(&_MessageEscrow.CallOpts)
+}
+
+// Operator is a free data retrieval call binding the contract method 0x570ca735.
+//
+// Solidity: function operator() view returns(address)
+func (_MessageEscrow *MessageEscrowCallerSession) Operator() (common.Address, error) {
+	return _MessageEscrow.Contract.Operator(&_MessageEscrow.CallOpts)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x379607f5.
+//
+// Solidity: function claim(uint256 id) returns()
+func (_MessageEscrow *MessageEscrowTransactor) Claim(opts *bind.TransactOpts, id *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.contract.Transact(opts, "claim", id)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x379607f5.
+//
+// Solidity: function claim(uint256 id) returns()
+func (_MessageEscrow *MessageEscrowSession) Claim(id *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.Claim(&_MessageEscrow.TransactOpts, id)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x379607f5.
+//
+// Solidity: function claim(uint256 id) returns()
+func (_MessageEscrow *MessageEscrowTransactorSession) Claim(id *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.Claim(&_MessageEscrow.TransactOpts, id)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0xf340fa01.
+//
+// Solidity: function deposit(address recipient) payable returns(uint256)
+func (_MessageEscrow *MessageEscrowTransactor) Deposit(opts *bind.TransactOpts, recipient common.Address) (*types.Transaction, error) {
+	return _MessageEscrow.contract.Transact(opts, "deposit", recipient)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0xf340fa01.
+//
+// Solidity: function deposit(address recipient) payable returns(uint256)
+func (_MessageEscrow *MessageEscrowSession) Deposit(recipient common.Address) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.Deposit(&_MessageEscrow.TransactOpts, recipient)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0xf340fa01.
+//
+// Solidity: function deposit(address recipient) payable returns(uint256)
+func (_MessageEscrow *MessageEscrowTransactorSession) Deposit(recipient common.Address) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.Deposit(&_MessageEscrow.TransactOpts, recipient)
+}
+
+// DepositToken is a paid mutator transaction binding the contract method 0xfb0f97a8.
+//
+// Solidity: function depositToken(address recipient, address token, uint256 amount) returns(uint256)
+func (_MessageEscrow *MessageEscrowTransactor) DepositToken(opts *bind.TransactOpts, recipient common.Address, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.contract.Transact(opts, "depositToken", recipient, token, amount)
+}
+
+// DepositToken is a paid mutator transaction binding the contract method 0xfb0f97a8.
+//
+// Solidity: function depositToken(address recipient, address token, uint256 amount) returns(uint256)
+func (_MessageEscrow *MessageEscrowSession) DepositToken(recipient common.Address, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.DepositToken(&_MessageEscrow.TransactOpts, recipient, token, amount)
+}
+
+// DepositToken is a paid mutator transaction binding the contract method 0xfb0f97a8.
+//
+// Solidity: function depositToken(address recipient, address token, uint256 amount) returns(uint256)
+func (_MessageEscrow *MessageEscrowTransactorSession) DepositToken(recipient common.Address, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.DepositToken(&_MessageEscrow.TransactOpts, recipient, token, amount)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x278ecde1.
+//
+// Solidity: function refund(uint256 id) returns()
+func (_MessageEscrow *MessageEscrowTransactor) Refund(opts *bind.TransactOpts, id *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.contract.Transact(opts, "refund", id)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x278ecde1.
+//
+// Solidity: function refund(uint256 id) returns()
+func (_MessageEscrow *MessageEscrowSession) Refund(id *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.Refund(&_MessageEscrow.TransactOpts, id)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x278ecde1.
+//
+// Solidity: function refund(uint256 id) returns()
+func (_MessageEscrow *MessageEscrowTransactorSession) Refund(id *big.Int) (*types.Transaction, error) {
+	return _MessageEscrow.Contract.Refund(&_MessageEscrow.TransactOpts, id)
+}
+
+// MessageEscrowDepositedIterator is returned from FilterDeposited and is used to iterate over the raw logs and unpacked data for Deposited events raised by the MessageEscrow contract.
+type MessageEscrowDepositedIterator struct {
+	Event *MessageEscrowDeposited // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *MessageEscrowDepositedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(MessageEscrowDeposited)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(MessageEscrowDeposited)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *MessageEscrowDepositedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *MessageEscrowDepositedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// MessageEscrowDeposited represents a Deposited event raised by the MessageEscrow contract.
+type MessageEscrowDeposited struct {
+	Id        *big.Int
+	Payer     common.Address
+	Recipient common.Address
+	Token     common.Address
+	Amount    *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeposited is a free log retrieval operation binding the contract event 0x7a449f26bcadb0ab23332b882f7c3cc37827ee0b22aac3c0dc80e7713c5933e9.
+//
+// Solidity: event Deposited(uint256 indexed id, address indexed payer, address indexed recipient, address token, uint256 amount)
+func (_MessageEscrow *MessageEscrowFilterer) FilterDeposited(opts *bind.FilterOpts, id []*big.Int, payer []common.Address, recipient []common.Address) (*MessageEscrowDepositedIterator, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+	var payerRule []interface{}
+	for _, payerItem := range payer {
+		payerRule = append(payerRule, payerItem)
+	}
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+
+	logs, sub, err := _MessageEscrow.contract.FilterLogs(opts, "Deposited", idRule, payerRule, recipientRule)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageEscrowDepositedIterator{contract: _MessageEscrow.contract, event: "Deposited", logs: logs, sub: sub}, nil
+}
+
+// WatchDeposited is a free log subscription operation binding the contract event 0x7a449f26bcadb0ab23332b882f7c3cc37827ee0b22aac3c0dc80e7713c5933e9.
+//
+// Solidity: event Deposited(uint256 indexed id, address indexed payer, address indexed recipient, address token, uint256 amount)
+func (_MessageEscrow *MessageEscrowFilterer) WatchDeposited(opts *bind.WatchOpts, sink chan<- *MessageEscrowDeposited, id []*big.Int, payer []common.Address, recipient []common.Address) (event.Subscription, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+	var payerRule []interface{}
+	for _, payerItem := range payer {
+		payerRule = append(payerRule, payerItem)
+	}
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+
+	logs, sub, err := _MessageEscrow.contract.WatchLogs(opts, "Deposited", idRule, payerRule, recipientRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(MessageEscrowDeposited)
+				if err := _MessageEscrow.contract.UnpackLog(event, "Deposited", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDeposited is a log parse operation binding the contract event 0x7a449f26bcadb0ab23332b882f7c3cc37827ee0b22aac3c0dc80e7713c5933e9.
+//
+// Solidity: event Deposited(uint256 indexed id, address indexed payer, address indexed recipient, address token, uint256 amount)
+func (_MessageEscrow *MessageEscrowFilterer) ParseDeposited(log types.Log) (*MessageEscrowDeposited, error) {
+	event := new(MessageEscrowDeposited)
+	if err := _MessageEscrow.contract.UnpackLog(event, "Deposited", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// MessageEscrowSettledIterator is returned from FilterSettled and is used to iterate over the raw logs and unpacked data for Settled events raised by the MessageEscrow contract.
+type MessageEscrowSettledIterator struct {
+	Event *MessageEscrowSettled // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *MessageEscrowSettledIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(MessageEscrowSettled)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(MessageEscrowSettled)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *MessageEscrowSettledIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *MessageEscrowSettledIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// MessageEscrowSettled represents a Settled event raised by the MessageEscrow contract.
+type MessageEscrowSettled struct {
+	Id  *big.Int
+	To  common.Address
+	Raw types.Log // Blockchain specific contextual infos
+}
+
+// FilterSettled is a free log retrieval operation binding the contract event 0x1ddc75d9b5fc6d37e23b3284e94f0db77b95dfe06b38540ac86c16e6ee3f7238.
+//
+// Solidity: event Settled(uint256 indexed id, address to)
+func (_MessageEscrow *MessageEscrowFilterer) FilterSettled(opts *bind.FilterOpts, id []*big.Int) (*MessageEscrowSettledIterator, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+
+	logs, sub, err := _MessageEscrow.contract.FilterLogs(opts, "Settled", idRule)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageEscrowSettledIterator{contract: _MessageEscrow.contract, event: "Settled", logs: logs, sub: sub}, nil
+}
+
+// WatchSettled is a free log subscription operation binding the contract event 0x1ddc75d9b5fc6d37e23b3284e94f0db77b95dfe06b38540ac86c16e6ee3f7238.
+//
+// Solidity: event Settled(uint256 indexed id, address to)
+func (_MessageEscrow *MessageEscrowFilterer) WatchSettled(opts *bind.WatchOpts, sink chan<- *MessageEscrowSettled, id []*big.Int) (event.Subscription, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+
+	logs, sub, err := _MessageEscrow.contract.WatchLogs(opts, "Settled", idRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(MessageEscrowSettled)
+				if err := _MessageEscrow.contract.UnpackLog(event, "Settled", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseSettled is a log parse operation binding the contract event 0x1ddc75d9b5fc6d37e23b3284e94f0db77b95dfe06b38540ac86c16e6ee3f7238.
+//
+// Solidity: event Settled(uint256 indexed id, address to)
+func (_MessageEscrow *MessageEscrowFilterer) ParseSettled(log types.Log) (*MessageEscrowSettled, error) {
+	event := new(MessageEscrowSettled)
+	if err := _MessageEscrow.contract.UnpackLog(event, "Settled", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,4 @@
+// Package escrow holds the Go bindings of the MessageEscrow contract from contracts/message_escrow.sol.
+package escrow
+
+//go:generate abigen --abi MessageEscrow.abi --bin MessageEscrow.bin --pkg escrow --type MessageEscrow --out escrow.go
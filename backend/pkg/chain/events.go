@@ -0,0 +1,95 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/messaging"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MessageSent is a MessageSent log of the Messaging contract.
+type MessageSent struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+
+	BlockNumber uint64
+	// BlockTime is the block timestamp in milliseconds
+	BlockTime int64
+	TxHash    common.Hash
+	LogIndex  uint
+}
+
+// EventSource reads the Messaging contract logs. Unlike Client it doesn't need a relayer key.
+type EventSource interface {
+	LatestBlock(ctx context.Context) (uint64, error)
+	// MessageSent returns the logs of the blocks from..to inclusive, in chain order.
+	MessageSent(ctx context.Context, from, to uint64) ([]*MessageSent, error)
+}
+
+type eventSource struct {
+	backend  Backend
+	contract *messaging.Messaging
+}
+
+func NewEventSource(backend Backend, contract common.Address) (EventSource, error) {
+	bound, err := messaging.NewMessaging(contract, backend)
+	if err != nil {
+		return nil, fmt.Errorf("NewEventSource/NewMessaging: %w", err)
+	}
+
+	return &eventSource{
+		backend:  backend,
+		contract: bound,
+	}, nil
+}
+
+func (e *eventSource) LatestBlock(ctx context.Context) (uint64, error) {
+	header, err := e.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("LatestBlock/HeaderByNumber: %w", err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+func (e *eventSource) MessageSent(ctx context.Context, from, to uint64) ([]*MessageSent, error) {
+	it, err := e.contract.FilterMessageSent(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("MessageSent/FilterMessageSent: %w", err)
+	}
+	defer it.Close()
+
+	var (
+		events     []*MessageSent
+		blockTimes = make(map[uint64]int64)
+	)
+	for it.Next() {
+		blockTime, ok := blockTimes[it.Event.Raw.BlockNumber]
+		if !ok {
+			header, err := e.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(it.Event.Raw.BlockNumber))
+			if err != nil {
+				return nil, fmt.Errorf("MessageSent/HeaderByNumber: %w", err)
+			}
+			blockTime = int64(header.Time) * 1000
+			blockTimes[it.Event.Raw.BlockNumber] = blockTime
+		}
+
+		events = append(events, &MessageSent{
+			Sender:      it.Event.Sender,
+			Receiver:    it.Event.Receiver,
+			Content:     it.Event.Content,
+			BlockNumber: it.Event.Raw.BlockNumber,
+			BlockTime:   blockTime,
+			TxHash:      it.Event.Raw.TxHash,
+			LogIndex:    it.Event.Raw.Index,
+		})
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("MessageSent/Next: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,438 @@
+// Package failover spreads the JSON-RPC calls of one chain over several endpoints. Calls go to the
+// healthiest endpoint, slow reads are hedged on the runner-up and writes only go to endpoints that
+// are in sync with the best known head.
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	ErrNoEndpoints     = errors.New("no endpoints")
+	ErrChainIDMismatch = errors.New("endpoint serves another chain")
+	// ErrNoSyncedEndpoint is returned for writes when every endpoint trails the head by more than MaxHeadLag
+	ErrNoSyncedEndpoint = errors.New("no endpoint in sync with the head")
+)
+
+const (
+	// scoreAlpha is the weight of the latest call in the latency and error rate averages
+	scoreAlpha = 0.2
+	// errorPenalty scales the latency of an endpoint that fails every call
+	errorPenalty = 100
+	// lagPenalty is the score of every block an endpoint trails the head by, in milliseconds of latency
+	lagPenalty = 100
+)
+
+type Config struct {
+	// HedgeDelay is how long a read waits for the healthiest endpoint before it's also sent to the next one
+	HedgeDelay time.Duration
+	// MaxHeadLag is how many blocks an endpoint may trail the best known head and still take writes
+	MaxHeadLag uint64
+	// HealthInterval is how often the endpoints' heads are polled
+	HealthInterval time.Duration
+}
+
+// Client implements chain.Backend over several endpoints of the same chain.
+type Client struct {
+	cfg       Config
+	endpoints []*endpoint
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Dial connects to every url and checks they all serve chainID. Endpoints that can't be reached
+// yet are kept, they start with a bad score and recover once they answer.
+func Dial(ctx context.Context, chainID *big.Int, urls []string, cfg Config) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	c := &Client{
+		cfg:    cfg,
+		stopCh: make(chan struct{}),
+	}
+	for _, url := range urls {
+		client, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			c.close()
+			return nil, fmt.Errorf("Dial/DialContext: %s: %w", url, err)
+		}
+		e := &endpoint{url: url, client: client}
+		c.endpoints = append(c.endpoints, e)
+
+		id, err := client.ChainID(ctx)
+		if err != nil {
+			e.record(0, err)
+			continue
+		}
+		if id.Cmp(chainID) != 0 {
+			c.close()
+			return nil, fmt.Errorf("Dial: %s: %w: %s", url, ErrChainIDMismatch, id)
+		}
+	}
+
+	c.pollHeads()
+	c.wg.Add(1)
+	go c.run()
+
+	return c, nil
+}
+
+// Close stops the health checks and closes the connections.
+func (c *Client) Close() {
+	close(c.stopCh)
+	c.wg.Wait()
+	c.close()
+}
+
+func (c *Client) close() {
+	for _, e := range c.endpoints {
+		e.client.Close()
+	}
+}
+
+func (c *Client) run() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.cfg.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.pollHeads()
+		}
+	}
+}
+
+func (c *Client) pollHeads() {
+	var wg sync.WaitGroup
+	for _, e := range c.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), c.cfg.HealthInterval)
+			defer cancel()
+
+			start := time.Now()
+			head, err := e.client.BlockNumber(ctx)
+			e.record(time.Since(start), err)
+			if err == nil {
+				e.setHead(head)
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+// Health is a snapshot of an endpoint's score inputs.
+type Health struct {
+	URL       string
+	Latency   time.Duration
+	ErrorRate float64
+	HeadLag   uint64
+	Score     float64
+}
+
+// Health reports the endpoints from the healthiest down.
+func (c *Client) Health() []Health {
+	ranked, bestHead := c.rank(false)
+	health := make([]Health, 0, len(ranked))
+	for _, e := range ranked {
+		health = append(health, e.health(bestHead))
+	}
+	return health
+}
+
+// rank orders the endpoints by score. With synced set, endpoints trailing the head by more
+// than MaxHeadLag are left out.
+func (c *Client) rank(synced bool) ([]*endpoint, uint64) {
+	var bestHead uint64
+	for _, e := range c.endpoints {
+		bestHead = max(bestHead, e.getHead())
+	}
+
+	ranked := make([]*endpoint, 0, len(c.endpoints))
+	scores := make(map[*endpoint]float64, len(c.endpoints))
+	for _, e := range c.endpoints {
+		h := e.health(bestHead)
+		if synced && h.HeadLag > c.cfg.MaxHeadLag {
+			continue
+		}
+		scores[e] = h.Score
+		ranked = append(ranked, e)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] < scores[ranked[j]]
+	})
+
+	return ranked, bestHead
+}
+
+type result[T any] struct {
+	value T
+	err   error
+}
+
+// read runs call on the healthiest endpoint. If it hasn't answered after HedgeDelay, or failed to
+// reach the node, the next endpoint is tried as well and the first answer wins.
+func read[T any](ctx context.Context, c *Client, call func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	return hedge(ctx, c, false, call)
+}
+
+// write runs call on the synced endpoints one after another until one of them reaches its node.
+func write[T any](ctx context.Context, c *Client, call func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	ranked, _ := c.rank(true)
+	var (
+		zero T
+		err  error
+	)
+	if len(ranked) == 0 {
+		return zero, ErrNoSyncedEndpoint
+	}
+	for _, e := range ranked {
+		var value T
+		value, err = callEndpoint(ctx, e, call)
+		if !isTransportError(err) {
+			return value, err
+		}
+	}
+	return zero, err
+}
+
+func hedge[T any](ctx context.Context, c *Client, synced bool, call func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	var zero T
+	ranked, _ := c.rank(synced)
+	if len(ranked) == 0 {
+		return zero, ErrNoSyncedEndpoint
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result[T], len(ranked))
+	start := func(e *endpoint) {
+		go func() {
+			value, err := callEndpoint(ctx, e, call)
+			results <- result[T]{value: value, err: err}
+		}()
+	}
+
+	timer := time.NewTimer(c.cfg.HedgeDelay)
+	defer timer.Stop()
+
+	start(ranked[0])
+	next, running := 1, 1
+	var lastErr error
+	for running > 0 {
+		select {
+		case <-timer.C:
+			if next < len(ranked) {
+				start(ranked[next])
+				next++
+				running++
+				timer.Reset(c.cfg.HedgeDelay)
+			}
+		case res := <-results:
+			running--
+			if !isTransportError(res.err) {
+				return res.value, res.err
+			}
+			lastErr = res.err
+			if next < len(ranked) {
+				start(ranked[next])
+				next++
+				running++
+			}
+		}
+	}
+
+	return zero, lastErr
+}
+
+// isTransportError reports whether err means the node couldn't be reached or failed, as opposed
+// to an answer such as a revert or a missing receipt that another node would give as well.
+func isTransportError(err error) bool {
+	if err == nil || errors.Is(err, ethereum.NotFound) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+func (c *Client) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (c *Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) (*types.Header, error) {
+		return client.HeaderByNumber(ctx, number)
+	})
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+}
+
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) ([]types.Log, error) {
+		return client.FilterLogs(ctx, query)
+	})
+}
+
+// SubscribeFilterLogs subscribes on the healthiest endpoint, the subscription doesn't fail over.
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	ranked, _ := c.rank(false)
+	return ranked[0].client.SubscribeFilterLogs(ctx, query, ch)
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+type txByHash struct {
+	tx      *types.Transaction
+	pending bool
+}
+
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	res, err := read(ctx, c, func(ctx context.Context, client *ethclient.Client) (txByHash, error) {
+		tx, pending, err := client.TransactionByHash(ctx, hash)
+		return txByHash{tx: tx, pending: pending}, err
+	})
+	return res.tx, res.pending, err
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
+	})
+}
+
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return read(ctx, c, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasTipCap(ctx)
+	})
+}
+
+// The pending state of a node that's behind is stale, so the calls preparing a transaction are
+// served by synced endpoints only.
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return hedge(ctx, c, true, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.PendingCodeAt(ctx, account)
+	})
+}
+
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return hedge(ctx, c, true, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.PendingNonceAt(ctx, account)
+	})
+}
+
+func (c *Client) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return hedge(ctx, c, true, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.EstimateGas(ctx, call)
+	})
+}
+
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := write(ctx, c, func(ctx context.Context, client *ethclient.Client) (struct{}, error) {
+		return struct{}{}, client.SendTransaction(ctx, tx)
+	})
+	return err
+}
+
+type endpoint struct {
+	url    string
+	client *ethclient.Client
+
+	mu        sync.Mutex
+	latency   time.Duration
+	errorRate float64
+	head      uint64
+}
+
+// callEndpoint runs call on e and scores the endpoint by the outcome. A call cancelled because a
+// hedged call answered first still counts its latency.
+func callEndpoint[T any](ctx context.Context, e *endpoint, call func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	start := time.Now()
+	value, err := call(ctx, e.client)
+	if ctx.Err() != nil {
+		e.record(time.Since(start), nil)
+	} else {
+		e.record(time.Since(start), err)
+	}
+	return value, err
+}
+
+// record folds a call into the averages, answers such as reverts aren't errors of the endpoint.
+func (e *endpoint) record(latency time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	failed := 0.0
+	if isTransportError(err) {
+		failed = 1
+	}
+	if e.latency == 0 && e.errorRate == 0 {
+		e.latency, e.errorRate = latency, failed
+		return
+	}
+	e.latency = time.Duration((1-scoreAlpha)*float64(e.latency) + scoreAlpha*float64(latency))
+	e.errorRate = (1-scoreAlpha)*e.errorRate + scoreAlpha*failed
+}
+
+func (e *endpoint) setHead(head uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.head = max(e.head, head)
+}
+
+func (e *endpoint) getHead() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.head
+}
+
+// health scores the endpoint, lower is better.
+func (e *endpoint) health(bestHead uint64) Health {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	lag := bestHead - e.head
+	latencyMs := float64(e.latency) / float64(time.Millisecond)
+	return Health{
+		URL:       e.url,
+		Latency:   e.latency,
+		ErrorRate: e.errorRate,
+		HeadLag:   lag,
+		Score:     (latencyMs+1)*(1+errorPenalty*e.errorRate) + lagPenalty*float64(lag),
+	}
+}
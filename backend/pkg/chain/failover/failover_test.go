@@ -0,0 +1,347 @@
+package failover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/stretchr/testify/require"
+)
+
+var chainID = big.NewInt(1337)
+
+// fakeNode is a JSON-RPC stand-in answering the handful of methods the tests need.
+type fakeNode struct {
+	chainID uint64
+	head    atomic.Uint64
+	delay   atomic.Int64
+	down    atomic.Bool
+	revert  atomic.Bool
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newFakeNode(t *testing.T, head uint64) (*fakeNode, string) {
+	n := &fakeNode{chainID: chainID.Uint64(), calls: make(map[string]int)}
+	n.head.Store(head)
+	srv := httptest.NewServer(n)
+	t.Cleanup(srv.Close)
+	return n, srv.URL
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n.mu.Lock()
+	n.calls[req.Method]++
+	n.mu.Unlock()
+
+	select {
+	case <-time.After(time.Duration(n.delay.Load())):
+	case <-r.Context().Done():
+		return
+	}
+	if n.down.Load() {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+		return
+	}
+
+	res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "eth_chainId":
+		res["result"] = hexutil.Uint64(n.chainID)
+	case "eth_blockNumber":
+		res["result"] = hexutil.Uint64(n.head.Load())
+	case "eth_getCode":
+		res["result"] = hexutil.Bytes{0x60, 0x00}
+	case "eth_call":
+		if n.revert.Load() {
+			res["error"] = map[string]any{"code": 3, "message": "execution reverted"}
+		} else {
+			res["result"] = hexutil.Bytes{0x01}
+		}
+	case "eth_sendRawTransaction":
+		res["result"] = common.Hash{}
+	case "eth_getTransactionReceipt":
+		res["result"] = nil
+	default:
+		res["error"] = map[string]any{"code": -32601, "message": "method not found"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (n *fakeNode) count(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+func dial(t *testing.T, cfg Config, urls ...string) *Client {
+	c, err := Dial(context.Background(), chainID, urls, cfg)
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	return c
+}
+
+var testConfig = Config{
+	HedgeDelay:     time.Second,
+	MaxHeadLag:     2,
+	HealthInterval: time.Hour,
+}
+
+func TestDialChainIDMismatch(t *testing.T) {
+	_, good := newFakeNode(t, 10)
+	other, bad := newFakeNode(t, 10)
+	other.chainID = 1
+
+	_, err := Dial(context.Background(), chainID, []string{good, bad}, testConfig)
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+
+	_, err = Dial(context.Background(), chainID, nil, testConfig)
+	require.ErrorIs(t, err, ErrNoEndpoints)
+}
+
+func TestReadsGoToHealthiest(t *testing.T) {
+	slow, slowURL := newFakeNode(t, 10)
+	slow.delay.Store(int64(50 * time.Millisecond))
+	fast, fastURL := newFakeNode(t, 10)
+
+	c := dial(t, testConfig, slowURL, fastURL)
+	for i := 0; i < 5; i++ {
+		_, err := c.CodeAt(context.Background(), common.Address{}, nil)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 5, fast.count("eth_getCode"))
+	require.Zero(t, slow.count("eth_getCode"))
+
+	health := c.Health()
+	require.Equal(t, fastURL, health[0].URL)
+	require.Greater(t, health[1].Latency, health[0].Latency)
+}
+
+func TestReadsFailOver(t *testing.T) {
+	flaky, flakyURL := newFakeNode(t, 10)
+	backup, backupURL := newFakeNode(t, 10)
+	backup.delay.Store(int64(10 * time.Millisecond))
+
+	c := dial(t, testConfig, flakyURL, backupURL)
+	flaky.down.Store(true)
+
+	_, err := c.CodeAt(context.Background(), common.Address{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, flaky.count("eth_getCode"))
+	require.Equal(t, 1, backup.count("eth_getCode"))
+
+	// the failure costs the endpoint its first place
+	_, err = c.CodeAt(context.Background(), common.Address{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, flaky.count("eth_getCode"))
+	require.Equal(t, 2, backup.count("eth_getCode"))
+	require.Equal(t, backupURL, c.Health()[0].URL)
+	require.Greater(t, c.Health()[1].ErrorRate, 0.0)
+}
+
+func TestSlowReadsAreHedged(t *testing.T) {
+	stalled, stalledURL := newFakeNode(t, 10)
+	other, otherURL := newFakeNode(t, 10)
+	other.delay.Store(int64(5 * time.Millisecond))
+
+	cfg := testConfig
+	cfg.HedgeDelay = 20 * time.Millisecond
+	c := dial(t, cfg, stalledURL, otherURL)
+	stalled.delay.Store(int64(time.Second))
+
+	start := time.Now()
+	_, err := c.CodeAt(context.Background(), common.Address{}, nil)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, 1, stalled.count("eth_getCode"))
+	require.Equal(t, 1, other.count("eth_getCode"))
+}
+
+func TestAnswersDoNotFailOver(t *testing.T) {
+	first, firstURL := newFakeNode(t, 10)
+	second, secondURL := newFakeNode(t, 10)
+	second.delay.Store(int64(10 * time.Millisecond))
+	first.revert.Store(true)
+
+	c := dial(t, testConfig, firstURL, secondURL)
+	_, err := c.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	require.ErrorContains(t, err, "execution reverted")
+
+	_, err = c.TransactionReceipt(context.Background(), common.Hash{})
+	require.ErrorIs(t, err, ethereum.NotFound)
+
+	require.Zero(t, second.count("eth_call"))
+	require.Zero(t, second.count("eth_getTransactionReceipt"))
+	require.Zero(t, c.Health()[0].ErrorRate)
+}
+
+func TestWritesSkipLaggingNodes(t *testing.T) {
+	lagging, laggingURL := newFakeNode(t, 90)
+	synced, syncedURL := newFakeNode(t, 100)
+	synced.delay.Store(int64(20 * time.Millisecond))
+
+	c := dial(t, testConfig, laggingURL, syncedURL)
+	// the lagging node answers faster, but it's still last
+	require.Equal(t, syncedURL, c.Health()[0].URL)
+	require.Equal(t, uint64(10), c.Health()[1].HeadLag)
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1)})
+	require.NoError(t, c.SendTransaction(context.Background(), tx))
+	_, err := c.PendingNonceAt(context.Background(), common.Address{})
+	require.Error(t, err)
+	require.Equal(t, 1, synced.count("eth_sendRawTransaction"))
+	require.Zero(t, lagging.count("eth_sendRawTransaction"))
+	require.Zero(t, lagging.count("eth_getTransactionCount"))
+
+	synced.down.Store(true)
+	require.Error(t, c.SendTransaction(context.Background(), tx))
+	require.Zero(t, lagging.count("eth_sendRawTransaction"))
+}
+
+func TestRegistry(t *testing.T) {
+	_, local := newFakeNode(t, 10)
+	mainnet, mainnetURL := newFakeNode(t, 20)
+	mainnet.chainID = 1
+
+	r, err := DialRegistry(context.Background(), map[int64][]string{
+		chainID.Int64(): {local},
+		1:               {mainnetURL},
+		10:              nil,
+	}, testConfig)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+	require.Equal(t, []int64{1, chainID.Int64()}, r.ChainIDs())
+
+	client, err := r.Client(1)
+	require.NoError(t, err)
+	head, err := client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), head)
+
+	_, err = r.Client(10)
+	require.ErrorIs(t, err, ErrUnknownChain)
+
+	// an endpoint of another chain fails the whole registry
+	_, err = DialRegistry(context.Background(), map[int64][]string{5: {local}}, testConfig)
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+}
+
+// simNode serves a simulated chain over HTTP, the way a real node does.
+func simNode(t *testing.T, alloc types.GenesisAlloc) (*simulated.Backend, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	sim := simulated.NewBackend(alloc, func(nodeConf *node.Config, _ *ethconfig.Config) {
+		nodeConf.HTTPHost, nodeConf.HTTPPort = "127.0.0.1", port
+		nodeConf.HTTPModules, nodeConf.HTTPVirtualHosts = []string{"eth"}, []string{"*"}
+	})
+	t.Cleanup(func() { sim.Close() })
+	return sim, fmt.Sprintf("http://127.0.0.1:%d", port)
+}
+
+// proxy forwards to the node until it's taken down, then fails like an unreachable endpoint.
+type proxy struct {
+	next  http.Handler
+	delay time.Duration
+	down  atomic.Bool
+	calls atomic.Int64
+}
+
+func newProxy(t *testing.T, target string, delay time.Duration) (*proxy, string) {
+	u, err := url.Parse(target)
+	require.NoError(t, err)
+	p := &proxy{next: httputil.NewSingleHostReverseProxy(u), delay: delay}
+	srv := httptest.NewServer(p)
+	t.Cleanup(srv.Close)
+	return p, srv.URL
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.calls.Add(1)
+	time.Sleep(p.delay)
+	if p.down.Load() {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+		return
+	}
+	p.next.ServeHTTP(w, r)
+}
+
+func TestFailOverSimulatedNode(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	sim, nodeURL := simNode(t, types.GenesisAlloc{from: {Balance: big.NewInt(1e18)}})
+	sim.Commit()
+
+	// each client starts out preferring the flaky endpoint, which goes down right after the dial
+	failingClient := func() (*Client, *proxy, *proxy) {
+		flaky, flakyURL := newProxy(t, nodeURL, 0)
+		backup, backupURL := newProxy(t, nodeURL, 10*time.Millisecond)
+		c := dial(t, testConfig, flakyURL, backupURL)
+		require.Equal(t, flakyURL, c.Health()[0].URL)
+		flaky.down.Store(true)
+		return c, flaky, backup
+	}
+
+	c, flaky, backup := failingClient()
+	flakyCalls, backupCalls := flaky.calls.Load(), backup.calls.Load()
+	head, err := c.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	want, err := sim.Client().HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, want.Hash(), head.Hash())
+	require.Equal(t, flakyCalls+1, flaky.calls.Load())
+	require.Equal(t, backupCalls+1, backup.calls.Load())
+
+	nonce, err := sim.Client().PendingNonceAt(ctx, from)
+	require.NoError(t, err)
+	gasPrice, err := sim.Client().SuggestGasPrice(ctx)
+	require.NoError(t, err)
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: nonce, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: gasPrice}),
+		types.LatestSignerForChainID(chainID), key)
+	require.NoError(t, err)
+
+	c, flaky, backup = failingClient()
+	flakyCalls, backupCalls = flaky.calls.Load(), backup.calls.Load()
+	require.NoError(t, c.SendTransaction(ctx, tx))
+	require.Equal(t, flakyCalls+1, flaky.calls.Load())
+	require.Equal(t, backupCalls+1, backup.calls.Load())
+
+	sim.Commit()
+	receipt, err := c.TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+}
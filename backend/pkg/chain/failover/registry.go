@@ -0,0 +1,58 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var ErrUnknownChain = errors.New("no client for the chain")
+
+// Registry holds a Client per chain, so features can run against several chains from one process.
+type Registry struct {
+	clients map[int64]*Client
+}
+
+// DialRegistry dials every chain of endpoints, chains without urls get no client.
+func DialRegistry(ctx context.Context, endpoints map[int64][]string, cfg Config) (*Registry, error) {
+	r := &Registry{clients: make(map[int64]*Client, len(endpoints))}
+	for chainID, urls := range endpoints {
+		if len(urls) == 0 {
+			continue
+		}
+		client, err := Dial(ctx, big.NewInt(chainID), urls, cfg)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("DialRegistry/Dial: chain %d: %w", chainID, err)
+		}
+		r.clients[chainID] = client
+	}
+	return r, nil
+}
+
+// Client returns ErrUnknownChain when the chain has no endpoints.
+func (r *Registry) Client(chainID int64) (*Client, error) {
+	client, ok := r.clients[chainID]
+	if !ok {
+		return nil, ErrUnknownChain
+	}
+	return client, nil
+}
+
+// ChainIDs are the chains with a client, in ascending order.
+func (r *Registry) ChainIDs() []int64 {
+	ids := make([]int64, 0, len(r.clients))
+	for id := range r.clients {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (r *Registry) Close() {
+	for _, client := range r.clients {
+		client.Close()
+	}
+}
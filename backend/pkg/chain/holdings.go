@@ -0,0 +1,99 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/token"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Holdings reads token balances. ERC-20 and ERC-721 both expose balanceOf(address),
+// for an ERC-721 collection it's the number of tokens the holder owns.
+type Holdings interface {
+	BalanceOf(ctx context.Context, token, holder common.Address) (*big.Int, error)
+}
+
+type holdings struct {
+	backend bind.ContractCaller
+}
+
+func NewHoldings(backend bind.ContractCaller) Holdings {
+	return &holdings{backend: backend}
+}
+
+func (h *holdings) BalanceOf(ctx context.Context, tokenAddress, holder common.Address) (*big.Int, error) {
+	caller, err := token.NewTokenCaller(tokenAddress, h.backend)
+	if err != nil {
+		return nil, fmt.Errorf("BalanceOf/NewTokenCaller: %w", err)
+	}
+	balance, err := caller.BalanceOf(&bind.CallOpts{Context: ctx}, holder)
+	if err != nil {
+		return nil, fmt.Errorf("BalanceOf: %w", err)
+	}
+	return balance, nil
+}
+
+type holdingKey struct {
+	token  common.Address
+	holder common.Address
+}
+
+type cachedBalance struct {
+	balance   *big.Int
+	expiresAt time.Time
+}
+
+// cachedHoldings remembers balances for ttl, errors aren't cached.
+type cachedHoldings struct {
+	holdings Holdings
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	balances  map[holdingKey]cachedBalance
+	lastSweep time.Time
+}
+
+func NewCachedHoldings(h Holdings, ttl time.Duration) Holdings {
+	return &cachedHoldings{
+		holdings:  h,
+		ttl:       ttl,
+		balances:  make(map[holdingKey]cachedBalance),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *cachedHoldings) BalanceOf(ctx context.Context, tokenAddress, holder common.Address) (*big.Int, error) {
+	key := holdingKey{token: tokenAddress, holder: holder}
+
+	c.mu.Lock()
+	cached, ok := c.balances[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return new(big.Int).Set(cached.balance), nil
+	}
+
+	balance, err := c.holdings.BalanceOf(ctx, tokenAddress, holder)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastSweep) > c.ttl {
+		for k, b := range c.balances {
+			if !now.Before(b.expiresAt) {
+				delete(c.balances, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.balances[key] = cachedBalance{balance: new(big.Int).Set(balance), expiresAt: now.Add(c.ttl)}
+
+	return balance, nil
+}
@@ -0,0 +1,4 @@
+// Package messaging holds the Go bindings of the Messaging contract from contracts/message.sol.
+package messaging
+
+//go:generate abigen --abi Messaging.abi --bin Messaging.bin --pkg messaging --type Messaging --out messaging.go
@@ -0,0 +1,547 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package messaging
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// MessagingMessage is an auto generated low-level Go binding around an user-defined struct.
+type MessagingMessage struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}
+
+// MessagingMetaData contains all meta data concerning the Messaging contract.
+var MessagingMetaData = &bind.MetaData{
+	ABI: "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"name\":\"MessageSent\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"dialogues\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"user1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"user2\",\"type\":\"address\"}],\"name\":\"getDialogue\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"internalType\":\"structMessaging.Message[]\",\"name\":\"\",\"type\":\"tuple[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"user\",\"type\":\"address\"}],\"name\":\"getInbox\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"internalType\":\"structMessaging.Message[]\",\"name\":\"\",\"type\":\"tuple[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"inbox\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"_content\",\"type\":\"string\"}],\"name\":\"sendMessage\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+	Bin: "0x6080604052348015600e575f5ffd5b50610a398061001c5f395ff3fe608060405234801561000f575f5ffd5b5060043610610055575f3560e01c8063022016811461005957806323b13485146100825780634d622e7a14610095578063de6f24bb146100b7578063ea9a7f3f146100cc575b5f5ffd5b61006c610067366004610633565b6100df565b6040516100799190610681565b60405180910390f35b61006c610090366004610713565b610203565b6100a86100a3366004610744565b610336565b6040516100799392919061076c565b6100ca6100c53660046107b4565b610407565b005b6100a86100da366004610878565b6105ba565b6001600160a01b0381165f90815260208181526040808320805482518185028101850190935280835260609492939192909184015b828210156101f8575f848152602090819020604080516060810182526003860290920180546001600160a01b0390811684526001820154169383019390935260028301805492939291840191610169906108b2565b80601f0160208091040260200160405190810160405280929190818152602001828054610195906108b2565b80156101e05780601f106101b7576101008083540402835291602001916101e0565b820191905f5260205f20905b8154815290600101906020018083116101c357829003601f168201915b50505050508152505081526020019060010190610114565b505050509050919050565b6001600160a01b038083165f9081526001602090815260408083209385168352928152828220805484518184028101840190955280855260609493919290919084015b8282101561032a575f848152602090819020604080516060810182526003860290920180546001600160a01b039081168452600182015416938301939093526002830180549293929184019161029b906108b2565b80601f01602080910402602001604051908101604052809291908181526020018280546102c7906108b2565b80156103125780601f106102e957610100808354040283529160200191610312565b820191905f5260205f20905b8154815290600101906020018083116102f557829003601f168201915b50505050508152505081526020019060010190610246565b50505050905092915050565b5f602052815f5260405f20818154811061034e575f80fd5b5f9182526020909120600390910201805460018201546002830180546001600160a01b039384169650919092169350610386906108b2565b80601f01602080910402602001604051908101604052809291908181526020018280546103b2906108b2565b80156103fd5780601f106103d4576101008083540402835291602001916103fd565b820191905f5260205f20905b8154815290600101906020018083116103e057829003601f168201915b5050505050905083565b336001600160a01b038316036104635760405162461bcd60e51b815260206004820152601f60248201527f43616e6e6f742073656e64206d65737361676520746f20796f757273656c6600604482015260640160405180910390fd5b604080516060810182523381526001600160a01b0384811660208084018281528486018781525f93845283835295832080546001818101835591855292909320855160039093020180549285166001600160a01b03199384161781559051928101805493909416929091169190911790915591519091829160028201906104ea9082610936565b5050335f9081526001602081815260408084206001600160a01b0389811686529083528185208054808601825590865294839020875160039096020180549582166001600160a01b031996871617815592870151938301805494909116939094169290921790925583015183925060028201906105679082610936565b505050826001600160a01b0316336001600160a01b03167fe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c846040516105ad91906109f1565b60405180910390a3505050565b6001602052825f5260405f20602052815f5260405f2081815481106105dd575f80fd5b5f9182526020909120600390910201805460018201546002830180546001600160a01b039384169750929091169450919250610386906108b2565b80356001600160a01b038116811461062e575f5ffd5b919050565b5f60208284031215610643575f5ffd5b61064c82610618565b9392505050565b5f81518084528060208401602086015e5f602082860101526020601f19601f83011685010191505092915050565b5f602082016020835280845180835260408501915060408160051b8601019250602086015f5b8281101561070757868503603f19018452815180516001600160a01b039081168752602080830151909116908701526040908101516060918701829052906106f190870182610653565b95505060209384019391909101906001016106a7565b50929695505050505050565b5f5f60408385031215610724575f5ffd5b61072d83610618565b915061073b60208401610618565b90509250929050565b5f5f60408385031215610755575f5ffd5b61075e83610618565b946020939093013593505050565b6001600160a01b038481168252831660208201526060604082018190525f9061079790830184610653565b95945050505050565b634e487b7160e01b5f52604160045260245ffd5b5f5f604083850312156107c5575f5ffd5b6107ce83610618565b9150602083013567ffffffffffffffff8111156107e9575f5ffd5b8301601f810185136107f9575f5ffd5b803567ffffffffffffffff811115610813576108136107a0565b604051601f8201601f19908116603f0116810167ffffffffffffffff81118282101715610842576108426107a0565b604052818152828201602001871015610859575f5ffd5b816020840160208301375f602083830101528093505050509250929050565b5f5f5f6060848603121561088a575f5ffd5b61089384610618565b92506108a160208501610618565b929592945050506040919091013590565b600181811c908216806108c657607f821691505b6020821081036108e457634e487b7160e01b5f52602260045260245ffd5b50919050565b601f82111561093157805f5260205f20601f840160051c8101602085101561090f5750805b601f840160051c820191505b8181101561092e575f815560010161091b565b50505b505050565b815167ffffffffffffffff811115610950576109506107a0565b6109648161095e84546108b2565b846108ea565b6020601f821160018114610996575f831561097f5750848201515b5f19600385901b1c1916600184901b17845561092e565b5f84815260208120601f198516915b828110156109c557878501518255602094850194600190920191016109a5565b50848210156109e257868401515f19600387901b60f8161c191681555b50505050600190811b01905550565b602081525f61064c602083018461065356fea2646970667358221220d5212f909d1cd0c13f5f40c1751c525a0631fd0bb2a48078c478a33d0102ff9f64736f6c634300081e0033",
+}
+
+// MessagingABI is the input ABI used to generate the binding from.
+// Deprecated: Use MessagingMetaData.ABI instead.
+var MessagingABI = MessagingMetaData.ABI
+
+// MessagingBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use MessagingMetaData.Bin instead.
+var MessagingBin = MessagingMetaData.Bin
+
+// DeployMessaging deploys a new Ethereum contract, binding an instance of Messaging to it.
+func DeployMessaging(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *Messaging, error) {
+	parsed, err := MessagingMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(MessagingBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Messaging{MessagingCaller: MessagingCaller{contract: contract}, MessagingTransactor: MessagingTransactor{contract: contract}, MessagingFilterer: MessagingFilterer{contract: contract}}, nil
+}
+
+// Messaging is an auto generated Go binding around an Ethereum contract.
+type Messaging struct {
+	MessagingCaller     // Read-only binding to the contract
+	MessagingTransactor // Write-only binding to the contract
+	MessagingFilterer   // Log filterer for contract events
+}
+
+// MessagingCaller is an auto generated read-only Go binding around an Ethereum contract.
+type MessagingCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MessagingTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type MessagingTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MessagingFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type MessagingFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MessagingSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type MessagingSession struct {
+	Contract     *Messaging        // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// MessagingCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type MessagingCallerSession struct {
+	Contract *MessagingCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts    // Call options to use throughout this session
+}
+
+// MessagingTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type MessagingTransactorSession struct {
+	Contract     *MessagingTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
+}
+
+// MessagingRaw is an auto generated low-level Go binding around an Ethereum contract.
+type MessagingRaw struct {
+	Contract *Messaging // Generic contract binding to access the raw methods on
+}
+
+// MessagingCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type MessagingCallerRaw struct {
+	Contract *MessagingCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// MessagingTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type MessagingTransactorRaw struct {
+	Contract *MessagingTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewMessaging creates a new instance of Messaging, bound to a specific deployed contract.
+func NewMessaging(address common.Address, backend bind.ContractBackend) (*Messaging, error) {
+	contract, err := bindMessaging(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Messaging{MessagingCaller: MessagingCaller{contract: contract}, MessagingTransactor: MessagingTransactor{contract: contract}, MessagingFilterer: MessagingFilterer{contract: contract}}, nil
+}
+
+// NewMessagingCaller creates a new read-only instance of Messaging, bound to a specific deployed contract.
+func NewMessagingCaller(address common.Address, caller bind.ContractCaller) (*MessagingCaller, error) {
+	contract, err := bindMessaging(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MessagingCaller{contract: contract}, nil
+}
+
+// NewMessagingTransactor creates a new write-only instance of Messaging, bound to a specific deployed contract.
+func NewMessagingTransactor(address common.Address, transactor bind.ContractTransactor) (*MessagingTransactor, error) {
+	contract, err := bindMessaging(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MessagingTransactor{contract: contract}, nil
+}
+
+// NewMessagingFilterer creates a new log filterer instance of Messaging, bound to a specific deployed contract.
+func NewMessagingFilterer(address common.Address, filterer bind.ContractFilterer) (*MessagingFilterer, error) {
+	contract, err := bindMessaging(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &MessagingFilterer{contract: contract}, nil
+}
+
+// bindMessaging binds a generic wrapper to an already deployed contract.
+func bindMessaging(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := MessagingMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Messaging *MessagingRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Messaging.Contract.MessagingCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Messaging *MessagingRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Messaging.Contract.MessagingTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Messaging *MessagingRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Messaging.Contract.MessagingTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Messaging *MessagingCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Messaging.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Messaging *MessagingTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Messaging.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Messaging *MessagingTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Messaging.Contract.contract.Transact(opts, method, params...)
+}
+
+// Dialogues is a free data retrieval call binding the contract method 0xea9a7f3f.
+//
+// Solidity: function dialogues(address , address , uint256 ) view returns(address sender, address receiver, string content)
+func (_Messaging *MessagingCaller) Dialogues(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address, arg2 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	var out []interface{}
+	err := _Messaging.contract.Call(opts, &out, "dialogues", arg0, arg1, arg2)
+
+	outstruct := new(struct {
+		Sender   common.Address
+		Receiver common.Address
+		Content  string
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Sender = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Receiver = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Content = *abi.ConvertType(out[2], new(string)).(*string)
+
+	return *outstruct, err
+
+}
+
+// Dialogues is a free data retrieval call binding the contract method 0xea9a7f3f.
+//
+// Solidity: function dialogues(address , address , uint256 ) view returns(address sender, address receiver, string content)
+func (_Messaging *MessagingSession) Dialogues(arg0 common.Address, arg1 common.Address, arg2 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _Messaging.Contract.Dialogues(&_Messaging.CallOpts, arg0, arg1, arg2)
+}
+
+// Dialogues is a free data retrieval call binding the contract method 0xea9a7f3f.
+//
+// Solidity: function dialogues(address , address , uint256 ) view returns(address sender, address receiver, string content)
+func (_Messaging *MessagingCallerSession) Dialogues(arg0 common.Address, arg1 common.Address, arg2 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _Messaging.Contract.Dialogues(&_Messaging.CallOpts, arg0, arg1, arg2)
+}
+
+// GetDialogue is a free data retrieval call binding the contract method 0x23b13485.
+//
+// Solidity: function getDialogue(address user1, address user2) view returns((address,address,string)[])
+func (_Messaging *MessagingCaller) GetDialogue(opts *bind.CallOpts, user1 common.Address, user2 common.Address) ([]MessagingMessage, error) {
+	var out []interface{}
+	err := _Messaging.contract.Call(opts, &out, "getDialogue", user1, user2)
+
+	if err != nil {
+		return *new([]MessagingMessage), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]MessagingMessage)).(*[]MessagingMessage)
+
+	return out0, err
+
+}
+
+// GetDialogue is a free data retrieval call binding the contract method 0x23b13485.
+//
+// Solidity: function getDialogue(address user1, address user2) view returns((address,address,string)[])
+func (_Messaging *MessagingSession) GetDialogue(user1 common.Address, user2 common.Address) ([]MessagingMessage, error) {
+	return _Messaging.Contract.GetDialogue(&_Messaging.CallOpts, user1, user2)
+}
+
+// GetDialogue is a free data retrieval call binding the contract method 0x23b13485.
+//
+// Solidity: function getDialogue(address user1, address user2) view returns((address,address,string)[])
+func (_Messaging *MessagingCallerSession) GetDialogue(user1 common.Address, user2 common.Address) ([]MessagingMessage, error) {
+	return _Messaging.Contract.GetDialogue(&_Messaging.CallOpts, user1, user2)
+}
+
+// GetInbox is a free data retrieval call binding the contract method 0x02201681.
+//
+// Solidity: function getInbox(address user) view returns((address,address,string)[])
+func (_Messaging *MessagingCaller) GetInbox(opts *bind.CallOpts, user common.Address) ([]MessagingMessage, error) {
+	var out []interface{}
+	err := _Messaging.contract.Call(opts, &out, "getInbox", user)
+
+	if err != nil {
+		return *new([]MessagingMessage), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]MessagingMessage)).(*[]MessagingMessage)
+
+	return out0, err
+
+}
+
+// GetInbox is a free data retrieval call binding the contract method 0x02201681.
+//
+// Solidity: function getInbox(address user) view returns((address,address,string)[])
+func (_Messaging *MessagingSession) GetInbox(user common.Address) ([]MessagingMessage, error) {
+	return _Messaging.Contract.GetInbox(&_Messaging.CallOpts, user)
+}
+
+// GetInbox is a free data retrieval call binding the contract method 0x02201681.
+//
+// Solidity: function getInbox(address user) view returns((address,address,string)[])
+func (_Messaging *MessagingCallerSession) GetInbox(user common.Address) ([]MessagingMessage, error) {
+	return _Messaging.Contract.GetInbox(&_Messaging.CallOpts, user)
+}
+
+// Inbox is a free data retrieval call binding the contract method 0x4d622e7a.
+//
+// Solidity: function inbox(address , uint256 ) view returns(address sender, address receiver, string content)
+func (_Messaging *MessagingCaller) Inbox(opts *bind.CallOpts, arg0 common.Address, arg1 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	var out []interface{}
+	err := _Messaging.contract.Call(opts, &out, "inbox", arg0, arg1)
+
+	outstruct := new(struct {
+		Sender   common.Address
+		Receiver common.Address
+		Content  string
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Sender = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Receiver = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Content = *abi.ConvertType(out[2], new(string)).(*string)
+
+	return *outstruct, err
+
+}
+
+// Inbox is a free data retrieval call binding the contract method 0x4d622e7a.
+//
+// Solidity: function inbox(address , uint256 ) view returns(address sender, address receiver, string content)
+func (_Messaging *MessagingSession) Inbox(arg0 common.Address, arg1 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _Messaging.Contract.Inbox(&_Messaging.CallOpts, arg0, arg1)
+}
+
+// Inbox is a free data retrieval call binding the contract method 0x4d622e7a.
+//
+// Solidity: function inbox(address , uint256 ) view returns(address sender, address receiver, string content)
+func (_Messaging *MessagingCallerSession) Inbox(arg0 common.Address, arg1 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _Messaging.Contract.Inbox(&_Messaging.CallOpts, arg0, arg1)
+}
+
+// SendMessage is a paid mutator transaction binding the contract method 0xde6f24bb.
+//
+// Solidity: function sendMessage(address _receiver, string _content) returns()
+func (_Messaging *MessagingTransactor) SendMessage(opts *bind.TransactOpts, _receiver common.Address, _content string) (*types.Transaction, error) {
+	return _Messaging.contract.Transact(opts, "sendMessage", _receiver, _content)
+}
+
+// SendMessage is a paid mutator transaction binding the contract method 0xde6f24bb.
+//
+// Solidity: function sendMessage(address _receiver, string _content) returns()
+func (_Messaging *MessagingSession) SendMessage(_receiver common.Address, _content string) (*types.Transaction, error) {
+	return _Messaging.Contract.SendMessage(&_Messaging.TransactOpts, _receiver, _content)
+}
+
+// SendMessage is a paid mutator transaction binding the contract method 0xde6f24bb.
+//
+// Solidity: function sendMessage(address _receiver, string _content) returns()
+func (_Messaging *MessagingTransactorSession) SendMessage(_receiver common.Address, _content string) (*types.Transaction, error) {
+	return _Messaging.Contract.SendMessage(&_Messaging.TransactOpts, _receiver, _content)
+}
+
+// MessagingMessageSentIterator is returned from FilterMessageSent and is used to iterate over the raw logs and unpacked data for MessageSent events raised by the Messaging contract.
+type MessagingMessageSentIterator struct {
+	Event *MessagingMessageSent // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *MessagingMessageSentIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(MessagingMessageSent)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(MessagingMessageSent)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *MessagingMessageSentIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *MessagingMessageSentIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// MessagingMessageSent represents a MessageSent event raised by the Messaging contract.
+type MessagingMessageSent struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterMessageSent is a free log retrieval operation binding the contract event 0xe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c.
+//
+// Solidity: event MessageSent(address indexed sender, address indexed receiver, string content)
+func (_Messaging *MessagingFilterer) FilterMessageSent(opts *bind.FilterOpts, sender []common.Address, receiver []common.Address) (*MessagingMessageSentIterator, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+	var receiverRule []interface{}
+	for _, receiverItem := range receiver {
+		receiverRule = append(receiverRule, receiverItem)
+	}
+
+	logs, sub, err := _Messaging.contract.FilterLogs(opts, "MessageSent", senderRule, receiverRule)
+	if err != nil {
+		return nil, err
+	}
+	return &MessagingMessageSentIterator{contract: _Messaging.contract, event: "MessageSent", logs: logs, sub: sub}, nil
+}
+
+// WatchMessageSent is a free log subscription operation binding the contract event 0xe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c.
+//
+// Solidity: event MessageSent(address indexed sender, address indexed receiver, string content)
+func (_Messaging *MessagingFilterer) WatchMessageSent(opts *bind.WatchOpts, sink chan<- *MessagingMessageSent, sender []common.Address, receiver []common.Address) (event.Subscription, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+	var receiverRule []interface{}
+	for _, receiverItem := range receiver {
+		receiverRule = append(receiverRule, receiverItem)
+	}
+
+	logs, sub, err := _Messaging.contract.WatchLogs(opts, "MessageSent", senderRule, receiverRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(MessagingMessageSent)
+				if err := _Messaging.contract.UnpackLog(event, "MessageSent", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseMessageSent is a log parse operation binding the contract event 0xe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c.
+//
+// Solidity: event MessageSent(address indexed sender, address indexed receiver, string content)
+func (_Messaging *MessagingFilterer) ParseMessageSent(log types.Log) (*MessagingMessageSent, error) {
+	event := new(MessagingMessageSent)
+	if err := _Messaging.contract.UnpackLog(event, "MessageSent", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,240 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/ens"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrNameNotFound = errors.New("name not found")
+	ErrInvalidName  = errors.New("invalid name")
+)
+
+// Names resolves ENS names through a registry. Forward records are the resolver's addr(node),
+// reverse records name(node) of <address>.addr.reverse.
+type Names interface {
+	// Resolve returns ErrNameNotFound when the name has no resolver or no address.
+	Resolve(ctx context.Context, name string) (common.Address, error)
+	// Lookup returns the address's primary name. It's ErrNameNotFound unless the reverse record
+	// is set and the name resolves back to the address, anyone can claim any name in reverse.
+	Lookup(ctx context.Context, address common.Address) (string, error)
+}
+
+// NormalizeName lowercases name and checks it has no empty labels. Names are expected in ASCII,
+// the full ENSIP-15 normalization isn't done.
+func NormalizeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return "", ErrInvalidName
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return "", ErrInvalidName
+		}
+	}
+	return name, nil
+}
+
+// Namehash is the ENS node of a normalized name.
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// ReverseNode is the node of the address's reverse record.
+func ReverseNode(address common.Address) common.Hash {
+	return Namehash(strings.ToLower(strings.TrimPrefix(address.Hex(), "0x")) + ".addr.reverse")
+}
+
+type names struct {
+	backend  bind.ContractCaller
+	registry *ens.RegistryCaller
+}
+
+func NewNames(backend bind.ContractCaller, registry common.Address) (Names, error) {
+	bound, err := ens.NewRegistryCaller(registry, backend)
+	if err != nil {
+		return nil, fmt.Errorf("NewNames/NewRegistryCaller: %w", err)
+	}
+	return &names{backend: backend, registry: bound}, nil
+}
+
+func (n *names) Resolve(ctx context.Context, name string) (common.Address, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	node := Namehash(name)
+	resolver, err := n.resolver(ctx, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("Resolve/resolver: %w", err)
+	}
+	address, err := resolver.Addr(&bind.CallOpts{Context: ctx}, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("Resolve/Addr: %w", err)
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, ErrNameNotFound
+	}
+	return address, nil
+}
+
+func (n *names) Lookup(ctx context.Context, address common.Address) (string, error) {
+	node := ReverseNode(address)
+	resolver, err := n.resolver(ctx, node)
+	if err != nil {
+		return "", fmt.Errorf("Lookup/resolver: %w", err)
+	}
+	name, err := resolver.Name(&bind.CallOpts{Context: ctx}, node)
+	if err != nil {
+		return "", fmt.Errorf("Lookup/Name: %w", err)
+	}
+	name, err = NormalizeName(name)
+	if err != nil {
+		return "", ErrNameNotFound
+	}
+
+	forward, err := n.Resolve(ctx, name)
+	if err != nil {
+		if errors.Is(err, ErrNameNotFound) {
+			return "", ErrNameNotFound
+		}
+		return "", fmt.Errorf("Lookup/Resolve: %w", err)
+	}
+	if forward != address {
+		return "", ErrNameNotFound
+	}
+	return name, nil
+}
+
+// resolver returns ErrNameNotFound when the node has no resolver.
+func (n *names) resolver(ctx context.Context, node common.Hash) (*ens.ResolverCaller, error) {
+	address, err := n.registry.Resolver(&bind.CallOpts{Context: ctx}, node)
+	if err != nil {
+		return nil, fmt.Errorf("resolver/Resolver: %w", err)
+	}
+	if address == (common.Address{}) {
+		return nil, ErrNameNotFound
+	}
+	resolver, err := ens.NewResolverCaller(address, n.backend)
+	if err != nil {
+		return nil, fmt.Errorf("resolver/NewResolverCaller: %w", err)
+	}
+	return resolver, nil
+}
+
+// cachedName is a resolved address or name, found is false for ErrNameNotFound.
+type cachedName struct {
+	address   common.Address
+	name      string
+	found     bool
+	expiresAt time.Time
+}
+
+// cachedNames remembers names for ttl. Names that aren't found are cached too, most addresses
+// have none. Other errors aren't cached.
+type cachedNames struct {
+	names Names
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	forward   map[string]cachedName
+	reverse   map[common.Address]cachedName
+	lastSweep time.Time
+}
+
+func NewCachedNames(n Names, ttl time.Duration) Names {
+	return &cachedNames{
+		names:     n,
+		ttl:       ttl,
+		forward:   make(map[string]cachedName),
+		reverse:   make(map[common.Address]cachedName),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *cachedNames) Resolve(ctx context.Context, name string) (common.Address, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.forward[name]
+	c.mu.Unlock()
+	if !ok || !time.Now().Before(cached.expiresAt) {
+		address, err := c.names.Resolve(ctx, name)
+		if err != nil && !errors.Is(err, ErrNameNotFound) {
+			return common.Address{}, err
+		}
+		cached = cachedName{address: address, name: name, found: err == nil}
+		c.store(func(expiresAt time.Time) {
+			cached.expiresAt = expiresAt
+			c.forward[name] = cached
+		})
+	}
+
+	if !cached.found {
+		return common.Address{}, ErrNameNotFound
+	}
+	return cached.address, nil
+}
+
+func (c *cachedNames) Lookup(ctx context.Context, address common.Address) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.reverse[address]
+	c.mu.Unlock()
+	if !ok || !time.Now().Before(cached.expiresAt) {
+		name, err := c.names.Lookup(ctx, address)
+		if err != nil && !errors.Is(err, ErrNameNotFound) {
+			return "", err
+		}
+		cached = cachedName{address: address, name: name, found: err == nil}
+		c.store(func(expiresAt time.Time) {
+			cached.expiresAt = expiresAt
+			c.reverse[address] = cached
+		})
+	}
+
+	if !cached.found {
+		return "", ErrNameNotFound
+	}
+	return cached.name, nil
+}
+
+// store sweeps expired entries at most once per ttl and runs set under the lock.
+func (c *cachedNames) store(set func(expiresAt time.Time)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastSweep) > c.ttl {
+		for k, v := range c.forward {
+			if !now.Before(v.expiresAt) {
+				delete(c.forward, k)
+			}
+		}
+		for k, v := range c.reverse {
+			if !now.Before(v.expiresAt) {
+				delete(c.reverse, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	set(now.Add(c.ttl))
+}
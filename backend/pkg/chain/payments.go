@@ -0,0 +1,204 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Pyegorchik/bdd/backend/pkg/chain/escrow"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrTxNotFound = errors.New("transaction not found")
+	ErrTxNotMined = errors.New("transaction is not mined yet")
+	ErrTxReverted = errors.New("transaction reverted")
+	ErrNoEscrow   = errors.New("escrow contract is not configured")
+	ErrNoOperator = errors.New("refunds need the relayer key of the escrow operator")
+
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// PaymentBackend also looks transactions up by hash. *ethclient.Client and the failover client satisfy it.
+type PaymentBackend interface {
+	Backend
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+}
+
+// Transfer is value a transaction moved: its ETH value, an ERC-20 Transfer log or a MessageEscrow deposit.
+type Transfer struct {
+	// Token is the zero address for ETH
+	Token  common.Address
+	From   common.Address
+	To     common.Address
+	Amount *big.Int
+	// DepositID is set for escrow deposits, To is then the recipient the deposit is held for
+	DepositID *big.Int
+}
+
+// Payment is a mined, successful transaction and the transfers it made.
+type Payment struct {
+	Hash          common.Hash
+	From          common.Address
+	BlockNumber   uint64
+	Confirmations uint64
+	Transfers     []*Transfer
+}
+
+// Payments verifies pay-to-message transfers and refunds MessageEscrow deposits.
+type Payments interface {
+	// Payment returns ErrTxNotFound, ErrTxNotMined or ErrTxReverted unless the transaction succeeded.
+	Payment(ctx context.Context, hash common.Hash) (*Payment, error)
+	// RefundDeposit broadcasts MessageEscrow.refund, the relayer has to be the escrow operator.
+	RefundDeposit(ctx context.Context, depositID *big.Int, replace *SentTx) (*SentTx, error)
+	TxStatus(ctx context.Context, hash common.Hash) (TxStatus, error)
+	// Escrow is the zero address when no escrow is configured.
+	Escrow() common.Address
+	// CanRefund reports whether an escrow is configured and the relayer can sign its refunds.
+	CanRefund() bool
+}
+
+type payments struct {
+	backend PaymentBackend
+	signer  types.Signer
+	escrow  common.Address
+	// contract is nil without an escrow, relayer is nil without a relayer key
+	contract *escrow.MessageEscrow
+	relayer  *Relayer
+}
+
+func NewPayments(backend PaymentBackend, chainID *big.Int, escrowAddress common.Address, relayer *Relayer) (Payments, error) {
+	p := &payments{
+		backend: backend,
+		signer:  types.LatestSignerForChainID(chainID),
+		escrow:  escrowAddress,
+		relayer: relayer,
+	}
+	if escrowAddress != (common.Address{}) {
+		bound, err := escrow.NewMessageEscrow(escrowAddress, backend)
+		if err != nil {
+			return nil, fmt.Errorf("NewPayments/NewMessageEscrow: %w", err)
+		}
+		p.contract = bound
+	}
+	return p, nil
+}
+
+func (p *payments) Payment(ctx context.Context, hash common.Hash) (*Payment, error) {
+	tx, pending, err := p.backend.TransactionByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTxNotFound
+		}
+		return nil, fmt.Errorf("Payment/TransactionByHash: %w", err)
+	}
+	if pending {
+		return nil, ErrTxNotMined
+	}
+	receipt, err := p.backend.TransactionReceipt(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTxNotMined
+		}
+		return nil, fmt.Errorf("Payment/TransactionReceipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, ErrTxReverted
+	}
+	from, err := types.Sender(p.signer, tx)
+	if err != nil {
+		return nil, fmt.Errorf("Payment/Sender: %w", err)
+	}
+	head, err := p.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Payment/HeaderByNumber: %w", err)
+	}
+
+	payment := &Payment{
+		Hash:        hash,
+		From:        from,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+	}
+	if head.Number.Uint64() >= payment.BlockNumber {
+		payment.Confirmations = head.Number.Uint64() - payment.BlockNumber + 1
+	}
+	if tx.To() != nil && tx.Value().Sign() > 0 && *tx.To() != p.escrow {
+		payment.Transfers = append(payment.Transfers, &Transfer{From: from, To: *tx.To(), Amount: tx.Value()})
+	}
+	for _, l := range receipt.Logs {
+		if transfer := p.transfer(l); transfer != nil {
+			payment.Transfers = append(payment.Transfers, transfer)
+		}
+	}
+
+	return payment, nil
+}
+
+// transfer decodes ERC-20 Transfer logs and escrow Deposited logs, other logs are skipped.
+// ERC-721 Transfer logs share the signature but index the token id, they have a topic more.
+func (p *payments) transfer(l *types.Log) *Transfer {
+	if p.contract != nil && l.Address == p.escrow {
+		deposited, err := p.contract.ParseDeposited(*l)
+		if err != nil {
+			return nil
+		}
+		return &Transfer{
+			Token:     deposited.Token,
+			From:      deposited.Payer,
+			To:        deposited.Recipient,
+			Amount:    deposited.Amount,
+			DepositID: deposited.Id,
+		}
+	}
+	if len(l.Topics) != 3 || l.Topics[0] != transferTopic || len(l.Data) != 32 {
+		return nil
+	}
+	to := common.BytesToAddress(l.Topics[2].Bytes())
+	if to == p.escrow {
+		// the escrow's own deposit log says who it's held for
+		return nil
+	}
+	return &Transfer{
+		Token:  l.Address,
+		From:   common.BytesToAddress(l.Topics[1].Bytes()),
+		To:     to,
+		Amount: new(big.Int).SetBytes(l.Data),
+	}
+}
+
+func (p *payments) RefundDeposit(ctx context.Context, depositID *big.Int, replace *SentTx) (*SentTx, error) {
+	if p.contract == nil {
+		return nil, ErrNoEscrow
+	}
+	if p.relayer == nil {
+		return nil, ErrNoOperator
+	}
+	sent, err := p.relayer.transact(ctx, replace, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return p.contract.Refund(opts, depositID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RefundDeposit: %w", err)
+	}
+	return sent, nil
+}
+
+func (p *payments) TxStatus(ctx context.Context, hash common.Hash) (TxStatus, error) {
+	if p.relayer == nil {
+		return TxPending, ErrNoOperator
+	}
+	status, _, err := p.relayer.receipt(ctx, hash)
+	return status, err
+}
+
+func (p *payments) Escrow() common.Address {
+	return p.escrow
+}
+
+func (p *payments) CanRefund() bool {
+	return p.contract != nil && p.relayer != nil
+}
@@ -0,0 +1,4 @@
+// Package relayed holds the Go bindings of the RelayedMessaging contract from contracts/message_relay.sol.
+package relayed
+
+//go:generate abigen --abi RelayedMessaging.abi --bin RelayedMessaging.bin --pkg relayed --type RelayedMessaging --out relayed.go
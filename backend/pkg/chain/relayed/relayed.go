@@ -0,0 +1,661 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package relayed
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// MessagingMessage is an auto generated low-level Go binding around an user-defined struct.
+type MessagingMessage struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}
+
+// RelayedMessagingMetaData contains all meta data concerning the RelayedMessaging contract.
+var RelayedMessagingMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"name\":\"MessageSent\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"DOMAIN_SEPARATOR\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"SEND_MESSAGE_TYPEHASH\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"dialogues\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"user1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"user2\",\"type\":\"address\"}],\"name\":\"getDialogue\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"internalType\":\"structMessaging.Message[]\",\"name\":\"\",\"type\":\"tuple[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"user\",\"type\":\"address\"}],\"name\":\"getInbox\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"internalType\":\"structMessaging.Message[]\",\"name\":\"\",\"type\":\"tuple[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"inbox\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"_content\",\"type\":\"string\"}],\"name\":\"sendMessage\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"receiver\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"content\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"nonce\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}],\"name\":\"sendMessageBySig\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"usedNonces\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x60a060405234801561000f575f5ffd5b50604080518082018252600c81526b4264644d6573736167696e6760a01b6020918201528151808301835260018152603160f81b9082015281517f8b73c3c69bb8fe3d512ecc4cf759cc79239f7b179b0ffacaa9a75d522b39400f918101919091527f6d9e5b27aa97937536a12e1f3c0f454218775d507d86b3aecf671b805f1ca20a918101919091527fc89efdaa54c0f20c7adf612882df0950f5a951637e0307cdcb4c672f298b8bc660608201524660808201523060a082015260c00160408051601f198184030181529190528051602090910120608052608051610e646101075f395f818160d5015261061e0152610e645ff3fe608060405234801561000f575f5ffd5b5060043610610090575f3560e01c8063511c736811610063578063511c7368146101275780636a8a68941461014e578063c93e1f2b1461018b578063de6f24bb146101a0578063ea9a7f3f146101b3575f5ffd5b8063022016811461009457806323b13485146100bd5780633644e515146100d05780634d622e7a14610105575b5f5ffd5b6100a76100a23660046109b5565b6101c6565b6040516100b49190610a03565b60405180910390f35b6100a76100cb366004610a95565b6102ea565b6100f77f000000000000000000000000000000000000000000000000000000000000000081565b6040519081526020016100b4565b610118610113366004610ac6565b61041d565b6040516100b493929190610aee565b6100f77f545ddbc7e064e61da37aa8d02c31aebcc5be5fc214e4262b8518ef340d605e4a81565b61017b61015c366004610ac6565b600260209081525f928352604080842090915290825290205460ff1681565b60405190151581526020016100b4565b61019e610199366004610bc1565b6104ee565b005b61019e6101ae366004610c58565b610770565b6101186101c1366004610ca3565b61077f565b6001600160a01b0381165f90815260208181526040808320805482518185028101850190935280835260609492939192909184015b828210156102df575f848152602090819020604080516060810182526003860290920180546001600160a01b039081168452600182015416938301939093526002830180549293929184019161025090610cdd565b80601f016020809104026020016040519081016040528092919081815260200182805461027c90610cdd565b80156102c75780601f1061029e576101008083540402835291602001916102c7565b820191905f5260205f20905b8154815290600101906020018083116102aa57829003601f168201915b505050505081525050815260200190600101906101fb565b505050509050919050565b6001600160a01b038083165f9081526001602090815260408083209385168352928152828220805484518184028101840190955280855260609493919290919084015b82821015610411575f848152602090819020604080516060810182526003860290920180546001600160a01b039081168452600182015416938301939093526002830180549293929184019161038290610cdd565b80601f01602080910402602001604051908101604052809291908181526020018280546103ae90610cdd565b80156103f95780601f106103d0576101008083540402835291602001916103f9565b820191905f5260205f20905b8154815290600101906020018083116103dc57829003601f168201915b5050505050815250508152602001906001019061032d565b50505050905092915050565b5f602052815f5260405f208181548110610435575f80fd5b5f9182526020909120600390910201805460018201546002830180546001600160a01b03938416965091909216935061046d90610cdd565b80601f016020809104026020016040519081016040528092919081815260200182805461049990610cdd565b80156104e45780601f106104bb576101008083540402835291602001916104e4565b820191905f5260205f20905b8154815290600101906020018083116104c757829003601f168201915b5050505050905083565b834211156105375760405162461bcd60e51b815260206004820152601160248201527014da59db985d1d5c9948195e1c1a5c9959607a1b60448201526064015b60405180910390fd5b6001600160a01b0388165f90815260026020908152604080832088845290915290205460ff161561059f5760405162461bcd60e51b8152602060048201526012602482015271139bdb98d948185b1c9958591e481d5cd95960721b604482015260640161052e565b8551602080880191909120604080517f545ddbc7e064e61da37aa8d02c31aebcc5be5fc214e4262b8518ef340d605e4a818501526001600160a01b038b168183015260608101929092526080820188905260a08083018890528151808403909101815260c08301909152805192019190912061190160f01b60e08301527f000000000000000000000000000000000000000000000000000000000000000060e28301526101028201525f906101220160408051601f1981840301815282825280516020918201205f80855291840180845281905260ff88169284019290925260608301869052608083018590529092509060019060a0016020604051602081039080840390855afa1580156106b6573d5f5f3e3d5ffd5b5050604051601f1901519150506001600160a01b038116158015906106ec5750896001600160a01b0316816001600160a01b0316145b61072c5760405162461bcd60e51b8152602060048201526011602482015270496e76616c6964207369676e617475726560781b604482015260640161052e565b6001600160a01b038a165f9081526002602090815260408083208a84529091529020805460ff191660011790556107648a8a8a6107dd565b50505050505050505050565b61077b3383836107dd565b5050565b6001602052825f5260405f20602052815f5260405f2081815481106107a2575f80fd5b5f9182526020909120600390910201805460018201546002830180546001600160a01b03938416975092909116945091925061046d90610cdd565b826001600160a01b0316826001600160a01b03160361083e5760405162461bcd60e51b815260206004820152601f60248201527f43616e6e6f742073656e64206d65737361676520746f20796f757273656c6600604482015260640161052e565b604080516060810182526001600160a01b03808616825284811660208084018281528486018781525f93845283835295832080546001818101835591855292909320855160039093020180549285166001600160a01b03199384161781559051928101805493909416929091169190911790915591519091829160028201906108c79082610d61565b5050506001600160a01b038085165f908152600160208181526040808420888616855282528084208054808501825590855293829020865160039095020180549486166001600160a01b031995861617815591860151928201805493909516929093169190911790925582015182919060028201906109469082610d61565b505050826001600160a01b0316846001600160a01b03167fe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c8460405161098c9190610e1c565b60405180910390a350505050565b80356001600160a01b03811681146109b0575f5ffd5b919050565b5f602082840312156109c5575f5ffd5b6109ce8261099a565b9392505050565b5f81518084528060208401602086015e5f602082860101526020601f19601f83011685010191505092915050565b5f602082016020835280845180835260408501915060408160051b8601019250602086015f5b82811015610a8957868503603f19018452815180516001600160a01b03908116875260208083015190911690870152604090810151606091870182905290610a73908701826109d5565b9550506020938401939190910190600101610a29565b50929695505050505050565b5f5f60408385031215610aa6575f5ffd5b610aaf8361099a565b9150610abd6020840161099a565b90509250929050565b5f5f60408385031215610ad7575f5ffd5b610ae08361099a565b946020939093013593505050565b6001600160a01b038481168252831660208201526060604082018190525f90610b19908301846109d5565b95945050505050565b634e487b7160e01b5f52604160045260245ffd5b5f82601f830112610b45575f5ffd5b813567ffffffffffffffff811115610b5f57610b5f610b22565b604051601f8201601f19908116603f0116810167ffffffffffffffff81118282101715610b8e57610b8e610b22565b604052818152838201602001851015610ba5575f5ffd5b816020850160208301375f918101602001919091529392505050565b5f5f5f5f5f5f5f5f610100898b031215610bd9575f5ffd5b610be28961099a565b9750610bf060208a0161099a565b9650604089013567ffffffffffffffff811115610c0b575f5ffd5b610c178b828c01610b36565b965050606089013594506080890135935060a089013560ff81168114610c3b575f5ffd5b979a969950949793969295929450505060c08201359160e0013590565b5f5f60408385031215610c69575f5ffd5b610c728361099a565b9150602083013567ffffffffffffffff811115610c8d575f5ffd5b610c9985828601610b36565b9150509250929050565b5f5f5f60608486031215610cb5575f5ffd5b610cbe8461099a565b9250610ccc6020850161099a565b929592945050506040919091013590565b600181811c90821680610cf157607f821691505b602082108103610d0f57634e487b7160e01b5f52602260045260245ffd5b50919050565b601f821115610d5c57805f5260205f20601f840160051c81016020851015610d3a5750805b601f840160051c820191505b81811015610d59575f8155600101610d46565b50505b505050565b815167ffffffffffffffff811115610d7b57610d7b610b22565b610d8f81610d898454610cdd565b84610d15565b6020601f821160018114610dc1575f8315610daa5750848201515b5f19600385901b1c1916600184901b178455610d59565b5f84815260208120601f198516915b82811015610df05787850151825560209485019460019092019101610dd0565b5084821015610e0d57868401515f19600387901b60f8161c191681555b50505050600190811b01905550565b602081525f6109ce60208301846109d556fea264697066735822122013c0e1e80a68a82d6b4f1c98a6a2ff06e9f8bd1f91865fa404a492cd385d054264736f6c634300081e0033",
+}
+
+// RelayedMessagingABI is the input ABI used to generate the binding from.
+// Deprecated: Use RelayedMessagingMetaData.ABI instead.
+var RelayedMessagingABI = RelayedMessagingMetaData.ABI
+
+// RelayedMessagingBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use RelayedMessagingMetaData.Bin instead.
+var RelayedMessagingBin = RelayedMessagingMetaData.Bin
+
+// DeployRelayedMessaging deploys a new Ethereum contract, binding an instance of RelayedMessaging to it.
+func DeployRelayedMessaging(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *RelayedMessaging, error) {
+	parsed, err := RelayedMessagingMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(RelayedMessagingBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &RelayedMessaging{RelayedMessagingCaller: RelayedMessagingCaller{contract: contract}, RelayedMessagingTransactor: RelayedMessagingTransactor{contract: contract}, RelayedMessagingFilterer: RelayedMessagingFilterer{contract: contract}}, nil
+}
+
+// RelayedMessaging is an auto generated Go binding around an Ethereum contract.
+type RelayedMessaging struct {
+	RelayedMessagingCaller     // Read-only binding to the contract
+	RelayedMessagingTransactor // Write-only binding to the contract
+	RelayedMessagingFilterer   // Log filterer for contract events
+}
+
+// RelayedMessagingCaller is an auto generated read-only Go binding around an Ethereum contract.
+type RelayedMessagingCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RelayedMessagingTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type RelayedMessagingTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RelayedMessagingFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type RelayedMessagingFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RelayedMessagingSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type RelayedMessagingSession struct {
+	Contract     *RelayedMessaging // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// RelayedMessagingCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type RelayedMessagingCallerSession struct {
+	Contract *RelayedMessagingCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts           // Call options to use throughout this session
+}
+
+// RelayedMessagingTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type RelayedMessagingTransactorSession struct {
+	Contract     *RelayedMessagingTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts           // Transaction auth options to use throughout this session
+}
+
+// RelayedMessagingRaw is an auto generated low-level Go binding around an Ethereum contract.
+type RelayedMessagingRaw struct {
+	Contract *RelayedMessaging // Generic contract binding to access the raw methods on
+}
+
+// RelayedMessagingCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type RelayedMessagingCallerRaw struct {
+	Contract *RelayedMessagingCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// RelayedMessagingTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type RelayedMessagingTransactorRaw struct {
+	Contract *RelayedMessagingTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewRelayedMessaging creates a new instance of RelayedMessaging, bound to a specific deployed contract.
+func NewRelayedMessaging(address common.Address, backend bind.ContractBackend) (*RelayedMessaging, error) {
+	contract, err := bindRelayedMessaging(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayedMessaging{RelayedMessagingCaller: RelayedMessagingCaller{contract: contract}, RelayedMessagingTransactor: RelayedMessagingTransactor{contract: contract}, RelayedMessagingFilterer: RelayedMessagingFilterer{contract: contract}}, nil
+}
+
+// NewRelayedMessagingCaller creates a new read-only instance of RelayedMessaging, bound to a specific deployed contract.
+func NewRelayedMessagingCaller(address common.Address, caller bind.ContractCaller) (*RelayedMessagingCaller, error) {
+	contract, err := bindRelayedMessaging(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayedMessagingCaller{contract: contract}, nil
+}
+
+// NewRelayedMessagingTransactor creates a new write-only instance of RelayedMessaging, bound to a specific deployed contract.
+func NewRelayedMessagingTransactor(address common.Address, transactor bind.ContractTransactor) (*RelayedMessagingTransactor, error) {
+	contract, err := bindRelayedMessaging(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayedMessagingTransactor{contract: contract}, nil
+}
+
+// NewRelayedMessagingFilterer creates a new log filterer instance of RelayedMessaging, bound to a specific deployed contract.
+func NewRelayedMessagingFilterer(address common.Address, filterer bind.ContractFilterer) (*RelayedMessagingFilterer, error) {
+	contract, err := bindRelayedMessaging(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayedMessagingFilterer{contract: contract}, nil
+}
+
+// bindRelayedMessaging binds a generic wrapper to an already deployed contract.
+func bindRelayedMessaging(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := RelayedMessagingMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_RelayedMessaging *RelayedMessagingRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _RelayedMessaging.Contract.RelayedMessagingCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_RelayedMessaging *RelayedMessagingRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.RelayedMessagingTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_RelayedMessaging *RelayedMessagingRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.RelayedMessagingTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_RelayedMessaging *RelayedMessagingCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _RelayedMessaging.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_RelayedMessaging *RelayedMessagingTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_RelayedMessaging *RelayedMessagingTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.contract.Transact(opts, method, params...)
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_RelayedMessaging *RelayedMessagingCaller) DOMAINSEPARATOR(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _RelayedMessaging.contract.Call(opts, &out, "DOMAIN_SEPARATOR")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_RelayedMessaging *RelayedMessagingSession) DOMAINSEPARATOR() ([32]byte, error) {
+	return _RelayedMessaging.Contract.DOMAINSEPARATOR(&_RelayedMessaging.CallOpts)
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_RelayedMessaging *RelayedMessagingCallerSession) DOMAINSEPARATOR() ([32]byte, error) {
+	return _RelayedMessaging.Contract.DOMAINSEPARATOR(&_RelayedMessaging.CallOpts)
+}
+
+// SENDMESSAGETYPEHASH is a free data retrieval call binding the contract method 0x511c7368.
+//
+// Solidity: function SEND_MESSAGE_TYPEHASH() view returns(bytes32)
+func (_RelayedMessaging *RelayedMessagingCaller) SENDMESSAGETYPEHASH(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _RelayedMessaging.contract.Call(opts, &out, "SEND_MESSAGE_TYPEHASH")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// SENDMESSAGETYPEHASH is a free data retrieval call binding the contract method 0x511c7368.
+//
+// Solidity: function SEND_MESSAGE_TYPEHASH() view returns(bytes32)
+func (_RelayedMessaging *RelayedMessagingSession) SENDMESSAGETYPEHASH() ([32]byte, error) {
+	return _RelayedMessaging.Contract.SENDMESSAGETYPEHASH(&_RelayedMessaging.CallOpts)
+}
+
+// SENDMESSAGETYPEHASH is a free data retrieval call binding the contract method 0x511c7368.
+//
+// Solidity: function SEND_MESSAGE_TYPEHASH() view returns(bytes32)
+func (_RelayedMessaging *RelayedMessagingCallerSession) SENDMESSAGETYPEHASH() ([32]byte, error) {
+	return _RelayedMessaging.Contract.SENDMESSAGETYPEHASH(&_RelayedMessaging.CallOpts)
+}
+
+// Dialogues is a free data retrieval call binding the contract method 0xea9a7f3f.
+//
+// Solidity: function dialogues(address , address , uint256 ) view returns(address sender, address receiver, string content)
+func (_RelayedMessaging *RelayedMessagingCaller) Dialogues(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address, arg2 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	var out []interface{}
+	err := _RelayedMessaging.contract.Call(opts, &out, "dialogues", arg0, arg1, arg2)
+
+	outstruct := new(struct {
+		Sender   common.Address
+		Receiver common.Address
+		Content  string
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Sender = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Receiver = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Content = *abi.ConvertType(out[2], new(string)).(*string)
+
+	return *outstruct, err
+
+}
+
+// Dialogues is a free data retrieval call binding the contract method 0xea9a7f3f.
+//
+// Solidity: function dialogues(address , address , uint256 ) view returns(address sender, address receiver, string content)
+func (_RelayedMessaging *RelayedMessagingSession) Dialogues(arg0 common.Address, arg1 common.Address, arg2 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _RelayedMessaging.Contract.Dialogues(&_RelayedMessaging.CallOpts, arg0, arg1, arg2)
+}
+
+// Dialogues is a free data retrieval call binding the contract method 0xea9a7f3f.
+//
+// Solidity: function dialogues(address , address , uint256 ) view returns(address sender, address receiver, string content)
+func (_RelayedMessaging *RelayedMessagingCallerSession) Dialogues(arg0 common.Address, arg1 common.Address, arg2 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _RelayedMessaging.Contract.Dialogues(&_RelayedMessaging.CallOpts, arg0, arg1, arg2)
+}
+
+// GetDialogue is a free data retrieval call binding the contract method 0x23b13485.
+//
+// Solidity: function getDialogue(address user1, address user2) view returns((address,address,string)[])
+func (_RelayedMessaging *RelayedMessagingCaller) GetDialogue(opts *bind.CallOpts, user1 common.Address, user2 common.Address) ([]MessagingMessage, error) {
+	var out []interface{}
+	err := _RelayedMessaging.contract.Call(opts, &out, "getDialogue", user1, user2)
+
+	if err != nil {
+		return *new([]MessagingMessage), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]MessagingMessage)).(*[]MessagingMessage)
+
+	return out0, err
+
+}
+
+// GetDialogue is a free data retrieval call binding the contract method 0x23b13485.
+//
+// Solidity: function getDialogue(address user1, address user2) view returns((address,address,string)[])
+func (_RelayedMessaging *RelayedMessagingSession) GetDialogue(user1 common.Address, user2 common.Address) ([]MessagingMessage, error) {
+	return _RelayedMessaging.Contract.GetDialogue(&_RelayedMessaging.CallOpts, user1, user2)
+}
+
+// GetDialogue is a free data retrieval call binding the contract method 0x23b13485.
+//
+// Solidity: function getDialogue(address user1, address user2) view returns((address,address,string)[])
+func (_RelayedMessaging *RelayedMessagingCallerSession) GetDialogue(user1 common.Address, user2 common.Address) ([]MessagingMessage, error) {
+	return _RelayedMessaging.Contract.GetDialogue(&_RelayedMessaging.CallOpts, user1, user2)
+}
+
+// GetInbox is a free data retrieval call binding the contract method 0x02201681.
+//
+// Solidity: function getInbox(address user) view returns((address,address,string)[])
+func (_RelayedMessaging *RelayedMessagingCaller) GetInbox(opts *bind.CallOpts, user common.Address) ([]MessagingMessage, error) {
+	var out []interface{}
+	err := _RelayedMessaging.contract.Call(opts, &out, "getInbox", user)
+
+	if err != nil {
+		return *new([]MessagingMessage), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]MessagingMessage)).(*[]MessagingMessage)
+
+	return out0, err
+
+}
+
+// GetInbox is a free data retrieval call binding the contract method 0x02201681.
+//
+// Solidity: function getInbox(address user) view returns((address,address,string)[])
+func (_RelayedMessaging *RelayedMessagingSession) GetInbox(user common.Address) ([]MessagingMessage, error) {
+	return _RelayedMessaging.Contract.GetInbox(&_RelayedMessaging.CallOpts, user)
+}
+
+// GetInbox is a free data retrieval call binding the contract method 0x02201681.
+//
+// Solidity: function getInbox(address user) view returns((address,address,string)[])
+func (_RelayedMessaging *RelayedMessagingCallerSession) GetInbox(user common.Address) ([]MessagingMessage, error) {
+	return _RelayedMessaging.Contract.GetInbox(&_RelayedMessaging.CallOpts, user)
+}
+
+// Inbox is a free data retrieval call binding the contract method 0x4d622e7a.
+//
+// Solidity: function inbox(address , uint256 ) view returns(address sender, address receiver, string content)
+func (_RelayedMessaging *RelayedMessagingCaller) Inbox(opts *bind.CallOpts, arg0 common.Address, arg1 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	var out []interface{}
+	err := _RelayedMessaging.contract.Call(opts, &out, "inbox", arg0, arg1)
+
+	outstruct := new(struct {
+		Sender   common.Address
+		Receiver common.Address
+		Content  string
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Sender = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Receiver = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Content = *abi.ConvertType(out[2], new(string)).(*string)
+
+	return *outstruct, err
+
+}
+
+// Inbox is a free data retrieval call binding the contract method 0x4d622e7a.
+//
+// Solidity: function inbox(address , uint256 ) view returns(address sender, address receiver, string content)
+func (_RelayedMessaging *RelayedMessagingSession) Inbox(arg0 common.Address, arg1 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _RelayedMessaging.Contract.Inbox(&_RelayedMessaging.CallOpts, arg0, arg1)
+}
+
+// Inbox is a free data retrieval call binding the contract method 0x4d622e7a.
+//
+// Solidity: function inbox(address , uint256 ) view returns(address sender, address receiver, string content)
+func (_RelayedMessaging *RelayedMessagingCallerSession) Inbox(arg0 common.Address, arg1 *big.Int) (struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+}, error) {
+	return _RelayedMessaging.Contract.Inbox(&_RelayedMessaging.CallOpts, arg0, arg1)
+}
+
+// UsedNonces is a free data retrieval call binding the contract method 0x6a8a6894.
+//
+// Solidity: function usedNonces(address , uint256 ) view returns(bool)
+func (_RelayedMessaging *RelayedMessagingCaller) UsedNonces(opts *bind.CallOpts, arg0 common.Address, arg1 *big.Int) (bool, error) {
+	var out []interface{}
+	err := _RelayedMessaging.contract.Call(opts, &out, "usedNonces", arg0, arg1)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// UsedNonces is a free data retrieval call binding the contract method 0x6a8a6894.
+//
+// Solidity: function usedNonces(address , uint256 ) view returns(bool)
+func (_RelayedMessaging *RelayedMessagingSession) UsedNonces(arg0 common.Address, arg1 *big.Int) (bool, error) {
+	return _RelayedMessaging.Contract.UsedNonces(&_RelayedMessaging.CallOpts, arg0, arg1)
+}
+
+// UsedNonces is a free data retrieval call binding the contract method 0x6a8a6894.
+//
+// Solidity: function usedNonces(address , uint256 ) view returns(bool)
+func (_RelayedMessaging *RelayedMessagingCallerSession) UsedNonces(arg0 common.Address, arg1 *big.Int) (bool, error) {
+	return _RelayedMessaging.Contract.UsedNonces(&_RelayedMessaging.CallOpts, arg0, arg1)
+}
+
+// SendMessage is a paid mutator transaction binding the contract method 0xde6f24bb.
+//
+// Solidity: function sendMessage(address _receiver, string _content) returns()
+func (_RelayedMessaging *RelayedMessagingTransactor) SendMessage(opts *bind.TransactOpts, _receiver common.Address, _content string) (*types.Transaction, error) {
+	return _RelayedMessaging.contract.Transact(opts, "sendMessage", _receiver, _content)
+}
+
+// SendMessage is a paid mutator transaction binding the contract method 0xde6f24bb.
+//
+// Solidity: function sendMessage(address _receiver, string _content) returns()
+func (_RelayedMessaging *RelayedMessagingSession) SendMessage(_receiver common.Address, _content string) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.SendMessage(&_RelayedMessaging.TransactOpts, _receiver, _content)
+}
+
+// SendMessage is a paid mutator transaction binding the contract method 0xde6f24bb.
+//
+// Solidity: function sendMessage(address _receiver, string _content) returns()
+func (_RelayedMessaging *RelayedMessagingTransactorSession) SendMessage(_receiver common.Address, _content string) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.SendMessage(&_RelayedMessaging.TransactOpts, _receiver, _content)
+}
+
+// SendMessageBySig is a paid mutator transaction binding the contract method 0xc93e1f2b.
+//
+// Solidity: function sendMessageBySig(address sender, address receiver, string content, uint256 nonce, uint256 deadline, uint8 v, bytes32 r, bytes32 s) returns()
+func (_RelayedMessaging *RelayedMessagingTransactor) SendMessageBySig(opts *bind.TransactOpts, sender common.Address, receiver common.Address, content string, nonce *big.Int, deadline *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _RelayedMessaging.contract.Transact(opts, "sendMessageBySig", sender, receiver, content, nonce, deadline, v, r, s)
+}
+
+// SendMessageBySig is a paid mutator transaction binding the contract method 0xc93e1f2b.
+//
+// Solidity: function sendMessageBySig(address sender, address receiver, string content, uint256 nonce, uint256 deadline, uint8 v, bytes32 r, bytes32 s) returns()
+func (_RelayedMessaging *RelayedMessagingSession) SendMessageBySig(sender common.Address, receiver common.Address, content string, nonce *big.Int, deadline *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.SendMessageBySig(&_RelayedMessaging.TransactOpts, sender, receiver, content, nonce, deadline, v, r, s)
+}
+
+// SendMessageBySig is a paid mutator transaction binding the contract method 0xc93e1f2b.
+//
+// Solidity: function sendMessageBySig(address sender, address receiver, string content, uint256 nonce, uint256 deadline, uint8 v, bytes32 r, bytes32 s) returns()
+func (_RelayedMessaging *RelayedMessagingTransactorSession) SendMessageBySig(sender common.Address, receiver common.Address, content string, nonce *big.Int, deadline *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _RelayedMessaging.Contract.SendMessageBySig(&_RelayedMessaging.TransactOpts, sender, receiver, content, nonce, deadline, v, r, s)
+}
+
+// RelayedMessagingMessageSentIterator is returned from FilterMessageSent and is used to iterate over the raw logs and unpacked data for MessageSent events raised by the RelayedMessaging contract.
+type RelayedMessagingMessageSentIterator struct {
+	Event *RelayedMessagingMessageSent // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RelayedMessagingMessageSentIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RelayedMessagingMessageSent)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RelayedMessagingMessageSent)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RelayedMessagingMessageSentIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RelayedMessagingMessageSentIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RelayedMessagingMessageSent represents a MessageSent event raised by the RelayedMessaging contract.
+type RelayedMessagingMessageSent struct {
+	Sender   common.Address
+	Receiver common.Address
+	Content  string
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterMessageSent is a free log retrieval operation binding the contract event 0xe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c.
+//
+// Solidity: event MessageSent(address indexed sender, address indexed receiver, string content)
+func (_RelayedMessaging *RelayedMessagingFilterer) FilterMessageSent(opts *bind.FilterOpts, sender []common.Address, receiver []common.Address) (*RelayedMessagingMessageSentIterator, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+	var receiverRule []interface{}
+	for _, receiverItem := range receiver {
+		receiverRule = append(receiverRule, receiverItem)
+	}
+
+	logs, sub, err := _RelayedMessaging.contract.FilterLogs(opts, "MessageSent", senderRule, receiverRule)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayedMessagingMessageSentIterator{contract: _RelayedMessaging.contract, event: "MessageSent", logs: logs, sub: sub}, nil
+}
+
+// WatchMessageSent is a free log subscription operation binding the contract event 0xe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c.
+//
+// Solidity: event MessageSent(address indexed sender, address indexed receiver, string content)
+func (_RelayedMessaging *RelayedMessagingFilterer) WatchMessageSent(opts *bind.WatchOpts, sink chan<- *RelayedMessagingMessageSent, sender []common.Address, receiver []common.Address) (event.Subscription, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+	var receiverRule []interface{}
+	for _, receiverItem := range receiver {
+		receiverRule = append(receiverRule, receiverItem)
+	}
+
+	logs, sub, err := _RelayedMessaging.contract.WatchLogs(opts, "MessageSent", senderRule, receiverRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RelayedMessagingMessageSent)
+				if err := _RelayedMessaging.contract.UnpackLog(event, "MessageSent", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseMessageSent is a log parse operation binding the contract event 0xe2cf7446a11cbcd14cd99ea3a1bb77fb7653a64f4064d660140b5100d001e13c.
+//
+// Solidity: event MessageSent(address indexed sender, address indexed receiver, string content)
+func (_RelayedMessaging *RelayedMessagingFilterer) ParseMessageSent(log types.Log) (*RelayedMessagingMessageSent, error) {
+	event := new(RelayedMessagingMessageSent)
+	if err := _RelayedMessaging.contract.UnpackLog(event, "MessageSent", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,186 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var ErrFeeCapReached = errors.New("fee cap reached")
+
+// GasPolicy prices the relayer's transactions. Fees follow the node's suggestion, replacements
+// pay at least BumpPercent more than the transaction they replace.
+type GasPolicy struct {
+	// MaxFeePerGas caps what the relayer pays per gas in wei, nil for no cap
+	MaxFeePerGas *big.Int
+	// BumpPercent is the fee increase of a replacement, nodes require at least 10
+	BumpPercent int64
+}
+
+// SentTx is a broadcast transaction, with what's needed to replace it.
+type SentTx struct {
+	Hash      common.Hash
+	Nonce     uint64
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// Relayer signs the backend's transactions. The clients sharing it send one transaction at a time
+// and the nonce is tracked locally, so they never pick the same nonce.
+type Relayer struct {
+	backend Backend
+	signer  *bind.TransactOpts
+	chainID *big.Int
+	gas     GasPolicy
+
+	mu sync.Mutex
+	// nonce is the next nonce to use, nil until it's fetched and after a failed send
+	nonce *uint64
+}
+
+func NewRelayer(backend Backend, key *ecdsa.PrivateKey, chainID *big.Int, gas GasPolicy) (*Relayer, error) {
+	signer, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("NewRelayer/NewKeyedTransactorWithChainID: %w", err)
+	}
+
+	return &Relayer{
+		backend: backend,
+		signer:  signer,
+		chainID: chainID,
+		gas:     gas,
+	}, nil
+}
+
+func (r *Relayer) Address() common.Address {
+	return r.signer.From
+}
+
+func (r *Relayer) ChainID() *big.Int {
+	return new(big.Int).Set(r.chainID)
+}
+
+// transact signs and broadcasts the transaction send builds. With replace set it reuses replace's
+// nonce and outbids its fees, so the node drops the stuck transaction for the new one.
+func (r *Relayer) transact(ctx context.Context, replace *SentTx, send func(opts *bind.TransactOpts) (*types.Transaction, error)) (*SentTx, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	opts := *r.signer
+	opts.Context = ctx
+	if replace != nil {
+		opts.Nonce = new(big.Int).SetUint64(replace.Nonce)
+	} else {
+		if r.nonce == nil {
+			nonce, err := r.backend.PendingNonceAt(ctx, r.signer.From)
+			if err != nil {
+				return nil, fmt.Errorf("transact/PendingNonceAt: %w", err)
+			}
+			r.nonce = &nonce
+		}
+		opts.Nonce = new(big.Int).SetUint64(*r.nonce)
+	}
+	if err := r.price(ctx, &opts, replace); err != nil {
+		return nil, fmt.Errorf("transact/price: %w", err)
+	}
+
+	tx, err := send(&opts)
+	if err != nil {
+		if replace == nil {
+			// the node may have seen the transaction anyway, ask it again next time
+			r.nonce = nil
+		}
+		return nil, err
+	}
+	if replace == nil {
+		*r.nonce++
+	}
+
+	return &SentTx{
+		Hash:      tx.Hash(),
+		Nonce:     tx.Nonce(),
+		GasTipCap: tx.GasTipCap(),
+		GasFeeCap: tx.GasFeeCap(),
+	}, nil
+}
+
+// price sets the fees of opts: the suggested tip on top of twice the base fee, so the transaction
+// stays valid through a few full blocks. Chains without a base fee get a legacy gas price.
+func (r *Relayer) price(ctx context.Context, opts *bind.TransactOpts, replace *SentTx) error {
+	head, err := r.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("price/HeaderByNumber: %w", err)
+	}
+
+	var tip, feeCap *big.Int
+	if head.BaseFee == nil {
+		if tip, err = r.backend.SuggestGasPrice(ctx); err != nil {
+			return fmt.Errorf("price/SuggestGasPrice: %w", err)
+		}
+		feeCap = tip
+	} else {
+		if tip, err = r.backend.SuggestGasTipCap(ctx); err != nil {
+			return fmt.Errorf("price/SuggestGasTipCap: %w", err)
+		}
+		feeCap = new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip)
+	}
+
+	if replace != nil {
+		tip = bigMax(tip, r.bump(replace.GasTipCap))
+		feeCap = bigMax(feeCap, r.bump(replace.GasFeeCap))
+	}
+	if r.gas.MaxFeePerGas != nil && feeCap.Cmp(r.gas.MaxFeePerGas) > 0 {
+		if replace != nil {
+			// a replacement under the cap wouldn't outbid the stuck transaction
+			return ErrFeeCapReached
+		}
+		feeCap = r.gas.MaxFeePerGas
+		if tip.Cmp(feeCap) > 0 {
+			tip = feeCap
+		}
+	}
+
+	if head.BaseFee == nil {
+		opts.GasPrice = feeCap
+	} else {
+		opts.GasTipCap, opts.GasFeeCap = tip, feeCap
+	}
+	return nil
+}
+
+// bump raises fee by BumpPercent, rounding up so small fees still grow.
+func (r *Relayer) bump(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+r.gas.BumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// receipt reports TxPending until the transaction has a receipt, and the block it was mined in.
+func (r *Relayer) receipt(ctx context.Context, hash common.Hash) (TxStatus, uint64, error) {
+	receipt, err := r.backend.TransactionReceipt(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return TxPending, 0, nil
+		}
+		return TxPending, 0, fmt.Errorf("receipt/TransactionReceipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return TxFailed, receipt.BlockNumber.Uint64(), nil
+	}
+	return TxConfirmed, receipt.BlockNumber.Uint64(), nil
+}
@@ -0,0 +1,4 @@
+// Package token holds the Go bindings of balanceOf(address), the one view ERC-20 and ERC-721 share.
+package token
+
+//go:generate abigen --abi Token.abi --pkg token --type Token --out token.go
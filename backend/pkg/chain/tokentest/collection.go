@@ -0,0 +1,469 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package tokentest
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// TestCollectionMetaData contains all meta data concerning the TestCollection contract.
+var TestCollectionMetaData = &bind.MetaData{
+	ABI: "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"}],\"name\":\"Transfer\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"}],\"name\":\"mint\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"ownerOf\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"}],\"name\":\"transferFrom\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+	Bin: "0x6080604052348015600e575f5ffd5b5061042f8061001c5f395ff3fe608060405234801561000f575f5ffd5b506004361061004a575f3560e01c806323b872dd1461004e57806340c10f19146100635780636352211e1461007657806370a08231146100bb575b5f5ffd5b61006161005c366004610320565b6100e8565b005b61006161007136600461035a565b610225565b61009e610084366004610382565b5f602081905290815260409020546001600160a01b031681565b6040516001600160a01b0390911681526020015b60405180910390f35b6100da6100c9366004610399565b60016020525f908152604090205481565b6040519081526020016100b2565b5f818152602081905260409020546001600160a01b0384811691161480156101185750336001600160a01b038416145b6101695760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920746865206f776e65722063616e207472616e73666572000000000060448201526064015b60405180910390fd5b5f8181526020818152604080832080546001600160a01b0319166001600160a01b03878116919091179091558616835260019182905282208054919290916101b29084906103cd565b90915550506001600160a01b0382165f90815260016020819052604082208054919290916101e19084906103e6565b909155505060405181906001600160a01b0380851691908616907fddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef905f90a4505050565b5f818152602081905260409020546001600160a01b0316156102805760405162461bcd60e51b8152602060048201526014602482015273151bdad95b88185b1c9958591e481b5a5b9d195960621b6044820152606401610160565b5f8181526020818152604080832080546001600160a01b0319166001600160a01b038716908117909155835260019182905282208054919290916102c59084906103e6565b909155505060405181906001600160a01b038416905f907fddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef908290a45050565b80356001600160a01b038116811461031b575f5ffd5b919050565b5f5f5f60608486031215610332575f5ffd5b61033b84610305565b925061034960208501610305565b929592945050506040919091013590565b5f5f6040838503121561036b575f5ffd5b61037483610305565b946020939093013593505050565b5f60208284031215610392575f5ffd5b5035919050565b5f602082840312156103a9575f5ffd5b6103b282610305565b9392505050565b634e487b7160e01b5f52601160045260245ffd5b818103818111156103e0576103e06103b9565b92915050565b808201808211156103e0576103e06103b956fea26469706673582212209cef0468bc8608ec9da22af2097e1952f42ae79a2ec184328ffa2f8801dbfc6464736f6c634300081e0033",
+}
+
+// TestCollectionABI is the input ABI used to generate the binding from.
+// Deprecated: Use TestCollectionMetaData.ABI instead.
+var TestCollectionABI = TestCollectionMetaData.ABI
+
+// TestCollectionBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use TestCollectionMetaData.Bin instead.
+var TestCollectionBin = TestCollectionMetaData.Bin
+
+// DeployTestCollection deploys a new Ethereum contract, binding an instance of TestCollection to it.
+func DeployTestCollection(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *TestCollection, error) {
+	parsed, err := TestCollectionMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(TestCollectionBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &TestCollection{TestCollectionCaller: TestCollectionCaller{contract: contract}, TestCollectionTransactor: TestCollectionTransactor{contract: contract}, TestCollectionFilterer: TestCollectionFilterer{contract: contract}}, nil
+}
+
+// TestCollection is an auto generated Go binding around an Ethereum contract.
+type TestCollection struct {
+	TestCollectionCaller     // Read-only binding to the contract
+	TestCollectionTransactor // Write-only binding to the contract
+	TestCollectionFilterer   // Log filterer for contract events
+}
+
+// TestCollectionCaller is an auto generated read-only Go binding around an Ethereum contract.
+type TestCollectionCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TestCollectionTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type TestCollectionTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TestCollectionFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type TestCollectionFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TestCollectionSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type TestCollectionSession struct {
+	Contract     *TestCollection   // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// TestCollectionCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type TestCollectionCallerSession struct {
+	Contract *TestCollectionCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts         // Call options to use throughout this session
+}
+
+// TestCollectionTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type TestCollectionTransactorSession struct {
+	Contract     *TestCollectionTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// TestCollectionRaw is an auto generated low-level Go binding around an Ethereum contract.
+type TestCollectionRaw struct {
+	Contract *TestCollection // Generic contract binding to access the raw methods on
+}
+
+// TestCollectionCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type TestCollectionCallerRaw struct {
+	Contract *TestCollectionCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// TestCollectionTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type TestCollectionTransactorRaw struct {
+	Contract *TestCollectionTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewTestCollection creates a new instance of TestCollection, bound to a specific deployed contract.
+func NewTestCollection(address common.Address, backend bind.ContractBackend) (*TestCollection, error) {
+	contract, err := bindTestCollection(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &TestCollection{TestCollectionCaller: TestCollectionCaller{contract: contract}, TestCollectionTransactor: TestCollectionTransactor{contract: contract}, TestCollectionFilterer: TestCollectionFilterer{contract: contract}}, nil
+}
+
+// NewTestCollectionCaller creates a new read-only instance of TestCollection, bound to a specific deployed contract.
+func NewTestCollectionCaller(address common.Address, caller bind.ContractCaller) (*TestCollectionCaller, error) {
+	contract, err := bindTestCollection(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TestCollectionCaller{contract: contract}, nil
+}
+
+// NewTestCollectionTransactor creates a new write-only instance of TestCollection, bound to a specific deployed contract.
+func NewTestCollectionTransactor(address common.Address, transactor bind.ContractTransactor) (*TestCollectionTransactor, error) {
+	contract, err := bindTestCollection(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TestCollectionTransactor{contract: contract}, nil
+}
+
+// NewTestCollectionFilterer creates a new log filterer instance of TestCollection, bound to a specific deployed contract.
+func NewTestCollectionFilterer(address common.Address, filterer bind.ContractFilterer) (*TestCollectionFilterer, error) {
+	contract, err := bindTestCollection(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &TestCollectionFilterer{contract: contract}, nil
+}
+
+// bindTestCollection binds a generic wrapper to an already deployed contract.
+func bindTestCollection(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := TestCollectionMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_TestCollection *TestCollectionRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _TestCollection.Contract.TestCollectionCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_TestCollection *TestCollectionRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _TestCollection.Contract.TestCollectionTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_TestCollection *TestCollectionRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _TestCollection.Contract.TestCollectionTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_TestCollection *TestCollectionCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _TestCollection.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_TestCollection *TestCollectionTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _TestCollection.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_TestCollection *TestCollectionTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _TestCollection.Contract.contract.Transact(opts, method, params...)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address ) view returns(uint256)
+func (_TestCollection *TestCollectionCaller) BalanceOf(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _TestCollection.contract.Call(opts, &out, "balanceOf", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address ) view returns(uint256)
+func (_TestCollection *TestCollectionSession) BalanceOf(arg0 common.Address) (*big.Int, error) {
+	return _TestCollection.Contract.BalanceOf(&_TestCollection.CallOpts, arg0)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address ) view returns(uint256)
+func (_TestCollection *TestCollectionCallerSession) BalanceOf(arg0 common.Address) (*big.Int, error) {
+	return _TestCollection.Contract.BalanceOf(&_TestCollection.CallOpts, arg0)
+}
+
+// OwnerOf is a free data retrieval call binding the contract method 0x6352211e.
+//
+// Solidity: function ownerOf(uint256 ) view returns(address)
+func (_TestCollection *TestCollectionCaller) OwnerOf(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _TestCollection.contract.Call(opts, &out, "ownerOf", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// OwnerOf is a free data retrieval call binding the contract method 0x6352211e.
+//
+// Solidity: function ownerOf(uint256 ) view returns(address)
+func (_TestCollection *TestCollectionSession) OwnerOf(arg0 *big.Int) (common.Address, error) {
+	return _TestCollection.Contract.OwnerOf(&_TestCollection.CallOpts, arg0)
+}
+
+// OwnerOf is a free data retrieval call binding the contract method 0x6352211e.
+//
+// Solidity: function ownerOf(uint256 ) view returns(address)
+func (_TestCollection *TestCollectionCallerSession) OwnerOf(arg0 *big.Int) (common.Address, error) {
+	return _TestCollection.Contract.OwnerOf(&_TestCollection.CallOpts, arg0)
+}
+
+// Mint is a paid mutator transaction binding the contract method 0x40c10f19.
+//
+// Solidity: function mint(address to, uint256 tokenId) returns()
+func (_TestCollection *TestCollectionTransactor) Mint(opts *bind.TransactOpts, to common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _TestCollection.contract.Transact(opts, "mint", to, tokenId)
+}
+
+// Mint is a paid mutator transaction binding the contract method 0x40c10f19.
+//
+// Solidity: function mint(address to, uint256 tokenId) returns()
+func (_TestCollection *TestCollectionSession) Mint(to common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _TestCollection.Contract.Mint(&_TestCollection.TransactOpts, to, tokenId)
+}
+
+// Mint is a paid mutator transaction binding the contract method 0x40c10f19.
+//
+// Solidity: function mint(address to, uint256 tokenId) returns()
+func (_TestCollection *TestCollectionTransactorSession) Mint(to common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _TestCollection.Contract.Mint(&_TestCollection.TransactOpts, to, tokenId)
+}
+
+// TransferFrom is a paid mutator transaction binding the contract method 0x23b872dd.
+//
+// Solidity: function transferFrom(address from, address to, uint256 tokenId) returns()
+func (_TestCollection *TestCollectionTransactor) TransferFrom(opts *bind.TransactOpts, from common.Address, to common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _TestCollection.contract.Transact(opts, "transferFrom", from, to, tokenId)
+}
+
+// TransferFrom is a paid mutator transaction binding the contract method 0x23b872dd.
+//
+// Solidity: function transferFrom(address from, address to, uint256 tokenId) returns()
+func (_TestCollection *TestCollectionSession) TransferFrom(from common.Address, to common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _TestCollection.Contract.TransferFrom(&_TestCollection.TransactOpts, from, to, tokenId)
+}
+
+// TransferFrom is a paid mutator transaction binding the contract method 0x23b872dd.
+//
+// Solidity: function transferFrom(address from, address to, uint256 tokenId) returns()
+func (_TestCollection *TestCollectionTransactorSession) TransferFrom(from common.Address, to common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _TestCollection.Contract.TransferFrom(&_TestCollection.TransactOpts, from, to, tokenId)
+}
+
+// TestCollectionTransferIterator is returned from FilterTransfer and is used to iterate over the raw logs and unpacked data for Transfer events raised by the TestCollection contract.
+type TestCollectionTransferIterator struct {
+	Event *TestCollectionTransfer // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TestCollectionTransferIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TestCollectionTransfer)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TestCollectionTransfer)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TestCollectionTransferIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TestCollectionTransferIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TestCollectionTransfer represents a Transfer event raised by the TestCollection contract.
+type TestCollectionTransfer struct {
+	From    common.Address
+	To      common.Address
+	TokenId *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterTransfer is a free log retrieval operation binding the contract event 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)
+func (_TestCollection *TestCollectionFilterer) FilterTransfer(opts *bind.FilterOpts, from []common.Address, to []common.Address, tokenId []*big.Int) (*TestCollectionTransferIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+
+	logs, sub, err := _TestCollection.contract.FilterLogs(opts, "Transfer", fromRule, toRule, tokenIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &TestCollectionTransferIterator{contract: _TestCollection.contract, event: "Transfer", logs: logs, sub: sub}, nil
+}
+
+// WatchTransfer is a free log subscription operation binding the contract event 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)
+func (_TestCollection *TestCollectionFilterer) WatchTransfer(opts *bind.WatchOpts, sink chan<- *TestCollectionTransfer, from []common.Address, to []common.Address, tokenId []*big.Int) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+
+	logs, sub, err := _TestCollection.contract.WatchLogs(opts, "Transfer", fromRule, toRule, tokenIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TestCollectionTransfer)
+				if err := _TestCollection.contract.UnpackLog(event, "Transfer", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseTransfer is a log parse operation binding the contract event 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)
+func (_TestCollection *TestCollectionFilterer) ParseTransfer(log types.Log) (*TestCollectionTransfer, error) {
+	event := new(TestCollectionTransfer)
+	if err := _TestCollection.contract.UnpackLog(event, "Transfer", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
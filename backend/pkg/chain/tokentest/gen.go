@@ -0,0 +1,6 @@
+// Package tokentest holds the Go bindings of the TestToken and TestCollection contracts from
+// contracts/test_tokens.sol, tests deploy them to pay and gate with.
+package tokentest
+
+//go:generate abigen --abi TestToken.abi --bin TestToken.bin --pkg tokentest --type TestToken --out token.go
+//go:generate abigen --abi TestCollection.abi --bin TestCollection.bin --pkg tokentest --type TestCollection --out collection.go
@@ -0,0 +1,121 @@
+package e2e
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// KeyMessage is the text a wallet signs to publish a messaging public key.
+const KeyMessage = "Publish messaging key %s for end-to-end encrypted messages"
+
+const NonceSize = 16
+
+var (
+	ErrInvalidPublicKey = errors.New("invalid messaging public key")
+	ErrInvalidNonce     = errors.New("invalid nonce")
+	ErrUnknownKey       = errors.New("no private key for key id")
+)
+
+// GenerateKey creates a new messaging key. It is unrelated to the wallet key.
+func GenerateKey() (*ecdsa.PrivateKey, error) {
+	return crypto.GenerateKey()
+}
+
+// PublicKeyHex encodes pub as uncompressed 0x-prefixed hex, the form stored in the key registry.
+func PublicKeyHex(pub *ecdsa.PublicKey) string {
+	return hexutil.Encode(crypto.FromECDSAPub(pub))
+}
+
+// ParsePublicKey accepts compressed or uncompressed 0x-prefixed hex.
+func ParsePublicKey(s string) (*ecdsa.PublicKey, error) {
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPublicKey, err)
+	}
+	var pub *ecdsa.PublicKey
+	if len(raw) == 33 {
+		pub, err = crypto.DecompressPubkey(raw)
+	} else {
+		pub, err = crypto.UnmarshalPubkey(raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPublicKey, err)
+	}
+
+	return pub, nil
+}
+
+// SignedKeyMessage returns the message a wallet has to sign for pubHex.
+func SignedKeyMessage(pubHex string) string {
+	return fmt.Sprintf(KeyMessage, strings.ToLower(pubHex))
+}
+
+// NewNonce returns a random hex nonce shared by all ciphertexts of one message.
+func NewNonce() (string, error) {
+	b := make([]byte, NonceSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// ValidateNonce checks that nonce is a hex string of NonceSize bytes.
+func ValidateNonce(nonce string) error {
+	b, err := hex.DecodeString(nonce)
+	if err != nil || len(b) != NonceSize {
+		return ErrInvalidNonce
+	}
+
+	return nil
+}
+
+// Encrypt seals plaintext for pub with ECIES. The nonce is authenticated together with the
+// ciphertext, so a ciphertext can't be replayed under another message.
+func Encrypt(pub *ecdsa.PublicKey, plaintext []byte, nonce string) (string, error) {
+	if err := ValidateNonce(nonce); err != nil {
+		return "", err
+	}
+	ct, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), plaintext, nil, []byte(nonce))
+	if err != nil {
+		return "", fmt.Errorf("Encrypt: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(ct), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt.
+func Decrypt(prv *ecdsa.PrivateKey, ciphertext, nonce string) ([]byte, error) {
+	ct, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("Decrypt/DecodeString: %w", err)
+	}
+	plaintext, err := ecies.ImportECDSA(prv).Decrypt(ct, nil, []byte(nonce))
+	if err != nil {
+		return nil, fmt.Errorf("Decrypt: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// Keyring keeps every messaging key a client has published, by registry key id. Keys are never
+// dropped on rotation so messages encrypted to an older key stay readable.
+type Keyring map[int64]*ecdsa.PrivateKey
+
+func (k Keyring) Decrypt(keyID int64, ciphertext, nonce string) ([]byte, error) {
+	prv, ok := k[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrUnknownKey, keyID)
+	}
+
+	return Decrypt(prv, ciphertext, nonce)
+}
@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	prv, err := GenerateKey()
+	require.NoError(t, err)
+	pub, err := ParsePublicKey(PublicKeyHex(&prv.PublicKey))
+	require.NoError(t, err)
+
+	nonce, err := NewNonce()
+	require.NoError(t, err)
+	ct, err := Encrypt(pub, []byte("hello"), nonce)
+	require.NoError(t, err)
+
+	plaintext, err := Decrypt(prv, ct, nonce)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(plaintext))
+
+	// the nonce is bound to the ciphertext
+	other, err := NewNonce()
+	require.NoError(t, err)
+	_, err = Decrypt(prv, ct, other)
+	require.Error(t, err)
+
+	// so is the recipient
+	stranger, err := GenerateKey()
+	require.NoError(t, err)
+	_, err = Decrypt(stranger, ct, nonce)
+	require.Error(t, err)
+
+	_, err = Encrypt(pub, []byte("hello"), "not a nonce")
+	require.ErrorIs(t, err, ErrInvalidNonce)
+	_, err = ParsePublicKey("0x1234")
+	require.ErrorIs(t, err, ErrInvalidPublicKey)
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := GenerateKey()
+	require.NoError(t, err)
+	current, err := GenerateKey()
+	require.NoError(t, err)
+	keyring := Keyring{1: old, 2: current}
+
+	nonce, err := NewNonce()
+	require.NoError(t, err)
+	ct, err := Encrypt(&old.PublicKey, []byte("before rotation"), nonce)
+	require.NoError(t, err)
+
+	plaintext, err := keyring.Decrypt(1, ct, nonce)
+	require.NoError(t, err)
+	require.Equal(t, "before rotation", string(plaintext))
+
+	_, err = keyring.Decrypt(3, ct, nonce)
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
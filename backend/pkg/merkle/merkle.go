@@ -0,0 +1,71 @@
+// Package merkle builds keccak256 Merkle trees with sorted pairs, the layout OpenZeppelin's
+// MerkleProof verifies on-chain. A node without a sibling moves up a level unchanged.
+package merkle
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type Tree struct {
+	// layers[0] are the leaves, the last layer holds the root
+	layers [][]common.Hash
+}
+
+// New builds the tree over leaves in their order. It panics on an empty slice.
+func New(leaves []common.Hash) *Tree {
+	if len(leaves) == 0 {
+		panic("merkle: no leaves")
+	}
+
+	layers := [][]common.Hash{leaves}
+	for layer := leaves; len(layer) > 1; {
+		next := make([]common.Hash, 0, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				next = append(next, layer[i])
+				continue
+			}
+			next = append(next, hashPair(layer[i], layer[i+1]))
+		}
+		layers = append(layers, next)
+		layer = next
+	}
+
+	return &Tree{layers: layers}
+}
+
+func (t *Tree) Root() common.Hash {
+	return t.layers[len(t.layers)-1][0]
+}
+
+// Proof returns the siblings of the leaf at index from the bottom up.
+func (t *Tree) Proof(index int) []common.Hash {
+	proof := []common.Hash{}
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		sibling := index ^ 1
+		if sibling < len(layer) {
+			proof = append(proof, layer[sibling])
+		}
+		index /= 2
+	}
+	return proof
+}
+
+// Verify reports whether proof leads from leaf to root.
+func Verify(proof []common.Hash, root, leaf common.Hash) bool {
+	hash := leaf
+	for _, sibling := range proof {
+		hash = hashPair(hash, sibling)
+	}
+	return hash == root
+}
+
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
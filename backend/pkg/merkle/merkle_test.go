@@ -0,0 +1,39 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func leaves(n int) []common.Hash {
+	var hashes []common.Hash
+	for i := 0; i < n; i++ {
+		hashes = append(hashes, crypto.Keccak256Hash([]byte(fmt.Sprint(i))))
+	}
+	return hashes
+}
+
+func TestProofs(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 4, 5, 8, 13} {
+		l := leaves(n)
+		tree := New(l)
+		for i := range l {
+			require.True(t, Verify(tree.Proof(i), tree.Root(), l[i]), "%d leaves, leaf %d", n, i)
+		}
+
+		other := crypto.Keccak256Hash([]byte("not a leaf"))
+		require.False(t, Verify(tree.Proof(0), tree.Root(), other))
+	}
+}
+
+func TestRoot(t *testing.T) {
+	l := leaves(3)
+	require.Equal(t, l[0], New(l[:1]).Root())
+	require.Equal(t, hashPair(hashPair(l[0], l[1]), l[2]), New(l).Root())
+	require.Equal(t, hashPair(l[1], l[0]), hashPair(l[0], l[1]))
+	require.Empty(t, New(l[:1]).Proof(0))
+}
@@ -0,0 +1,16 @@
+contract MessageAnchor {
+    address public owner;
+    mapping(uint256 => bytes32) public roots;
+    event Anchored(uint256 indexed batchId, bytes32 root);
+
+    constructor() {
+        owner = msg.sender;
+    }
+
+    function anchor(uint256 batchId, bytes32 root) public {
+        require(msg.sender == owner, "Only the owner can anchor");
+        require(roots[batchId] == bytes32(0), "Batch is already anchored");
+        roots[batchId] = root;
+        emit Anchored(batchId, root);
+    }
+}
@@ -0,0 +1,88 @@
+interface IERC20 {
+    function transfer(address to, uint256 amount) external returns (bool);
+
+    function transferFrom(
+        address from,
+        address to,
+        uint256 amount
+    ) external returns (bool);
+}
+
+// MessageEscrow holds pay-to-message deposits. The operator refunds a deposit to the payer when
+// the recipient replies, the recipient can claim any deposit that wasn't refunded.
+contract MessageEscrow {
+    struct Deposit {
+        address payer;
+        address recipient;
+        // token is address(0) for ETH
+        address token;
+        uint256 amount;
+        bool settled;
+    }
+    address public operator;
+    Deposit[] public deposits;
+    event Deposited(
+        uint256 indexed id,
+        address indexed payer,
+        address indexed recipient,
+        address token,
+        uint256 amount
+    );
+    event Settled(uint256 indexed id, address to);
+
+    constructor() {
+        operator = msg.sender;
+    }
+
+    function deposit(address recipient) public payable returns (uint256) {
+        require(msg.value > 0, "Nothing to deposit");
+        return _deposit(recipient, address(0), msg.value);
+    }
+
+    function depositToken(
+        address recipient,
+        address token,
+        uint256 amount
+    ) public returns (uint256) {
+        require(token != address(0) && amount > 0, "Nothing to deposit");
+        require(
+            IERC20(token).transferFrom(msg.sender, address(this), amount),
+            "Transfer failed"
+        );
+        return _deposit(recipient, token, amount);
+    }
+
+    function refund(uint256 id) public {
+        require(msg.sender == operator, "Only the operator can refund");
+        _settle(id, deposits[id].payer);
+    }
+
+    function claim(uint256 id) public {
+        require(msg.sender == deposits[id].recipient, "Only the recipient can claim");
+        _settle(id, deposits[id].recipient);
+    }
+
+    function _deposit(
+        address recipient,
+        address token,
+        uint256 amount
+    ) internal returns (uint256) {
+        deposits.push(Deposit(msg.sender, recipient, token, amount, false));
+        uint256 id = deposits.length - 1;
+        emit Deposited(id, msg.sender, recipient, token, amount);
+        return id;
+    }
+
+    function _settle(uint256 id, address to) internal {
+        Deposit storage d = deposits[id];
+        require(!d.settled, "Deposit is already settled");
+        d.settled = true;
+        if (d.token == address(0)) {
+            (bool ok, ) = payable(to).call{value: d.amount}("");
+            require(ok, "Transfer failed");
+        } else {
+            require(IERC20(d.token).transfer(to, d.amount), "Transfer failed");
+        }
+        emit Settled(id, to);
+    }
+}
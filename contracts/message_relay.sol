@@ -0,0 +1,98 @@
+// RelayedMessaging is Messaging that also takes messages signed off-chain, so a relayer can pay
+// the gas for senders without ETH. Signatures are EIP-712 typed data. Nonces are unordered,
+// relayed messages may be mined in any order and a dropped one doesn't hold back the rest.
+contract RelayedMessaging {
+    struct Message {
+        address sender;
+        address receiver;
+        string content;
+    }
+    mapping(address => Message[]) public inbox;
+    mapping(address => mapping(address => Message[])) public dialogues;
+    event MessageSent(
+        address indexed sender,
+        address indexed receiver,
+        string content
+    );
+
+    bytes32 public constant SEND_MESSAGE_TYPEHASH =
+        keccak256(
+            "SendMessage(address receiver,string content,uint256 nonce,uint256 deadline)"
+        );
+    bytes32 public immutable DOMAIN_SEPARATOR;
+    mapping(address => mapping(uint256 => bool)) public usedNonces;
+
+    constructor() {
+        DOMAIN_SEPARATOR = keccak256(
+            abi.encode(
+                keccak256(
+                    "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+                ),
+                keccak256(bytes("BddMessaging")),
+                keccak256(bytes("1")),
+                block.chainid,
+                address(this)
+            )
+        );
+    }
+
+    function sendMessage(address _receiver, string memory _content) public {
+        _send(msg.sender, _receiver, _content);
+    }
+
+    function sendMessageBySig(
+        address sender,
+        address receiver,
+        string memory content,
+        uint256 nonce,
+        uint256 deadline,
+        uint8 v,
+        bytes32 r,
+        bytes32 s
+    ) public {
+        require(block.timestamp <= deadline, "Signature expired");
+        require(!usedNonces[sender][nonce], "Nonce already used");
+        bytes32 digest = keccak256(
+            abi.encodePacked(
+                "\x19\x01",
+                DOMAIN_SEPARATOR,
+                keccak256(
+                    abi.encode(
+                        SEND_MESSAGE_TYPEHASH,
+                        receiver,
+                        keccak256(bytes(content)),
+                        nonce,
+                        deadline
+                    )
+                )
+            )
+        );
+        address signer = ecrecover(digest, v, r, s);
+        require(signer != address(0) && signer == sender, "Invalid signature");
+        usedNonces[sender][nonce] = true;
+        _send(sender, receiver, content);
+    }
+
+    function getInbox(address user) public view returns (Message[] memory) {
+        return inbox[user];
+    }
+
+    function getDialogue(
+        address user1,
+        address user2
+    ) public view returns (Message[] memory) {
+        return dialogues[user1][user2];
+    }
+
+    function _send(
+        address sender,
+        address _receiver,
+        string memory _content
+    ) internal {
+        require(_receiver != sender, "Cannot send message to yourself");
+        Message memory newMessage = Message(sender, _receiver, _content);
+        inbox[_receiver].push(newMessage);
+        dialogues[sender][_receiver].push(newMessage);
+        emit MessageSent(sender, _receiver, _content);
+    }
+}
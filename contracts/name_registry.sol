@@ -0,0 +1,87 @@
+// NameRegistry is the part of the ENS registry names are resolved through: every node has an owner
+// and a resolver. Deploy it with a NameResolver to resolve names without the real ENS, e.g. in tests.
+contract NameRegistry {
+    struct Record {
+        address owner;
+        address resolver;
+    }
+    mapping(bytes32 => Record) records;
+    event NewOwner(bytes32 indexed node, bytes32 indexed label, address owner);
+    event Transfer(bytes32 indexed node, address owner);
+    event NewResolver(bytes32 indexed node, address resolver);
+
+    constructor() {
+        records[0x0].owner = msg.sender;
+    }
+
+    modifier authorised(bytes32 node) {
+        require(records[node].owner == msg.sender, "Only the node owner");
+        _;
+    }
+
+    function owner(bytes32 node) public view returns (address) {
+        return records[node].owner;
+    }
+
+    function resolver(bytes32 node) public view returns (address) {
+        return records[node].resolver;
+    }
+
+    function setOwner(bytes32 node, address _owner) public authorised(node) {
+        records[node].owner = _owner;
+        emit Transfer(node, _owner);
+    }
+
+    function setSubnodeOwner(
+        bytes32 node,
+        bytes32 label,
+        address _owner
+    ) public authorised(node) returns (bytes32) {
+        bytes32 subnode = keccak256(abi.encodePacked(node, label));
+        records[subnode].owner = _owner;
+        emit NewOwner(node, label, _owner);
+        return subnode;
+    }
+
+    function setResolver(bytes32 node, address _resolver) public authorised(node) {
+        records[node].resolver = _resolver;
+        emit NewResolver(node, _resolver);
+    }
+}
+
+// NameResolver answers addr(node) for forward and name(node) for reverse records, the owner
+// of a node in the registry sets them.
+contract NameResolver {
+    NameRegistry public registry;
+    mapping(bytes32 => address) addresses;
+    mapping(bytes32 => string) names;
+    event AddrChanged(bytes32 indexed node, address a);
+    event NameChanged(bytes32 indexed node, string name);
+
+    constructor(NameRegistry _registry) {
+        registry = _registry;
+    }
+
+    modifier authorised(bytes32 node) {
+        require(registry.owner(node) == msg.sender, "Only the node owner");
+        _;
+    }
+
+    function addr(bytes32 node) public view returns (address) {
+        return addresses[node];
+    }
+
+    function name(bytes32 node) public view returns (string memory) {
+        return names[node];
+    }
+
+    function setAddr(bytes32 node, address a) public authorised(node) {
+        addresses[node] = a;
+        emit AddrChanged(node, a);
+    }
+
+    function setName(bytes32 node, string memory _name) public authorised(node) {
+        names[node] = _name;
+        emit NameChanged(node, _name);
+    }
+}
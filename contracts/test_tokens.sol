@@ -0,0 +1,78 @@
+// TestToken is an ERC-20 anyone can mint, for tests that need a token to pay or gate with.
+contract TestToken {
+    mapping(address => uint256) public balanceOf;
+    mapping(address => mapping(address => uint256)) public allowance;
+    event Transfer(address indexed from, address indexed to, uint256 value);
+    event Approval(
+        address indexed owner,
+        address indexed spender,
+        uint256 value
+    );
+
+    function mint(address to, uint256 amount) public {
+        balanceOf[to] += amount;
+        emit Transfer(address(0), to, amount);
+    }
+
+    function transfer(address to, uint256 amount) public returns (bool) {
+        _transfer(msg.sender, to, amount);
+        return true;
+    }
+
+    function approve(address spender, uint256 amount) public returns (bool) {
+        allowance[msg.sender][spender] = amount;
+        emit Approval(msg.sender, spender, amount);
+        return true;
+    }
+
+    function transferFrom(
+        address from,
+        address to,
+        uint256 amount
+    ) public returns (bool) {
+        require(
+            allowance[from][msg.sender] >= amount,
+            "Insufficient allowance"
+        );
+        allowance[from][msg.sender] -= amount;
+        _transfer(from, to, amount);
+        return true;
+    }
+
+    function _transfer(address from, address to, uint256 amount) internal {
+        require(balanceOf[from] >= amount, "Insufficient balance");
+        balanceOf[from] -= amount;
+        balanceOf[to] += amount;
+        emit Transfer(from, to, amount);
+    }
+}
+
+// TestCollection is an ERC-721 anyone can mint, for tests. Only the owner moves a token,
+// there are no approvals.
+contract TestCollection {
+    mapping(uint256 => address) public ownerOf;
+    mapping(address => uint256) public balanceOf;
+    event Transfer(
+        address indexed from,
+        address indexed to,
+        uint256 indexed tokenId
+    );
+
+    function mint(address to, uint256 tokenId) public {
+        require(ownerOf[tokenId] == address(0), "Token already minted");
+        ownerOf[tokenId] = to;
+        balanceOf[to] += 1;
+        emit Transfer(address(0), to, tokenId);
+    }
+
+    function transferFrom(address from, address to, uint256 tokenId) public {
+        require(
+            ownerOf[tokenId] == from && msg.sender == from,
+            "Only the owner can transfer"
+        );
+        ownerOf[tokenId] = to;
+        balanceOf[from] -= 1;
+        balanceOf[to] += 1;
+        emit Transfer(from, to, tokenId);
+    }
+}